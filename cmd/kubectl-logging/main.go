@@ -0,0 +1,58 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// kubectl-logging is a kubectl plugin to inspect the configuration generated by the logging operator.
+//
+//	kubectl logging render --logging <name>                  renders the config of a logging in the current cluster
+//	kubectl logging render -f logging.yaml -f flows.yaml     renders the config from resources in files
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "render" {
+		fmt.Fprintln(os.Stderr, "usage: kubectl logging render [--logging <name>] [-f <file>]...")
+		os.Exit(2)
+	}
+
+	var opts renderOptions
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	flags.StringVar(&opts.loggingName, "logging", "", "Name of the Logging to render, can be omitted if there is only one")
+	flags.Var((*fileList)(&opts.files), "f", "File holding Logging, Flow, ClusterFlow, Output, ClusterOutput, Namespace and Secret resources, "+
+		"can be repeated. The resources are read from the current cluster if no file is given.")
+	flags.BoolVar(&opts.showSecrets, "show-secrets", true, "List the secrets mounted into the fluentd pods")
+	flags.BoolVar(&opts.showProblems, "show-problems", true, "List the problems found in the resources")
+	_ = flags.Parse(os.Args[2:])
+
+	if err := renderConfig(os.Stdout, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
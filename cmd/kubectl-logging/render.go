@@ -0,0 +1,119 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/render"
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
+)
+
+type renderOptions struct {
+	loggingName  string
+	files        []string
+	showSecrets  bool
+	showProblems bool
+}
+
+// renderConfig prints the fluentd configuration of the logging, followed by the mounted secrets and the problems
+// of the resources as comments, so that the output is still a valid configuration
+func renderConfig(out io.Writer, opts renderOptions) error {
+	ctx := context.Background()
+
+	c, err := newReader(opts.files)
+	if err != nil {
+		return err
+	}
+	logging, err := render.SelectLogging(ctx, c, opts.loggingName)
+	if err != nil {
+		return err
+	}
+
+	result, err := render.RenderWithClient(ctx, c, *logging)
+	if err != nil {
+		if result != nil {
+			printProblems(out, result.Problems)
+		}
+		return err
+	}
+	fmt.Fprint(out, result.Config)
+
+	if opts.showSecrets {
+		fmt.Fprintln(out, "\n# Mounted secrets:")
+		for _, s := range result.MountSecrets {
+			if s.Name == "" {
+				fmt.Fprintf(out, "#   %s -> %s/%s\n", s.Key, fluentd.OutputSecretPath, s.MappedKey)
+				continue
+			}
+			fmt.Fprintf(out, "#   %s/%s %s -> %s/%s\n", s.Namespace, s.Name, s.Key, fluentd.OutputSecretPath, s.MappedKey)
+		}
+	}
+	if opts.showProblems {
+		printProblems(out, result.Problems)
+	}
+	return nil
+}
+
+func printProblems(out io.Writer, problems []render.Problem) {
+	fmt.Fprintln(out, "\n# Problems:")
+	for _, p := range problems {
+		fmt.Fprintf(out, "#   %s\n", p)
+	}
+}
+
+// newReader returns a client of the current cluster, or a reader serving the resources of the files
+func newReader(files []string) (client.Reader, error) {
+	scheme := render.Scheme()
+	if len(files) == 0 {
+		config, err := ctrl.GetConfig()
+		if err != nil {
+			return nil, errors.WrapIf(err, "failed to load kubeconfig")
+		}
+		return client.New(config, client.Options{Scheme: scheme})
+	}
+
+	var objects []client.Object
+	for _, file := range files {
+		loaded, err := readObjects(scheme, file)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, loaded...)
+	}
+	return render.NewReader(objects...), nil
+}
+
+func readObjects(scheme *runtime.Scheme, file string) ([]client.Object, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to open file")
+	}
+	defer f.Close()
+
+	objects, err := render.DecodeObjects(scheme, f)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to read resources", "file", file)
+	}
+	return objects, nil
+}
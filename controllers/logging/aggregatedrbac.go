@@ -0,0 +1,82 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	"github.com/banzaicloud/operator-tools/pkg/utils"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// aggregatedClusterRoleName is the ClusterRole collecting the roles of the components with createRBAC: aggregated
+const aggregatedClusterRoleName = "logging-aggregated"
+
+// aggregatesRBAC tells whether any component of the logging labels its roles for aggregation
+func aggregatesRBAC(logging *loggingv1beta1.Logging) bool {
+	aggregated := func(s *loggingv1beta1.Security) bool {
+		return s != nil && s.CreateRBAC == loggingv1beta1.CreateRBACAggregated
+	}
+	if logging.Spec.FluentdSpec != nil && aggregated(logging.Spec.FluentdSpec.Security) {
+		return true
+	}
+	if logging.Spec.FluentbitSpec != nil && aggregated(logging.Spec.FluentbitSpec.Security) {
+		return true
+	}
+	for _, agent := range logging.Spec.NodeAgents {
+		if agent != nil && agent.FluentbitSpec != nil && aggregated(agent.FluentbitSpec.Security) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileAggregatedClusterRole maintains the ClusterRole the aggregation controller fills with the rules of the
+// labeled roles of the logging. It can be bound to audit or grant the permissions of all the components at once.
+func (r *LoggingReconciler) reconcileAggregatedClusterRole(c client.Client, logging *loggingv1beta1.Logging, opts reconciler.ReconcilerOpts) error {
+	role := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   logging.QualifiedName(aggregatedClusterRoleName),
+			Labels: loggingv1beta1.GenerateLoggingRefLabels(logging.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: logging.APIVersion,
+					Kind:       logging.Kind,
+					Name:       logging.Name,
+					UID:        logging.UID,
+					Controller: utils.BoolPointer(true),
+				},
+			},
+		},
+	}
+	state := reconciler.StateAbsent
+	if aggregatesRBAC(logging) {
+		role.AggregationRule = &rbacv1.AggregationRule{
+			ClusterRoleSelectors: []metav1.LabelSelector{
+				{
+					MatchLabels: utils.MergeLabels(loggingv1beta1.GenerateLoggingRefLabels(logging.Name),
+						map[string]string{loggingv1beta1.AggregateRBACLabel: "true"}),
+				},
+			},
+		}
+		state = reconciler.StatePresent
+	}
+	_, err := reconciler.NewGenericReconciler(c, r.Log, opts).ReconcileResource(role, state)
+	return errors.WrapIf(err, "failed to reconcile the aggregated cluster role")
+}
@@ -0,0 +1,73 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestReconcileAggregatedClusterRole(t *testing.T) {
+	logging := &loggingv1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: loggingv1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			FluentdSpec:      &loggingv1beta1.FluentdSpec{Security: &loggingv1beta1.Security{}},
+			NodeAgents: []*loggingv1beta1.NodeAgent{{
+				Name:          "agent",
+				FluentbitSpec: &loggingv1beta1.NodeAgentFluentbit{Security: &loggingv1beta1.Security{CreateRBAC: loggingv1beta1.CreateRBACAggregated}},
+			}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	r := &LoggingReconciler{Client: c, Log: logr.Discard()}
+	ctx := context.Background()
+	key := client.ObjectKey{Name: logging.QualifiedName(aggregatedClusterRoleName)}
+
+	if err := r.reconcileAggregatedClusterRole(c, logging, reconciler.ReconcilerOpts{}); err != nil {
+		t.Fatal(err)
+	}
+	var role rbacv1.ClusterRole
+	if err := c.Get(ctx, key, &role); err != nil {
+		t.Fatal(err)
+	}
+	want := &rbacv1.AggregationRule{ClusterRoleSelectors: []metav1.LabelSelector{{MatchLabels: map[string]string{
+		"app.kubernetes.io/managed-by":    "test",
+		loggingv1beta1.AggregateRBACLabel: "true",
+	}}}}
+	if !reflect.DeepEqual(role.AggregationRule, want) {
+		t.Errorf("aggregation rule = %+v, want %+v", role.AggregationRule, want)
+	}
+
+	logging.Spec.NodeAgents[0].FluentbitSpec.Security.CreateRBAC = loggingv1beta1.CreateRBACMinimal
+	if err := r.reconcileAggregatedClusterRole(c, logging, reconciler.ReconcilerOpts{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, key, &role); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the aggregated cluster role to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+const (
+	rolloutCheckInterval = 30 * time.Second
+	// rolloutBaselineAnnotation of a config history entry holds the output error metrics of the fluentd pods by pod
+	// and plugin when its configuration was applied, so that the rollout is watched across restarts of the operator
+	rolloutBaselineAnnotation = "logging.banzaicloud.io/rollout-baseline"
+)
+
+// outputErrorMetrics are summed up to tell the error rate of the outputs
+var outputErrorMetrics = []string{"fluentd_output_status_num_errors", "fluentd_output_status_retry_count"}
+
+// autoRollbackConfig returns the configuration and the mounted secrets applied by the auto rollback instead of the
+// rendered ones, as long as the rendered configuration is the rolled back one. The rollback is cleared once the
+// rendered configuration changes.
+func (r *LoggingReconciler) autoRollbackConfig(ctx context.Context, logging *loggingv1beta1.Logging, rendered string, renderedSecrets *secret.MountSecrets) (string, *secret.MountSecrets, error) {
+	rollback := logging.Status.AutoRollback
+	if rollback == nil {
+		return rendered, renderedSecrets, nil
+	}
+	if logging.Spec.AutoRollback != nil && rollback.FromConfigHash == configHash(rendered) {
+		history, err := r.configHistory(ctx, logging)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, entry := range history {
+			if entry.Annotations[configHistoryHashAnnotation] == rollback.ToConfigHash {
+				secrets, err := historySecrets(entry, renderedSecrets)
+				if err != nil {
+					return "", nil, err
+				}
+				return string(entry.Data[configHistoryConfigKey]), secrets, nil
+			}
+		}
+		return "", nil, errors.Errorf("configuration %.12s rolled back to not found in the config history", rollback.ToConfigHash)
+	}
+
+	patchBase := client.MergeFrom(logging.DeepCopy())
+	logging.Status.AutoRollback = nil
+	meta.SetStatusCondition(&logging.Status.Conditions, metav1.Condition{
+		Type:               loggingv1beta1.LoggingConditionRollbackPerformed,
+		Status:             metav1.ConditionFalse,
+		Reason:             "ConfigurationChanged",
+		Message:            "The rendered configuration changed since the rollback",
+		ObservedGeneration: logging.Generation,
+	})
+	if err := r.Client.Status().Patch(ctx, logging, patchBase); err != nil {
+		return "", nil, errors.WrapIfWithDetails(err, "failed to clear auto rollback", "logging", logging.Name)
+	}
+	return rendered, renderedSecrets, nil
+}
+
+// watchRollout watches the output errors after a new configuration is applied and rolls it back to the previous one
+// of the config history once they exceed the threshold within the window. The state of the rollout is kept in the
+// config history entry of the configuration: the errors are counted from the baseline recorded there when the
+// configuration is first checked.
+func (r *LoggingReconciler) watchRollout(ctx context.Context, logging *loggingv1beta1.Logging, config string) (*reconcile.Result, error) {
+	spec := logging.Spec.AutoRollback
+	if spec == nil || logging.Spec.RollbackTo != "" || logging.Status.AutoRollback != nil {
+		return nil, nil
+	}
+	if logging.Spec.FluentdSpec.Metrics == nil || logging.Spec.ConfigHistory == nil {
+		return nil, errors.New("autoRollback requires fluentd metrics and the config history")
+	}
+	window := time.Duration(spec.WindowSeconds) * time.Second
+	if window <= 0 {
+		window = loggingv1beta1.DefaultAutoRollbackWindowSeconds * time.Second
+	}
+	threshold := spec.ErrorThreshold
+	if threshold <= 0 {
+		threshold = loggingv1beta1.DefaultAutoRollbackErrorThreshold
+	}
+
+	history, err := r.configHistory(ctx, logging)
+	if err != nil {
+		return nil, err
+	}
+	hash := configHash(config)
+	if len(history) < 2 || history[0].Annotations[configHistoryHashAnnotation] != hash {
+		return nil, nil
+	}
+	entry := &history[0]
+	appliedAt, err := time.Parse(time.RFC3339Nano, entry.Annotations[configHistoryAppliedAtAnnotation])
+	if err != nil {
+		return nil, nil
+	}
+	remaining := time.Until(appliedAt.Add(window))
+	if remaining <= 0 {
+		return nil, nil
+	}
+	if remaining > rolloutCheckInterval {
+		remaining = rolloutCheckInterval
+	}
+
+	errs, err := r.outputErrors(ctx, logging)
+	if err != nil {
+		return nil, err
+	}
+	encoded, ok := entry.Annotations[rolloutBaselineAnnotation]
+	if !ok {
+		baseline, err := json.Marshal(errs)
+		if err != nil {
+			return nil, err
+		}
+		patchBase := client.MergeFrom(entry.DeepCopy())
+		entry.Annotations[rolloutBaselineAnnotation] = string(baseline)
+		if err := r.Client.Patch(ctx, entry, patchBase); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to record rollout baseline", "name", entry.Name)
+		}
+		return &reconcile.Result{RequeueAfter: remaining}, nil
+	}
+	var baseline map[string]float64
+	if err := json.Unmarshal([]byte(encoded), &baseline); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "invalid rollout baseline", "name", entry.Name)
+	}
+
+	increase := 0.0
+	for key, value := range errs {
+		// The counters start over when fluentd reloads the configuration
+		if base := baseline[key]; value >= base {
+			increase += value - base
+		} else {
+			increase += value
+		}
+	}
+	if increase > float64(threshold) {
+		previous := history[1].Annotations[configHistoryHashAnnotation]
+		return &reconcile.Result{Requeue: true}, r.rollback(ctx, logging, hash, previous, increase)
+	}
+	return &reconcile.Result{RequeueAfter: remaining}, nil
+}
+
+func (r *LoggingReconciler) rollback(ctx context.Context, logging *loggingv1beta1.Logging, from, to string, errs float64) error {
+	message := fmt.Sprintf("Configuration %.12s was rolled back to %.12s after %.0f output errors and retries", from, to, errs)
+	patchBase := client.MergeFrom(logging.DeepCopy())
+	logging.Status.AutoRollback = &loggingv1beta1.AutoRollbackStatus{
+		FromConfigHash: from,
+		ToConfigHash:   to,
+	}
+	meta.SetStatusCondition(&logging.Status.Conditions, metav1.Condition{
+		Type:               loggingv1beta1.LoggingConditionRollbackPerformed,
+		Status:             metav1.ConditionTrue,
+		Reason:             "OutputErrors",
+		Message:            message,
+		ObservedGeneration: logging.Generation,
+	})
+	if err := r.Client.Status().Patch(ctx, logging, patchBase); err != nil {
+		return errors.WrapIfWithDetails(err, "failed to record auto rollback", "logging", logging.Name)
+	}
+	if r.Recorder != nil {
+		r.Recorder.Event(logging, corev1.EventTypeWarning, "RollbackPerformed", message)
+	}
+	return nil
+}
+
+// outputErrors returns the output error metrics of the running fluentd pods by pod and plugin
+func (r *LoggingReconciler) outputErrors(ctx context.Context, logging *loggingv1beta1.Logging) (map[string]float64, error) {
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods, client.InNamespace(logging.Spec.ControlNamespace), client.MatchingLabels(logging.GetFluentdLabels(fluentd.ComponentFluentd))); err != nil {
+		return nil, errors.WrapIf(err, "failed to list fluentd pods")
+	}
+	errs := make(map[string]float64)
+	for _, pod := range pods.Items {
+		if pod.Status.PodIP == "" || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, metric := range outputErrorMetrics {
+			values, err := pluginMetric(pod.Status.PodIP, logging.Spec.FluentdSpec.Metrics, metric)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "failed to get output errors", "pod", pod.Name)
+			}
+			for plugin, value := range values {
+				errs[fmt.Sprintf("%s/%s/%s", pod.UID, plugin, metric)] = value
+			}
+		}
+	}
+	return errs, nil
+}
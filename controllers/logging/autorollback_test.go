@@ -0,0 +1,157 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestWatchRollout(t *testing.T) {
+	errorCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "fluentd_output_status_num_errors{plugin_id=\"out\"} %d\n", errorCount)
+		fmt.Fprintf(w, "fluentd_output_status_retry_count{plugin_id=\"out\"} 0\n")
+	}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logging := &loggingv1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: loggingv1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			FluentdSpec:      &loggingv1beta1.FluentdSpec{Metrics: &loggingv1beta1.Metrics{Port: int32(portNumber)}},
+			ConfigHistory:    &loggingv1beta1.ConfigHistory{},
+			AutoRollback:     &loggingv1beta1.AutoRollback{ErrorThreshold: 10, WindowSeconds: 300},
+		},
+	}
+	entry := func(config string, appliedAt time.Time) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fluentd-config-" + configHash(config)[:12],
+				Namespace: "logging",
+				Labels:    map[string]string{loggingv1beta1.ConfigHistoryLabel: logging.Name},
+				Annotations: map[string]string{
+					configHistoryHashAnnotation:      configHash(config),
+					configHistoryAppliedAtAnnotation: appliedAt.UTC().Format(time.RFC3339Nano),
+				},
+			},
+			Data: map[string][]byte{configHistoryConfigKey: []byte(config)},
+		}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "fluentd-0", Namespace: "logging", UID: "fluentd-0", Labels: logging.GetFluentdLabels(fluentd.ComponentFluentd)},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: host},
+	}
+	newReconciler := func(current *corev1.Secret) *LoggingReconciler {
+		scheme := runtime.NewScheme()
+		if err := clientgoscheme.AddToScheme(scheme); err != nil {
+			t.Fatal(err)
+		}
+		if err := loggingv1beta1.AddToScheme(scheme); err != nil {
+			t.Fatal(err)
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			logging.DeepCopy(),
+			pod.DeepCopy(),
+			entry("previous", time.Now().Add(-time.Hour)),
+			current,
+		).Build()
+		return &LoggingReconciler{Client: c}
+	}
+	ctx := context.Background()
+
+	t.Run("rollback once the errors exceed the threshold", func(t *testing.T) {
+		errorCount = 5
+		r := newReconciler(entry("current", time.Now()))
+		current := logging.DeepCopy()
+
+		result, err := r.watchRollout(ctx, current, "current")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result == nil || result.RequeueAfter == 0 {
+			t.Fatalf("expected the rollout to be watched, got %+v", result)
+		}
+		var stored corev1.Secret
+		if err := r.Client.Get(ctx, client.ObjectKey{Namespace: "logging", Name: "fluentd-config-" + configHash("current")[:12]}, &stored); err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"fluentd-0/out/fluentd_output_status_num_errors":5,"fluentd-0/out/fluentd_output_status_retry_count":0}`; stored.Annotations[rolloutBaselineAnnotation] != want {
+			t.Errorf("baseline = %s, want %s", stored.Annotations[rolloutBaselineAnnotation], want)
+		}
+
+		// A restarted operator continues from the stored baseline
+		errorCount = 15
+		r = &LoggingReconciler{Client: r.Client}
+		if _, err := r.watchRollout(ctx, current, "current"); err != nil {
+			t.Fatal(err)
+		}
+		if current.Status.AutoRollback != nil {
+			t.Fatalf("rolled back below the threshold: %+v", current.Status.AutoRollback)
+		}
+
+		errorCount = 16
+		result, err = r.watchRollout(ctx, current, "current")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result == nil || !result.Requeue {
+			t.Errorf("expected a requeue after the rollback, got %+v", result)
+		}
+		want := &loggingv1beta1.AutoRollbackStatus{FromConfigHash: configHash("current"), ToConfigHash: configHash("previous")}
+		if rollback := current.Status.AutoRollback; rollback == nil || *rollback != *want {
+			t.Errorf("auto rollback = %+v, want %+v", rollback, want)
+		}
+		if !meta.IsStatusConditionTrue(current.Status.Conditions, loggingv1beta1.LoggingConditionRollbackPerformed) {
+			t.Errorf("the rollback condition is not set: %+v", current.Status.Conditions)
+		}
+	})
+
+	t.Run("window passed", func(t *testing.T) {
+		errorCount = 100
+		r := newReconciler(entry("current", time.Now().Add(-time.Hour)))
+		current := logging.DeepCopy()
+
+		result, err := r.watchRollout(ctx, current, "current")
+		if err != nil || result != nil {
+			t.Errorf("expected the rollout not to be watched, got %+v, %v", result, err)
+		}
+	})
+}
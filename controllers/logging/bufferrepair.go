@@ -0,0 +1,82 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// reportBufferRepairs counts the buffer chunks quarantined by the buffer repair init container of the fluentd pods
+// and emits an event on the pods having any. Every pod is reported once.
+func (r *LoggingReconciler) reportBufferRepairs(ctx context.Context, logging *loggingv1beta1.Logging) error {
+	if logging.Spec.FluentdSpec == nil || logging.Spec.FluentdSpec.BufferVolumeRepair == nil || !logging.Spec.FluentdSpec.BufferVolumeRepair.Enabled {
+		r.forgetBufferRepairs(logging.Name)
+		return nil
+	}
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods,
+		client.InNamespace(logging.Spec.ControlNamespace),
+		client.MatchingLabels(logging.GetFluentdLabels(fluentd.ComponentFluentd))); err != nil {
+		return err
+	}
+
+	r.bufferRepairsMu.Lock()
+	defer r.bufferRepairsMu.Unlock()
+	if r.bufferRepairs == nil {
+		r.bufferRepairs = make(map[string]map[types.UID]bool)
+	}
+	reported := make(map[types.UID]bool)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if r.bufferRepairs[logging.Name][pod.UID] {
+			reported[pod.UID] = true
+			continue
+		}
+		for _, status := range pod.Status.InitContainerStatuses {
+			if status.Name != fluentd.BufferRepairContainerName || status.State.Terminated == nil {
+				continue
+			}
+			reported[pod.UID] = true
+			chunks, bytes, err := fluentd.ParseBufferRepairResult(status.State.Terminated.Message)
+			if err != nil || chunks == 0 {
+				continue
+			}
+			bufferQuarantinedChunks.With(prometheus.Labels{"logging": logging.Name}).Add(float64(chunks))
+			bufferQuarantinedBytes.With(prometheus.Labels{"logging": logging.Name}).Add(float64(bytes))
+			if r.Recorder != nil {
+				r.Recorder.Eventf(pod, corev1.EventTypeWarning, "BufferChunksQuarantined",
+					"%d corrupt buffer chunks (%d bytes) were moved into quarantine", chunks, bytes)
+			}
+		}
+	}
+	// Pods that are gone are forgotten
+	r.bufferRepairs[logging.Name] = reported
+	return nil
+}
+
+// forgetBufferRepairs drops the pods reported for a logging that is deleted or has the buffer repair disabled
+func (r *LoggingReconciler) forgetBufferRepairs(logging string) {
+	r.bufferRepairsMu.Lock()
+	defer r.bufferRepairsMu.Unlock()
+	delete(r.bufferRepairs, logging)
+}
@@ -0,0 +1,84 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestReportBufferRepairs(t *testing.T) {
+	logging := &loggingv1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: loggingv1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			FluentdSpec:      &loggingv1beta1.FluentdSpec{BufferVolumeRepair: &loggingv1beta1.BufferVolumeRepair{Enabled: true}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "fluentd-0", Namespace: "logging", UID: "fluentd-0", Labels: logging.GetFluentdLabels(fluentd.ComponentFluentd)},
+		Status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{{
+			Name:  fluentd.BufferRepairContainerName,
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "2 1024"}},
+		}}},
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := loggingv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	recorder := record.NewFakeRecorder(10)
+	r := &LoggingReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build(),
+		Log:      logr.Discard(),
+		Recorder: recorder,
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := r.reportBufferRepairs(ctx, logging); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected the pod to be reported once, got %d events", len(recorder.Events))
+	}
+	if !r.bufferRepairs[logging.Name][pod.UID] {
+		t.Errorf("the pod is not remembered: %v", r.bufferRepairs)
+	}
+
+	// The logging is not found anymore
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: logging.Name}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.bufferRepairs[logging.Name]; ok {
+		t.Errorf("the pods of the deleted logging are kept: %v", r.bufferRepairs)
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+const (
+	configHistoryHashAnnotation      = "logging.banzaicloud.io/config-history-hash"
+	configHistoryAppliedAtAnnotation = "logging.banzaicloud.io/config-history-applied-at"
+	configHistoryChangesAnnotation   = "logging.banzaicloud.io/config-history-changes"
+	configHistoryConfigKey           = "fluent.conf"
+	configHistoryResourcesKey        = "resources"
+	// configHistorySecretsKey holds the secrets mounted for the outputs of the configuration, they are applied along
+	// with it when the configuration is rolled back to
+	configHistorySecretsKey = "secrets"
+)
+
+// configHash identifies a fluentd configuration in the config history
+func configHash(config string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(config)))
+}
+
+// configHistory returns the config history entries of the logging. They are Secrets, as the configurations hold
+// the inlined output credentials.
+func (r *LoggingReconciler) configHistory(ctx context.Context, logging *loggingv1beta1.Logging) ([]corev1.Secret, error) {
+	var list corev1.SecretList
+	if err := r.Client.List(ctx, &list,
+		client.InNamespace(logging.Spec.ControlNamespace),
+		client.MatchingLabels{loggingv1beta1.ConfigHistoryLabel: logging.Name}); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to list config history", "logging", logging.Name)
+	}
+	// Most recently applied first, RFC 3339 timestamps of the same zone sort lexically
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Annotations[configHistoryAppliedAtAnnotation] > list.Items[j].Annotations[configHistoryAppliedAtAnnotation]
+	})
+	return list.Items, nil
+}
+
+// historySecrets returns the secrets mounted for the configuration of the config history entry. Entries recorded
+// without them fall back to the rendered secrets.
+func historySecrets(entry corev1.Secret, rendered *secret.MountSecrets) (*secret.MountSecrets, error) {
+	data, ok := entry.Data[configHistorySecretsKey]
+	if !ok {
+		return rendered, nil
+	}
+	secrets := secret.MountSecrets{}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to decode secrets of config history entry", "name", entry.Name)
+	}
+	return &secrets, nil
+}
+
+// rollbackConfig returns the configuration and the mounted secrets of the config history entry spec.rollbackTo
+// refers to
+func (r *LoggingReconciler) rollbackConfig(ctx context.Context, logging *loggingv1beta1.Logging, rendered *secret.MountSecrets) (string, *secret.MountSecrets, error) {
+	history, err := r.configHistory(ctx, logging)
+	if err != nil {
+		return "", nil, err
+	}
+	var found []corev1.Secret
+	for _, entry := range history {
+		if strings.HasPrefix(entry.Annotations[configHistoryHashAnnotation], logging.Spec.RollbackTo) {
+			found = append(found, entry)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", nil, errors.Errorf("configuration %s to roll back to not found in the config history", logging.Spec.RollbackTo)
+	case 1:
+		secrets, err := historySecrets(found[0], rendered)
+		if err != nil {
+			return "", nil, err
+		}
+		return string(found[0].Data[configHistoryConfigKey]), secrets, nil
+	default:
+		return "", nil, errors.Errorf("configuration %s to roll back to is ambiguous in the config history", logging.Spec.RollbackTo)
+	}
+}
+
+// recordConfigHistory records the applied configuration with its mounted secrets in the config history and in the
+// status of the logging. A configuration applied again is moved to the front of the history, the oldest entries
+// beyond the limit are removed.
+func (r *LoggingReconciler) recordConfigHistory(ctx context.Context, logging *loggingv1beta1.Logging, config string, secrets *secret.MountSecrets, resources model.LoggingResources) error {
+	hash := configHash(config)
+	if logging.Status.AppliedConfigHash != hash {
+		patchBase := client.MergeFrom(logging.DeepCopy())
+		logging.Status.AppliedConfigHash = hash
+		if err := r.Client.Status().Patch(ctx, logging, patchBase); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to update applied config hash", "logging", logging.Name)
+		}
+	}
+
+	history, err := r.configHistory(ctx, logging)
+	if err != nil {
+		return err
+	}
+	limit := 0
+	if logging.Spec.ConfigHistory != nil {
+		limit = loggingv1beta1.DefaultConfigHistoryLimit
+		if logging.Spec.ConfigHistory.Limit > 0 {
+			limit = logging.Spec.ConfigHistory.Limit
+		}
+	}
+	if limit > 0 && (len(history) == 0 || history[0].Annotations[configHistoryHashAnnotation] != hash) {
+		entry, err := r.recordConfig(ctx, logging, config, secrets, hash, history, resources)
+		if err != nil {
+			return err
+		}
+		kept := []corev1.Secret{*entry}
+		for _, e := range history {
+			if e.Name != entry.Name {
+				kept = append(kept, e)
+			}
+		}
+		history = kept
+	}
+	if len(history) > limit {
+		for i := range history[limit:] {
+			if err := client.IgnoreNotFound(r.Client.Delete(ctx, &history[limit+i])); err != nil {
+				return errors.WrapIfWithDetails(err, "failed to remove config history entry", "name", history[limit+i].Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *LoggingReconciler) recordConfig(ctx context.Context, logging *loggingv1beta1.Logging, config string, secrets *secret.MountSecrets, hash string, history []corev1.Secret, resources model.LoggingResources) (*corev1.Secret, error) {
+	changes := "initial configuration"
+	if len(history) > 0 {
+		added, removed := lineChanges(string(history[0].Data[configHistoryConfigKey]), config)
+		changes = fmt.Sprintf("+%d -%d lines since %.12s", added, removed, history[0].Annotations[configHistoryHashAnnotation])
+	}
+	mounted := secret.MountSecrets{}
+	if secrets != nil {
+		mounted = *secrets
+	}
+	secretsData, err := json.Marshal(mounted)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to encode mounted secrets")
+	}
+	annotations := map[string]string{
+		configHistoryHashAnnotation:      hash,
+		configHistoryAppliedAtAnnotation: time.Now().UTC().Format(time.RFC3339Nano),
+		configHistoryChangesAnnotation:   changes,
+	}
+
+	entry := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      logging.QualifiedName("fluentd-config-" + hash[:12]),
+			Namespace: logging.Spec.ControlNamespace,
+		},
+	}
+	err = r.Client.Get(ctx, client.ObjectKeyFromObject(entry), entry)
+	switch {
+	case err == nil:
+		// Applied again, only the annotations and the values of rotated secrets change
+		patchBase := client.MergeFrom(entry.DeepCopy())
+		if entry.Annotations == nil {
+			entry.Annotations = make(map[string]string)
+		}
+		for k, v := range annotations {
+			entry.Annotations[k] = v
+		}
+		if entry.Data == nil {
+			entry.Data = make(map[string][]byte)
+		}
+		entry.Data[configHistorySecretsKey] = secretsData
+		// The rollout of the configuration is watched again from a new baseline
+		delete(entry.Annotations, rolloutBaselineAnnotation)
+		if err := r.Client.Patch(ctx, entry, patchBase); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to update config history entry", "name", entry.Name)
+		}
+	case apierrors.IsNotFound(err):
+		entry.Labels = map[string]string{loggingv1beta1.ConfigHistoryLabel: logging.Name}
+		entry.Annotations = annotations
+		entry.OwnerReferences = []metav1.OwnerReference{
+			*metav1.NewControllerRef(logging, loggingv1beta1.GroupVersion.WithKind("Logging")),
+		}
+		entry.Data = map[string][]byte{
+			configHistoryConfigKey:    []byte(config),
+			configHistoryResourcesKey: []byte(resourceVersions(resources)),
+			configHistorySecretsKey:   secretsData,
+		}
+		if err := r.Client.Create(ctx, entry); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to create config history entry", "name", entry.Name)
+		}
+	default:
+		return nil, errors.WrapIfWithDetails(err, "failed to get config history entry", "name", entry.Name)
+	}
+	return entry, nil
+}
+
+// lineChanges counts the lines added and removed by the change, regardless of their order
+func lineChanges(from, to string) (added, removed int) {
+	lines := make(map[string]int)
+	for _, l := range strings.Split(from, "\n") {
+		lines[l]++
+	}
+	for _, l := range strings.Split(to, "\n") {
+		if lines[l] > 0 {
+			lines[l]--
+		} else {
+			added++
+		}
+	}
+	for _, n := range lines {
+		removed += n
+	}
+	return
+}
+
+// resourceVersions lists the resources the configuration was rendered from with their versions
+func resourceVersions(resources model.LoggingResources) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Logging %s %s\n", resources.Logging.Name, resources.Logging.ResourceVersion)
+	for _, o := range resources.ClusterFlows {
+		fmt.Fprintf(&b, "ClusterFlow %s/%s %s\n", o.Namespace, o.Name, o.ResourceVersion)
+	}
+	for _, o := range resources.ClusterOutputs {
+		fmt.Fprintf(&b, "ClusterOutput %s/%s %s\n", o.Namespace, o.Name, o.ResourceVersion)
+	}
+	for _, o := range resources.Flows {
+		fmt.Fprintf(&b, "Flow %s/%s %s\n", o.Namespace, o.Name, o.ResourceVersion)
+	}
+	for _, o := range resources.Outputs {
+		fmt.Fprintf(&b, "Output %s/%s %s\n", o.Namespace, o.Name, o.ResourceVersion)
+	}
+	return b.String()
+}
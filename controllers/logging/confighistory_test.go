@@ -0,0 +1,108 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestRollbackRestoresSecrets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := loggingv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	logging := &loggingv1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: loggingv1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			ConfigHistory:    &loggingv1beta1.ConfigHistory{},
+			AutoRollback:     &loggingv1beta1.AutoRollback{},
+		},
+	}
+	// An entry recorded before the secrets were kept in the config history
+	legacy := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      logging.QualifiedName("fluentd-config-" + configHash("legacy")[:12]),
+			Namespace: "logging",
+			Labels:    map[string]string{loggingv1beta1.ConfigHistoryLabel: logging.Name},
+			Annotations: map[string]string{
+				configHistoryHashAnnotation:      configHash("legacy"),
+				configHistoryAppliedAtAnnotation: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano),
+			},
+		},
+		Data: map[string][]byte{configHistoryConfigKey: []byte("legacy")},
+	}
+	r := &LoggingReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(logging.DeepCopy(), legacy).Build()}
+	ctx := context.Background()
+
+	previousSecrets := &secret.MountSecrets{{Namespace: "app", Name: "old", Key: "password", MappedKey: "app-old-password", Value: []byte("old")}}
+	if err := r.recordConfigHistory(ctx, logging, "previous", previousSecrets, model.LoggingResources{Logging: *logging}); err != nil {
+		t.Fatal(err)
+	}
+	currentSecrets := &secret.MountSecrets{{Namespace: "app", Name: "new", Key: "password", MappedKey: "app-new-password", Value: []byte("new")}}
+	if err := r.recordConfigHistory(ctx, logging, "current", currentSecrets, model.LoggingResources{Logging: *logging}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		rollbackTo  string
+		wantConfig  string
+		wantSecrets *secret.MountSecrets
+	}{
+		{name: "rolled back", rollbackTo: configHash("previous")[:12], wantConfig: "previous", wantSecrets: previousSecrets},
+		{name: "recorded without secrets", rollbackTo: configHash("legacy")[:12], wantConfig: "legacy", wantSecrets: currentSecrets},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			l := logging.DeepCopy()
+			l.Spec.RollbackTo = tt.rollbackTo
+			config, secrets, err := r.rollbackConfig(ctx, l, currentSecrets)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config != tt.wantConfig || !reflect.DeepEqual(secrets, tt.wantSecrets) {
+				t.Errorf("rollbackConfig() = %q, %+v, want %q, %+v", config, secrets, tt.wantConfig, tt.wantSecrets)
+			}
+
+			l = logging.DeepCopy()
+			l.Status.AutoRollback = &loggingv1beta1.AutoRollbackStatus{FromConfigHash: configHash("current"), ToConfigHash: configHash(tt.wantConfig)}
+			config, secrets, err = r.autoRollbackConfig(ctx, l, "current", currentSecrets)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config != tt.wantConfig || !reflect.DeepEqual(secrets, tt.wantSecrets) {
+				t.Errorf("autoRollbackConfig() = %q, %+v, want %q, %+v", config, secrets, tt.wantConfig, tt.wantSecrets)
+			}
+		})
+	}
+}
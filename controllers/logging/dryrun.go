@@ -0,0 +1,68 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	"github.com/banzaicloud/operator-tools/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+const (
+	dryRunSecretName = "dry-run"
+	dryRunConfigKey  = "fluentd.conf"
+	dryRunChangesKey = "changes.yaml"
+)
+
+// reconcileDryRunResult writes the rendered configuration and the recorded changes into the dry-run Secret,
+// or removes the Secret if the logging is not in dry-run mode. The configuration holds the inlined output
+// credentials, hence it is not stored in a ConfigMap.
+func (r *LoggingReconciler) reconcileDryRunResult(logging *loggingv1beta1.Logging, opts reconciler.ReconcilerOpts, config string, dryRunClient *resources.DryRunClient) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      logging.QualifiedName(dryRunSecretName),
+			Namespace: logging.Spec.ControlNamespace,
+			Labels:    loggingv1beta1.GenerateLoggingRefLabels(logging.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: logging.APIVersion,
+					Kind:       logging.Kind,
+					Name:       logging.Name,
+					UID:        logging.UID,
+					Controller: utils.BoolPointer(true),
+				},
+			},
+		},
+	}
+	state := reconciler.StateAbsent
+	if dryRunClient != nil {
+		changes, err := dryRunClient.Changes()
+		if err != nil {
+			return errors.WrapIf(err, "failed to collect dry-run changes")
+		}
+		secret.Data = map[string][]byte{
+			dryRunConfigKey:  []byte(config),
+			dryRunChangesKey: []byte(changes),
+		}
+		state = reconciler.StatePresent
+	}
+	_, err := reconciler.NewGenericReconciler(r.Client, r.Log, opts).ReconcileResource(secret, state)
+	return errors.WrapIf(err, "failed to reconcile dry-run result")
+}
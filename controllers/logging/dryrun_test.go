@@ -0,0 +1,172 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+)
+
+func TestReconcileDryRun(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := loggingv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	logging := &loggingv1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: loggingv1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			FluentdSpec:      &loggingv1beta1.FluentdSpec{},
+			DryRun:           true,
+		},
+	}
+	objects := []client.Object{
+		logging,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "logging"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app"}},
+		&loggingv1beta1.Flow{
+			ObjectMeta: metav1.ObjectMeta{Name: "flow", Namespace: "app"},
+			Spec:       loggingv1beta1.FlowSpec{LocalOutputRefs: []string{"null"}},
+		},
+		&loggingv1beta1.Output{
+			ObjectMeta: metav1.ObjectMeta{Name: "null", Namespace: "app"},
+			Spec:       loggingv1beta1.OutputSpec{NullOutputConfig: output.NewNullOutputConfig()},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &LoggingReconciler{Client: c, Log: logr.Discard(), Recorder: recorder}
+	ctx := context.Background()
+
+	versions := make(map[string]string)
+	key := func(o client.Object) string {
+		return strings.Join([]string{o.GetObjectKind().GroupVersionKind().Kind, o.GetNamespace(), o.GetName()}, "/")
+	}
+	snapshot := func() map[string]string {
+		current := make(map[string]string)
+		lists := []client.ObjectList{
+			&loggingv1beta1.LoggingList{}, &loggingv1beta1.FlowList{}, &loggingv1beta1.OutputList{},
+			&corev1.NamespaceList{}, &corev1.SecretList{}, &corev1.ConfigMapList{}, &corev1.ServiceList{},
+		}
+		for _, list := range lists {
+			if err := c.List(ctx, list); err != nil {
+				t.Fatal(err)
+			}
+			for _, o := range metaItems(list) {
+				current[key(o)] = o.GetResourceVersion()
+			}
+		}
+		return current
+	}
+	for k, v := range snapshot() {
+		versions[k] = v
+	}
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: logging.Name}}); err != nil {
+		t.Fatal(err)
+	}
+
+	dryRunSecret := key(&corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "logging", Name: logging.QualifiedName(dryRunSecretName)},
+	})
+	after := snapshot()
+	for k, v := range after {
+		if k == dryRunSecret {
+			continue
+		}
+		if versions[k] != v {
+			t.Errorf("%s was written in dry-run mode", k)
+		}
+	}
+	if _, ok := after[dryRunSecret]; !ok {
+		t.Fatalf("the dry-run secret is not created, found %v", after)
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("unexpected events in dry-run mode: %d", len(recorder.Events))
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "logging", Name: logging.QualifiedName(dryRunSecretName)}, secret); err != nil {
+		t.Fatal(err)
+	}
+	changes := string(secret.Data[dryRunChangesKey])
+	for _, want := range []string{"# update status Flow/app/flow/status", "# update status Output/app/null/status"} {
+		if !strings.Contains(changes, want) {
+			t.Errorf("the dry-run changes do not contain %q:\n%s", want, changes)
+		}
+	}
+}
+
+// metaItems returns the items of the list with their kind set, the fake client leaves it empty
+func metaItems(list client.ObjectList) []client.Object {
+	var items []client.Object
+	switch l := list.(type) {
+	case *loggingv1beta1.LoggingList:
+		for i := range l.Items {
+			l.Items[i].Kind = "Logging"
+			items = append(items, &l.Items[i])
+		}
+	case *loggingv1beta1.FlowList:
+		for i := range l.Items {
+			l.Items[i].Kind = "Flow"
+			items = append(items, &l.Items[i])
+		}
+	case *loggingv1beta1.OutputList:
+		for i := range l.Items {
+			l.Items[i].Kind = "Output"
+			items = append(items, &l.Items[i])
+		}
+	case *corev1.NamespaceList:
+		for i := range l.Items {
+			l.Items[i].Kind = "Namespace"
+			items = append(items, &l.Items[i])
+		}
+	case *corev1.SecretList:
+		for i := range l.Items {
+			l.Items[i].Kind = "Secret"
+			items = append(items, &l.Items[i])
+		}
+	case *corev1.ConfigMapList:
+		for i := range l.Items {
+			l.Items[i].Kind = "ConfigMap"
+			items = append(items, &l.Items[i])
+		}
+	case *corev1.ServiceList:
+		for i := range l.Items {
+			l.Items[i].Kind = "Service"
+			items = append(items, &l.Items[i])
+		}
+	}
+	return items
+}
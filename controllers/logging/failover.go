@@ -0,0 +1,192 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+const (
+	failoverCheckInterval = time.Minute
+	// retryWaitMetric is positive while an output waits for the next retry of a failed flush
+	retryWaitMetric = "fluentd_output_status_retry_wait"
+)
+
+// failoverChain is an output with failover or dead letter outputs, the names of the outputs in the chain and the
+// plugin ids rendered for each of them by the flows using the output
+type failoverChain struct {
+	object    client.Object
+	status    *loggingv1beta1.OutputStatus
+	legs      []string
+	pluginIDs [][]string
+}
+
+// activeOutput is the first output of the chain not retrying in any of the fluentd pods, the last one when all of
+// them are retrying, as the events end up there
+func (c failoverChain) activeOutput(retrying map[string]bool) string {
+	for i, ids := range c.pluginIDs {
+		active := true
+		for _, id := range ids {
+			if retrying[id] {
+				active = false
+				break
+			}
+		}
+		if active {
+			return c.legs[i]
+		}
+	}
+	return c.legs[len(c.legs)-1]
+}
+
+// newFailoverChain returns the chain of the output for the output plugins rendered for it
+func newFailoverChain(object client.Object, status *loggingv1beta1.OutputStatus, spec loggingv1beta1.OutputSpec, outputIDs []string) *failoverChain {
+	if len(outputIDs) == 0 || (len(spec.Failover) == 0 && spec.DeadLetter == "") {
+		return nil
+	}
+	chain := &failoverChain{object: object, status: status, legs: []string{object.GetName()}, pluginIDs: [][]string{outputIDs}}
+	for i, ref := range spec.Failover {
+		var ids []string
+		for _, id := range outputIDs {
+			ids = append(ids, model.FailoverPluginID(id, i, ref))
+		}
+		chain.legs = append(chain.legs, ref)
+		chain.pluginIDs = append(chain.pluginIDs, ids)
+	}
+	if spec.DeadLetter != "" {
+		var ids []string
+		for _, id := range outputIDs {
+			ids = append(ids, model.DeadLetterPluginID(id, spec.DeadLetter))
+		}
+		chain.legs = append(chain.legs, spec.DeadLetter)
+		chain.pluginIDs = append(chain.pluginIDs, ids)
+	}
+	return chain
+}
+
+// failoverChains returns the outputs with failover or dead letter outputs used by the flows, and the other outputs,
+// see model.FlowForFlow and model.FlowForClusterFlow for the ids of the output plugins
+func failoverChains(resources model.LoggingResources) (chains []failoverChain, others []failoverChain) {
+	clusterOutputIDs := make(map[string][]string)
+	outputIDs := make(map[string][]string)
+	addClusterOutputs := func(flowID string, refs []string) {
+		for _, ref := range refs {
+			if output := resources.ClusterOutputs.FindByName(ref); output != nil {
+				clusterOutputIDs[ref] = append(clusterOutputIDs[ref], model.ClusterOutputPluginID(flowID, *output))
+			}
+		}
+	}
+	for _, flow := range resources.Flows {
+		flowID := model.FlowID(flow)
+		addClusterOutputs(flowID, flow.Spec.GlobalOutputRefs)
+		for _, ref := range flow.Spec.LocalOutputRefs {
+			if output := resources.Outputs.FindByNamespacedName(flow.Namespace, ref); output != nil {
+				key := flow.Namespace + "/" + ref
+				outputIDs[key] = append(outputIDs[key], model.OutputPluginID(flowID, *output))
+			}
+		}
+	}
+	for _, flow := range resources.ClusterFlows {
+		addClusterOutputs(model.ClusterFlowID(flow), flow.Spec.GlobalOutputRefs)
+	}
+	if resources.Logging.Spec.DefaultFlowSpec != nil {
+		addClusterOutputs(model.DefaultFlowID(resources.Logging), resources.Logging.Spec.DefaultFlowSpec.GlobalOutputRefs)
+	}
+
+	add := func(object client.Object, status *loggingv1beta1.OutputStatus, spec loggingv1beta1.OutputSpec, ids []string) {
+		if chain := newFailoverChain(object, status, spec, ids); chain != nil {
+			chains = append(chains, *chain)
+		} else {
+			others = append(others, failoverChain{object: object, status: status})
+		}
+	}
+	for i := range resources.ClusterOutputs {
+		o := &resources.ClusterOutputs[i]
+		add(o, &o.Status, o.Spec.OutputSpec, clusterOutputIDs[o.Name])
+	}
+	for i := range resources.Outputs {
+		o := &resources.Outputs[i]
+		add(o, &o.Status, o.Spec, outputIDs[o.Namespace+"/"+o.Name])
+	}
+	return chains, others
+}
+
+// updateFailoverStatus reports the active output of the failover chains in the status of the outputs, based on the
+// retry state of the output plugins in the fluentd metrics. The outputs are checked periodically while any of them
+// has a failover chain.
+func (r *LoggingReconciler) updateFailoverStatus(ctx context.Context, logging *loggingv1beta1.Logging, resources model.LoggingResources) (*reconcile.Result, error) {
+	chains, others := failoverChains(resources)
+	if logging.Spec.FluentdSpec == nil || logging.Spec.FluentdSpec.Metrics == nil {
+		others = append(others, chains...)
+		chains = nil
+	}
+	// The active output of removed chains is not known anymore
+	for _, output := range others {
+		if err := r.setActiveOutput(ctx, output, ""); err != nil {
+			return nil, err
+		}
+	}
+	if len(chains) == 0 {
+		return nil, nil
+	}
+
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods, client.InNamespace(logging.Spec.ControlNamespace), client.MatchingLabels(logging.GetFluentdLabels(fluentd.ComponentFluentd))); err != nil {
+		return nil, errors.WrapIf(err, "failed to list fluentd pods")
+	}
+	retrying := make(map[string]bool)
+	for _, pod := range pods.Items {
+		if pod.Status.PodIP == "" || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		values, err := pluginMetric(pod.Status.PodIP, logging.Spec.FluentdSpec.Metrics, retryWaitMetric)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to get output retries", "pod", pod.Name)
+		}
+		for plugin, value := range values {
+			if value > 0 {
+				retrying[plugin] = true
+			}
+		}
+	}
+	for _, chain := range chains {
+		if err := r.setActiveOutput(ctx, chain, chain.activeOutput(retrying)); err != nil {
+			return nil, err
+		}
+	}
+	return &reconcile.Result{RequeueAfter: failoverCheckInterval}, nil
+}
+
+func (r *LoggingReconciler) setActiveOutput(ctx context.Context, output failoverChain, active string) error {
+	if output.status.ActiveOutput == active {
+		return nil
+	}
+	patch := client.MergeFrom(output.object.DeepCopyObject().(client.Object))
+	output.status.ActiveOutput = active
+	if err := r.Client.Status().Patch(ctx, output.object, patch); err != nil {
+		return errors.WrapIfWithDetails(err, "failed to update active failover output", "name", output.object.GetName(), "namespace", output.object.GetNamespace())
+	}
+	return nil
+}
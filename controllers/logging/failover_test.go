@@ -0,0 +1,143 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestUpdateFailoverStatus(t *testing.T) {
+	var retrying []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, id := range retrying {
+			fmt.Fprintf(w, "fluentd_output_status_retry_wait{plugin_id=%q} 4.5\n", id)
+		}
+	}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logging := &loggingv1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: loggingv1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			FluentdSpec:      &loggingv1beta1.FluentdSpec{Metrics: &loggingv1beta1.Metrics{Port: int32(portNumber)}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "fluentd-0", Namespace: "logging", Labels: logging.GetFluentdLabels(fluentd.ComponentFluentd)},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: host},
+	}
+	flow := loggingv1beta1.Flow{
+		ObjectMeta: metav1.ObjectMeta{Name: "flow", Namespace: "app"},
+		Spec:       loggingv1beta1.FlowSpec{LocalOutputRefs: []string{"primary", "plain"}},
+	}
+	outputs := []loggingv1beta1.Output{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "primary", Namespace: "app"},
+			Spec:       loggingv1beta1.OutputSpec{Failover: []string{"secondary"}, DeadLetter: "dlq"},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "secondary", Namespace: "app"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "dlq", Namespace: "app"}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "app"},
+			Status:     loggingv1beta1.OutputStatus{ActiveOutput: "removed"},
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := loggingv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod)
+	for i := range outputs {
+		builder = builder.WithObjects(outputs[i].DeepCopy())
+	}
+	r := &LoggingReconciler{Client: builder.Build()}
+	ctx := context.Background()
+
+	primaryID := model.OutputPluginID(model.FlowID(flow), outputs[0])
+	secondaryID := model.FailoverPluginID(primaryID, 0, "secondary")
+	dlqID := model.DeadLetterPluginID(primaryID, "dlq")
+	tests := []struct {
+		name     string
+		retrying []string
+		want     string
+	}{
+		{name: "primary", want: "primary"},
+		{name: "primary retrying", retrying: []string{primaryID}, want: "secondary"},
+		{name: "failover retrying", retrying: []string{primaryID, secondaryID}, want: "dlq"},
+		{name: "all retrying", retrying: []string{primaryID, secondaryID, dlqID}, want: "dlq"},
+		{name: "primary recovered", retrying: []string{secondaryID}, want: "primary"},
+	}
+	for _, tt := range tests {
+		retrying = tt.retrying
+		resources := model.LoggingResources{Logging: *logging, Flows: []loggingv1beta1.Flow{flow}}
+		for _, o := range outputs {
+			current := &loggingv1beta1.Output{}
+			if err := r.Client.Get(ctx, types.NamespacedName{Namespace: o.Namespace, Name: o.Name}, current); err != nil {
+				t.Fatal(err)
+			}
+			resources.Outputs = append(resources.Outputs, *current)
+		}
+
+		result, err := r.updateFailoverStatus(ctx, logging, resources)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if result == nil || result.RequeueAfter == 0 {
+			t.Errorf("%s: expected the failover to be checked periodically, got %+v", tt.name, result)
+		}
+		primary := &loggingv1beta1.Output{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: "app", Name: "primary"}, primary); err != nil {
+			t.Fatal(err)
+		}
+		if primary.Status.ActiveOutput != tt.want {
+			t.Errorf("%s: active output = %q, want %q", tt.name, primary.Status.ActiveOutput, tt.want)
+		}
+		plain := &loggingv1beta1.Output{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: "app", Name: "plain"}, plain); err != nil {
+			t.Fatal(err)
+		}
+		if plain.Status.ActiveOutput != "" {
+			t.Errorf("%s: unexpected active output of an output without failover: %q", tt.name, plain.Status.ActiveOutput)
+		}
+	}
+}
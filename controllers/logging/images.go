@@ -0,0 +1,45 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"strings"
+
+	"emperror.dev/errors"
+
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// verifyImages refuses to render the logging if it requires image digests and an image it deploys is not pinned
+func verifyImages(logging *loggingv1beta1.Logging) error {
+	if !logging.Spec.RequireImageDigests {
+		return nil
+	}
+	// Node agents without an image run the default of the operator, which is not pinned
+	for _, agent := range logging.Spec.NodeAgents {
+		if agent != nil && (agent.FluentbitSpec == nil || agent.FluentbitSpec.Image.Repository == "") {
+			return errors.Errorf("node agent %s has to set its image pinned by digest", agent.Name)
+		}
+	}
+	if fluentbit := logging.Spec.FluentbitSpec; fluentbit != nil && fluentbit.Windows != nil && fluentbit.Windows.Image.Repository == "" {
+		return errors.New("the windows fluent-bit has to set its image pinned by digest")
+	}
+	for _, image := range logging.Images() {
+		if !strings.Contains(image, "@") {
+			return errors.Errorf("image %s is not pinned by digest", image)
+		}
+	}
+	return nil
+}
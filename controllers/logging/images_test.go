@@ -0,0 +1,152 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/typeoverride"
+	"github.com/banzaicloud/operator-tools/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestVerifyImages(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	pinned := func(repository string) loggingv1beta1.ImageSpec {
+		return loggingv1beta1.ImageSpec{Repository: repository, Tag: "ignored", Digest: digest}
+	}
+	pinnedLogging := func() *loggingv1beta1.Logging {
+		return &loggingv1beta1.Logging{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec: loggingv1beta1.LoggingSpec{
+				RequireImageDigests: true,
+				FluentdSpec: &loggingv1beta1.FluentdSpec{
+					Image:               pinned("fluentd"),
+					ConfigReloaderImage: pinned("reloader"),
+					VolumeModImage:      pinned("busybox"),
+					BufferVolumeImage:   pinned("exporter"),
+				},
+				FluentbitSpec: &loggingv1beta1.FluentbitSpec{Image: pinned("fluent-bit")},
+			},
+		}
+	}
+	tests := []struct {
+		name    string
+		modify  func(*loggingv1beta1.Logging)
+		wantErr bool
+	}{
+		{
+			name:   "pinned",
+			modify: func(*loggingv1beta1.Logging) {},
+		},
+		{
+			name: "not required",
+			modify: func(l *loggingv1beta1.Logging) {
+				l.Spec.RequireImageDigests = false
+				l.Spec.FluentdSpec.Image.Digest = ""
+			},
+		},
+		{
+			name: "tagged fluentd",
+			modify: func(l *loggingv1beta1.Logging) {
+				l.Spec.FluentdSpec.Image.Digest = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "extra container",
+			modify: func(l *loggingv1beta1.Logging) {
+				l.Spec.FluentdSpec.ExtraContainers = []corev1.Container{{Name: "extra", Image: "busybox:1.36"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "extra init container",
+			modify: func(l *loggingv1beta1.Logging) {
+				l.Spec.FluentbitSpec.ExtraInitContainers = []corev1.Container{{Name: "extra", Image: "busybox@" + digest}, {Name: "unpinned", Image: "busybox"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "daemonset override",
+			modify: func(l *loggingv1beta1.Logging) {
+				l.Spec.FluentbitSpec.DaemonSetOverrides = &typeoverride.DaemonSet{Spec: typeoverride.DaemonSetSpec{Template: typeoverride.PodTemplateSpec{
+					Spec: typeoverride.PodSpec{Containers: []corev1.Container{{Name: "fluent-bit", Image: "fluent/fluent-bit:1.9.5"}}},
+				}}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "default event tailer",
+			modify: func(l *loggingv1beta1.Logging) {
+				l.Spec.EventTailer = &loggingv1beta1.EventTailer{}
+			},
+			wantErr: true,
+		},
+		{
+			name: "pinned event tailer",
+			modify: func(l *loggingv1beta1.Logging) {
+				l.Spec.EventTailer = &loggingv1beta1.EventTailer{ContainerBase: &types.ContainerBase{Image: "banzaicloud/eventrouter@" + digest}}
+			},
+		},
+		{
+			name: "node agent without image",
+			modify: func(l *loggingv1beta1.Logging) {
+				l.Spec.NodeAgents = []*loggingv1beta1.NodeAgent{{Name: "agent", FluentbitSpec: &loggingv1beta1.NodeAgentFluentbit{}}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "pinned node agent",
+			modify: func(l *loggingv1beta1.Logging) {
+				l.Spec.NodeAgents = []*loggingv1beta1.NodeAgent{{Name: "agent", FluentbitSpec: &loggingv1beta1.NodeAgentFluentbit{Image: pinned("fluent-bit")}}}
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			logging := pinnedLogging()
+			tt.modify(logging)
+			if err := verifyImages(logging); (err != nil) != tt.wantErr {
+				t.Errorf("verifyImages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpiffeRestartImageDefault(t *testing.T) {
+	logging := &loggingv1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: loggingv1beta1.LoggingSpec{
+			FluentdSpec: &loggingv1beta1.FluentdSpec{
+				TLS: loggingv1beta1.FluentdTLS{Enabled: true, Spiffe: &loggingv1beta1.SpiffeTLS{}},
+			},
+			FluentbitSpec: &loggingv1beta1.FluentbitSpec{},
+		},
+	}
+	if err := logging.SetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	for _, image := range logging.Images() {
+		if image == "busybox:1.36" {
+			return
+		}
+	}
+	t.Errorf("the pinned restart image is not listed in %v", logging.Images())
+}
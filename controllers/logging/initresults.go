@@ -0,0 +1,78 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentbit"
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
+)
+
+// initResultContainers report their results in the termination message, see reportBufferRepairs and
+// reportPositionDBRecoveries
+var initResultContainers = map[string]bool{
+	fluentd.BufferRepairContainerName:         true,
+	fluentbit.PositionDBRecoveryContainerName: true,
+}
+
+func terminatedInitResults(pod *corev1.Pod) (terminated int) {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if initResultContainers[status.Name] && status.State.Terminated != nil {
+			terminated++
+		}
+	}
+	return
+}
+
+// initResultsPredicate passes the pods once an init container reporting its result terminates. The pods of the
+// daemonsets and statefulsets are not owned by the logging directly, so their changes do not trigger a reconcile
+// otherwise and the results would wait for an unrelated one.
+func initResultsPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			pod, ok := e.Object.(*corev1.Pod)
+			return ok && terminatedInitResults(pod) > 0
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			return ok && terminatedInitResults(newPod) > terminatedInitResults(oldPod)
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// initResultsRequests maps the pod to the logging it belongs to
+func initResultsRequests(obj client.Object) []reconcile.Request {
+	name := obj.GetLabels()["app.kubernetes.io/managed-by"]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name}}}
+}
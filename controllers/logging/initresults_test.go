@@ -0,0 +1,75 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentbit"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestInitResultsPredicate(t *testing.T) {
+	pod := func(init string, terminated bool) *corev1.Pod {
+		status := corev1.ContainerStatus{Name: init}
+		if terminated {
+			status.State.Terminated = &corev1.ContainerStateTerminated{Message: "1"}
+		} else {
+			status.State.Running = &corev1.ContainerStateRunning{}
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "fluentbit-abcde", Namespace: "logging", Labels: loggingv1beta1.GenerateLoggingRefLabels("test")},
+			Status:     corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{status}},
+		}
+	}
+	tests := []struct {
+		name string
+		old  *corev1.Pod
+		new  *corev1.Pod
+		want bool
+	}{
+		{name: "created terminated", new: pod(fluentbit.PositionDBRecoveryContainerName, true), want: true},
+		{name: "created running", new: pod(fluentbit.PositionDBRecoveryContainerName, false)},
+		{name: "terminated", old: pod(fluentbit.PositionDBRecoveryContainerName, false), new: pod(fluentbit.PositionDBRecoveryContainerName, true), want: true},
+		{name: "already terminated", old: pod(fluentbit.PositionDBRecoveryContainerName, true), new: pod(fluentbit.PositionDBRecoveryContainerName, true)},
+		{name: "other init container", old: pod("init", false), new: pod("init", true)},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var got bool
+			if tt.old == nil {
+				got = initResultsPredicate().Create(event.CreateEvent{Object: tt.new})
+			} else {
+				got = initResultsPredicate().Update(event.UpdateEvent{ObjectOld: tt.old, ObjectNew: tt.new})
+			}
+			if got != tt.want {
+				t.Errorf("predicate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "test"}}}
+	if got := initResultsRequests(pod(fluentbit.PositionDBRecoveryContainerName, true)); !reflect.DeepEqual(got, want) {
+		t.Errorf("requests = %v, want %v", got, want)
+	}
+}
@@ -15,25 +15,34 @@
 package controllers
 
 import (
-	"bytes"
 	"context"
 	"regexp"
+	"sync"
+	"time"
 
 	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/render"
 	"github.com/banzaicloud/logging-operator/pkg/resources"
+	"github.com/banzaicloud/logging-operator/pkg/resources/auditlog"
+	"github.com/banzaicloud/logging-operator/pkg/resources/eventtailer"
 	"github.com/banzaicloud/logging-operator/pkg/resources/fluentbit"
 	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
 	"github.com/banzaicloud/logging-operator/pkg/resources/model"
 	"github.com/banzaicloud/logging-operator/pkg/resources/nodeagent"
-	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/render"
+	"github.com/banzaicloud/logging-operator/pkg/resources/outputcheck"
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
 	"github.com/banzaicloud/operator-tools/pkg/secret"
 	"github.com/banzaicloud/operator-tools/pkg/utils"
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -55,10 +64,27 @@ func NewLoggingReconciler(client client.Client, log logr.Logger) *LoggingReconci
 type LoggingReconciler struct {
 	client.Client
 	Log logr.Logger
+	// Recorder is optional, events are not emitted without it
+	Recorder record.EventRecorder
+	// ServerSideApply applies the resources of all loggings with server-side apply
+	ServerSideApply bool
+
+	flowCachesMu sync.Mutex
+	// flowCaches keep the flows built for each logging, so that only the changed ones are rebuilt on reconcile
+	flowCaches map[string]*model.FlowCache
+
+	bufferRepairsMu sync.Mutex
+	// bufferRepairs holds the fluentd pods of each logging the buffer repair result was reported for
+	bufferRepairs map[string]map[types.UID]bool
+
+	positionDBRecoveriesMu sync.Mutex
+	// positionDBRecoveries holds the fluent-bit pods of each logging the position database recovery was reported for
+	positionDBRecoveries map[string]map[types.UID]bool
 }
 
 // +kubebuilder:rbac:groups=logging.banzaicloud.io,resources=loggings;flows;clusterflows;outputs;clusteroutputs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=logging.banzaicloud.io,resources=loggings/status;flows/status;clusterflows/status;outputs/status;clusteroutputs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=logging.banzaicloud.io,resources=loggingprofiles,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=extensions;apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=extensions;networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
@@ -66,11 +92,17 @@ type LoggingReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=statefulsets;daemonsets;replicasets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services;persistentvolumeclaims;serviceaccounts;pods,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=nodes;namespaces;endpoints;nodes/proxy,verbs=get;list;watch
-// +kubebuilder:rbac:groups="";events.k8s.io,resources=events,verbs=create;get;list;watch
+// +kubebuilder:rbac:groups="";events.k8s.io,resources=events,verbs=create;get;list;watch;patch
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterroles;clusterrolebindings;roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterroles,verbs=escalate
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules;servicemonitors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=*
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=certificates.k8s.io,resources=clustertrustbundles,verbs=get;list;watch
+// +kubebuilder:rbac:groups=autoscaling.k8s.io,resources=verticalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=logging-extensions.banzaicloud.io,resources=eventtailers,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile logging resources
 func (r *LoggingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -81,13 +113,31 @@ func (r *LoggingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		// If object is not found, return without error.
 		// Created objects are automatically garbage collected.
 		// For additional cleanup logic use finalizers.
+		if apierrors.IsNotFound(err) {
+			r.dropFlowCache(req.Name)
+			deleteLoggingMetrics(req.Name)
+			r.forgetBufferRepairs(req.Name)
+			r.forgetPositionDBRecoveries(req.Name)
+		}
 		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if suspended, err := r.updateSuspendedCondition(ctx, &logging); err != nil || suspended {
+		return reconcile.Result{}, err
+	}
+
+	if err := model.ApplyLoggingProfile(ctx, r.Client, &logging); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	if err := logging.SetDefaults(); err != nil {
 		return reconcile.Result{}, err
 	}
 
+	if err := verifyImages(&logging); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	reconcilerOpts := reconciler.ReconcilerOpts{
 		RecreateErrorMessageCondition:                reconciler.MatchImmutableErrorMessages,
 		EnableRecreateWorkloadOnImmutableFieldChange: logging.Spec.EnableRecreateWorkloadOnImmutableFieldChange,
@@ -97,10 +147,37 @@ func (r *LoggingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			"As of fluent-bit, to avoid duplicated logs, make sure to configure a hostPath volume for the positions through `logging.spec.fluentbit.spec.positiondb`. ",
 	}
 
+	// The components write through the server-side apply client if enabled, everything else is left as is
+	componentClient := r.Client
+	if r.ServerSideApply || logging.Spec.EnableServerSideApply {
+		componentClient = resources.NewServerSideApplyClient(r.Client)
+	}
+	// The statuses of the flows and outputs are recorded in dry-run mode as well
+	var statusClient client.StatusClient = r.Client
+	var dryRunClient *resources.DryRunClient
+	if logging.Spec.DryRun {
+		dryRunClient = resources.NewDryRunClient(r.Client)
+		componentClient = dryRunClient
+		statusClient = dryRunClient
+		// Configcheck pods would have to be created to validate the config
+		logging.Spec.FlowConfigCheckDisabled = true
+	}
+
 	loggingResources, err := model.NewLoggingResourceRepository(r.Client).LoggingResourcesFor(ctx, logging)
 	if err != nil {
 		return reconcile.Result{}, errors.WrapIfWithDetails(err, "failed to get logging resources", "logging", logging)
 	}
+	if dryRunClient == nil {
+		r.recordRejectedResources(&logging, loggingResources)
+		if err := r.updateTopologyCondition(ctx, &logging, loggingResources); err != nil {
+			return reconcile.Result{}, err
+		}
+		// The configuration is rendered with the token, wait for the new secret to show up in the cache
+		if created, err := r.ensureTestMessagesToken(ctx, &logging); err != nil || created {
+			return reconcile.Result{Requeue: created}, err
+		}
+	}
+	var renderedConfig string
 	// metrics
 	defer func() {
 		gv := getResourceStateMetrics(log)
@@ -117,33 +194,93 @@ func (r *LoggingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		for _, ob := range loggingResources.ClusterOutputs {
 			updateResourceStateMetrics(&ob, utils.PointerToBool(ob.Status.Active), gv)
 		}
+		updateTopologyMetrics(loggingResources, &logging, renderedConfig)
+		if err := r.updateDrainJobMetrics(ctx, &logging); err != nil {
+			log.Error(err, "failed to update drain job metrics")
+		}
+		if err := r.reportBufferRepairs(ctx, &logging); err != nil {
+			log.Error(err, "failed to report buffer repairs")
+		}
+		if err := r.reportPositionDBRecoveries(ctx, &logging); err != nil {
+			log.Error(err, "failed to report position database recoveries")
+		}
 	}()
 
 	reconcilers := []resources.ComponentReconciler{
-		model.NewValidationReconciler(ctx, r.Client, loggingResources, &secretLoaderFactory{Client: r.Client}),
+		model.NewValidationReconciler(ctx, statusClient, loggingResources, &render.SecretLoaderFactory{Client: r.Client, SecretNamespaces: logging.Spec.SecretNamespaces}),
 	}
 
 	if logging.Spec.FluentdSpec != nil {
+		renderStart := time.Now()
 		fluentdConfig, secretList, err := r.clusterConfiguration(loggingResources)
+		if err == nil && logging.Spec.RollbackTo != "" {
+			fluentdConfig, secretList, err = r.rollbackConfig(ctx, &logging, secretList)
+		} else if err == nil && dryRunClient == nil {
+			fluentdConfig, secretList, err = r.autoRollbackConfig(ctx, &logging, fluentdConfig, secretList)
+		}
+		configRenderDuration.WithLabelValues(logging.Name).Observe(time.Since(renderStart).Seconds())
 		if err != nil {
+			configRenderFailures.WithLabelValues(logging.Name).Inc()
 			// TODO: move config generation into Fluentd reconciler
 			reconcilers = append(reconcilers, func() (*reconcile.Result, error) {
 				return &reconcile.Result{}, err
 			})
 		} else {
+			renderedConfig = fluentdConfig
 			log.V(1).Info("flow configuration", "config", fluentdConfig)
 
-			reconcilers = append(reconcilers, fluentd.New(r.Client, r.Log, &logging, &fluentdConfig, secretList, reconcilerOpts).Reconcile)
+			if dryRunClient == nil {
+				reconcilers = append(reconcilers, outputcheck.New(r.Client, r.Log, &logging, loggingResources, reconcilerOpts).Reconcile)
+			}
+			reconcilers = append(reconcilers, fluentd.New(componentClient, r.Log, &logging, &fluentdConfig, secretList, reconcilerOpts).Reconcile)
+			if dryRunClient == nil {
+				reconcilers = append(reconcilers, func() (*reconcile.Result, error) {
+					return nil, r.recordConfigHistory(ctx, &logging, fluentdConfig, secretList, loggingResources)
+				})
+			}
 		}
 	}
 
 	if logging.Spec.FluentbitSpec != nil {
-		reconcilers = append(reconcilers, fluentbit.New(r.Client, r.Log, &logging, reconcilerOpts, fluentd.NewDataProvider(r.Client)).Reconcile)
+		reconcilers = append(reconcilers, fluentbit.New(componentClient, r.Log, &logging, reconcilerOpts, fluentd.NewDataProvider(r.Client), model.AgentLogClass(loggingResources)).Reconcile)
+	}
+
+	if len(logging.Spec.NodeAgents) > 0 || (logging.Spec.FluentbitSpec != nil && logging.Spec.FluentbitSpec.Windows != nil) {
+		reconcilers = append(reconcilers, nodeagent.New(componentClient, r.Log, &logging, reconcilerOpts, fluentd.NewDataProvider(r.Client)).Reconcile)
+	}
+
+	if logging.Spec.AuditLog != nil {
+		reconcilers = append(reconcilers, auditlog.New(componentClient, r.Log, &logging, reconcilerOpts).Reconcile)
 	}
 
-	if len(logging.Spec.NodeAgents) > 0 {
-		reconcilers = append(reconcilers, nodeagent.New(r.Client, r.Log, &logging, reconcilerOpts, fluentd.NewDataProvider(r.Client)).Reconcile)
+	reconcilers = append(reconcilers, eventtailer.NewLoggingEventTailer(componentClient, r.Log, &logging, reconcilerOpts).Reconcile)
+	reconcilers = append(reconcilers, func() (*reconcile.Result, error) {
+		return nil, r.reconcileAggregatedClusterRole(componentClient, &logging, reconcilerOpts)
+	})
+
+	if dryRunClient != nil {
+		// Every component renders its resources, requeues requested for the sake of applying them are ignored
+		for _, rec := range reconcilers {
+			if _, err := rec(); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{}, r.reconcileDryRunResult(&logging, reconcilerOpts, renderedConfig, dryRunClient)
+	}
+	reconcilers = append(reconcilers, func() (*reconcile.Result, error) {
+		return nil, r.reconcileDryRunResult(&logging, reconcilerOpts, "", nil)
+	})
+	reconcilers = append(reconcilers, func() (*reconcile.Result, error) {
+		return r.sendTestMessages(ctx, loggingResources)
+	})
+	if logging.Spec.FluentdSpec != nil && renderedConfig != "" {
+		reconcilers = append(reconcilers, func() (*reconcile.Result, error) {
+			return r.watchRollout(ctx, &logging, renderedConfig)
+		})
 	}
+	reconcilers = append(reconcilers, func() (*reconcile.Result, error) {
+		return r.updateFailoverStatus(ctx, &logging, loggingResources)
+	})
 
 	for _, rec := range reconcilers {
 		result, err := rec()
@@ -190,34 +327,87 @@ func (r *LoggingReconciler) clusterConfiguration(resources model.LoggingResource
 		return cfg, nil, nil
 	}
 
-	slf := secretLoaderFactory{
-		Client: r.Client,
+	slf := &render.SecretLoaderFactory{
+		Client:           r.Client,
+		SecretNamespaces: resources.Logging.Spec.SecretNamespaces,
 	}
 
-	fluentConfig, err := model.CreateSystem(resources, &slf, r.Log)
+	config, secrets, err := render.Config(resources, slf, r.flowCache(resources.Logging.Name), r.Log)
 	if err != nil {
-		return "", nil, errors.WrapIfWithDetails(err, "failed to build model", "logging", resources.Logging)
+		return "", nil, err
 	}
+	return config, &secrets, nil
+}
 
-	output := &bytes.Buffer{}
-	renderer := render.FluentRender{
-		Out:    output,
-		Indent: 2,
+func (r *LoggingReconciler) flowCache(logging string) *model.FlowCache {
+	r.flowCachesMu.Lock()
+	defer r.flowCachesMu.Unlock()
+	if r.flowCaches == nil {
+		r.flowCaches = make(map[string]*model.FlowCache)
 	}
-	if err := renderer.Render(fluentConfig); err != nil {
-		return "", nil, errors.WrapIfWithDetails(err, "failed to render fluentd config", "logging", resources.Logging)
+	cache, ok := r.flowCaches[logging]
+	if !ok {
+		cache = model.NewFlowCache()
+		r.flowCaches[logging] = cache
 	}
+	return cache
+}
 
-	return output.String(), &slf.Secrets, nil
+func (r *LoggingReconciler) dropFlowCache(logging string) {
+	r.flowCachesMu.Lock()
+	defer r.flowCachesMu.Unlock()
+	delete(r.flowCaches, logging)
 }
 
-type secretLoaderFactory struct {
-	Client  client.Client
-	Secrets secret.MountSecrets
+// updateSuspendedCondition reports whether the reconciliation of the logging is suspended in its status
+func (r *LoggingReconciler) updateSuspendedCondition(ctx context.Context, logging *loggingv1beta1.Logging) (bool, error) {
+	condition := metav1.Condition{
+		Type:    loggingv1beta1.LoggingConditionSuspended,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Suspended",
+		Message: "Reconciliation is suspended by spec.suspend",
+	}
+	if !logging.Spec.Suspend {
+		if current := meta.FindStatusCondition(logging.Status.Conditions, condition.Type); current == nil || current.Status == metav1.ConditionFalse {
+			return false, nil
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Resumed"
+		condition.Message = "Reconciliation is resumed"
+	} else if meta.IsStatusConditionTrue(logging.Status.Conditions, condition.Type) {
+		return true, nil
+	}
+
+	patchBase := client.MergeFrom(logging.DeepCopy())
+	condition.ObservedGeneration = logging.Generation
+	meta.SetStatusCondition(&logging.Status.Conditions, condition)
+	if err := r.Client.Status().Patch(ctx, logging, patchBase); err != nil {
+		return logging.Spec.Suspend, errors.WrapIfWithDetails(err, "failed to update suspended condition", "logging", logging.Name)
+	}
+	return logging.Spec.Suspend, nil
 }
 
-func (f *secretLoaderFactory) OutputSecretLoaderForNamespace(namespace string) secret.SecretLoader {
-	return secret.NewSecretLoader(f.Client, namespace, fluentd.OutputSecretPath, &f.Secrets)
+// recordRejectedResources emits a warning event for each resource not accepted by the logging
+func (r *LoggingReconciler) recordRejectedResources(logging *loggingv1beta1.Logging, resources model.LoggingResources) {
+	if r.Recorder == nil {
+		return
+	}
+	for i := range resources.RejectedClusterFlows {
+		r.Recorder.Eventf(&resources.RejectedClusterFlows[i], corev1.EventTypeWarning, "NotAccepted",
+			"ClusterFlows are not accepted from namespace %s by logging %s", resources.RejectedClusterFlows[i].Namespace, logging.Name)
+	}
+	for i := range resources.RejectedClusterOutputs {
+		r.Recorder.Eventf(&resources.RejectedClusterOutputs[i], corev1.EventTypeWarning, "NotAccepted",
+			"ClusterOutputs are not accepted from namespace %s by logging %s", resources.RejectedClusterOutputs[i].Namespace, logging.Name)
+	}
+	for i := range resources.RejectedFlows {
+		r.Recorder.Eventf(&resources.RejectedFlows[i], corev1.EventTypeWarning, "QuotaExceeded",
+			"Flow is not accepted by logging %s, the namespace is limited to %d flows", logging.Name, resources.NamespaceQuotas[resources.RejectedFlows[i].Namespace].MaxFlows)
+	}
+	for i := range resources.RejectedOutputs {
+		r.Recorder.Eventf(&resources.RejectedOutputs[i], corev1.EventTypeWarning, "QuotaExceeded",
+			"Output is not accepted by logging %s, the namespace is limited to %d outputs", logging.Name, resources.NamespaceQuotas[resources.RejectedOutputs[i].Namespace].MaxOutputs)
+	}
 }
 
 // SetupLoggingWithManager setup logging manager
@@ -253,6 +443,23 @@ func SetupLoggingWithManager(mgr ctrl.Manager, logger logr.Logger) *ctrl.Builder
 				}
 			}
 			return requestList
+		case *loggingv1beta1.LoggingProfile:
+			var requestList []reconcile.Request
+			for _, l := range loggingList.Items {
+				if l.Spec.Profile == o.Name {
+					requestList = append(requestList, reconcile.Request{NamespacedName: types.NamespacedName{Name: l.Name}})
+				}
+			}
+			return requestList
+		case *corev1.Namespace:
+			// Namespaces coming and going may change the set selected by watchNamespaceSelector and
+			// their quota annotations limit the resources accepted from them. Namespaces change rarely,
+			// so every logging is reconciled.
+			var requestList []reconcile.Request
+			for _, l := range loggingList.Items {
+				requestList = append(requestList, reconcile.Request{NamespacedName: types.NamespacedName{Name: l.Name}})
+			}
+			return requestList
 		}
 		return nil
 	})
@@ -264,11 +471,15 @@ func SetupLoggingWithManager(mgr ctrl.Manager, logger logr.Logger) *ctrl.Builder
 		Watches(&source.Kind{Type: &loggingv1beta1.ClusterFlow{}}, requestMapper).
 		Watches(&source.Kind{Type: &loggingv1beta1.Output{}}, requestMapper).
 		Watches(&source.Kind{Type: &loggingv1beta1.Flow{}}, requestMapper).
-		Watches(&source.Kind{Type: &corev1.Secret{}}, requestMapper)
+		Watches(&source.Kind{Type: &corev1.Secret{}}, requestMapper).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, requestMapper).
+		Watches(&source.Kind{Type: &loggingv1beta1.LoggingProfile{}}, requestMapper).
+		Watches(&source.Kind{Type: &corev1.Pod{}}, handler.EnqueueRequestsFromMapFunc(initResultsRequests), ctrlbuilder.WithPredicates(initResultsPredicate()))
 
 	fluentd.RegisterWatches(builder)
 	fluentbit.RegisterWatches(builder)
 	nodeagent.RegisterWatches(builder)
+	eventtailer.RegisterLoggingWatches(builder)
 
 	return builder
 }
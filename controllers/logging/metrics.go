@@ -0,0 +1,220 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+var (
+	renderedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "logging_rendered_resources",
+		Help: "Number of flows and outputs rendered into the configuration of the logging",
+	}, []string{"logging", "kind"})
+	invalidResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "logging_invalid_resources",
+		Help: "Number of flows and outputs of the logging having problems",
+	}, []string{"logging", "kind"})
+	configRenderDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "logging_config_render_duration_seconds",
+		Help: "Time spent building and rendering the fluentd configuration",
+	}, []string{"logging"})
+	configRenderFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "logging_config_render_failures_total",
+		Help: "Number of failed fluentd configuration renders",
+	}, []string{"logging"})
+	configCheckResults = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "logging_config_check_results",
+		Help: "Number of recorded configcheck results of the logging by outcome",
+	}, []string{"logging", "result"})
+	drainJobs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "logging_drain_jobs",
+		Help: "Number of fluentd drain jobs of the logging by state",
+	}, []string{"logging", "state"})
+	bufferQuarantinedChunks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "logging_buffer_quarantined_chunks_total",
+		Help: "Number of corrupt fluentd buffer chunks moved into quarantine by the buffer repair init container",
+	}, []string{"logging"})
+	bufferQuarantinedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "logging_buffer_quarantined_bytes_total",
+		Help: "Size of the corrupt fluentd buffer chunks moved into quarantine by the buffer repair init container",
+	}, []string{"logging"})
+	positionDBRecoveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "logging_fluentbit_positiondb_recoveries_total",
+		Help: "Number of corrupt fluent-bit position databases set aside by the position database recovery init container",
+	}, []string{"logging"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		renderedResources,
+		invalidResources,
+		configRenderDuration,
+		configRenderFailures,
+		configCheckResults,
+		drainJobs,
+		bufferQuarantinedChunks,
+		bufferQuarantinedBytes,
+		positionDBRecoveries,
+	)
+}
+
+// resourceKinds are the kinds of the routing resources the per logging metrics are labeled with
+var resourceKinds = []string{"Flow", "ClusterFlow", "Output", "ClusterOutput"}
+
+// updateTopologyMetrics records the routing resources and the configcheck results of the logging. The resources are
+// counted as rendered if the plugins created for them are part of the fluentd configuration applied.
+func updateTopologyMetrics(resources model.LoggingResources, logging *loggingv1beta1.Logging, config string) {
+	name := logging.Name
+	rendered := renderedResourceKeys(config, logging.Spec.ControlNamespace, logging.Spec.ErrorOutputRef)
+	counts := make(map[string]int)
+	invalid := make(map[string]int)
+	count := func(kind string, meta metav1.ObjectMeta, problems int) {
+		counts[kind] += boolToInt(rendered[resourceKey(kind, meta.Namespace, meta.Name)])
+		invalid[kind] += boolToInt(problems > 0)
+	}
+	for _, o := range resources.Flows {
+		count("Flow", o.ObjectMeta, o.Status.ProblemsCount)
+	}
+	for _, o := range resources.ClusterFlows {
+		count("ClusterFlow", o.ObjectMeta, o.Status.ProblemsCount)
+	}
+	for _, o := range resources.Outputs {
+		count("Output", o.ObjectMeta, o.Status.ProblemsCount)
+	}
+	for _, o := range resources.ClusterOutputs {
+		count("ClusterOutput", o.ObjectMeta, o.Status.ProblemsCount)
+	}
+	for _, kind := range resourceKinds {
+		renderedResources.With(prometheus.Labels{"logging": name, "kind": kind}).Set(float64(counts[kind]))
+		invalidResources.With(prometheus.Labels{"logging": name, "kind": kind}).Set(float64(invalid[kind]))
+	}
+
+	var valid, failed int
+	for _, result := range logging.Status.ConfigCheckResults {
+		valid += boolToInt(result)
+		failed += boolToInt(!result)
+	}
+	configCheckResults.With(prometheus.Labels{"logging": name, "result": "valid"}).Set(float64(valid))
+	configCheckResults.With(prometheus.Labels{"logging": name, "result": "invalid"}).Set(float64(failed))
+}
+
+func resourceKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// renderedResourceKeys returns the keys of the flows and outputs in the configuration by the ids of their plugins,
+// see model.FlowID, model.OutputPluginID and the failover and dead letter outputs chained to them. Resource names
+// cannot contain colons, so the ids can be split safely.
+func renderedResourceKeys(config, controlNamespace, errorOutputRef string) map[string]bool {
+	flowKinds := map[string]string{"flow": "Flow", "clusterflow": "ClusterFlow"}
+	outputKinds := map[string]string{"output": "Output", "clusteroutput": "ClusterOutput"}
+	keys := make(map[string]bool)
+	chained := func(kind, namespace string, rest []string) {
+		switch {
+		case len(rest) >= 3 && rest[0] == "failover":
+			keys[resourceKey(kind, namespace, rest[2])] = true
+		case len(rest) >= 2 && rest[0] == "deadletter":
+			keys[resourceKey(kind, namespace, rest[1])] = true
+		}
+	}
+	for _, line := range strings.Split(config, "\n") {
+		id := strings.TrimPrefix(strings.TrimSpace(line), "@id ")
+		if id == strings.TrimSpace(line) {
+			continue
+		}
+		parts := strings.Split(id, ":")
+		if parts[0] == "main-fluentd-error" && errorOutputRef != "" {
+			keys[resourceKey("ClusterOutput", controlNamespace, errorOutputRef)] = true
+			chained("ClusterOutput", controlNamespace, parts[1:])
+			continue
+		}
+		if len(parts) < 3 {
+			continue
+		}
+		if kind, ok := flowKinds[parts[0]]; ok {
+			keys[resourceKey(kind, parts[1], parts[2])] = true
+		}
+		if len(parts) >= 6 {
+			if kind, ok := outputKinds[parts[3]]; ok {
+				keys[resourceKey(kind, parts[4], parts[5])] = true
+				chained(kind, parts[4], parts[6:])
+			}
+		}
+	}
+	return keys
+}
+
+// deleteLoggingMetrics removes the series of a deleted logging
+func deleteLoggingMetrics(name string) {
+	for _, kind := range resourceKinds {
+		renderedResources.Delete(prometheus.Labels{"logging": name, "kind": kind})
+		invalidResources.Delete(prometheus.Labels{"logging": name, "kind": kind})
+	}
+	for _, result := range []string{"valid", "invalid"} {
+		configCheckResults.Delete(prometheus.Labels{"logging": name, "result": result})
+	}
+	deleteDrainJobMetrics(name)
+	for _, vec := range []*prometheus.CounterVec{configRenderFailures, bufferQuarantinedChunks, bufferQuarantinedBytes, positionDBRecoveries} {
+		vec.DeleteLabelValues(name)
+	}
+	configRenderDuration.DeleteLabelValues(name)
+}
+
+func deleteDrainJobMetrics(name string) {
+	for _, state := range []string{"active", "failed"} {
+		drainJobs.Delete(prometheus.Labels{"logging": name, "state": state})
+	}
+}
+
+// updateDrainJobMetrics records the active and failed drain jobs of the logging
+func (r *LoggingReconciler) updateDrainJobMetrics(ctx context.Context, logging *loggingv1beta1.Logging) error {
+	if logging.Spec.FluentdSpec == nil {
+		deleteDrainJobMetrics(logging.Name)
+		return nil
+	}
+	var jobs batchv1.JobList
+	if err := r.Client.List(ctx, &jobs,
+		client.InNamespace(logging.Spec.ControlNamespace),
+		client.MatchingLabels(logging.GetFluentdLabels(fluentd.ComponentDrainer))); err != nil {
+		return err
+	}
+	var active, failed int
+	for _, job := range jobs.Items {
+		active += boolToInt(job.Status.Active > 0)
+		failed += boolToInt(job.Status.Failed > 0)
+	}
+	drainJobs.With(prometheus.Labels{"logging": logging.Name, "state": "active"}).Set(float64(active))
+	drainJobs.With(prometheus.Labels{"logging": logging.Name, "state": "failed"}).Set(float64(failed))
+	return nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
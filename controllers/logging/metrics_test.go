@@ -0,0 +1,130 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestRenderedResourceKeys(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         string
+		errorOutputRef string
+		want           map[string]bool
+	}{
+		{
+			name:   "not rendered",
+			config: "",
+			want:   map[string]bool{},
+		},
+		{
+			name: "flows and outputs",
+			config: `
+<match **>
+  @type label_router
+  @id logging:logging:test:main
+</match>
+<label @a>
+  <filter **>
+    @type stdout
+    @id flow:app:flow:0
+  </filter>
+  <match **>
+    @type null
+    @id flow:app:flow:output:app:null
+  </match>
+</label>
+<label @b>
+  <match **>
+    @type null
+    @id clusterflow:logging:all:clusteroutput:logging:primary
+  </match>
+</label>
+<label @c>
+  <match **>
+    @type null
+    @id clusterflow:logging:all:clusteroutput:logging:primary:failover:0:secondary
+  </match>
+</label>
+<label @d>
+  <match **>
+    @type null
+    @id flow:app:flow:output:app:null:deadletter:dropped
+  </match>
+</label>`,
+			want: map[string]bool{
+				"Flow/app/flow":                   true,
+				"ClusterFlow/logging/all":         true,
+				"Output/app/null":                 true,
+				"Output/app/dropped":              true,
+				"ClusterOutput/logging/primary":   true,
+				"ClusterOutput/logging/secondary": true,
+			},
+		},
+		{
+			name:           "error output",
+			config:         "  @id main-fluentd-error\n  @id main-fluentd-error:failover:0:backup\n",
+			errorOutputRef: "errors",
+			want: map[string]bool{
+				"ClusterOutput/logging/errors": true,
+				"ClusterOutput/logging/backup": true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderedResourceKeys(tt.config, "logging", tt.errorOutputRef)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("renderedResourceKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggingMetrics(t *testing.T) {
+	logging := &loggingv1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-test"},
+		Spec:       loggingv1beta1.LoggingSpec{ControlNamespace: "logging"},
+	}
+	resources := model.LoggingResources{
+		Flows: []loggingv1beta1.Flow{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "flow"}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "skipped"}, Status: loggingv1beta1.FlowStatus{ProblemsCount: 1}},
+		},
+	}
+	updateTopologyMetrics(resources, logging, "@id flow:app:flow:output:app:null\n")
+	labels := prometheus.Labels{"logging": logging.Name, "kind": "Flow"}
+	if got := testutil.ToFloat64(renderedResources.With(labels)); got != 1 {
+		t.Errorf("rendered flows = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(invalidResources.With(labels)); got != 1 {
+		t.Errorf("invalid flows = %v, want 1", got)
+	}
+
+	deleteLoggingMetrics(logging.Name)
+	if renderedResources.Delete(labels) || invalidResources.Delete(labels) {
+		t.Error("the metrics of the deleted logging are kept")
+	}
+}
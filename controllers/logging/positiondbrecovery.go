@@ -0,0 +1,84 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentbit"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// reportPositionDBRecoveries counts the position databases set aside by the recovery init container of the
+// fluent-bit pods and emits an event on the pods having any. Every pod is reported once.
+func (r *LoggingReconciler) reportPositionDBRecoveries(ctx context.Context, logging *loggingv1beta1.Logging) error {
+	if logging.Spec.FluentbitSpec == nil || logging.Spec.FluentbitSpec.PositionDBRecovery == nil || !logging.Spec.FluentbitSpec.PositionDBRecovery.Enabled {
+		r.forgetPositionDBRecoveries(logging.Name)
+		return nil
+	}
+	labels := loggingv1beta1.GenerateLoggingRefLabels(logging.Name)
+	labels["app.kubernetes.io/name"] = "fluentbit"
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods,
+		client.InNamespace(logging.Spec.ControlNamespace),
+		client.MatchingLabels(labels)); err != nil {
+		return err
+	}
+
+	r.positionDBRecoveriesMu.Lock()
+	defer r.positionDBRecoveriesMu.Unlock()
+	if r.positionDBRecoveries == nil {
+		r.positionDBRecoveries = make(map[string]map[types.UID]bool)
+	}
+	reported := make(map[types.UID]bool)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if r.positionDBRecoveries[logging.Name][pod.UID] {
+			reported[pod.UID] = true
+			continue
+		}
+		for _, status := range pod.Status.InitContainerStatuses {
+			if status.Name != fluentbit.PositionDBRecoveryContainerName || status.State.Terminated == nil {
+				continue
+			}
+			reported[pod.UID] = true
+			recovered, err := fluentbit.ParsePositionDBRecoveryResult(status.State.Terminated.Message)
+			if err != nil || recovered == 0 {
+				continue
+			}
+			positionDBRecoveries.With(prometheus.Labels{"logging": logging.Name}).Add(float64(recovered))
+			if r.Recorder != nil {
+				r.Recorder.Eventf(pod, corev1.EventTypeWarning, "PositionDBRecovered",
+					"%d corrupt position databases were set aside (%s), the files are read again according to readFromHead",
+					recovered, logging.Spec.FluentbitSpec.PositionDBRecovery.Action)
+			}
+		}
+	}
+	// Pods that are gone are forgotten
+	r.positionDBRecoveries[logging.Name] = reported
+	return nil
+}
+
+// forgetPositionDBRecoveries drops the pods reported for a logging that is deleted or has the position database recovery disabled
+func (r *LoggingReconciler) forgetPositionDBRecoveries(logging string) {
+	r.positionDBRecoveriesMu.Lock()
+	defer r.positionDBRecoveriesMu.Unlock()
+	delete(r.positionDBRecoveries, logging)
+}
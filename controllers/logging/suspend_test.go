@@ -0,0 +1,157 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestUpdateSuspendedCondition(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := loggingv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	logging := &loggingv1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 1},
+		Spec:       loggingv1beta1.LoggingSpec{ControlNamespace: "logging"},
+	}
+	r := &LoggingReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(logging).Build(),
+		Log:    logr.Discard(),
+	}
+	ctx := context.Background()
+
+	// steps run in order against the same logging
+	steps := []struct {
+		name          string
+		suspend       bool
+		wantSuspended bool
+		wantCondition metav1.ConditionStatus
+		wantReason    string
+	}{
+		{name: "never suspended", suspend: false},
+		{name: "suspended", suspend: true, wantSuspended: true, wantCondition: metav1.ConditionTrue, wantReason: "Suspended"},
+		{name: "still suspended", suspend: true, wantSuspended: true, wantCondition: metav1.ConditionTrue, wantReason: "Suspended"},
+		{name: "resumed", suspend: false, wantCondition: metav1.ConditionFalse, wantReason: "Resumed"},
+		{name: "still resumed", suspend: false, wantCondition: metav1.ConditionFalse, wantReason: "Resumed"},
+	}
+	for _, tt := range steps {
+		current := &loggingv1beta1.Logging{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: logging.Name}, current); err != nil {
+			t.Fatal(err)
+		}
+		current.Spec.Suspend = tt.suspend
+		if err := r.Client.Update(ctx, current); err != nil {
+			t.Fatal(err)
+		}
+		suspended, err := r.updateSuspendedCondition(ctx, current)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if suspended != tt.wantSuspended {
+			t.Errorf("%s: suspended = %v, want %v", tt.name, suspended, tt.wantSuspended)
+		}
+
+		stored := &loggingv1beta1.Logging{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: logging.Name}, stored); err != nil {
+			t.Fatal(err)
+		}
+		condition := meta.FindStatusCondition(stored.Status.Conditions, loggingv1beta1.LoggingConditionSuspended)
+		if tt.wantCondition == "" {
+			if condition != nil {
+				t.Errorf("%s: unexpected condition %+v", tt.name, condition)
+			}
+			continue
+		}
+		if condition == nil {
+			t.Fatalf("%s: the suspended condition is not set", tt.name)
+		}
+		if condition.Status != tt.wantCondition || condition.Reason != tt.wantReason {
+			t.Errorf("%s: condition = %s/%s, want %s/%s", tt.name, condition.Status, condition.Reason, tt.wantCondition, tt.wantReason)
+		}
+		if condition.ObservedGeneration != logging.Generation {
+			t.Errorf("%s: observed generation = %d, want %d", tt.name, condition.ObservedGeneration, logging.Generation)
+		}
+	}
+}
+
+func TestReconcileSuspended(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := loggingv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	logging := &loggingv1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: loggingv1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			FluentdSpec:      &loggingv1beta1.FluentdSpec{},
+			FluentbitSpec:    &loggingv1beta1.FluentbitSpec{},
+			Suspend:          true,
+		},
+	}
+	r := &LoggingReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(logging).Build(),
+		Log:    logr.Discard(),
+	}
+	ctx := context.Background()
+
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: logging.Name}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Requeue || result.RequeueAfter != 0 {
+		t.Errorf("unexpected requeue of the suspended logging: %+v", result)
+	}
+
+	// Nothing is created for the suspended logging
+	secrets := &corev1.SecretList{}
+	if err := r.Client.List(ctx, secrets); err != nil {
+		t.Fatal(err)
+	}
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.Client.List(ctx, configMaps); err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets.Items) != 0 || len(configMaps.Items) != 0 {
+		t.Errorf("expected no objects, got %d secrets and %d configmaps", len(secrets.Items), len(configMaps.Items))
+	}
+
+	stored := &loggingv1beta1.Logging{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: logging.Name}, stored); err != nil {
+		t.Fatal(err)
+	}
+	if !meta.IsStatusConditionTrue(stored.Status.Conditions, loggingv1beta1.LoggingConditionSuspended) {
+		t.Errorf("expected the logging to be reported suspended, got %+v", stored.Status.Conditions)
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/utils"
+	"github.com/prometheus/common/expfmt"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+const (
+	testMessageTag = "logging.test-message"
+	// emitRecordsMetric counts the records passed to the output plugins, it is increased before the http input responds
+	emitRecordsMetric = "fluentd_output_status_emit_records"
+)
+
+var testMessageHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// ensureTestMessagesToken generates the token the test messages are authenticated with, unless it exists already.
+// It returns true if the secret has just been created.
+func (r *LoggingReconciler) ensureTestMessagesToken(ctx context.Context, logging *loggingv1beta1.Logging) (bool, error) {
+	if logging.Spec.FluentdSpec == nil || logging.Spec.FluentdSpec.TestMessages == nil || !logging.Spec.FluentdSpec.TestMessages.Enabled {
+		return false, nil
+	}
+	tokenSecret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: logging.Spec.ControlNamespace, Name: logging.QualifiedName(model.TestMessagesSecretName)}
+	err := r.Client.Get(ctx, key, tokenSecret)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return false, errors.WrapIf(err, "failed to get test messages token")
+	}
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return false, errors.WrapIf(err, "failed to generate test messages token")
+	}
+	tokenSecret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Labels:    logging.GetFluentdLabels(fluentd.ComponentFluentd),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(logging, loggingv1beta1.GroupVersion.WithKind("Logging")),
+			},
+		},
+		Data: map[string][]byte{
+			model.TestMessagesTokenKey: []byte(hex.EncodeToString(token)),
+		},
+	}
+	if err := r.Client.Create(ctx, tokenSecret); err != nil && !apierrors.IsAlreadyExists(err) {
+		return false, errors.WrapIf(err, "failed to create test messages token")
+	}
+	return true, nil
+}
+
+// testMessageTarget is a flow or clusterflow a test message is sent through
+type testMessageTarget struct {
+	object client.Object
+	status **loggingv1beta1.TestMessageStatus
+	id     string
+	record map[string]interface{}
+	// outputs of the flow in the order of the status and the ids of their plugins
+	outputs   []loggingv1beta1.TestMessageOutputStatus
+	pluginIDs map[string]int
+}
+
+// sendTestMessages sends a test message through every flow and clusterflow with a new value in its test message
+// annotation, and records whether the outputs of the flow received it
+func (r *LoggingReconciler) sendTestMessages(ctx context.Context, resources model.LoggingResources) (*reconcile.Result, error) {
+	logging := resources.Logging
+	if logging.Spec.FluentdSpec == nil || logging.Spec.FluentdSpec.TestMessages == nil || !logging.Spec.FluentdSpec.TestMessages.Enabled {
+		return nil, nil
+	}
+
+	pending := pendingTestMessages(resources)
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	podIP, err := r.readyFluentdPodIP(ctx, logging)
+	if err != nil {
+		return nil, err
+	}
+	if podIP == "" {
+		r.Log.Info("no ready fluentd pod to send test messages to, retrying later")
+		return &ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	tokenSecret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: logging.Spec.ControlNamespace, Name: logging.QualifiedName(model.TestMessagesSecretName)}, tokenSecret); err != nil {
+		return nil, errors.WrapIf(err, "failed to get test messages token")
+	}
+	token := string(tokenSecret.Data[model.TestMessagesTokenKey])
+
+	for _, target := range pending {
+		patch := client.MergeFrom(target.object.DeepCopyObject().(client.Object))
+		*target.status = sendTestMessage(podIP, token, logging.Spec.FluentdSpec, target)
+		if err := r.Client.Status().Patch(ctx, target.object, patch); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to update test message status", "name", target.object.GetName(), "namespace", target.object.GetNamespace())
+		}
+	}
+	return nil, nil
+}
+
+// pendingTestMessages returns the flows and clusterflows with a test message annotation not sent yet
+func pendingTestMessages(resources model.LoggingResources) []testMessageTarget {
+	var pending []testMessageTarget
+	for i := range resources.Flows {
+		flow := &resources.Flows[i]
+		id := flow.Annotations[loggingv1beta1.TestMessageAnnotation]
+		if id == "" || (flow.Status.TestMessage != nil && flow.Status.TestMessage.ID == id) {
+			continue
+		}
+		target := testMessageTarget{
+			object:    flow,
+			status:    &flow.Status.TestMessage,
+			id:        id,
+			record:    testMessageRecord(flow.Namespace, flow.Name, flow.Namespace, flowSelect(flow.Spec), id),
+			pluginIDs: make(map[string]int),
+		}
+		flowID := model.FlowID(*flow)
+		for _, ref := range flow.Spec.GlobalOutputRefs {
+			target.outputs = append(target.outputs, loggingv1beta1.TestMessageOutputStatus{Kind: "ClusterOutput", Name: ref})
+			if output := resources.ClusterOutputs.FindByName(ref); output != nil {
+				target.pluginIDs[model.ClusterOutputPluginID(flowID, *output)] = len(target.outputs) - 1
+			}
+		}
+		for _, ref := range flow.Spec.LocalOutputRefs {
+			target.outputs = append(target.outputs, loggingv1beta1.TestMessageOutputStatus{Kind: "Output", Name: ref})
+			if output := resources.Outputs.FindByNamespacedName(flow.Namespace, ref); output != nil {
+				target.pluginIDs[model.OutputPluginID(flowID, *output)] = len(target.outputs) - 1
+			}
+		}
+		pending = append(pending, target)
+	}
+	for i := range resources.ClusterFlows {
+		flow := &resources.ClusterFlows[i]
+		id := flow.Annotations[loggingv1beta1.TestMessageAnnotation]
+		if id == "" || (flow.Status.TestMessage != nil && flow.Status.TestMessage.ID == id) {
+			continue
+		}
+		selected, namespace := clusterFlowSelect(flow.Spec)
+		if namespace == "" {
+			namespace = resources.Logging.Spec.ControlNamespace
+		}
+		target := testMessageTarget{
+			object:    flow,
+			status:    &flow.Status.TestMessage,
+			id:        id,
+			record:    testMessageRecord(flow.Namespace, flow.Name, namespace, selected, id),
+			pluginIDs: make(map[string]int),
+		}
+		flowID := model.ClusterFlowID(*flow)
+		for _, ref := range flow.Spec.GlobalOutputRefs {
+			target.outputs = append(target.outputs, loggingv1beta1.TestMessageOutputStatus{Kind: "ClusterOutput", Name: ref})
+			if output := resources.ClusterOutputs.FindByName(ref); output != nil {
+				target.pluginIDs[model.ClusterOutputPluginID(flowID, *output)] = len(target.outputs) - 1
+			}
+		}
+		pending = append(pending, target)
+	}
+	return pending
+}
+
+// flowSelect returns the selection of the first selecting match of the flow
+func flowSelect(spec loggingv1beta1.FlowSpec) loggingv1beta1.Select {
+	for _, match := range spec.Match {
+		if match.Select != nil {
+			return *match.Select
+		}
+	}
+	return loggingv1beta1.Select{Labels: spec.Selectors}
+}
+
+// clusterFlowSelect returns the selection of the first selecting match of the clusterflow and the first namespace
+// it selects
+func clusterFlowSelect(spec loggingv1beta1.ClusterFlowSpec) (loggingv1beta1.Select, string) {
+	for _, match := range spec.Match {
+		if match.ClusterSelect != nil {
+			var namespace string
+			if len(match.ClusterSelect.Namespaces) > 0 {
+				namespace = match.ClusterSelect.Namespaces[0]
+			}
+			return loggingv1beta1.Select{
+				Labels:         match.ClusterSelect.Labels,
+				Hosts:          match.ClusterSelect.Hosts,
+				ContainerNames: match.ClusterSelect.ContainerNames,
+			}, namespace
+		}
+	}
+	return loggingv1beta1.Select{Labels: spec.Selectors}, ""
+}
+
+func sendTestMessage(podIP, token string, fluentdSpec *loggingv1beta1.FluentdSpec, target testMessageTarget) *loggingv1beta1.TestMessageStatus {
+	status := &loggingv1beta1.TestMessageStatus{
+		ID:      target.id,
+		Outputs: append([]loggingv1beta1.TestMessageOutputStatus(nil), target.outputs...),
+	}
+
+	var before map[string]float64
+	if fluentdSpec.Metrics != nil {
+		var err error
+		if before, err = emittedRecords(podIP, fluentdSpec.Metrics); err != nil {
+			status.Problem = err.Error()
+			return status
+		}
+	}
+
+	body, err := json.Marshal(target.record)
+	if err != nil {
+		status.Problem = err.Error()
+		return status
+	}
+	url := fmt.Sprintf("http://%s/%s", net.JoinHostPort(podIP, strconv.Itoa(int(fluentdSpec.TestMessages.Port))), testMessageTag)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		status.Problem = err.Error()
+		return status
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := testMessageHTTPClient.Do(req)
+	if err != nil {
+		status.Problem = errors.WrapIf(err, "failed to send test message").Error()
+		return status
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		status.Problem = fmt.Sprintf("test message rejected by fluentd: %s", resp.Status)
+		return status
+	}
+	status.SentAt = &metav1.Time{Time: time.Now()}
+
+	if fluentdSpec.Metrics == nil {
+		status.Problem = "delivery cannot be verified, fluentd metrics are disabled"
+		return status
+	}
+	after, err := emittedRecords(podIP, fluentdSpec.Metrics)
+	if err != nil {
+		status.Problem = err.Error()
+		return status
+	}
+	for i := range status.Outputs {
+		status.Outputs[i].Delivered = utils.BoolPointer(false)
+	}
+	for pluginID, i := range target.pluginIDs {
+		// Other logs of the flow may increase the counter as well, a delivery is reliable on quiet flows only
+		status.Outputs[i].Delivered = utils.BoolPointer(after[pluginID] > before[pluginID])
+	}
+	return status
+}
+
+// testMessageRecord returns a record of the namespace routed to the flow by the metadata of its selection
+func testMessageRecord(flowNamespace, flowName, namespace string, selected loggingv1beta1.Select, id string) map[string]interface{} {
+	var containerName, host string
+	if len(selected.ContainerNames) > 0 {
+		containerName = selected.ContainerNames[0]
+	}
+	if len(selected.Hosts) > 0 {
+		host = selected.Hosts[0]
+	}
+	return map[string]interface{}{
+		"message":      fmt.Sprintf("test message %s of flow %s/%s", id, flowNamespace, flowName),
+		"test_message": id,
+		"kubernetes": map[string]interface{}{
+			"namespace_name": namespace,
+			"pod_name":       "logging-test-message",
+			"container_name": containerName,
+			"host":           host,
+			"labels":         selected.Labels,
+		},
+	}
+}
+
+// emittedRecords returns the number of records emitted to each output plugin of fluentd
+func emittedRecords(podIP string, metrics *loggingv1beta1.Metrics) (map[string]float64, error) {
+	return pluginMetric(podIP, metrics, emitRecordsMetric)
+}
+
+// pluginMetric returns the value of the fluentd metric for each plugin
+func pluginMetric(podIP string, metrics *loggingv1beta1.Metrics, name string) (map[string]float64, error) {
+	url := fmt.Sprintf("http://%s%s", net.JoinHostPort(podIP, strconv.Itoa(int(metrics.Port))), metrics.Path)
+	resp, err := testMessageHTTPClient.Get(url)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to get fluentd metrics")
+	}
+	defer resp.Body.Close()
+
+	families, err := new(expfmt.TextParser).TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to parse fluentd metrics")
+	}
+	values := make(map[string]float64)
+	if family, ok := families[name]; ok {
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "plugin_id" {
+					values[label.GetValue()] += m.GetGauge().GetValue() + m.GetCounter().GetValue() + m.GetUntyped().GetValue()
+				}
+			}
+		}
+	}
+	return values, nil
+}
+
+func (r *LoggingReconciler) readyFluentdPodIP(ctx context.Context, logging loggingv1beta1.Logging) (string, error) {
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods, client.InNamespace(logging.Spec.ControlNamespace), client.MatchingLabels(logging.GetFluentdLabels(fluentd.ComponentFluentd))); err != nil {
+		return "", errors.WrapIf(err, "failed to list fluentd pods")
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+				return pod.Status.PodIP, nil
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,178 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func testMessageResources() model.LoggingResources {
+	annotations := map[string]string{loggingv1beta1.TestMessageAnnotation: "1"}
+	return model.LoggingResources{
+		Logging: loggingv1beta1.Logging{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       loggingv1beta1.LoggingSpec{ControlNamespace: "logging"},
+		},
+		ClusterOutputs: model.ClusterOutputs{
+			{ObjectMeta: metav1.ObjectMeta{Name: "central", Namespace: "logging"}},
+		},
+		Outputs: model.Outputs{
+			{ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "app"}},
+		},
+		Flows: []loggingv1beta1.Flow{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "sent", Namespace: "app", Annotations: annotations},
+				Status: loggingv1beta1.FlowStatus{
+					TestMessage: &loggingv1beta1.TestMessageStatus{ID: "1"},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "app", Annotations: annotations},
+				Spec: loggingv1beta1.FlowSpec{
+					Match: []loggingv1beta1.Match{
+						{Select: &loggingv1beta1.Select{Labels: map[string]string{"app": "web"}, ContainerNames: []string{"nginx"}}},
+					},
+					GlobalOutputRefs: []string{"central"},
+					LocalOutputRefs:  []string{"local", "missing"},
+				},
+			},
+		},
+		ClusterFlows: []loggingv1beta1.ClusterFlow{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "all", Namespace: "logging", Annotations: annotations},
+				Spec: loggingv1beta1.ClusterFlowSpec{
+					GlobalOutputRefs: []string{"central"},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "selected", Namespace: "logging", Annotations: annotations},
+				Spec: loggingv1beta1.ClusterFlowSpec{
+					Match: []loggingv1beta1.ClusterMatch{
+						{ClusterSelect: &loggingv1beta1.ClusterSelect{Namespaces: []string{"team-a", "team-b"}, Hosts: []string{"node-1"}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestPendingTestMessages(t *testing.T) {
+	resources := testMessageResources()
+	pending := pendingTestMessages(resources)
+	if len(pending) != 3 {
+		t.Fatalf("expected 3 pending test messages, got %d", len(pending))
+	}
+
+	flow := pending[0]
+	if flow.object.GetName() != "pending" {
+		t.Fatalf("expected the pending flow first, got %s", flow.object.GetName())
+	}
+	kubernetes := flow.record["kubernetes"].(map[string]interface{})
+	if kubernetes["namespace_name"] != "app" || kubernetes["container_name"] != "nginx" || kubernetes["labels"].(map[string]string)["app"] != "web" {
+		t.Errorf("unexpected metadata of the flow test message %v", kubernetes)
+	}
+	if len(flow.outputs) != 3 {
+		t.Fatalf("expected 3 outputs of the flow, got %d", len(flow.outputs))
+	}
+	flowID := model.FlowID(resources.Flows[1])
+	wantPlugins := map[string]int{
+		model.ClusterOutputPluginID(flowID, resources.ClusterOutputs[0]): 0,
+		model.OutputPluginID(flowID, resources.Outputs[0]):               1,
+	}
+	if len(flow.pluginIDs) != len(wantPlugins) {
+		t.Errorf("unexpected plugin ids %v", flow.pluginIDs)
+	}
+	for id, i := range wantPlugins {
+		if got, ok := flow.pluginIDs[id]; !ok || got != i {
+			t.Errorf("plugin %s should be output %d, got %d", id, i, got)
+		}
+	}
+
+	all := pending[1]
+	kubernetes = all.record["kubernetes"].(map[string]interface{})
+	if kubernetes["namespace_name"] != "logging" {
+		t.Errorf("a clusterflow without namespaces should be tested from the control namespace, got %v", kubernetes["namespace_name"])
+	}
+	if id := model.ClusterOutputPluginID(model.ClusterFlowID(resources.ClusterFlows[0]), resources.ClusterOutputs[0]); len(all.pluginIDs) != 1 || all.pluginIDs[id] != 0 {
+		t.Errorf("unexpected plugin ids of the clusterflow %v", all.pluginIDs)
+	}
+
+	selected := pending[2]
+	kubernetes = selected.record["kubernetes"].(map[string]interface{})
+	if kubernetes["namespace_name"] != "team-a" || kubernetes["host"] != "node-1" {
+		t.Errorf("unexpected metadata of the clusterflow test message %v", kubernetes)
+	}
+
+	// The status is written through the target
+	*selected.status = &loggingv1beta1.TestMessageStatus{ID: "1"}
+	if resources.ClusterFlows[1].Status.TestMessage == nil {
+		t.Error("the status of the clusterflow is not updated")
+	}
+}
+
+func TestSendTestMessageAuthentication(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fluentdSpec := &loggingv1beta1.FluentdSpec{
+		TestMessages: &loggingv1beta1.FluentdTestMessages{Enabled: true, Port: int32(portNumber)},
+	}
+	target := pendingTestMessages(testMessageResources())[0]
+
+	status := sendTestMessage(host, "wrong", fluentdSpec, target)
+	if status.SentAt != nil || status.Problem == "" {
+		t.Errorf("a test message with a wrong token should be rejected, got %+v", status)
+	}
+
+	status = sendTestMessage(host, "secret-token", fluentdSpec, target)
+	if status.SentAt == nil {
+		t.Fatalf("the test message is not sent: %s", status.Problem)
+	}
+	if received["test_message"] != "1" {
+		t.Errorf("unexpected test message %v", received)
+	}
+	if status.Problem != "delivery cannot be verified, fluentd metrics are disabled" {
+		t.Errorf("unexpected problem %q", status.Problem)
+	}
+	if len(status.Outputs) != 3 || status.Outputs[0].Delivered != nil {
+		t.Errorf("unexpected outputs %+v", status.Outputs)
+	}
+}
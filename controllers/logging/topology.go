@@ -0,0 +1,83 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	loggingv1beta1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// topologyCondition describes where the logs of the logging are collected and aggregated
+func topologyCondition(logging *loggingv1beta1.Logging, resources model.LoggingResources) metav1.Condition {
+	condition := metav1.Condition{
+		Type:   loggingv1beta1.LoggingConditionTopology,
+		Status: metav1.ConditionTrue,
+	}
+	var directOutputs int
+	if logging.Spec.FluentbitSpec != nil {
+		directOutputs = len(logging.Spec.FluentbitSpec.DirectOutputs)
+	}
+	switch {
+	case logging.Spec.FluentdSpec != nil:
+		condition.Reason = "Aggregator"
+		condition.Message = "fluentd aggregates the logs"
+		if logging.Spec.FluentbitSpec != nil {
+			condition.Message = "fluent-bit forwards the logs to fluentd"
+		}
+	case logging.AggregatorLess():
+		condition.Reason = "AggregatorLess"
+		condition.Message = "fluent-bit ships the logs to its direct outputs only"
+		if flows := len(resources.Flows) + len(resources.ClusterFlows); flows > 0 {
+			condition.Message += fmt.Sprintf(", %d flows are not rendered without an aggregator", flows)
+		}
+		return condition
+	case logging.Spec.FluentbitSpec != nil:
+		condition.Reason = "ExternalAggregator"
+		condition.Message = fmt.Sprintf("fluent-bit forwards the logs to %s", logging.Spec.FluentbitSpec.TargetHost)
+	default:
+		condition.Reason = "NoAggregator"
+		condition.Message = "neither fluentd nor fluent-bit is configured"
+		return condition
+	}
+	if directOutputs > 0 {
+		condition.Message += fmt.Sprintf(", %d direct outputs bypass the aggregator", directOutputs)
+	}
+	return condition
+}
+
+// updateTopologyCondition reports the effective topology of the logging in its status
+func (r *LoggingReconciler) updateTopologyCondition(ctx context.Context, logging *loggingv1beta1.Logging, resources model.LoggingResources) error {
+	condition := topologyCondition(logging, resources)
+	if current := meta.FindStatusCondition(logging.Status.Conditions, condition.Type); current != nil &&
+		current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+		return nil
+	}
+
+	patchBase := client.MergeFrom(logging.DeepCopy())
+	condition.ObservedGeneration = logging.Generation
+	meta.SetStatusCondition(&logging.Status.Conditions, condition)
+	if err := r.Client.Status().Patch(ctx, logging, patchBase); err != nil {
+		return errors.WrapIfWithDetails(err, "failed to update topology condition", "logging", logging.Name)
+	}
+	return nil
+}
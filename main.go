@@ -18,15 +18,18 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"hash/fnv"
 	"net/http"
 	"net/http/pprof"
 	"os"
 	"strings"
+	"time"
 
 	"emperror.dev/errors"
 	extensionsControllers "github.com/banzaicloud/logging-operator/controllers/extensions"
 	loggingControllers "github.com/banzaicloud/logging-operator/controllers/logging"
 	"github.com/banzaicloud/logging-operator/pkg/k8sutil"
+	"github.com/banzaicloud/logging-operator/pkg/render"
 	extensionsv1alpha1 "github.com/banzaicloud/logging-operator/pkg/sdk/extensions/api/v1alpha1"
 	config "github.com/banzaicloud/logging-operator/pkg/sdk/extensions/extensionsconfig"
 	loggingv1alpha1 "github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1alpha1"
@@ -35,6 +38,7 @@ import (
 	"github.com/banzaicloud/logging-operator/pkg/webhook/podhandler"
 	prometheusOperator "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"github.com/spf13/cast"
+	"golang.org/x/time/rate"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -43,15 +47,25 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	// +kubebuilder:scaffold:imports
 )
 
+const (
+	modeReconcile = "reconcile"
+	// modeValidateOnly serves the webhooks, and the render preview if its address is set, without reconciling, to lint
+	// the logging resources in staging and CI environments with read access only
+	modeValidateOnly = "validate-only"
+)
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -75,7 +89,16 @@ func main() {
 	var enableprofile bool
 	var namespace string
 	var loggingRef string
+	var loggingSelector string
+	var maxConcurrentReconciles int
+	var reconcileBackoffBase time.Duration
+	var reconcileBackoffMax time.Duration
+	var reconcileRateLimitQPS float64
+	var reconcileRateLimitBurst int
 	var klogLevel int
+	var serverSideApply bool
+	var mode string
+	var renderPreviewAddr string
 
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
@@ -86,6 +109,17 @@ func main() {
 	flag.BoolVar(&enableprofile, "pprof", false, "Enable pprof")
 	flag.StringVar(&namespace, "watch-namespace", "", "Namespace to filter the list of watched objects")
 	flag.StringVar(&loggingRef, "watch-logging-name", "", "Logging resource name to optionally filter the list of watched objects based on which logging they belong to by checking the app.kubernetes.io/managed-by label")
+	flag.StringVar(&loggingSelector, "watch-logging-selector", "", "Label selector of the Logging, EventTailer and HostTailer resources reconciled by this instance, to shard them between multiple operator instances. Each shard elects its own leader.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1, "Number of Logging resources reconciled in parallel")
+	flag.DurationVar(&reconcileBackoffBase, "reconcile-backoff-base", 5*time.Millisecond, "Initial delay of the exponential backoff when requeueing a failed reconcile")
+	flag.DurationVar(&reconcileBackoffMax, "reconcile-backoff-max", 1000*time.Second, "Maximum delay of the exponential backoff when requeueing a failed reconcile")
+	flag.Float64Var(&reconcileRateLimitQPS, "reconcile-rate-limit-qps", 10, "Overall rate of reconciles per second, across all Logging resources")
+	flag.IntVar(&reconcileRateLimitBurst, "reconcile-rate-limit-burst", 100, "Burst of reconciles allowed above the overall rate")
+	flag.BoolVar(&serverSideApply, "server-side-apply", false, "Apply the resources of every Logging with server-side apply, as with spec.enableServerSideApply")
+	flag.StringVar(&mode, "mode", modeReconcile, "Run mode of the operator: reconcile, or validate-only to serve the webhooks and the render preview endpoint (see render-preview-addr) without reconciling, leader election or write access to the cluster")
+	flag.StringVar(&renderPreviewAddr, "render-preview-addr", "", "The address the render preview endpoint (POST /render) binds to in validate-only mode, disabled if empty. "+
+		"The endpoint is not authenticated: it only renders the posted resources without reading the cluster, but anyone reaching it can use the CPU and memory of the operator, "+
+		"so bind it to localhost or restrict the access to it, e.g. with a NetworkPolicy.")
 	flag.Parse()
 
 	ctx := context.Background()
@@ -118,16 +152,28 @@ func main() {
 	}
 	klog.SetLogger(zapLogger)
 
+	validateOnly := mode == modeValidateOnly
+	if !validateOnly && mode != modeReconcile {
+		setupLog.Error(errors.New("unknown mode"), "invalid mode", "mode", mode)
+		os.Exit(1)
+	}
+
+	loggingLabelSelector, err := labels.Parse(loggingSelector)
+	if err != nil {
+		setupLog.Error(err, "invalid logging selector", "selector", loggingSelector)
+		os.Exit(1)
+	}
+
 	mgrOptions := ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
-		LeaderElection:     enableLeaderElection,
-		LeaderElectionID:   "logging-operator." + loggingv1beta1.GroupVersion.Group,
+		LeaderElection:     enableLeaderElection && !validateOnly,
+		LeaderElectionID:   leaderElectionID(loggingSelector),
 		MapperProvider:     k8sutil.NewCached,
 		Port:               9443,
 	}
 
-	customMgrOptions, err := setupCustomCache(&mgrOptions, namespace, loggingRef)
+	customMgrOptions, err := setupCustomCache(&mgrOptions, namespace, loggingRef, loggingLabelSelector)
 	if err != nil {
 		setupLog.Error(err, "unable to set up custom cache settings")
 		os.Exit(1)
@@ -149,33 +195,52 @@ func main() {
 		}
 	}
 
-	if err := detectContainerRuntime(ctx, mgr.GetAPIReader()); err != nil {
-		setupLog.Error(err, "failed to detect container runtime")
-		os.Exit(1)
-	}
+	if validateOnly {
+		setupLog.Info("running in validate-only mode, resources are not reconciled")
+		if renderPreviewAddr != "" {
+			if err := mgr.Add(renderPreviewServer(renderPreviewAddr)); err != nil {
+				setupLog.Error(err, "unable to set up the render preview server")
+				os.Exit(1)
+			}
+		}
+	} else {
+		if err := detectContainerRuntime(ctx, mgr.GetAPIReader()); err != nil {
+			setupLog.Error(err, "failed to detect container runtime")
+			os.Exit(1)
+		}
 
-	loggingReconciler := loggingControllers.NewLoggingReconciler(mgr.GetClient(), ctrl.Log.WithName("controllers").WithName("Logging"))
+		loggingReconciler := loggingControllers.NewLoggingReconciler(mgr.GetClient(), ctrl.Log.WithName("controllers").WithName("Logging"))
+		loggingReconciler.Recorder = mgr.GetEventRecorderFor("logging-operator")
+		loggingReconciler.ServerSideApply = serverSideApply
 
-	if err := (&extensionsControllers.EventTailerReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("EventTailer"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "EventTailer")
-		os.Exit(1)
-	}
-	if err := (&extensionsControllers.HostTailerReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("HostTailer"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "HostTailer")
-		os.Exit(1)
-	}
+		if err := (&extensionsControllers.EventTailerReconciler{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("controllers").WithName("EventTailer"),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "EventTailer")
+			os.Exit(1)
+		}
+		if err := (&extensionsControllers.HostTailerReconciler{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("controllers").WithName("HostTailer"),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "HostTailer")
+			os.Exit(1)
+		}
 
-	if err := loggingControllers.SetupLoggingWithManager(mgr, ctrl.Log.WithName("manager")).Complete(loggingReconciler); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Logging")
-		os.Exit(1)
+		loggingControllerOptions := controller.Options{
+			MaxConcurrentReconciles: maxConcurrentReconciles,
+			RateLimiter: workqueue.NewMaxOfRateLimiter(
+				workqueue.NewItemExponentialFailureRateLimiter(reconcileBackoffBase, reconcileBackoffMax),
+				&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(reconcileRateLimitQPS), reconcileRateLimitBurst)},
+			),
+		}
+		if err := loggingControllers.SetupLoggingWithManager(mgr, ctrl.Log.WithName("manager")).WithOptions(loggingControllerOptions).Complete(loggingReconciler); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Logging")
+			os.Exit(1)
+		}
 	}
 
 	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
@@ -198,9 +263,10 @@ func main() {
 			webhookServer.CertDir = config.TailerWebhook.CertDir
 		}
 
-		setupLog.Info("Registering webhooks...")
-		webhookServer.Register(config.TailerWebhook.ServerPath, &webhook.Admission{Handler: podhandler.NewPodHandler(mgr.GetClient())})
-
+		if !validateOnly {
+			setupLog.Info("Registering webhooks...")
+			webhookServer.Register(config.TailerWebhook.ServerPath, &webhook.Admission{Handler: podhandler.NewPodHandler(mgr.GetClient())})
+		}
 	}
 
 	// +kubebuilder:scaffold:builder
@@ -211,6 +277,27 @@ func main() {
 	}
 }
 
+// renderPreviewServer serves the render preview on its own address instead of the metrics one, as it is not
+// authenticated and renders arbitrary resources posted to it
+func renderPreviewServer(addr string) manager.RunnableFunc {
+	return func(ctx context.Context) error {
+		mux := http.NewServeMux()
+		mux.Handle("/render", render.PreviewHandler())
+		server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+		setupLog.Info("serving the render preview", "address", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return errors.WrapIfWithDetails(err, "render preview server failed", "address", addr)
+		}
+		return nil
+	}
+}
+
 func detectContainerRuntime(ctx context.Context, c client.Reader) error {
 	var nodeList corev1.NodeList
 	if err := c.List(ctx, &nodeList, client.Limit(1)); err != nil {
@@ -229,8 +316,8 @@ func detectContainerRuntime(ctx context.Context, c client.Reader) error {
 	return nil
 }
 
-func setupCustomCache(mgrOptions *ctrl.Options, namespace string, loggingRef string) (*ctrl.Options, error) {
-	if namespace == "" && loggingRef == "" {
+func setupCustomCache(mgrOptions *ctrl.Options, namespace string, loggingRef string, loggingSelector labels.Selector) (*ctrl.Options, error) {
+	if namespace == "" && loggingRef == "" && loggingSelector.Empty() {
 		return mgrOptions, nil
 	}
 
@@ -266,7 +353,27 @@ func setupCustomCache(mgrOptions *ctrl.Options, namespace string, loggingRef str
 		},
 	}
 
+	if !loggingSelector.Empty() {
+		// Only the loggings and tailers of the shard are visible for the controllers, otherwise every shard would
+		// reconcile the same tailers
+		for _, obj := range []client.Object{&loggingv1beta1.Logging{}, &extensionsv1alpha1.EventTailer{}, &extensionsv1alpha1.HostTailer{}} {
+			selectorsByObject[obj] = cache.ObjectSelector{
+				Label: loggingSelector,
+			}
+		}
+	}
+
 	mgrOptions.NewCache = cache.BuilderWithOptions(cache.Options{SelectorsByObject: selectorsByObject})
 
 	return mgrOptions, nil
 }
+
+// leaderElectionID returns a distinct leader election ID for each shard of loggings
+func leaderElectionID(loggingSelector string) string {
+	if loggingSelector == "" {
+		return "logging-operator." + loggingv1beta1.GroupVersion.Group
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(loggingSelector))
+	return fmt.Sprintf("logging-operator-%x.%s", h.Sum32(), loggingv1beta1.GroupVersion.Group)
+}
@@ -0,0 +1,71 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"io"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// DecodeObjects decodes the YAML or JSON documents of the reader into the typed objects of the scheme
+func DecodeObjects(scheme *runtime.Scheme, r io.Reader) ([]client.Object, error) {
+	var objects []client.Object
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		u := &unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); err != nil {
+			if err == io.EOF {
+				return objects, nil
+			}
+			return nil, errors.WrapIf(err, "failed to decode resource")
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+		typed, err := scheme.New(u.GroupVersionKind())
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "unsupported resource", "kind", u.GetKind())
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, typed); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to convert resource", "name", u.GetName())
+		}
+		objects = append(objects, typed.(client.Object))
+	}
+}
+
+// SelectLogging returns the logging with the given name, or the only one if the name is empty
+func SelectLogging(ctx context.Context, c client.Reader, name string) (*v1beta1.Logging, error) {
+	var loggings v1beta1.LoggingList
+	if err := c.List(ctx, &loggings); err != nil {
+		return nil, errors.WrapIf(err, "failed to list loggings")
+	}
+	for i := range loggings.Items {
+		if loggings.Items[i].Name == name || (name == "" && len(loggings.Items) == 1) {
+			return &loggings.Items[i], nil
+		}
+	}
+	if name == "" {
+		return nil, errors.Errorf("found %d loggings, select one by name", len(loggings.Items))
+	}
+	return nil, errors.Errorf("logging %s not found", name)
+}
@@ -0,0 +1,62 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// maxPreviewSize limits the size of the resources posted to the preview handler
+const maxPreviewSize = 8 << 20
+
+// PreviewHandler renders the fluentd configuration of the resources posted as YAML or JSON documents, without
+// reaching the cluster. The logging is selected by the logging query parameter if the resources hold more than one.
+// The problems of the resources follow the configuration as comments.
+func PreviewHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "resources have to be posted", http.StatusMethodNotAllowed)
+			return
+		}
+		objects, err := DecodeObjects(Scheme(), http.MaxBytesReader(w, req.Body, maxPreviewSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c := NewReader(objects...)
+		logging, err := SelectLogging(req.Context(), c, req.URL.Query().Get("logging"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := RenderWithClient(req.Context(), c, *logging)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, "# %s\n", err)
+		} else {
+			fmt.Fprint(w, result.Config)
+		}
+		if result != nil && len(result.Problems) > 0 {
+			fmt.Fprintln(w, "\n# Problems:")
+			for _, p := range result.Problems {
+				fmt.Fprintf(w, "#   %s\n", p)
+			}
+		}
+	})
+}
@@ -0,0 +1,76 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const previewResources = `
+apiVersion: logging.banzaicloud.io/v1beta1
+kind: Logging
+metadata:
+  name: test
+spec:
+  controlNamespace: logging
+  fluentd: {}
+---
+apiVersion: logging.banzaicloud.io/v1beta1
+kind: Output
+metadata:
+  name: devnull
+  namespace: app
+spec:
+  nullout: {}
+---
+apiVersion: logging.banzaicloud.io/v1beta1
+kind: Flow
+metadata:
+  name: flow
+  namespace: app
+spec:
+  localOutputRefs: [%s]
+`
+
+func TestPreviewHandler(t *testing.T) {
+	preview := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		PreviewHandler().ServeHTTP(rec, httptest.NewRequest(method, "/render", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := preview(http.MethodPost, strings.Replace(previewResources, "%s", "devnull", 1))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), "@type null") {
+		t.Errorf("expected the rendered config, got:\n%s", rec.Body)
+	}
+
+	rec = preview(http.MethodPost, strings.Replace(previewResources, "%s", "missing", 1))
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "# Problems:") {
+		t.Errorf("expected the problems of the missing output, got %d:\n%s", rec.Code, rec.Body)
+	}
+
+	if rec := preview(http.MethodPost, "kind: Unknown\napiVersion: v1\n"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected bad request for an unknown kind, got %d", rec.Code)
+	}
+	if rec := preview(http.MethodGet, ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected method not allowed, got %d", rec.Code)
+	}
+}
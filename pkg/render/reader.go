@@ -0,0 +1,142 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// NewReader returns a reader serving the given objects. Namespaces of the namespaced objects are added
+// unless present, as the namespaces are listed to find the flows and outputs.
+func NewReader(objects ...client.Object) client.Reader {
+	objects = append([]client.Object(nil), objects...)
+	namespaces := make(map[string]bool)
+	for _, obj := range objects {
+		if ns, ok := obj.(*corev1.Namespace); ok {
+			namespaces[ns.Name] = true
+		}
+	}
+	for _, obj := range objects {
+		if ns := obj.GetNamespace(); ns != "" && !namespaces[ns] {
+			namespaces[ns] = true
+			objects = append(objects, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})
+		}
+	}
+	// Lists are ordered like the ones of the API server
+	sort.SliceStable(objects, func(i, j int) bool {
+		if objects[i].GetNamespace() != objects[j].GetNamespace() {
+			return objects[i].GetNamespace() < objects[j].GetNamespace()
+		}
+		return objects[i].GetName() < objects[j].GetName()
+	})
+	return &objectReader{scheme: Scheme(), objects: objects}
+}
+
+// objectReader reads the objects it was created with, it supports the namespace and label selection of lists only
+type objectReader struct {
+	scheme  *runtime.Scheme
+	objects []client.Object
+}
+
+func (r *objectReader) Get(_ context.Context, key client.ObjectKey, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return err
+	}
+	for _, o := range r.objects {
+		if o.GetName() != key.Name || o.GetNamespace() != key.Namespace {
+			continue
+		}
+		if ogvk, err := apiutil.GVKForObject(o, r.scheme); err != nil || ogvk.GroupKind() != gvk.GroupKind() {
+			continue
+		}
+		return r.convert(o, obj, gvk)
+	}
+	return apierrors.NewNotFound(resourceFor(gvk), key.Name)
+}
+
+func (r *objectReader) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.FieldSelector != nil && !listOpts.FieldSelector.Empty() {
+		return errors.New("field selectors are not supported")
+	}
+	selector := listOpts.LabelSelector
+	if selector == nil {
+		selector = labels.Everything()
+	}
+
+	listGVK, err := apiutil.GVKForObject(list, r.scheme)
+	if err != nil {
+		return err
+	}
+	gvk := listGVK.GroupVersion().WithKind(strings.TrimSuffix(listGVK.Kind, "List"))
+	var items []runtime.Object
+	for _, o := range r.objects {
+		if listOpts.Namespace != "" && o.GetNamespace() != listOpts.Namespace {
+			continue
+		}
+		if !selector.Matches(labels.Set(o.GetLabels())) {
+			continue
+		}
+		if ogvk, err := apiutil.GVKForObject(o, r.scheme); err != nil || ogvk.GroupKind() != gvk.GroupKind() {
+			continue
+		}
+		var item runtime.Object
+		if _, ok := list.(*unstructured.UnstructuredList); ok {
+			item = &unstructured.Unstructured{}
+		} else if item, err = r.scheme.New(gvk); err != nil {
+			return err
+		}
+		if err := r.convert(o, item, gvk); err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+	return meta.SetList(list, items)
+}
+
+// convert copies the object into the target, which may be the typed or the unstructured form of the object
+func (r *objectReader) convert(from client.Object, to runtime.Object, gvk schema.GroupVersionKind) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(from)
+	if err != nil {
+		return err
+	}
+	if u, ok := to.(*unstructured.Unstructured); ok {
+		u.SetUnstructuredContent(content)
+		u.SetGroupVersionKind(gvk)
+		return nil
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(content, to)
+}
+
+func resourceFor(gvk schema.GroupVersionKind) schema.GroupResource {
+	resource, _ := meta.UnsafeGuessKindToResource(gvk)
+	return resource.GroupResource()
+}
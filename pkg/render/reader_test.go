@@ -0,0 +1,83 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestObjectReader(t *testing.T) {
+	ctx := context.Background()
+	r := NewReader(
+		&v1beta1.Flow{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "app", Labels: map[string]string{"team": "web"}}},
+		&v1beta1.Flow{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "app"}},
+		&v1beta1.Flow{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "other"}},
+		&v1beta1.Output{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "app"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app", Labels: map[string]string{"env": "prod"}}},
+	)
+
+	flowNames := func(opts ...client.ListOption) []string {
+		var list v1beta1.FlowList
+		if err := r.List(ctx, &list, opts...); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, f := range list.Items {
+			names = append(names, f.Namespace+"/"+f.Name)
+		}
+		return names
+	}
+	if got, want := flowNames(), []string{"app/a", "app/b", "other/a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("all flows = %v, want %v", got, want)
+	}
+	if got, want := flowNames(client.InNamespace("app")), []string{"app/a", "app/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("flows in namespace = %v, want %v", got, want)
+	}
+	if got, want := flowNames(client.MatchingLabels{"team": "web"}), []string{"app/a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("selected flows = %v, want %v", got, want)
+	}
+
+	var namespaces corev1.NamespaceList
+	if err := r.List(ctx, &namespaces); err != nil {
+		t.Fatal(err)
+	}
+	if len(namespaces.Items) != 2 || namespaces.Items[0].Labels["env"] != "prod" || namespaces.Items[1].Name != "other" {
+		t.Errorf("expected the given and the missing namespaces, got %+v", namespaces.Items)
+	}
+
+	var output v1beta1.Output
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "app", Name: "a"}, &output); err != nil || output.Name != "a" {
+		t.Errorf("failed to get output: %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "app", Name: "missing"}, &output); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	bundles := &unstructured.UnstructuredList{}
+	bundles.SetGroupVersionKind(clusterTrustBundleGVK.GroupVersion().WithKind(clusterTrustBundleGVK.Kind + "List"))
+	if err := r.List(ctx, bundles); err != nil || len(bundles.Items) != 0 {
+		t.Errorf("expected no unstructured items, got %v %v", bundles.Items, err)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package render generates the fluentd configuration of a Logging the same way the operator does,
+// either from typed resources or from the resources available through a client.
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	fluentrender "github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/render"
+)
+
+// Resources are the resources the configuration of the Logging is generated from
+type Resources struct {
+	Logging        v1beta1.Logging
+	Flows          []v1beta1.Flow
+	ClusterFlows   []v1beta1.ClusterFlow
+	Outputs        []v1beta1.Output
+	ClusterOutputs []v1beta1.ClusterOutput
+	// Secrets referenced by the outputs and filters
+	Secrets []corev1.Secret
+	// Namespaces are needed only for their labels, e.g. to evaluate the watchNamespaceSelector or namespace quotas
+	Namespaces []corev1.Namespace
+}
+
+// Result is the generated configuration together with everything needed to run it
+type Result struct {
+	// Config is the fluentd configuration generated from the flows and outputs
+	Config string
+	// MountSecrets are the secrets mounted into the fluentd pods, referenced from the configuration
+	MountSecrets secret.MountSecrets
+	// Problems found in the resources, as reported in their status by the operator
+	Problems []Problem
+}
+
+// Problem of a Flow, ClusterFlow, Output or ClusterOutput
+type Problem struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Message   string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s %s/%s: %s", p.Kind, p.Namespace, p.Name, p.Message)
+}
+
+// Scheme returns a scheme with the types the configuration is generated from
+func Scheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1beta1.AddToScheme(scheme)
+	return scheme
+}
+
+// Render generates the configuration from the given resources, without a cluster
+func Render(ctx context.Context, resources Resources) (*Result, error) {
+	return RenderWithClient(ctx, NewReader(resources.Objects()...), resources.Logging)
+}
+
+// Objects returns copies of the resources as client objects
+func (r Resources) Objects() []client.Object {
+	objects := []client.Object{r.Logging.DeepCopy()}
+	for i := range r.Flows {
+		objects = append(objects, r.Flows[i].DeepCopy())
+	}
+	for i := range r.ClusterFlows {
+		objects = append(objects, r.ClusterFlows[i].DeepCopy())
+	}
+	for i := range r.Outputs {
+		objects = append(objects, r.Outputs[i].DeepCopy())
+	}
+	for i := range r.ClusterOutputs {
+		objects = append(objects, r.ClusterOutputs[i].DeepCopy())
+	}
+	for i := range r.Secrets {
+		objects = append(objects, r.Secrets[i].DeepCopy())
+	}
+	for i := range r.Namespaces {
+		objects = append(objects, r.Namespaces[i].DeepCopy())
+	}
+	return objects
+}
+
+// RenderWithClient generates the configuration of the logging from the resources available through the reader.
+// The resources are not modified, the problems found are returned instead of being written into their status.
+func RenderWithClient(ctx context.Context, c client.Reader, logging v1beta1.Logging) (*Result, error) {
+	if err := model.ApplyLoggingProfile(ctx, c, &logging); err != nil {
+		return nil, err
+	}
+	if err := logging.SetDefaults(); err != nil {
+		return nil, err
+	}
+	if logging.Spec.FluentdSpec == nil {
+		return nil, errors.Errorf("logging %s has no fluentd configured", logging.Name)
+	}
+
+	resources, err := model.NewLoggingResourceRepository(c).LoggingResourcesFor(ctx, logging)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to get logging resources")
+	}
+	secrets := &SecretLoaderFactory{
+		Client:           c,
+		SecretNamespaces: logging.Spec.SecretNamespaces,
+	}
+
+	// The validation updates the statuses of the resources in place, the patches are discarded
+	if _, err := model.NewValidationReconciler(ctx, discardStatusClient{}, resources, secrets)(); err != nil {
+		return nil, errors.WrapIf(err, "failed to validate resources")
+	}
+	result := &Result{
+		Problems: problems(resources),
+	}
+
+	config, mountSecrets, err := Config(resources, secrets, nil, logr.Discard())
+	if err != nil {
+		return result, err
+	}
+	result.Config = config
+	result.MountSecrets = mountSecrets
+	return result, nil
+}
+
+// Config renders the fluentd configuration of the resources. Flows are reused from the cache if given.
+func Config(resources model.LoggingResources, secrets *SecretLoaderFactory, cache *model.FlowCache, logger logr.Logger) (string, secret.MountSecrets, error) {
+	system, err := model.CreateSystem(resources, secrets, cache, logger)
+	if err != nil {
+		return "", nil, errors.WrapIfWithDetails(err, "failed to build model", "logging", resources.Logging.Name)
+	}
+
+	output := &bytes.Buffer{}
+	extraConfig := resources.Logging.Spec.FluentdSpec.ExtraConfig
+	if extraConfig != nil {
+		writeFragment(output, extraConfig.Prepend)
+	}
+	renderer := fluentrender.FluentRender{
+		Out:    output,
+		Indent: 2,
+	}
+	if err := renderer.Render(system); err != nil {
+		return "", nil, errors.WrapIfWithDetails(err, "failed to render fluentd config", "logging", resources.Logging.Name)
+	}
+	if extraConfig != nil {
+		writeFragment(output, extraConfig.Append)
+	}
+	return output.String(), UniqueMountSecrets(secrets.Secrets), nil
+}
+
+// writeFragment writes a raw configuration fragment, terminated by a newline
+func writeFragment(output *bytes.Buffer, fragment string) {
+	if fragment == "" {
+		return
+	}
+	output.WriteString(fragment)
+	if !strings.HasSuffix(fragment, "\n") {
+		output.WriteString("\n")
+	}
+}
+
+func problems(resources model.LoggingResources) (res []Problem) {
+	add := func(kind string, meta metav1.ObjectMeta, problems []string) {
+		for _, p := range problems {
+			res = append(res, Problem{Kind: kind, Namespace: meta.Namespace, Name: meta.Name, Message: p})
+		}
+	}
+	// The rejected resources are appended to copies, appending to the accepted ones could overwrite the elements
+	// beyond their length shared with the caller
+	for _, o := range append(append(model.ClusterOutputs{}, resources.ClusterOutputs...), resources.RejectedClusterOutputs...) {
+		add("ClusterOutput", o.ObjectMeta, o.Status.Problems)
+	}
+	for _, o := range append(append(model.Outputs{}, resources.Outputs...), resources.RejectedOutputs...) {
+		add("Output", o.ObjectMeta, o.Status.Problems)
+	}
+	for _, f := range append(append([]v1beta1.ClusterFlow{}, resources.ClusterFlows...), resources.RejectedClusterFlows...) {
+		add("ClusterFlow", f.ObjectMeta, f.Status.Problems)
+	}
+	for _, f := range append(append([]v1beta1.Flow{}, resources.Flows...), resources.RejectedFlows...) {
+		add("Flow", f.ObjectMeta, f.Status.Problems)
+	}
+	return
+}
+
+type discardStatusClient struct{}
+
+func (discardStatusClient) Status() client.StatusWriter {
+	return discardStatusWriter{}
+}
+
+type discardStatusWriter struct{}
+
+func (discardStatusWriter) Update(context.Context, client.Object, ...client.UpdateOption) error {
+	return nil
+}
+
+func (discardStatusWriter) Patch(context.Context, client.Object, client.Patch, ...client.PatchOption) error {
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+)
+
+func TestRender(t *testing.T) {
+	resources := Resources{
+		Logging: v1beta1.Logging{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec: v1beta1.LoggingSpec{
+				ControlNamespace: "logging",
+				FluentdSpec:      &v1beta1.FluentdSpec{},
+			},
+		},
+		Outputs: []v1beta1.Output{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "null", Namespace: "app"},
+				Spec:       v1beta1.OutputSpec{NullOutputConfig: output.NewNullOutputConfig()},
+			},
+		},
+		Flows: []v1beta1.Flow{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "flow", Namespace: "app"},
+				Spec:       v1beta1.FlowSpec{LocalOutputRefs: []string{"null"}},
+			},
+		},
+	}
+
+	result, err := Render(context.Background(), resources)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	for _, expected := range []string{"@id flow:app:flow:output:app:null", "@type null"} {
+		if !strings.Contains(result.Config, expected) {
+			t.Errorf("expected %q in config:\n%s", expected, result.Config)
+		}
+	}
+	if len(result.Problems) != 0 {
+		t.Errorf("unexpected problems: %v", result.Problems)
+	}
+
+	resources.Flows = append(resources.Flows, v1beta1.Flow{
+		ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "app"},
+		Spec:       v1beta1.FlowSpec{LocalOutputRefs: []string{"missing"}},
+	})
+	result, err = Render(context.Background(), resources)
+	if err == nil {
+		t.Fatal("expected an error for the missing output")
+	}
+	if result == nil || len(result.Problems) != 1 || result.Problems[0].Name != "missing" {
+		t.Errorf("expected a single problem of the flow with the missing output, got %v", result)
+	}
+}
+
+func TestRenderOTLP(t *testing.T) {
+	resources := Resources{
+		Logging: v1beta1.Logging{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec: v1beta1.LoggingSpec{
+				ControlNamespace: "logging",
+				FluentdSpec:      &v1beta1.FluentdSpec{},
+			},
+		},
+		Outputs: []v1beta1.Output{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "otel", Namespace: "app"},
+				Spec: v1beta1.OutputSpec{OTLPOutput: &output.OTLPOutput{
+					Endpoint: "otel-collector:4317",
+					Headers:  output.OTLPHeaders{"x-tenant": {Value: "app"}},
+				}},
+			},
+		},
+		Flows: []v1beta1.Flow{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "flow", Namespace: "app"},
+				Spec:       v1beta1.FlowSpec{LocalOutputRefs: []string{"otel"}},
+			},
+		},
+	}
+
+	result, err := Render(context.Background(), resources)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	for _, expected := range []string{
+		"@type opentelemetry",
+		"@id flow:app:flow:output:app:otel",
+		"endpoint otel-collector:4317",
+		"k8s.namespace.name $.kubernetes.namespace_name",
+		"x-tenant app",
+	} {
+		if !strings.Contains(result.Config, expected) {
+			t.Errorf("expected %q in config:\n%s", expected, result.Config)
+		}
+	}
+}
+
+func TestRenderWithExtraConfig(t *testing.T) {
+	logging := v1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: v1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			FluentdSpec: &v1beta1.FluentdSpec{
+				ExtraConfig: &v1beta1.FluentdExtraConfig{
+					Prepend: "<source>\n  @type sample\n  @label @SAMPLE\n</source>",
+					Append:  "<label @SAMPLE>\n  <match **>\n    @type null\n  </match>\n</label>\n",
+				},
+			},
+		},
+	}
+
+	result, err := RenderWithClient(context.Background(), NewReader(&logging), logging)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if !strings.HasPrefix(result.Config, "<source>\n  @type sample\n  @label @SAMPLE\n</source>\n<source>\n  @type forward") {
+		t.Errorf("expected the prepended fragment before the generated config:\n%s", result.Config)
+	}
+	if !strings.HasSuffix(result.Config, "</label>\n<label @SAMPLE>\n  <match **>\n    @type null\n  </match>\n</label>\n") {
+		t.Errorf("expected the appended fragment after the generated config:\n%s", result.Config)
+	}
+}
+
+func TestProblemsKeepResources(t *testing.T) {
+	// Spare capacity behind the accepted outputs, appending the rejected ones in place would overwrite it
+	backing := make(model.Outputs, 2)
+	backing[1].Name = "spare"
+	resources := model.LoggingResources{
+		Outputs: backing[:1],
+		RejectedOutputs: model.Outputs{{
+			ObjectMeta: metav1.ObjectMeta{Name: "rejected", Namespace: "app"},
+			Status:     v1beta1.OutputStatus{Problems: []string{"invalid"}},
+		}},
+	}
+
+	got := problems(resources)
+	if len(got) != 1 || got[0].Name != "rejected" {
+		t.Errorf("unexpected problems %+v", got)
+	}
+	if backing[1].Name != "spare" {
+		t.Errorf("the outputs were overwritten by the rejected ones: %q", backing[1].Name)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// clusterTrustBundleGVK is still alpha, so it is read as unstructured
+var clusterTrustBundleGVK = schema.GroupVersionKind{Group: "certificates.k8s.io", Version: "v1alpha1", Kind: "ClusterTrustBundle"}
+
+// SecretLoaderFactory loads the secrets referenced by the outputs and collects the ones to be mounted into fluentd
+type SecretLoaderFactory struct {
+	Client  client.Reader
+	Secrets secret.MountSecrets
+	// SecretNamespaces are the namespaces the ClusterOutputs can reference secrets from as <namespace>/<name>
+	SecretNamespaces []string
+}
+
+func (f *SecretLoaderFactory) OutputSecretLoaderForNamespace(namespace string) secret.SecretLoader {
+	return &namespacedSecretLoader{
+		factory:   f,
+		namespace: namespace,
+	}
+}
+
+func (f *SecretLoaderFactory) ClusterOutputSecretLoaderForNamespace(namespace string) secret.SecretLoader {
+	return &namespacedSecretLoader{
+		factory:        f,
+		namespace:      namespace,
+		crossNamespace: true,
+	}
+}
+
+// namespacedSecretLoader loads secrets from the namespace of the output,
+// or from one of the allowed secret namespaces when the reference is qualified with it
+type namespacedSecretLoader struct {
+	factory   *SecretLoaderFactory
+	namespace string
+	// crossNamespace allows the references to the secret namespaces, they are shared by the ClusterOutputs only,
+	// otherwise anyone allowed to create an Output could read the central credentials
+	crossNamespace bool
+}
+
+func (l *namespacedSecretLoader) Load(s *secret.Secret) (string, error) {
+	var ref *secret.ValueFrom
+	switch {
+	case s.Value != "":
+	case s.MountFrom != nil:
+		ref = s.MountFrom
+	case s.ValueFrom != nil:
+		ref = s.ValueFrom
+	}
+	if ref == nil || ref.SecretKeyRef == nil || !strings.Contains(ref.SecretKeyRef.Name, "/") {
+		return l.loader(l.namespace).Load(s)
+	}
+
+	namespace, name := splitSecretName(ref.SecretKeyRef.Name)
+	if err := l.allowed(namespace); err != nil {
+		return "", errors.WrapIff(err, "secret %q cannot be referenced", ref.SecretKeyRef.Name)
+	}
+	local := s.DeepCopy()
+	if local.MountFrom != nil {
+		local.MountFrom.SecretKeyRef.Name = name
+	} else {
+		local.ValueFrom.SecretKeyRef.Name = name
+	}
+	return l.loader(namespace).Load(local)
+}
+
+// LoadTLSSource mounts the PEM bundle of the source next to the secrets of the outputs. The sources are not marked
+// like the secrets, their changes are picked up with the next reconcile.
+func (l *namespacedSecretLoader) LoadTLSSource(source *v1beta1.TLSSource) (string, []byte, error) {
+	var mounted secret.MountSecret
+	switch {
+	case source.ConfigMapKeyRef != nil:
+		ref := source.ConfigMapKeyRef
+		namespace, name := l.namespace, ref.Name
+		if strings.Contains(name, "/") {
+			namespace, name = splitSecretName(name)
+			if err := l.allowed(namespace); err != nil {
+				return "", nil, errors.WrapIff(err, "configmap %q cannot be referenced", ref.Name)
+			}
+		}
+		var configMap corev1.ConfigMap
+		if err := l.factory.Client.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: name}, &configMap); err != nil {
+			return "", nil, errors.WrapIfWithDetails(err, "failed to load configmap", "configmap", name, "namespace", namespace)
+		}
+		value, ok := configMap.Data[ref.Key]
+		if !ok {
+			return "", nil, errors.Errorf("key %q not found in configmap %q in namespace %q", ref.Key, name, namespace)
+		}
+		mounted = secret.MountSecret{
+			Namespace: namespace,
+			Key:       fmt.Sprintf("configmap %s/%s %s", namespace, name, ref.Key),
+			MappedKey: fmt.Sprintf("configmap-%s-%s-%s", namespace, name, ref.Key),
+			Value:     []byte(value),
+		}
+	case source.ClusterTrustBundle != nil:
+		value, id, err := l.clusterTrustBundle(source.ClusterTrustBundle)
+		if err != nil {
+			return "", nil, err
+		}
+		mounted = secret.MountSecret{
+			Key:       "clustertrustbundle " + id,
+			MappedKey: "clustertrustbundle-" + strings.NewReplacer("/", "-", ":", "-").Replace(id),
+			Value:     value,
+		}
+	default:
+		return "", nil, errors.New("no configMapKeyRef or clusterTrustBundle defined for the TLS source")
+	}
+	l.factory.Secrets = append(l.factory.Secrets, mounted)
+	return fluentd.OutputSecretPath + "/" + mounted.MappedKey, mounted.Value, nil
+}
+
+// clusterTrustBundle returns the concatenated trust bundles of the selected ClusterTrustBundles, and an identifier
+// of the selection usable in file names
+func (l *namespacedSecretLoader) clusterTrustBundle(source *v1beta1.ClusterTrustBundleSource) ([]byte, string, error) {
+	trustBundle := func(o unstructured.Unstructured) string {
+		bundle, _, _ := unstructured.NestedString(o.Object, "spec", "trustBundle")
+		return bundle
+	}
+	if source.Name != "" {
+		o := unstructured.Unstructured{}
+		o.SetGroupVersionKind(clusterTrustBundleGVK)
+		if err := l.factory.Client.Get(context.TODO(), client.ObjectKey{Name: source.Name}, &o); err != nil {
+			return nil, "", errors.WrapIfWithDetails(err, "failed to load clustertrustbundle", "name", source.Name)
+		}
+		return []byte(trustBundle(o)), source.Name, nil
+	}
+	if source.SignerName == "" {
+		return nil, "", errors.New("clusterTrustBundle needs a name or a signerName")
+	}
+
+	list := unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(clusterTrustBundleGVK.GroupVersion().WithKind(clusterTrustBundleGVK.Kind + "List"))
+	id := source.SignerName
+	var opts []client.ListOption
+	if source.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(source.LabelSelector)
+		if err != nil {
+			return nil, "", errors.WrapIf(err, "invalid clusterTrustBundle label selector")
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(selector.String()))
+		id = fmt.Sprintf("%s-%x", id, h.Sum32())
+	}
+	if err := l.factory.Client.List(context.TODO(), &list, opts...); err != nil {
+		return nil, "", errors.WrapIfWithDetails(err, "failed to list clustertrustbundles", "signerName", source.SignerName)
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].GetName() < list.Items[j].GetName() })
+	var bundles []string
+	for _, o := range list.Items {
+		if signer, _, _ := unstructured.NestedString(o.Object, "spec", "signerName"); signer == source.SignerName {
+			bundles = append(bundles, strings.TrimSpace(trustBundle(o)))
+		}
+	}
+	if len(bundles) == 0 {
+		return nil, "", errors.Errorf("no clustertrustbundle found for signer %q", source.SignerName)
+	}
+	return []byte(strings.Join(bundles, "\n") + "\n"), id, nil
+}
+
+func (l *namespacedSecretLoader) loader(namespace string) secret.SecretLoader {
+	return secret.NewSecretLoader(l.factory.Client, namespace, fluentd.OutputSecretPath, &l.factory.Secrets)
+}
+
+func (l *namespacedSecretLoader) allowed(namespace string) error {
+	if !l.crossNamespace {
+		return errors.New("only ClusterOutputs can reference other namespaces")
+	}
+	for _, n := range l.factory.SecretNamespaces {
+		if n == namespace {
+			return nil
+		}
+	}
+	return errors.Errorf("namespace %q is not listed in secretNamespaces of the logging", namespace)
+}
+
+func splitSecretName(name string) (namespace string, secretName string) {
+	parts := strings.SplitN(name, "/", 2)
+	return parts[0], parts[1]
+}
+
+// UniqueMountSecrets drops the secrets mounted more than once, cached flows load their secrets again on every render
+func UniqueMountSecrets(secrets secret.MountSecrets) secret.MountSecrets {
+	seen := make(map[string]bool, len(secrets))
+	unique := make(secret.MountSecrets, 0, len(secrets))
+	for _, s := range secrets {
+		if seen[s.MappedKey] {
+			continue
+		}
+		seen[s.MappedKey] = true
+		unique = append(unique, s)
+	}
+	return unique
+}
@@ -0,0 +1,111 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestLoadTLSSource(t *testing.T) {
+	factory := &SecretLoaderFactory{
+		Client: NewReader(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "trust", Namespace: "app"},
+			Data:       map[string]string{"ca.crt": "-----BEGIN CERTIFICATE-----"},
+		}),
+	}
+	loader := factory.OutputSecretLoaderForNamespace("app").(model.TLSSourceLoader)
+	source := func(name, key string) *v1beta1.TLSSource {
+		return &v1beta1.TLSSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Key:                  key,
+		}}
+	}
+
+	path, value, err := loader.LoadTLSSource(source("trust", "ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/fluentd/secret/configmap-app-trust-ca.crt" || string(value) != "-----BEGIN CERTIFICATE-----" {
+		t.Errorf("unexpected source %s: %q", path, value)
+	}
+	want := secret.MountSecrets{{
+		Namespace: "app",
+		Key:       "configmap app/trust ca.crt",
+		MappedKey: "configmap-app-trust-ca.crt",
+		Value:     []byte("-----BEGIN CERTIFICATE-----"),
+	}}
+	if !reflect.DeepEqual(factory.Secrets, want) {
+		t.Errorf("mounted secrets = %+v, want %+v", factory.Secrets, want)
+	}
+
+	for _, s := range []*v1beta1.TLSSource{source("trust", "tls.crt"), source("missing", "ca.crt"), {}} {
+		if _, _, err := loader.LoadTLSSource(s); err == nil {
+			t.Errorf("expected an error for %+v", s)
+		}
+	}
+}
+
+func TestSecretNamespaces(t *testing.T) {
+	factory := &SecretLoaderFactory{
+		Client: NewReader(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "central", Namespace: "shared"},
+				Data:       map[string][]byte{"token": []byte("s3cr3t")},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "trust", Namespace: "shared"},
+				Data:       map[string]string{"ca.crt": "-----BEGIN CERTIFICATE-----"},
+			},
+		),
+		SecretNamespaces: []string{"shared"},
+	}
+	ref := func(name string) *secret.Secret {
+		return &secret.Secret{ValueFrom: &secret.ValueFrom{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Key:                  "token",
+		}}}
+	}
+	trust := &v1beta1.TLSSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "shared/trust"},
+		Key:                  "ca.crt",
+	}}
+
+	clusterOutput := factory.ClusterOutputSecretLoaderForNamespace("logging")
+	if value, err := clusterOutput.Load(ref("shared/central")); err != nil || value != "s3cr3t" {
+		t.Errorf("clusteroutput secret = %q, %v", value, err)
+	}
+	if _, _, err := clusterOutput.(model.TLSSourceLoader).LoadTLSSource(trust); err != nil {
+		t.Errorf("clusteroutput configmap: %v", err)
+	}
+	if _, err := clusterOutput.Load(ref("other/central")); err == nil {
+		t.Error("expected an error for a namespace not listed in secretNamespaces")
+	}
+
+	output := factory.OutputSecretLoaderForNamespace("app")
+	if _, err := output.Load(ref("shared/central")); err == nil {
+		t.Error("an output must not reference the secret namespaces")
+	}
+	if _, _, err := output.(model.TLSSourceLoader).LoadTLSSource(trust); err == nil {
+		t.Error("an output must not reference the configmaps of the secret namespaces")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+const (
+	// AppName is the value of the app.kubernetes.io/name label of the receiver pods
+	AppName = "audit-webhook"
+
+	resourceName  = "audit-webhook"
+	caSecretName  = "audit-tls-ca"
+	containerName = "fluent-bit"
+	configName    = "fluent-bit.conf"
+	scriptName    = "audit.lua"
+)
+
+// Reconciler holds info what resource to reconcile
+type Reconciler struct {
+	Logging *v1beta1.Logging
+	*reconciler.GenericResourceReconciler
+	configChecksum string
+	tlsChecksum    string
+}
+
+// New creates a new audit log receiver reconciler
+func New(client client.Client, logger logr.Logger, logging *v1beta1.Logging, opts reconciler.ReconcilerOpts) *Reconciler {
+	return &Reconciler{
+		Logging:                   logging,
+		GenericResourceReconciler: reconciler.NewGenericReconciler(client, logger, opts),
+	}
+}
+
+// Reconcile reconciles the audit log receiver
+func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
+	var objects []runtime.Object
+	secrets, err := r.tlsSecrets()
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to create desired TLS secrets")
+	}
+	objects = append(objects, secrets...)
+
+	for _, factory := range []func() (runtime.Object, error){
+		r.configSecret,
+		r.deployment,
+		r.service,
+	} {
+		o, err := factory()
+		if err != nil {
+			return nil, errors.WrapIf(err, "failed to create desired object")
+		}
+		objects = append(objects, o)
+	}
+
+	for _, o := range objects {
+		result, err := r.ReconcileResource(o, reconciler.StatePresent)
+		if err != nil {
+			return nil, errors.WrapWithDetails(err,
+				"failed to reconcile resource", "resource", o.GetObjectKind().GroupVersionKind())
+		}
+		if result != nil {
+			return result, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *Reconciler) labels() map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       AppName,
+		"app.kubernetes.io/managed-by": r.Logging.Name,
+	}
+}
+
+func (r *Reconciler) objectMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      r.Logging.QualifiedName(name),
+		Namespace: r.Logging.Spec.ControlNamespace,
+		Labels:    r.labels(),
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				APIVersion: r.Logging.APIVersion,
+				Kind:       r.Logging.Kind,
+				Name:       r.Logging.Name,
+				UID:        r.Logging.UID,
+				Controller: util.BoolPointer(true),
+			},
+		},
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"text/template"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/fluentd"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+var configTemplate = `
+[SERVICE]
+    Flush        1
+    Grace        5
+    Daemon       Off
+    Log_Level    info
+
+[INPUT]
+    Name         http
+    Listen       0.0.0.0
+    Port         {{ .Port }}
+    tls          On
+    tls.verify   Off
+    tls.crt_file /fluent-bit/tls/tls.crt
+    tls.key_file /fluent-bit/tls/tls.key
+
+[FILTER]
+    Name         lua
+    Match        *
+    script       /fluent-bit/etc/audit.lua
+    call         split_events
+
+[OUTPUT]
+    Name          forward
+    Match         *
+    Host          {{ .TargetHost }}
+    Port          {{ .TargetPort }}
+    Tag           {{ .Tag }}
+    Retry_Limit   False
+    {{- if .TLS.Enabled }}
+    tls           On
+    tls.verify    Off
+    tls.ca_file   /fluent-bit/forward-tls/ca.crt
+    tls.crt_file  /fluent-bit/forward-tls/tls.crt
+    tls.key_file  /fluent-bit/forward-tls/tls.key
+    {{- if .TLS.SharedKey }}
+    Shared_Key    {{ .TLS.SharedKey }}
+    {{- else }}
+    Empty_Shared_Key true
+    {{- end }}
+    {{- end }}
+`
+
+// The http input tags the records with the path of the request, the ones not posted to the path of the token are
+// dropped. The webhook backend posts an EventList, the events are emitted one by one with kubernetes metadata so
+// that the label router of the aggregator can route them like container logs.
+var scriptTemplate = `
+function split_events(tag, timestamp, record)
+    if tag ~= "{{ .Token }}" then
+        return -1, timestamp, record
+    end
+    if record["items"] == nil then
+        return 0, timestamp, record
+    end
+    local events = {}
+    for _, event in ipairs(record["items"]) do
+        event["kubernetes"] = {
+            namespace_name = "{{ .Namespace }}",
+            container_name = "kube-apiserver-audit",
+            labels = { ["{{ .SourceLabel }}"] = "audit" },
+        }
+        table.insert(events, event)
+    end
+    return 1, timestamp, events
+end
+`
+
+var tokenRegex = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+type forwardTLS struct {
+	Enabled    bool
+	SecretName string
+	SharedKey  string
+}
+
+type config struct {
+	Port        int32
+	Tag         string
+	Token       string
+	TargetHost  string
+	TargetPort  int32
+	TLS         forwardTLS
+	Namespace   string
+	SourceLabel string
+}
+
+// forwardTLS follows the TLS settings of the agents towards the aggregator
+func (r *Reconciler) forwardTLS() forwardTLS {
+	if r.Logging.Spec.FluentbitSpec != nil && r.Logging.Spec.FluentbitSpec.TLS != nil {
+		tls := r.Logging.Spec.FluentbitSpec.TLS
+		if tls.Enabled != nil && *tls.Enabled {
+			return forwardTLS{Enabled: true, SecretName: tls.SecretName, SharedKey: tls.SharedKey}
+		}
+		return forwardTLS{}
+	}
+	if r.Logging.Spec.FluentdSpec != nil && r.Logging.Spec.FluentdSpec.TLS.Enabled {
+		tls := r.Logging.Spec.FluentdSpec.TLS
+		return forwardTLS{Enabled: true, SecretName: tls.SecretName, SharedKey: tls.SharedKey}
+	}
+	return forwardTLS{}
+}
+
+// token loads the token of the kube-apiserver, it is the path of the webhook URL and the tag the http input gives
+// the records, so it is restricted to the characters kept in tags
+func (r *Reconciler) token() (string, error) {
+	spec := r.Logging.Spec.AuditLog
+	if spec.Token == nil || spec.Token.MountFrom != nil {
+		return "", errors.New("auditLog requires a token given as a value or valueFrom")
+	}
+	token, err := secret.NewSecretLoader(r.Client, r.Logging.Spec.ControlNamespace, "", nil).Load(spec.Token)
+	if err != nil {
+		return "", errors.WrapIf(err, "loading the token of the audit log receiver")
+	}
+	if !tokenRegex.MatchString(token) {
+		return "", errors.New("the token of the audit log receiver has to consist of letters and digits")
+	}
+	return token, nil
+}
+
+func (r *Reconciler) configSecret() (runtime.Object, error) {
+	if r.Logging.Spec.FluentdSpec == nil {
+		return nil, errors.New("the audit log receiver requires fluentd")
+	}
+	token, err := r.token()
+	if err != nil {
+		return nil, err
+	}
+	input := config{
+		Port:        r.Logging.Spec.AuditLog.Port,
+		Tag:         r.Logging.Spec.AuditLog.Tag,
+		Token:       token,
+		TargetHost:  fmt.Sprintf("%s.%s.svc.cluster.local", r.Logging.QualifiedName(fluentd.ServiceName), r.Logging.Spec.ControlNamespace),
+		TargetPort:  r.Logging.Spec.FluentdSpec.Port,
+		TLS:         r.forwardTLS(),
+		Namespace:   r.Logging.Spec.ControlNamespace,
+		SourceLabel: v1beta1.SourceLabel,
+	}
+	conf, err := render("config", configTemplate, input)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to generate config for the audit log receiver")
+	}
+	script, err := render("script", scriptTemplate, input)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to generate script for the audit log receiver")
+	}
+
+	data := map[string][]byte{
+		configName: conf,
+		scriptName: script,
+	}
+	h := sha256.New()
+	_, _ = h.Write(conf)
+	_, _ = h.Write(script)
+	r.configChecksum = fmt.Sprintf("%x", h.Sum(nil))
+
+	return &corev1.Secret{
+		ObjectMeta: r.objectMeta(resourceName),
+		Data:       data,
+	}, nil
+}
+
+func render(name, text string, input interface{}) ([]byte, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	output := new(bytes.Buffer)
+	if err := tmpl.Execute(output, input); err != nil {
+		return nil, err
+	}
+	return output.Bytes(), nil
+}
@@ -0,0 +1,90 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestConfigSecretToken(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "audit-token", Namespace: "logging"},
+		Data:       map[string][]byte{"token": []byte("fromSecret42")},
+	}).Build()
+	valueFrom := &secret.Secret{ValueFrom: &secret.ValueFrom{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "audit-token"},
+		Key:                  "token",
+	}}}
+	tests := []struct {
+		name      string
+		token     *secret.Secret
+		wantToken string
+		wantErr   bool
+	}{
+		{name: "value", token: &secret.Secret{Value: "s3cr3t"}, wantToken: "s3cr3t"},
+		{name: "valueFrom", token: valueFrom, wantToken: "fromSecret42"},
+		{name: "missing", wantErr: true},
+		{name: "empty", token: &secret.Secret{Value: ""}, wantErr: true},
+		{name: "mounted", token: &secret.Secret{MountFrom: &secret.ValueFrom{}}, wantErr: true},
+		{name: "not a tag", token: &secret.Secret{Value: "s3cr3t/token"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			logging := &v1beta1.Logging{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1beta1.LoggingSpec{
+					ControlNamespace: "logging",
+					FluentdSpec:      &v1beta1.FluentdSpec{Port: 24240},
+					AuditLog:         &v1beta1.AuditLog{Port: 9880, Tag: "audit", Token: tt.token},
+				},
+			}
+			o, err := New(c, logr.Discard(), logging, reconciler.ReconcilerOpts{}).configSecret()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("configSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data := o.(*corev1.Secret).Data
+			if script := string(data[scriptName]); !strings.Contains(script, `if tag ~= "`+tt.wantToken+`" then`) {
+				t.Errorf("the records are not checked against the token:\n%s", script)
+			}
+			conf := string(data[configName])
+			if strings.Contains(conf, tt.wantToken) {
+				t.Errorf("the token is forwarded as the tag:\n%s", conf)
+			}
+			if !strings.Contains(conf, "Tag           audit") {
+				t.Errorf("the tag of the events is not set on the forward output:\n%s", conf)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func (r *Reconciler) deployment() (runtime.Object, error) {
+	spec := r.Logging.Spec.AuditLog
+	volumes := []corev1.Volume{
+		{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: r.Logging.QualifiedName(resourceName)},
+			},
+		},
+		{
+			Name: "tls",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: spec.SecretName},
+			},
+		},
+	}
+	mounts := []corev1.VolumeMount{
+		{Name: "config", MountPath: "/fluent-bit/etc/"},
+		{Name: "tls", MountPath: "/fluent-bit/tls/", ReadOnly: true},
+	}
+	if tls := r.forwardTLS(); tls.Enabled {
+		volumes = append(volumes, corev1.Volume{
+			Name: "forward-tls",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: tls.SecretName},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: "forward-tls", MountPath: "/fluent-bit/forward-tls/", ReadOnly: true})
+	}
+
+	return &appsv1.Deployment{
+		ObjectMeta: r.objectMeta(resourceName),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: spec.Replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: r.labels()},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: r.labels(),
+					Annotations: map[string]string{
+						"checksum/config": r.configChecksum,
+						"checksum/tls":    r.tlsChecksum,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Volumes:          volumes,
+					ImagePullSecrets: spec.Image.ImagePullSecrets,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot: util.BoolPointer(true),
+						RunAsUser:    util.IntPointer64(10000),
+						SeccompProfile: &corev1.SeccompProfile{
+							Type: corev1.SeccompProfileTypeRuntimeDefault,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            containerName,
+							Image:           spec.Image.RepositoryWithTag(),
+							ImagePullPolicy: corev1.PullPolicy(spec.Image.PullPolicy),
+							Ports: []corev1.ContainerPort{
+								{Name: "https", ContainerPort: spec.Port, Protocol: corev1.ProtocolTCP},
+							},
+							Resources:    spec.Resources,
+							VolumeMounts: mounts,
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString("https")},
+								},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								AllowPrivilegeEscalation: util.BoolPointer(false),
+								ReadOnlyRootFilesystem:   util.BoolPointer(true),
+								Capabilities: &corev1.Capabilities{
+									Drop: []corev1.Capability{"ALL"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (r *Reconciler) service() (runtime.Object, error) {
+	return &corev1.Service{
+		ObjectMeta: r.objectMeta(resourceName),
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "https",
+					Port:       443,
+					TargetPort: intstr.FromString("https"),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+			Selector: r.labels(),
+			Type:     corev1.ServiceTypeClusterIP,
+		},
+	}, nil
+}
@@ -0,0 +1,116 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/certs"
+)
+
+// tlsSecrets maintains the CA and the serving certificate of the receiver when autoGenerate is set.
+// The checksum of the serving certificate is recorded to roll the receiver on renewal.
+func (r *Reconciler) tlsSecrets() ([]runtime.Object, error) {
+	secretName := r.Logging.Spec.AuditLog.SecretName
+	if !util.PointerToBool(r.Logging.Spec.AuditLog.AutoGenerate) {
+		secret, err := r.existingSecret(secretName)
+		if err != nil {
+			return nil, err
+		}
+		r.tlsChecksum = fmt.Sprintf("%x", sha256.Sum256(secret.Data["tls.crt"]))
+		return nil, nil
+	}
+	now := time.Now()
+
+	caSecret, err := r.existingSecret(r.Logging.QualifiedName(caSecretName))
+	if err != nil {
+		return nil, err
+	}
+	ca, err := certs.ParseKeyPair(caSecret.Data["ca.crt"], caSecret.Data["ca.key"])
+	if err != nil || certs.NeedsRenewal(ca.CertPEM, nil, now, certs.RenewBefore) {
+		ca, err = certs.NewCA(r.Logging.QualifiedName("audit-ca"), certs.CAValidity)
+		if err != nil {
+			return nil, errors.WrapIf(err, "failed to generate CA")
+		}
+	}
+	caSecret.Data = map[string][]byte{
+		"ca.crt": ca.CertPEM,
+		"ca.key": ca.KeyPEM,
+	}
+
+	serverSecret, err := r.existingSecret(secretName)
+	if err != nil {
+		return nil, err
+	}
+	if len(serverSecret.Data["tls.key"]) == 0 || certs.NeedsRenewal(serverSecret.Data["tls.crt"], ca, now, certs.RenewBefore) {
+		cert, err := ca.Sign(r.serviceHost(), r.dnsNames(), x509.ExtKeyUsageServerAuth, certs.CertificateValidity)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to issue certificate", "secret", secretName)
+		}
+		serverSecret.Data = map[string][]byte{
+			"tls.crt": cert.CertPEM,
+			"tls.key": cert.KeyPEM,
+		}
+	}
+	serverSecret.Data["ca.crt"] = ca.CertPEM
+	r.tlsChecksum = fmt.Sprintf("%x", sha256.Sum256(serverSecret.Data["tls.crt"]))
+
+	return []runtime.Object{caSecret, serverSecret}, nil
+}
+
+// existingSecret returns the desired secret for the given name, carrying over the data of the existing one
+func (r *Reconciler) existingSecret(name string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: r.objectMeta(""),
+	}
+	secret.ObjectMeta.Name = name
+
+	existing := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: secret.Namespace}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.WrapIfWithDetails(err, "failed to load secret", "secret", name, "namespace", secret.Namespace)
+	}
+	secret.Data = existing.Data
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	return secret, nil
+}
+
+func (r *Reconciler) serviceHost() string {
+	return fmt.Sprintf("%s.%s.svc", r.Logging.QualifiedName(resourceName), r.Logging.Spec.ControlNamespace)
+}
+
+func (r *Reconciler) dnsNames() []string {
+	service := r.Logging.QualifiedName(resourceName)
+	namespace := r.Logging.Spec.ControlNamespace
+	return []string{
+		service,
+		fmt.Sprintf("%s.%s", service, namespace),
+		fmt.Sprintf("%s.%s.svc", service, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace),
+	}
+}
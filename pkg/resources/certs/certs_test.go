@@ -0,0 +1,69 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certs
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestSignAndRenew(t *testing.T) {
+	ca, err := NewCA("test-ca", CAValidity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := ca.Sign("fluentd", []string{"fluentd.logging.svc"}, x509.ExtKeyUsageServerAuth, CertificateValidity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	if _, err := server.Cert.Verify(x509.VerifyOptions{
+		DNSName:   "fluentd.logging.svc",
+		Roots:     pool,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}); err != nil {
+		t.Fatalf("failed to verify server certificate: %s", err)
+	}
+
+	parsed, err := ParseKeyPair(server.CertPEM, server.KeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Key.Equal(server.Key) {
+		t.Fatal("parsed key does not match the generated one")
+	}
+
+	now := time.Now()
+	if NeedsRenewal(server.CertPEM, ca, now, RenewBefore) {
+		t.Fatal("fresh certificate should not need renewal")
+	}
+	if !NeedsRenewal(server.CertPEM, ca, now.Add(CertificateValidity-RenewBefore/2), RenewBefore) {
+		t.Fatal("certificate within the renewal window should need renewal")
+	}
+	if !NeedsRenewal(nil, ca, now, RenewBefore) {
+		t.Fatal("missing certificate should need renewal")
+	}
+
+	otherCA, err := NewCA("other-ca", CAValidity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !NeedsRenewal(server.CertPEM, otherCA, now, RenewBefore) {
+		t.Fatal("certificate signed by a different CA should need renewal")
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+// DryRunClient reads through the wrapped client but only records the writes of the resource reconcilers.
+// Objects already in the desired state are not written by the reconcilers, so the recorded objects are
+// the changes that would be applied.
+type DryRunClient struct {
+	client.Client
+
+	mu      sync.Mutex
+	order   []string
+	changes map[string]dryRunChange
+}
+
+type dryRunChange struct {
+	operation string
+	object    client.Object
+}
+
+func NewDryRunClient(c client.Client) *DryRunClient {
+	return &DryRunClient{
+		Client:  c,
+		changes: make(map[string]dryRunChange),
+	}
+}
+
+func (c *DryRunClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	return c.record("create", obj)
+}
+
+func (c *DryRunClient) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	return c.record("update", obj)
+}
+
+func (c *DryRunClient) Patch(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+	return c.record("update", obj)
+}
+
+func (c *DryRunClient) Delete(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+	return c.record("delete", obj)
+}
+
+func (c *DryRunClient) DeleteAllOf(_ context.Context, obj client.Object, _ ...client.DeleteAllOfOption) error {
+	return c.record("delete all of", obj)
+}
+
+// Status records the writes of the status subresource as well, e.g. the validation results of the flows and outputs
+func (c *DryRunClient) Status() client.StatusWriter {
+	return dryRunStatusWriter{client: c}
+}
+
+type dryRunStatusWriter struct {
+	client *DryRunClient
+}
+
+func (w dryRunStatusWriter) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	return w.client.record("update status", obj)
+}
+
+func (w dryRunStatusWriter) Patch(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+	return w.client.record("update status", obj)
+}
+
+func (c *DryRunClient) record(operation string, obj client.Object) error {
+	obj = obj.DeepCopyObject().(client.Object)
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return errors.WrapIf(err, "failed to determine the kind of the object")
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetManagedFields(nil)
+	if secret, ok := obj.(*corev1.Secret); ok {
+		// Only the keys of the secrets are shown, the rendered values may hold credentials
+		for k := range secret.Data {
+			secret.Data[k] = nil
+		}
+		secret.StringData = nil
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName())
+	if operation == "update status" {
+		key += "/status"
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.changes[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.changes[key] = dryRunChange{operation: operation, object: obj}
+	return nil
+}
+
+// Changes returns the recorded objects as a multi-document yaml, annotated with the operation that would be done
+func (c *DryRunClient) Changes() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := &bytes.Buffer{}
+	for _, key := range c.order {
+		change := c.changes[key]
+		raw, err := yaml.Marshal(change.object)
+		if err != nil {
+			return "", errors.WrapIfWithDetails(err, "failed to marshal object", "object", key)
+		}
+		fmt.Fprintf(out, "---\n# %s %s\n%s", change.operation, key, raw)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,93 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventtailer
+
+import (
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	"github.com/banzaicloud/operator-tools/pkg/types"
+	"github.com/banzaicloud/operator-tools/pkg/utils"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	loggingextensionsv1alpha1 "github.com/banzaicloud/logging-operator/pkg/sdk/extensions/api/v1alpha1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// LoggingEventTailer maintains the EventTailer requested through logging.spec.eventTailer
+type LoggingEventTailer struct {
+	Logging *v1beta1.Logging
+	*reconciler.GenericResourceReconciler
+}
+
+// NewLoggingEventTailer creates a reconciler for the EventTailer of the logging
+func NewLoggingEventTailer(client client.Client, log logr.Logger, logging *v1beta1.Logging, opts reconciler.ReconcilerOpts) *LoggingEventTailer {
+	return &LoggingEventTailer{
+		Logging:                   logging,
+		GenericResourceReconciler: reconciler.NewGenericReconciler(client, log, opts),
+	}
+}
+
+// Reconcile creates or removes the EventTailer of the logging
+func (e *LoggingEventTailer) Reconcile() (*reconcile.Result, error) {
+	result, err := e.ReconcileResource(e.eventTailer())
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to reconcile event tailer")
+	}
+	return result, nil
+}
+
+func (e *LoggingEventTailer) eventTailer() (*loggingextensionsv1alpha1.EventTailer, reconciler.DesiredState) {
+	eventTailer := &loggingextensionsv1alpha1.EventTailer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: e.Logging.QualifiedName("events"),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: e.Logging.APIVersion,
+					Kind:       e.Logging.Kind,
+					Name:       e.Logging.Name,
+					UID:        e.Logging.UID,
+					Controller: utils.BoolPointer(true),
+				},
+			},
+		},
+	}
+	spec := e.Logging.Spec.EventTailer
+	if spec == nil {
+		return eventTailer, reconciler.StateAbsent
+	}
+
+	metaBase := &types.MetaBase{}
+	if spec.WorkloadMetaBase != nil {
+		metaBase = spec.WorkloadMetaBase.DeepCopy()
+	}
+	metaBase.Labels = utils.MergeLabels(metaBase.Labels, map[string]string{v1beta1.EventsLabel: e.Logging.Name})
+
+	eventTailer.Spec = loggingextensionsv1alpha1.EventTailerSpec{
+		ControlNamespace: e.Logging.Spec.ControlNamespace,
+		PositionVolume:   spec.PositionVolume,
+		WorkloadMetaBase: metaBase,
+		WorkloadBase:     spec.WorkloadBase,
+		ContainerBase:    spec.ContainerBase,
+	}
+	return eventTailer, reconciler.StatePresent
+}
+
+func RegisterLoggingWatches(builder *builder.Builder) *builder.Builder {
+	return builder.Owns(&loggingextensionsv1alpha1.EventTailer{})
+}
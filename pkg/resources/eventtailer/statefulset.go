@@ -60,7 +60,7 @@ func (e *EventTailer) statefulSetSpec() *appsv1.StatefulSetSpec {
 					Containers: []corev1.Container{
 						e.customResource.Spec.ContainerBase.Override(corev1.Container{
 							Name:            config.EventTailer.TailerAffix,
-							Image:           "banzaicloud/eventrouter:v0.1.0",
+							Image:           config.EventTailer.Image,
 							ImagePullPolicy: corev1.PullIfNotPresent,
 							VolumeMounts: []corev1.VolumeMount{
 								{
@@ -16,6 +16,8 @@ package fluentbit
 
 const BaseConfigName = "fluent-bit.conf"
 const UpstreamConfigName = "upstream.conf"
+const MultilineParsersConfigName = "parsers_multiline.conf"
+const StreamsConfigName = "streams.conf"
 
 var fluentBitConfigTemplate = `
 [SERVICE]
@@ -23,13 +25,29 @@ var fluentBitConfigTemplate = `
     Grace        {{ .Grace }}
     Daemon       Off
     Log_Level    {{ .LogLevel }}
+    {{- if .HotReload.Enabled }}
+    Parsers_File /fluent-bit/etc/parsers.conf
+    {{- else }}
     Parsers_File parsers.conf
+    {{- end }}
+    {{- if .MultilineParsers }}
+    Parsers_File parsers_multiline.conf
+    {{- end }}
     Coro_Stack_Size    {{ .CoroStackSize }}
+    {{- if .StreamTasks }}
+    Streams_File {{ .StreamsFile }}
+    {{- end }}
     {{- if .Monitor.Enabled }}
     HTTP_Server  On
     HTTP_Listen  0.0.0.0
     HTTP_Port    {{ .Monitor.Port }}
     {{- end }}
+    {{- if .HotReload.Enabled }}
+    Hot_Reload   On
+    HTTP_Server  On
+    HTTP_Listen  127.0.0.1
+    HTTP_Port    {{ .HotReload.Port }}
+    {{- end }}
     {{- range $key, $value := .BufferStorage }}
     {{- if $value }}
     {{ $key }}  {{$value}}
@@ -52,6 +70,58 @@ var fluentBitConfigTemplate = `
     multiline.parser {{- range $i, $v := .Input.MultilineParser }}{{ if $i }},{{ end}} {{ $v }}{{ end }}
     {{- end }}
 
+{{- range $tailer := .HostTailers }}
+
+[INPUT]
+    Name         tail
+    Path         {{ $tailer.Path }}
+    Tag          {{ or $tailer.Tag (printf "host.%s" $tailer.Name) }}
+    DB           /tail-db/host-{{ $tailer.Name }}.db
+    Refresh_Interval  5
+    Skip_Long_Lines   On
+    {{- if $tailer.MultilineParser }}
+    multiline.parser {{- range $i, $v := $tailer.MultilineParser }}{{ if $i }},{{ end}} {{ $v }}{{ end }}
+    {{- else if $tailer.Parser }}
+    Parser       {{ $tailer.Parser }}
+    {{- end }}
+{{- end }}
+
+{{- with .SystemdInput }}
+
+[INPUT]
+    Name         systemd
+    Tag          {{ or .Tag "host.systemd.*" }}
+    DB           {{ or .DB "/tail-db/systemd.db" }}
+    {{- if .Path }}
+    Path         {{ .Path }}
+    {{- end }}
+    {{- range $filter := .SystemdFilter }}
+    Systemd_Filter  {{ $filter }}
+    {{- end }}
+    {{- if .SystemdFilterType }}
+    Systemd_Filter_Type  {{ .SystemdFilterType }}
+    {{- end }}
+    {{- if .MaxEntries }}
+    Max_Entries  {{ .MaxEntries }}
+    {{- end }}
+    {{- if .ReadFromTail }}
+    Read_From_Tail  On
+    {{- end }}
+    {{- if .StripUnderscores }}
+    Strip_Underscores  On
+    {{- end }}
+{{- end }}
+
+{{- range $processor := .Processors }}
+
+[FILTER]
+    Name        {{ $processor.Name }}
+    Match       {{ $processor.Match }}
+    {{- range $param := $processor.Params }}
+    {{ $param.Key }}  {{ $param.Value }}
+    {{- end }}
+{{- end }}
+
 {{- if not .DisableKubernetesFilter }}
 [FILTER]
     Name        kubernetes
@@ -73,6 +143,32 @@ var fluentBitConfigTemplate = `
     {{- end }}
 {{- end}}
 
+{{- range $stream := .Streams }}
+
+[FILTER]
+    Name        modify
+    Match       {{ $stream.Tag }}
+    Add         kubernetes_namespace_name {{ $stream.Namespace }}
+    Add         kubernetes_container_name {{ $stream.Container }}
+    Add         kubernetes_labels_{{ $stream.Label }} stream
+
+[FILTER]
+    Name        nest
+    Match       {{ $stream.Tag }}
+    Operation   nest
+    Wildcard    kubernetes_labels_*
+    Nest_under  kubernetes_labels
+    Remove_prefix  kubernetes_labels_
+
+[FILTER]
+    Name        nest
+    Match       {{ $stream.Tag }}
+    Operation   nest
+    Wildcard    kubernetes_*
+    Nest_under  kubernetes
+    Remove_prefix  kubernetes_
+{{- end }}
+
 {{- range $modify := .FilterModify }}
 
 [FILTER]
@@ -89,9 +185,39 @@ var fluentBitConfigTemplate = `
     {{- end }}
 {{- end}}
 
+{{- range $lua := .LuaFilters }}
+
+[FILTER]
+    Name lua
+    {{- range $key, $value := $lua }}
+    {{- if $value }}
+    {{ $key }}  {{$value}}
+    {{- end }}
+    {{- end }}
+{{- end}}
+
+{{- range $output := .DirectOutputs }}
+
+[OUTPUT]
+    Name          {{ $output.Plugin }}
+    Alias         {{ $output.Name }}
+    Match         {{ $output.Match }}
+    {{- range $key, $value := $output.Params }}
+    {{- if $value }}
+    {{ $key }}  {{ $value }}
+    {{- end }}
+    {{- end }}
+{{- end }}
+
+{{- if not .AggregatorLess }}
+
 [OUTPUT]
     Name          forward
+    {{- if .ForwardMatchRegex }}
+    Match_Regex   {{ .ForwardMatchRegex }}
+    {{- else }}
     Match         *
+    {{- end }}
     {{- if .Upstream.Enabled }}
     Upstream upstream.conf
     {{- else }}
@@ -144,6 +270,7 @@ var fluentBitConfigTemplate = `
     {{- end }}
     {{- end }}
     {{- end }}
+{{- end }}
 `
 
 var upstreamConfigTemplate = `
@@ -156,3 +283,31 @@ var upstreamConfigTemplate = `
     Port {{.Port}}
 {{- end}}
 `
+
+var streamsConfigTemplate = `
+{{- range $task := . }}
+[STREAM_TASK]
+    Name {{ $task.Name }}
+    Exec {{ $task.Exec }}
+{{ end }}
+`
+
+var multilineParsersConfigTemplate = `
+{{- range $parser := . }}
+[MULTILINE_PARSER]
+    name          {{ $parser.Name }}
+    type          {{ or $parser.Type "regex" }}
+    {{- if $parser.Parser }}
+    parser        {{ $parser.Parser }}
+    {{- end }}
+    {{- if $parser.KeyContent }}
+    key_content   {{ $parser.KeyContent }}
+    {{- end }}
+    {{- if $parser.FlushTimeout }}
+    flush_timeout {{ $parser.FlushTimeout }}
+    {{- end }}
+    {{- range $rule := $parser.Rules }}
+    rule          "{{ $rule.StateName }}" "{{ $rule.Regex }}" "{{ $rule.NextState }}"
+    {{- end }}
+{{ end }}
+`
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"text/template"
 
 	"emperror.dev/errors"
@@ -59,6 +60,10 @@ type fluentBitConfig struct {
 		Port    int32
 		Path    string
 	}
+	HotReload struct {
+		Enabled bool
+		Port    int32
+	}
 	Flush                   int32
 	Grace                   int32
 	LogLevel                string
@@ -72,6 +77,17 @@ type fluentBitConfig struct {
 	AwsFilter               map[string]string
 	BufferStorage           map[string]string
 	FilterModify            []v1beta1.FilterModify
+	LuaFilters              []map[string]string
+	MultilineParsers        []v1beta1.MultilineParser
+	StreamTasks             []v1beta1.FluentbitStreamTask
+	StreamsFile             string
+	Streams                 []streamAttribution
+	Processors              []processor
+	DirectOutputs           []directOutput
+	AggregatorLess          bool
+	ForwardMatchRegex       string
+	SystemdInput            *v1beta1.InputSystemd
+	HostTailers             []v1beta1.FluentbitHostTailer
 	Network                 struct {
 		ConnectTimeoutSet         bool
 		ConnectTimeout            uint32
@@ -99,7 +115,7 @@ type fluentBitConfig struct {
 func (r *Reconciler) configSecret() (runtime.Object, reconciler.DesiredState, error) {
 	if r.Logging.Spec.FluentbitSpec.CustomConfigSecret != "" {
 		return &corev1.Secret{
-			ObjectMeta: r.FluentbitObjectMeta(fluentBitSecretConfigName),
+			ObjectMeta: r.FluentbitObjectMeta(r.profileName(fluentBitSecretConfigName)),
 		}, reconciler.StateAbsent, nil
 	}
 	monitor := struct {
@@ -215,8 +231,38 @@ func (r *Reconciler) configSecret() (runtime.Object, reconciler.DesiredState, er
 		DisableKubernetesFilter: disableKubernetesFilter,
 		KubernetesFilter:        fluentbitKubernetesFilter,
 		FilterModify:            r.Logging.Spec.FluentbitSpec.FilterModify,
+		MultilineParsers:        r.Logging.Spec.FluentbitSpec.MultilineParsers,
+		SystemdInput:            r.Logging.Spec.FluentbitSpec.SystemdInput,
 		BufferStorage:           fluentbitBufferStorage,
 	}
+	input.HostTailers, err = hostTailers(r.Logging.Spec.FluentbitSpec.HostTailers)
+	if err != nil {
+		return nil, reconciler.StatePresent, err
+	}
+	if len(r.Logging.Spec.FluentbitSpec.StreamTasks) > 0 {
+		tasks, err := streamTasks(r.Logging.Spec.FluentbitSpec.StreamTasks)
+		if err != nil {
+			return nil, reconciler.StatePresent, err
+		}
+		input.StreamTasks = tasks
+		input.StreamsFile = r.configDir() + "/" + StreamsConfigName
+		input.Streams = streamAttributions(tasks, r.Logging.Spec.ControlNamespace)
+	}
+	input.Processors, err = processors(r.Logging.Spec.FluentbitSpec)
+	if err != nil {
+		return nil, reconciler.StatePresent, err
+	}
+	input.AggregatorLess = r.Logging.AggregatorLess()
+	if len(r.Logging.Spec.FluentbitSpec.DirectOutputs) > 0 {
+		input.DirectOutputs, input.ForwardMatchRegex, err = r.directOutputs()
+		if err != nil {
+			return nil, reconciler.StatePresent, err
+		}
+	}
+	if r.configHotReloadEnabled() {
+		input.HotReload.Enabled = true
+		input.HotReload.Port = hotReloadPort
+	}
 	if r.Logging.Spec.FluentbitSpec.FilterAws != nil {
 		awsFilter, err := mapper.StringsMap(r.Logging.Spec.FluentbitSpec.FilterAws)
 		if err != nil {
@@ -224,6 +270,9 @@ func (r *Reconciler) configSecret() (runtime.Object, reconciler.DesiredState, er
 		}
 		input.AwsFilter = awsFilter
 	}
+	for _, lua := range r.Logging.Spec.FluentbitSpec.CustomLuaScripts {
+		input.LuaFilters = append(input.LuaFilters, luaFilterConfig(lua))
+	}
 	if r.Logging.Spec.FluentbitSpec.TargetHost != "" {
 		input.TargetHost = r.Logging.Spec.FluentbitSpec.TargetHost
 	}
@@ -237,6 +286,12 @@ func (r *Reconciler) configSecret() (runtime.Object, reconciler.DesiredState, er
 		}
 		input.ForwardOptions = forwardOptions
 	}
+	if limit := r.Logging.Spec.LogClasses.Settings(r.logClass).AgentStorageTotalLimitSize; limit != "" && input.ForwardOptions["storage.total_limit_size"] == "" {
+		if input.ForwardOptions == nil {
+			input.ForwardOptions = make(map[string]string)
+		}
+		input.ForwardOptions["storage.total_limit_size"] = limit
+	}
 
 	if r.Logging.Spec.FluentbitSpec.Network != nil {
 		if r.Logging.Spec.FluentbitSpec.Network.ConnectTimeout != nil {
@@ -298,6 +353,9 @@ func (r *Reconciler) configSecret() (runtime.Object, reconciler.DesiredState, er
 	}
 
 	if r.Logging.Spec.FluentbitSpec.EnableUpstream {
+		if r.Logging.Spec.FluentdSpec != nil && r.Logging.Spec.FluentdSpec.IsDeployment() {
+			return nil, nil, errors.New("upstream requires fluentd to run as a statefulset")
+		}
 		input.Upstream.Enabled = true
 		input.Upstream.Config.Name = "fluentd-upstream"
 		for i := int32(0); i < utils.PointerToInt32(fluentdReplicas); i++ {
@@ -321,10 +379,26 @@ func (r *Reconciler) configSecret() (runtime.Object, reconciler.DesiredState, er
 		confs[UpstreamConfigName] = []byte(upstreamConfig)
 	}
 
+	if len(input.MultilineParsers) > 0 {
+		multilineParsersConfig, err := generateMultilineParsersConfig(input)
+		if err != nil {
+			return nil, reconciler.StatePresent, errors.WrapIf(err, "failed to generate multiline parsers config for fluentbit")
+		}
+		confs[MultilineParsersConfigName] = []byte(multilineParsersConfig)
+	}
+
+	if len(input.StreamTasks) > 0 {
+		streamsConfig, err := generateStreamsConfig(input)
+		if err != nil {
+			return nil, reconciler.StatePresent, errors.WrapIf(err, "failed to generate streams config for fluentbit")
+		}
+		confs[StreamsConfigName] = []byte(streamsConfig)
+	}
+
 	r.configs = confs
 
 	return &corev1.Secret{
-		ObjectMeta: r.FluentbitObjectMeta(fluentBitSecretConfigName),
+		ObjectMeta: r.FluentbitObjectMeta(r.profileName(fluentBitSecretConfigName)),
 		Data:       confs,
 	}, reconciler.StatePresent, nil
 }
@@ -356,6 +430,57 @@ func generateUpstreamConfig(input fluentBitConfig) (string, error) {
 	return output.String(), nil
 }
 
+func generateMultilineParsersConfig(input fluentBitConfig) (string, error) {
+	output := new(bytes.Buffer)
+	tmpl, err := template.New("multiline").Parse(multilineParsersConfigTemplate)
+	if err != nil {
+		return "", err
+	}
+	err = tmpl.Execute(output, input.MultilineParsers)
+	if err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
+
+func generateStreamsConfig(input fluentBitConfig) (string, error) {
+	output := new(bytes.Buffer)
+	tmpl, err := template.New("streams").Parse(streamsConfigTemplate)
+	if err != nil {
+		return "", err
+	}
+	err = tmpl.Execute(output, input.StreamTasks)
+	if err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
+
+// streamTasks validates the stream tasks and terminates their statements, the stream processor reads a statement
+// from a single line
+func streamTasks(tasks []v1beta1.FluentbitStreamTask) ([]v1beta1.FluentbitStreamTask, error) {
+	result := make([]v1beta1.FluentbitStreamTask, 0, len(tasks))
+	names := make(map[string]bool)
+	for _, t := range tasks {
+		t.Exec = strings.TrimSpace(t.Exec)
+		if t.Name == "" || strings.ContainsAny(t.Name, " \t\r\n") {
+			return nil, errors.Errorf("invalid stream task name %q", t.Name)
+		}
+		if names[t.Name] {
+			return nil, errors.Errorf("duplicate stream task %s", t.Name)
+		}
+		names[t.Name] = true
+		if t.Exec == "" || strings.ContainsAny(t.Exec, "\r\n") {
+			return nil, errors.Errorf("the statement of stream task %s must be a single line", t.Name)
+		}
+		if !strings.HasSuffix(t.Exec, ";") {
+			t.Exec += ";"
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
 func (r *Reconciler) generateUpstreamNode(index int32) upstreamNode {
 	podName := r.Logging.QualifiedName(fmt.Sprintf("%s-%d", fluentd.ComponentFluentd, index))
 	return upstreamNode{
@@ -15,19 +15,26 @@
 package fluentbit
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"strconv"
 
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/resources"
+	"github.com/banzaicloud/logging-operator/pkg/resources/spiffe"
 	"github.com/banzaicloud/logging-operator/pkg/resources/templates"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/operator-tools/pkg/merge"
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
 	util "github.com/banzaicloud/operator-tools/pkg/utils"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const (
@@ -46,24 +53,59 @@ func (r *Reconciler) daemonSet() (runtime.Object, reconciler.DesiredState, error
 	}
 
 	labels := util.MergeLabels(r.Logging.Spec.FluentbitSpec.Labels, r.getFluentBitLabels())
-	meta := r.FluentbitObjectMeta(fluentbitDaemonSetName)
+	meta := r.FluentbitObjectMeta(r.profileName(fluentbitDaemonSetName))
 	meta.Annotations = util.MergeLabels(meta.Annotations, r.Logging.Spec.FluentbitSpec.DaemonSetAnnotations)
 	podMeta := metav1.ObjectMeta{
 		Labels:      labels,
 		Annotations: r.Logging.Spec.FluentbitSpec.Annotations,
 	}
 
-	if r.configs != nil {
+	// The reloader applies the configuration changes without restarting the pods
+	if r.configs != nil && !r.configHotReloadEnabled() {
 		for key, config := range r.configs {
 			h := sha256.New()
 			_, _ = h.Write(config)
 			podMeta = templates.Annotate(podMeta, fmt.Sprintf("checksum/%s", key), fmt.Sprintf("%x", h.Sum(nil)))
 		}
 	}
+
+	if r.Logging.Spec.ServiceMesh.IsEnabled() {
+		var excludePorts []int32
+		if r.Logging.Spec.FluentbitSpec.Metrics != nil && r.Logging.Spec.FluentbitSpec.Metrics.Port != 0 {
+			excludePorts = append(excludePorts, r.Logging.Spec.FluentbitSpec.Metrics.Port)
+		}
+		if r.positionDBMetricsEnabled() {
+			excludePorts = append(excludePorts, r.Logging.Spec.FluentbitSpec.PositionDBMetrics.Port)
+		}
+		podMeta.Annotations = util.MergeLabels(podMeta.Annotations, r.Logging.Spec.ServiceMesh.PodAnnotations(excludePorts...))
+	}
+
+	if r.Logging.Spec.FluentdSpec != nil && r.Logging.Spec.FluentdSpec.TLS.IsManaged() &&
+		r.Logging.Spec.FluentbitSpec.TLS != nil && util.PointerToBool(r.Logging.Spec.FluentbitSpec.TLS.Enabled) {
+		// Roll the agents when the client certificate gets rotated
+		tlsSecret := &corev1.Secret{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{
+			Name:      r.Logging.Spec.FluentbitSpec.TLS.SecretName,
+			Namespace: r.Logging.Spec.ControlNamespace}, tlsSecret)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, reconciler.StatePresent, errors.WrapIfWithDetails(err, "failed to load TLS secret",
+				"secret", r.Logging.Spec.FluentbitSpec.TLS.SecretName)
+		}
+		if crt, ok := tlsSecret.Data["tls.crt"]; ok {
+			podMeta = templates.Annotate(podMeta, "checksum/tls", fmt.Sprintf("%x", sha256.Sum256(crt)))
+		}
+	}
+	luaChecksum, err := r.luaChecksum()
+	if err != nil {
+		return nil, reconciler.StatePresent, err
+	}
+	if luaChecksum != "" {
+		podMeta = templates.Annotate(podMeta, "checksum/lua", luaChecksum)
+	}
 	desired := &appsv1.DaemonSet{
 		ObjectMeta: meta,
 		Spec: appsv1.DaemonSetSpec{
-			Selector: &metav1.LabelSelector{MatchLabels: util.MergeLabels(r.Logging.Spec.FluentbitSpec.Labels, r.getFluentBitLabels())},
+			Selector: r.daemonSetSelector(),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: podMeta,
 				Spec: corev1.PodSpec{
@@ -71,13 +113,14 @@ func (r *Reconciler) daemonSet() (runtime.Object, reconciler.DesiredState, error
 					Volumes:            r.generateVolume(),
 					Tolerations:        r.Logging.Spec.FluentbitSpec.Tolerations,
 					NodeSelector:       r.Logging.Spec.FluentbitSpec.NodeSelector,
-					Affinity:           r.Logging.Spec.FluentbitSpec.Affinity,
+					Affinity:           profileExclusion(r.Logging.Spec.FluentbitSpec.Affinity, r.Logging.Spec.FluentbitSpec.Profiles),
 					PriorityClassName:  r.Logging.Spec.FluentbitSpec.PodPriorityClassName,
 					SecurityContext: &corev1.PodSecurityContext{
-						FSGroup:      r.Logging.Spec.FluentbitSpec.Security.PodSecurityContext.FSGroup,
-						RunAsNonRoot: r.Logging.Spec.FluentbitSpec.Security.PodSecurityContext.RunAsNonRoot,
-						RunAsUser:    r.Logging.Spec.FluentbitSpec.Security.PodSecurityContext.RunAsUser,
-						RunAsGroup:   r.Logging.Spec.FluentbitSpec.Security.PodSecurityContext.RunAsGroup,
+						FSGroup:        r.Logging.Spec.FluentbitSpec.Security.PodSecurityContext.FSGroup,
+						RunAsNonRoot:   r.Logging.Spec.FluentbitSpec.Security.PodSecurityContext.RunAsNonRoot,
+						RunAsUser:      r.Logging.Spec.FluentbitSpec.Security.PodSecurityContext.RunAsUser,
+						RunAsGroup:     r.Logging.Spec.FluentbitSpec.Security.PodSecurityContext.RunAsGroup,
+						SeccompProfile: r.Logging.Spec.FluentbitSpec.Security.PodSecurityContext.SeccompProfile,
 					},
 					ImagePullSecrets: r.Logging.Spec.FluentbitSpec.Image.ImagePullSecrets,
 					DNSPolicy:        r.Logging.Spec.FluentbitSpec.DNSPolicy,
@@ -99,6 +142,8 @@ func (r *Reconciler) daemonSet() (runtime.Object, reconciler.DesiredState, error
 								AllowPrivilegeEscalation: r.Logging.Spec.FluentbitSpec.Security.SecurityContext.AllowPrivilegeEscalation,
 								Privileged:               r.Logging.Spec.FluentbitSpec.Security.SecurityContext.Privileged,
 								SELinuxOptions:           r.Logging.Spec.FluentbitSpec.Security.SecurityContext.SELinuxOptions,
+								Capabilities:             r.Logging.Spec.FluentbitSpec.Security.SecurityContext.Capabilities,
+								SeccompProfile:           r.Logging.Spec.FluentbitSpec.Security.SecurityContext.SeccompProfile,
 							},
 							Env:            r.Logging.Spec.FluentbitSpec.EnvVars,
 							LivenessProbe:  r.Logging.Spec.FluentbitSpec.LivenessProbe,
@@ -109,18 +154,41 @@ func (r *Reconciler) daemonSet() (runtime.Object, reconciler.DesiredState, error
 			},
 		},
 	}
+	if r.configHotReloadEnabled() {
+		if r.Logging.Spec.FluentbitSpec.CustomConfigSecret == "" {
+			desired.Spec.Template.Spec.Containers[0].Args = []string{"-c", hotReloadConfigDir + "/" + BaseConfigName}
+		}
+		desired.Spec.Template.Spec.Containers = append(desired.Spec.Template.Spec.Containers, *r.configReloaderContainer())
+	}
+	if c := r.positionDBRecoveryContainer(); c != nil {
+		desired.Spec.Template.Spec.InitContainers = append(desired.Spec.Template.Spec.InitContainers, *c)
+	}
+	desired.Spec.Template.Spec.InitContainers = append(desired.Spec.Template.Spec.InitContainers, r.Logging.Spec.FluentbitSpec.ExtraInitContainers...)
+	desired.Spec.Template.Spec.Containers = append(desired.Spec.Template.Spec.Containers, r.Logging.Spec.FluentbitSpec.ExtraContainers...)
 
 	r.Logging.Spec.FluentbitSpec.PositionDB.WithDefaultHostPath(
 		fmt.Sprintf(v1beta1.HostPath, r.Logging.Name, TailPositionVolume))
 	r.Logging.Spec.FluentbitSpec.BufferStorageVolume.WithDefaultHostPath(
 		fmt.Sprintf(v1beta1.HostPath, r.Logging.Name, BufferStorageVolume))
 
-	if err := r.Logging.Spec.FluentbitSpec.PositionDB.ApplyVolumeForPodSpec(TailPositionVolume, containerName, "/tail-db", &desired.Spec.Template.Spec); err != nil {
+	if err := r.Logging.Spec.FluentbitSpec.PositionDB.ApplyVolumeForPodSpec(TailPositionVolume, containerName, positionDBPath, &desired.Spec.Template.Spec); err != nil {
 		return desired, reconciler.StatePresent, err
 	}
 	if err := r.Logging.Spec.FluentbitSpec.BufferStorageVolume.ApplyVolumeForPodSpec(BufferStorageVolume, containerName, r.Logging.Spec.FluentbitSpec.BufferStorage.StoragePath, &desired.Spec.Template.Spec); err != nil {
 		return desired, reconciler.StatePresent, err
 	}
+	if c := r.positionDBMetricsContainer(&desired.Spec.Template.Spec.Containers[0]); c != nil {
+		desired.Spec.Template.Spec.Containers = append(desired.Spec.Template.Spec.Containers, *c)
+	}
+
+	if r.Logging.Spec.FluentdSpec != nil && r.Logging.Spec.FluentdSpec.TLS.Spiffe != nil {
+		spiffe.Apply(&desired.Spec.Template.Spec, r.Logging.Spec.FluentdSpec.TLS.Spiffe, "fluent-bit-tls", r.Logging.QualifiedName(spiffe.ConfigMapName), true)
+		spiffe.RestartOnRenewal(&desired.Spec.Template.Spec, r.Logging.Spec.FluentdSpec.TLS.Spiffe, "fluent-bit-tls", containerName, "fluent-bit")
+	}
+
+	if err := merge.Merge(desired, r.Logging.Spec.FluentbitSpec.DaemonSetOverrides); err != nil {
+		return desired, reconciler.StatePresent, errors.WrapIf(err, "unable to merge overrides to base object")
+	}
 
 	return desired, reconciler.StatePresent, nil
 }
@@ -147,7 +215,12 @@ func (r *Reconciler) generateVolumeMounts() (v []corev1.VolumeMount) {
 		})
 	}
 
-	if r.Logging.Spec.FluentbitSpec.CustomConfigSecret == "" {
+	if r.Logging.Spec.FluentbitSpec.CustomConfigSecret == "" && r.configHotReloadEnabled() {
+		v = append(v, corev1.VolumeMount{
+			Name:      "config",
+			MountPath: hotReloadConfigDir,
+		})
+	} else if r.Logging.Spec.FluentbitSpec.CustomConfigSecret == "" {
 		v = append(v, corev1.VolumeMount{
 			Name:      "config",
 			MountPath: "/fluent-bit/etc/fluent-bit.conf",
@@ -160,6 +233,20 @@ func (r *Reconciler) generateVolumeMounts() (v []corev1.VolumeMount) {
 				SubPath:   UpstreamConfigName,
 			})
 		}
+		if len(r.Logging.Spec.FluentbitSpec.MultilineParsers) > 0 {
+			v = append(v, corev1.VolumeMount{
+				Name:      "config",
+				MountPath: "/fluent-bit/etc/" + MultilineParsersConfigName,
+				SubPath:   MultilineParsersConfigName,
+			})
+		}
+		if len(r.Logging.Spec.FluentbitSpec.StreamTasks) > 0 {
+			v = append(v, corev1.VolumeMount{
+				Name:      "config",
+				MountPath: "/fluent-bit/etc/" + StreamsConfigName,
+				SubPath:   StreamsConfigName,
+			})
+		}
 	} else {
 		v = append(v, corev1.VolumeMount{
 			Name:      "config",
@@ -176,6 +263,25 @@ func (r *Reconciler) generateVolumeMounts() (v []corev1.VolumeMount) {
 		}
 		v = append(v, tlsRelatedVolume...)
 	}
+	_, luaMounts := r.luaVolumes()
+	v = append(v, luaMounts...)
+	_, hostTailerMounts := r.hostTailerVolumes()
+	v = append(v, hostTailerMounts...)
+	if r.Logging.Spec.FluentbitSpec.SystemdInput != nil {
+		// the persistent journal is available through the /var/log mount
+		v = append(v,
+			corev1.VolumeMount{
+				Name:      "runlogjournal",
+				ReadOnly:  true,
+				MountPath: "/run/log/journal",
+			},
+			corev1.VolumeMount{
+				Name:      "machineid",
+				ReadOnly:  true,
+				MountPath: "/etc/machine-id",
+			},
+		)
+	}
 	return
 }
 
@@ -214,7 +320,7 @@ func (r *Reconciler) generateVolume() (v []corev1.Volume) {
 			Name: "config",
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
-					SecretName: r.Logging.QualifiedName(fluentBitSecretConfigName),
+					SecretName: r.Logging.QualifiedName(r.profileName(fluentBitSecretConfigName)),
 					Items: []corev1.KeyToPath{
 						{
 							Key:  BaseConfigName,
@@ -230,6 +336,33 @@ func (r *Reconciler) generateVolume() (v []corev1.Volume) {
 				Path: UpstreamConfigName,
 			})
 		}
+		if len(r.Logging.Spec.FluentbitSpec.MultilineParsers) > 0 {
+			volume.VolumeSource.Secret.Items = append(volume.VolumeSource.Secret.Items, corev1.KeyToPath{
+				Key:  MultilineParsersConfigName,
+				Path: MultilineParsersConfigName,
+			})
+		}
+		if len(r.Logging.Spec.FluentbitSpec.StreamTasks) > 0 {
+			volume.VolumeSource.Secret.Items = append(volume.VolumeSource.Secret.Items, corev1.KeyToPath{
+				Key:  StreamsConfigName,
+				Path: StreamsConfigName,
+			})
+		}
+		if r.configHotReloadEnabled() {
+			secret := volume.VolumeSource.Secret
+			volume.VolumeSource = corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{
+						{
+							Secret: &corev1.SecretProjection{
+								LocalObjectReference: corev1.LocalObjectReference{Name: secret.SecretName},
+								Items:                secret.Items,
+							},
+						},
+					},
+				},
+			}
+		}
 		v = append(v, volume)
 	} else {
 		v = append(v, corev1.Volume{
@@ -252,5 +385,39 @@ func (r *Reconciler) generateVolume() (v []corev1.Volume) {
 		}
 		v = append(v, tlsRelatedVolume)
 	}
+	luaVolumes, _ := r.luaVolumes()
+	v = append(v, luaVolumes...)
+	hostTailerVolumes, _ := r.hostTailerVolumes()
+	v = append(v, hostTailerVolumes...)
+	if r.Logging.Spec.FluentbitSpec.SystemdInput != nil {
+		v = append(v,
+			corev1.Volume{
+				Name: "runlogjournal",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{
+						Path: "/run/log/journal",
+					},
+				},
+			},
+			corev1.Volume{
+				Name: "machineid",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{
+						Path: "/etc/machine-id",
+						Type: hostPathType(corev1.HostPathFile),
+					},
+				},
+			},
+		)
+	}
 	return
 }
+
+func hostPathType(t corev1.HostPathType) *corev1.HostPathType {
+	return &t
+}
+
+func (r *Reconciler) verticalPodAutoscaler() (runtime.Object, reconciler.DesiredState, error) {
+	o, state := resources.VerticalPodAutoscaler(r.FluentbitObjectMeta(r.profileName(fluentbitDaemonSetName)), "DaemonSet", containerName, r.Logging.Spec.FluentbitSpec.VPA)
+	return o, state, nil
+}
@@ -0,0 +1,118 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"regexp"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+)
+
+type directOutput struct {
+	Name   string
+	Plugin string
+	Match  string
+	Params map[string]string
+}
+
+// valueSecretLoader loads the secrets given by value or from the control namespace, the secrets cannot be mounted
+// into fluent-bit
+type valueSecretLoader struct {
+	secret.SecretLoader
+}
+
+func (l valueSecretLoader) Load(s *secret.Secret) (string, error) {
+	if s != nil && s.MountFrom != nil {
+		return "", errors.New("the secrets of the direct outputs cannot be mounted, use value or valueFrom")
+	}
+	return l.SecretLoader.Load(s)
+}
+
+// directOutputs returns the direct outputs of fluent-bit and the regular expression of the tags forwarded to the
+// aggregator, empty if every record is forwarded
+func (r *Reconciler) directOutputs() ([]directOutput, string, error) {
+	mapper := types.NewStructToStringMapper(valueSecretLoader{
+		SecretLoader: secret.NewSecretLoader(r.Client, r.Logging.Spec.ControlNamespace, "", nil),
+	})
+	var outputs []directOutput
+	var exclusive []string
+	names := make(map[string]bool)
+	for _, o := range r.Logging.Spec.FluentbitSpec.DirectOutputs {
+		if o.Name == "" || strings.ContainsAny(o.Name, " \t\r\n") {
+			return nil, "", errors.Errorf("invalid direct output name %q", o.Name)
+		}
+		if names[o.Name] {
+			return nil, "", errors.Errorf("duplicate direct output %s", o.Name)
+		}
+		names[o.Name] = true
+		if o.Match == "" || strings.ContainsAny(o.Match, " \t\r\n") {
+			return nil, "", errors.Errorf("invalid match pattern of direct output %s", o.Name)
+		}
+
+		var plugins []string
+		var config interface{}
+		if o.Loki != nil {
+			plugins = append(plugins, "loki")
+			config = o.Loki
+		}
+		if o.Elasticsearch != nil {
+			plugins = append(plugins, "es")
+			config = o.Elasticsearch
+		}
+		if o.S3 != nil {
+			plugins = append(plugins, "s3")
+			config = o.S3
+		}
+		if len(plugins) != 1 {
+			return nil, "", errors.Errorf("direct output %s has to configure exactly one destination", o.Name)
+		}
+		params, err := mapper.StringsMap(config)
+		if err != nil {
+			return nil, "", errors.WrapIff(err, "failed to map direct output %s", o.Name)
+		}
+		// The parameters are rendered one per line, a line break would inject further parameters or sections
+		for key, value := range params {
+			if strings.ContainsAny(value, "\r\n") {
+				return nil, "", errors.Errorf("parameter %s of direct output %s contains a line break", key, o.Name)
+			}
+		}
+		outputs = append(outputs, directOutput{
+			Name:   o.Name,
+			Plugin: plugins[0],
+			Match:  o.Match,
+			Params: params,
+		})
+		if !o.KeepForwarding {
+			exclusive = append(exclusive, tagPatternRegex(o.Match))
+		}
+	}
+	if len(exclusive) == 0 {
+		return outputs, "", nil
+	}
+	return outputs, "^(?!(?:" + strings.Join(exclusive, "|") + ")$)", nil
+}
+
+// tagPatternRegex returns the regular expression of a fluent-bit match pattern, where * matches anything
+func tagPatternRegex(pattern string) string {
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return strings.Join(parts, ".*")
+}
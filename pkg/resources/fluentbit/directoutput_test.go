@@ -0,0 +1,213 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestTagPatternRegex(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		want     string
+		matching []string
+		other    []string
+	}{
+		{
+			pattern:  "*",
+			want:     ".*",
+			matching: []string{"kubernetes.var.log.containers.a", ""},
+		},
+		{
+			pattern:  "kubernetes.var.log.containers.*_payments_*",
+			want:     `kubernetes\.var\.log\.containers\..*_payments_.*`,
+			matching: []string{"kubernetes.var.log.containers.api-0_payments_api-1.log"},
+			other:    []string{"kubernetes.var.log.containers.api-0_billing_api-1.log", "kubernetesXvarXlogXcontainersXa_payments_"},
+		},
+		{
+			pattern:  "host.audit",
+			want:     `host\.audit`,
+			matching: []string{"host.audit"},
+			other:    []string{"host.auditd", "hostXaudit"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.pattern, func(t *testing.T) {
+			got := tagPatternRegex(tt.pattern)
+			if got != tt.want {
+				t.Fatalf("tagPatternRegex() = %s, want %s", got, tt.want)
+			}
+			re := regexp.MustCompile("^(?:" + got + ")$")
+			for _, tag := range tt.matching {
+				if !re.MatchString(tag) {
+					t.Errorf("%s does not match %s", got, tag)
+				}
+			}
+			for _, tag := range tt.other {
+				if re.MatchString(tag) {
+					t.Errorf("%s matches %s", got, tag)
+				}
+			}
+		})
+	}
+}
+
+func TestDirectOutputs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "logging"},
+		Data:       map[string][]byte{"password": []byte("s3cr3t")},
+	}).Build()
+	password := &secret.Secret{ValueFrom: &secret.ValueFrom{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "es"},
+		Key:                  "password",
+	}}}
+	tests := []struct {
+		name        string
+		outputs     []v1beta1.FluentbitDirectOutput
+		want        []directOutput
+		wantForward string
+		forwarded   map[string]bool
+		wantErr     bool
+	}{
+		{
+			name: "exclusive and forwarded",
+			outputs: []v1beta1.FluentbitDirectOutput{
+				{Name: "payments", Match: "kubernetes.*_payments_*", Loki: &v1beta1.FluentbitDirectLoki{Host: "loki"}},
+				{Name: "archive", Match: "host.*", KeepForwarding: true, S3: &v1beta1.FluentbitDirectS3{Bucket: "logs", Region: "eu-west-1"}},
+				{Name: "es", Match: "host.audit", Elasticsearch: &v1beta1.FluentbitDirectElasticsearch{Host: "es", HTTPPasswd: password}},
+			},
+			want: []directOutput{
+				{Name: "payments", Plugin: "loki", Match: "kubernetes.*_payments_*", Params: map[string]string{"host": "loki"}},
+				{Name: "archive", Plugin: "s3", Match: "host.*", Params: map[string]string{"bucket": "logs", "region": "eu-west-1"}},
+				{Name: "es", Plugin: "es", Match: "host.audit", Params: map[string]string{"host": "es", "http_passwd": "s3cr3t"}},
+			},
+			wantForward: `^(?!(?:kubernetes\..*_payments_.*|host\.audit)$)`,
+			forwarded: map[string]bool{
+				"kubernetes.var.log.containers.api_payments_api.log": false,
+				"kubernetes.var.log.containers.api_billing_api.log":  true,
+				"host.audit":   false,
+				"host.auditd":  true,
+				"host.kubelet": true,
+			},
+		},
+		{
+			name: "all forwarded",
+			outputs: []v1beta1.FluentbitDirectOutput{
+				{Name: "copy", Match: "*", KeepForwarding: true, Loki: &v1beta1.FluentbitDirectLoki{Host: "loki"}},
+			},
+			want: []directOutput{
+				{Name: "copy", Plugin: "loki", Match: "*", Params: map[string]string{"host": "loki"}},
+			},
+		},
+		{
+			name:    "no destination",
+			outputs: []v1beta1.FluentbitDirectOutput{{Name: "none", Match: "*"}},
+			wantErr: true,
+		},
+		{
+			name: "two destinations",
+			outputs: []v1beta1.FluentbitDirectOutput{
+				{Name: "both", Match: "*", Loki: &v1beta1.FluentbitDirectLoki{Host: "loki"}, S3: &v1beta1.FluentbitDirectS3{Bucket: "logs"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate name",
+			outputs: []v1beta1.FluentbitDirectOutput{
+				{Name: "a", Match: "*", Loki: &v1beta1.FluentbitDirectLoki{Host: "loki"}},
+				{Name: "a", Match: "*", Loki: &v1beta1.FluentbitDirectLoki{Host: "loki"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid match",
+			outputs: []v1beta1.FluentbitDirectOutput{{Name: "a", Match: "host.* kubernetes.*", Loki: &v1beta1.FluentbitDirectLoki{Host: "loki"}}},
+			wantErr: true,
+		},
+		{
+			name: "line break in a parameter",
+			outputs: []v1beta1.FluentbitDirectOutput{
+				{Name: "a", Match: "*", Loki: &v1beta1.FluentbitDirectLoki{Host: "loki", Labels: "job=fluent-bit\n    Match *"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mounted secret",
+			outputs: []v1beta1.FluentbitDirectOutput{
+				{Name: "a", Match: "*", Elasticsearch: &v1beta1.FluentbitDirectElasticsearch{Host: "es", HTTPPasswd: &secret.Secret{MountFrom: password.ValueFrom}}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			logging := &v1beta1.Logging{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: v1beta1.LoggingSpec{
+					ControlNamespace: "logging",
+					FluentbitSpec:    &v1beta1.FluentbitSpec{DirectOutputs: tt.outputs},
+				},
+			}
+			r := &Reconciler{
+				Logging:                   logging,
+				GenericResourceReconciler: reconciler.NewGenericReconciler(c, logr.Discard(), reconciler.ReconcilerOpts{}),
+			}
+			got, forward, err := r.directOutputs()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("directOutputs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("directOutputs() = %+v, want %+v", got, tt.want)
+			}
+			if forward != tt.wantForward {
+				t.Errorf("forward regex = %s, want %s", forward, tt.wantForward)
+			}
+			if len(tt.forwarded) == 0 {
+				return
+			}
+			// The regexp package has no lookahead, the tags matched by the alternatives inside it are not forwarded
+			inner := strings.TrimSuffix(strings.TrimPrefix(forward, "^(?!"), ")")
+			excluded := regexp.MustCompile("^" + inner)
+			for tag, want := range tt.forwarded {
+				if forwarded := !excluded.MatchString(tag); forwarded != want {
+					t.Errorf("tag %s forwarded = %v, want %v", tag, forwarded, want)
+				}
+			}
+		})
+	}
+}
@@ -48,8 +48,12 @@ func generateLoggingRefLabels(loggingRef string) map[string]string {
 }
 
 func (r *Reconciler) getFluentBitLabels() map[string]string {
-	return util.MergeLabels(r.Logging.Spec.FluentbitSpec.Labels, map[string]string{
+	labels := util.MergeLabels(r.Logging.Spec.FluentbitSpec.Labels, map[string]string{
 		"app.kubernetes.io/name": "fluentbit"}, generateLoggingRefLabels(r.Logging.ObjectMeta.GetName()))
+	if r.profile != "" {
+		labels[profileLabel] = r.profile
+	}
+	return labels
 }
 
 func (r *Reconciler) getServiceAccount() string {
@@ -70,32 +74,52 @@ type Reconciler struct {
 	*reconciler.GenericResourceReconciler
 	configs             map[string][]byte
 	fluentdDataProvider fluentddataprovider.FluentdDataProvider
+	// name of the agent profile rendered by this reconciler, empty for the default daemonset
+	profile string
+	// most critical log class of the flows, its agent storage limit applies to the forward output
+	logClass string
 }
 
 // NewReconciler creates a new Fluentbit reconciler
-func New(client client.Client, logger logr.Logger, logging *v1beta1.Logging, opts reconciler.ReconcilerOpts, fluentdDataProvider fluentddataprovider.FluentdDataProvider) *Reconciler {
+func New(client client.Client, logger logr.Logger, logging *v1beta1.Logging, opts reconciler.ReconcilerOpts, fluentdDataProvider fluentddataprovider.FluentdDataProvider, logClass string) *Reconciler {
 	return &Reconciler{
 		Logging:                   logging,
 		GenericResourceReconciler: reconciler.NewGenericReconciler(client, logger, opts),
 		fluentdDataProvider:       fluentdDataProvider,
+		logClass:                  logClass,
 	}
 }
 
 // Reconcile reconciles the fluentBit resource
 func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
-	for _, factory := range []resources.Resource{
+	resourceList := []resources.Resource{
 		r.serviceAccount,
 		r.clusterRole,
 		r.clusterRoleBinding,
+	}
+	resourceList = append(resourceList, resources.PodSecurityPolicyResources(r.Client,
 		r.clusterPodSecurityPolicy,
 		r.pspClusterRole,
 		r.pspClusterRoleBinding,
+	)...)
+	resourceList = append(resourceList,
 		r.configSecret,
 		r.daemonSet,
+		r.verticalPodAutoscaler,
 		r.serviceMetrics,
 		r.monitorServiceMetrics,
 		r.prometheusRules,
-	} {
+	)
+	for _, profile := range r.Logging.Spec.FluentbitSpec.Profiles {
+		p := r.forProfile(profile)
+		resourceList = append(resourceList, p.configSecret, p.daemonSet, p.verticalPodAutoscaler)
+	}
+	stale, err := r.staleProfiles()
+	if err != nil {
+		return nil, err
+	}
+	resourceList = append(resourceList, stale...)
+	for _, factory := range resourceList {
 		o, state, err := factory()
 		if err != nil {
 			return nil, errors.WrapIf(err, "failed to create desired object")
@@ -0,0 +1,105 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// hostTailerMountPrefix prefixes the mount paths of the host directories, so that they never shadow the
+// directories of the fluent-bit image
+const hostTailerMountPrefix = "/host"
+
+// validateHostTailer rejects the paths that would mount the root of the host, escape their directory or break
+// the configuration they are rendered into
+func validateHostTailer(tailer v1beta1.FluentbitHostTailer) error {
+	p := tailer.Path
+	switch {
+	case !path.IsAbs(p):
+		return errors.Errorf("path %q of host tailer %s is not absolute", p, tailer.Name)
+	case path.Clean(p) != p:
+		return errors.Errorf("path %q of host tailer %s is not clean", p, tailer.Name)
+	case strings.IndexFunc(p, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0:
+		return errors.Errorf("path %q of host tailer %s contains whitespace", p, tailer.Name)
+	case hostTailerDir(p) == "/":
+		return errors.Errorf("path %q of host tailer %s would mount the root of the host", p, tailer.Name)
+	}
+	return nil
+}
+
+// hostTailerInsideVarLog tells whether the directory is covered by the default /var/log mount
+func hostTailerInsideVarLog(dir string) bool {
+	return dir == "/var/log" || strings.HasPrefix(dir, "/var/log/")
+}
+
+// hostTailers returns the host tailers with the paths they are mounted at in the fluent-bit container
+func hostTailers(tailers []v1beta1.FluentbitHostTailer) ([]v1beta1.FluentbitHostTailer, error) {
+	mounted := make([]v1beta1.FluentbitHostTailer, 0, len(tailers))
+	for _, tailer := range tailers {
+		if err := validateHostTailer(tailer); err != nil {
+			return nil, err
+		}
+		if !hostTailerInsideVarLog(hostTailerDir(tailer.Path)) {
+			tailer.Path = hostTailerMountPrefix + tailer.Path
+		}
+		mounted = append(mounted, tailer)
+	}
+	return mounted, nil
+}
+
+// hostTailerDir returns the deepest directory of the path without wildcards
+func hostTailerDir(p string) string {
+	dir := path.Dir(path.Clean(p))
+	for strings.ContainsAny(dir, "*?[") {
+		dir = path.Dir(dir)
+	}
+	return dir
+}
+
+// hostTailerVolumes mounts the directories of the host tailers which are not covered by the default mounts read-only
+// under /host. Invalid tailers are not mounted, they fail the configuration.
+func (r *Reconciler) hostTailerVolumes() (volumes []corev1.Volume, mounts []corev1.VolumeMount) {
+	mounted := map[string]bool{}
+	for i, tailer := range r.Logging.Spec.FluentbitSpec.HostTailers {
+		dir := hostTailerDir(tailer.Path)
+		if validateHostTailer(tailer) != nil || mounted[dir] || hostTailerInsideVarLog(dir) {
+			continue
+		}
+		mounted[dir] = true
+		name := "hosttailer" + strconv.Itoa(i)
+		volumes = append(volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: dir,
+				},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      name,
+			ReadOnly:  true,
+			MountPath: hostTailerMountPrefix + dir,
+		})
+	}
+	return
+}
@@ -0,0 +1,76 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestHostTailers(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "mounted under host", path: "/etc/kubernetes/audit/*.log", wantPath: "/host/etc/kubernetes/audit/*.log"},
+		{name: "inside var log", path: "/var/log/kubelet.log", wantPath: "/var/log/kubelet.log"},
+		{name: "relative", path: "var/log/kubelet.log", wantErr: true},
+		{name: "parent reference", path: "/var/log/../../etc/shadow", wantErr: true},
+		{name: "root", path: "/*.log", wantErr: true},
+		{name: "wildcard below root", path: "/*/kubelet.log", wantErr: true},
+		{name: "newline", path: "/var/log/a.log\n    Parser evil", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hostTailers([]v1beta1.FluentbitHostTailer{{Name: "test", Path: tt.path}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("hostTailers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got[0].Path != tt.wantPath {
+				t.Errorf("path = %s, want %s", got[0].Path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestHostTailerVolumes(t *testing.T) {
+	r := &Reconciler{Logging: &v1beta1.Logging{Spec: v1beta1.LoggingSpec{FluentbitSpec: &v1beta1.FluentbitSpec{
+		HostTailers: []v1beta1.FluentbitHostTailer{
+			{Name: "audit", Path: "/etc/kubernetes/audit/*.log"},
+			{Name: "audit-json", Path: "/etc/kubernetes/audit/*.json"},
+			{Name: "kubelet", Path: "/var/log/kubelet.log"},
+			{Name: "invalid", Path: "/*.log"},
+		},
+	}}}}
+	volumes, mounts := r.hostTailerVolumes()
+	wantVolumes := []corev1.Volume{{
+		Name:         "hosttailer0",
+		VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/etc/kubernetes/audit"}},
+	}}
+	if !reflect.DeepEqual(volumes, wantVolumes) {
+		t.Errorf("volumes = %+v, want %+v", volumes, wantVolumes)
+	}
+	wantMounts := []corev1.VolumeMount{{Name: "hosttailer0", ReadOnly: true, MountPath: "/host/etc/kubernetes/audit"}}
+	if !reflect.DeepEqual(mounts, wantMounts) {
+		t.Errorf("mounts = %+v, want %+v", mounts, wantMounts)
+	}
+}
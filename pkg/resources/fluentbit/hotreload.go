@@ -0,0 +1,63 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// hotReloadConfigDir is where the generated configuration is mounted as a directory, files mounted with subPath
+	// are not updated in the running pods
+	hotReloadConfigDir = "/fluent-bit/etc/operator"
+	// hotReloadPort serves the reload endpoint on localhost, hot reload cannot be combined with the metrics
+	hotReloadPort = 2020
+)
+
+func (r *Reconciler) configHotReloadEnabled() bool {
+	return r.Logging.Spec.FluentbitSpec.ConfigHotReload != nil && r.Logging.Spec.FluentbitSpec.ConfigHotReload.Enabled
+}
+
+// configDir returns the directory fluent-bit reads the configuration from
+func (r *Reconciler) configDir() string {
+	if r.configHotReloadEnabled() && r.Logging.Spec.FluentbitSpec.CustomConfigSecret == "" {
+		return hotReloadConfigDir
+	}
+	return "/fluent-bit/etc"
+}
+
+func (r *Reconciler) configReloaderContainer() *corev1.Container {
+	spec := r.Logging.Spec.FluentbitSpec.ConfigHotReload
+	return &corev1.Container{
+		Name:            "config-reloader",
+		Image:           spec.Image.RepositoryWithTag(),
+		ImagePullPolicy: corev1.PullPolicy(spec.Image.PullPolicy),
+		Resources:       spec.Resources,
+		Args: []string{
+			"-volume-dir=" + r.configDir(),
+			fmt.Sprintf("-webhook-url=http://127.0.0.1:%d/api/v2/reload", hotReloadPort),
+			"-webhook-method=POST",
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "config",
+				MountPath: r.configDir(),
+				ReadOnly:  true,
+			},
+		},
+	}
+}
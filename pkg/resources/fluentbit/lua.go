@@ -0,0 +1,116 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const luaScriptsPath = "/fluent-bit/lua"
+
+func luaFilterConfig(lua v1beta1.FilterLua) map[string]string {
+	config := map[string]string{
+		"Match":  "*",
+		"script": path.Join(luaScriptsPath, lua.Script.Name, lua.Script.Key),
+		"call":   lua.Call,
+	}
+	if lua.Match != "" {
+		config["Match"] = lua.Match
+	}
+	if lua.TimeAsTable {
+		config["time_as_table"] = "On"
+	}
+	if lua.ProtectedMode != nil {
+		config["protected_mode"] = "On"
+		if !*lua.ProtectedMode {
+			config["protected_mode"] = "Off"
+		}
+	}
+	if lua.TypeIntKey != "" {
+		config["type_int_key"] = lua.TypeIntKey
+	}
+	return config
+}
+
+// luaConfigMaps returns the names of the ConfigMaps holding the Lua scripts in order of their first use
+func (r *Reconciler) luaConfigMaps() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, lua := range r.Logging.Spec.FluentbitSpec.CustomLuaScripts {
+		if !seen[lua.Script.Name] {
+			seen[lua.Script.Name] = true
+			names = append(names, lua.Script.Name)
+		}
+	}
+	return names
+}
+
+func luaVolumeName(index int) string {
+	return "lua-scripts-" + strconv.Itoa(index)
+}
+
+func (r *Reconciler) luaVolumes() (volumes []corev1.Volume, mounts []corev1.VolumeMount) {
+	for i, name := range r.luaConfigMaps() {
+		volumes = append(volumes, corev1.Volume{
+			Name: luaVolumeName(i),
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      luaVolumeName(i),
+			ReadOnly:  true,
+			MountPath: path.Join(luaScriptsPath, name),
+		})
+	}
+	return
+}
+
+// luaChecksum hashes the referenced scripts so that the agents are rolled when a script changes. A missing script
+// is an error, the agents would not start without it.
+func (r *Reconciler) luaChecksum() (string, error) {
+	if len(r.Logging.Spec.FluentbitSpec.CustomLuaScripts) == 0 {
+		return "", nil
+	}
+	h := sha256.New()
+	for _, lua := range r.Logging.Spec.FluentbitSpec.CustomLuaScripts {
+		cm := &corev1.ConfigMap{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: lua.Script.Name, Namespace: r.Logging.Spec.ControlNamespace}, cm)
+		if apierrors.IsNotFound(err) {
+			return "", errors.NewWithDetails("lua script configmap not found", "configmap", lua.Script.Name, "namespace", r.Logging.Spec.ControlNamespace)
+		}
+		if err != nil {
+			return "", errors.WrapIfWithDetails(err, "failed to load lua script", "configmap", lua.Script.Name)
+		}
+		script, ok := cm.Data[lua.Script.Key]
+		if !ok {
+			return "", errors.NewWithDetails("lua script key not found in configmap", "configmap", lua.Script.Name, "key", lua.Script.Key)
+		}
+		_, _ = h.Write([]byte(script))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
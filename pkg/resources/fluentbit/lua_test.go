@@ -0,0 +1,68 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestLuaChecksum(t *testing.T) {
+	script := func(name, key string) v1beta1.FilterLua {
+		return v1beta1.FilterLua{Script: corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key}}
+	}
+	tests := []struct {
+		name    string
+		scripts []v1beta1.FilterLua
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "existing", scripts: []v1beta1.FilterLua{script("scripts", "a.lua"), script("scripts", "b.lua")}},
+		{name: "missing configmap", scripts: []v1beta1.FilterLua{script("scripts", "a.lua"), script("missing", "a.lua")}, wantErr: true},
+		{name: "missing key", scripts: []v1beta1.FilterLua{script("scripts", "c.lua")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reconciler{
+				Logging: &v1beta1.Logging{Spec: v1beta1.LoggingSpec{
+					ControlNamespace: "logging",
+					FluentbitSpec:    &v1beta1.FluentbitSpec{CustomLuaScripts: tt.scripts},
+				}},
+				GenericResourceReconciler: reconciler.NewGenericReconciler(
+					fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(&corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Name: "scripts", Namespace: "logging"},
+						Data:       map[string]string{"a.lua": "function a() end", "b.lua": "function b() end"},
+					}).Build(),
+					log.Log, reconciler.ReconcilerOpts{}),
+			}
+			checksum, err := r.luaChecksum()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("luaChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if wantChecksum := !tt.wantErr && len(tt.scripts) > 0; wantChecksum != (checksum != "") {
+				t.Errorf("luaChecksum() = %q", checksum)
+			}
+		})
+	}
+}
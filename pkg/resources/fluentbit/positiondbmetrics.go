@@ -0,0 +1,131 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// PositionDBMetricsContainerName is the name of the sidecar exporting the lag of the tailed files
+	PositionDBMetricsContainerName = "positiondb-metrics"
+	// positionDBMetricsPortName names the port of the sidecar in the pods and in the metrics service
+	positionDBMetricsPortName = "http-positiondb"
+)
+
+// positionDBMetricsScript serves the lag of the files tracked in the position databases. The databases are opened
+// read-only, the files are stated through the same mounts fluent-bit reads them from. Rotated files still tracked by
+// their inode are not found by name and are left out of the lag.
+const positionDBMetricsScript = `import glob, os, sqlite3
+from http.server import BaseHTTPRequestHandler, HTTPServer
+
+def label(value):
+    return value.replace("\\", "\\\\").replace('"', '\\"')
+
+def collect():
+    lines = [
+        "# HELP fluentbit_positiondb_lag_bytes Bytes of the tracked files not read yet",
+        "# TYPE fluentbit_positiondb_lag_bytes gauge",
+        "# HELP fluentbit_positiondb_files Files tracked in the position database",
+        "# TYPE fluentbit_positiondb_files gauge",
+    ]
+    for db in sorted(glob.glob(os.path.join(os.environ["POSITIONDB_PATH"], "*.db"))):
+        try:
+            conn = sqlite3.connect("file:%s?mode=ro" % db, uri=True, timeout=1)
+            try:
+                rows = conn.execute("SELECT name, offset FROM in_tail_files").fetchall()
+            finally:
+                conn.close()
+        except sqlite3.Error:
+            continue
+        lag = 0
+        for name, offset in rows:
+            try:
+                lag += max(os.stat(name).st_size - offset, 0)
+            except OSError:
+                pass
+        name = label(os.path.basename(db))
+        lines.append('fluentbit_positiondb_lag_bytes{database="%s"} %d' % (name, lag))
+        lines.append('fluentbit_positiondb_files{database="%s"} %d' % (name, len(rows)))
+    return ("\n".join(lines) + "\n").encode()
+
+class Handler(BaseHTTPRequestHandler):
+    def do_GET(self):
+        if self.path != "/metrics":
+            self.send_error(404)
+            return
+        body = collect()
+        self.send_response(200)
+        self.send_header("Content-Type", "text/plain; version=0.0.4")
+        self.send_header("Content-Length", str(len(body)))
+        self.end_headers()
+        self.wfile.write(body)
+
+    def log_message(self, *args):
+        pass
+
+HTTPServer(("", int(os.environ["METRICS_PORT"])), Handler).serve_forever()
+`
+
+func (r *Reconciler) positionDBMetricsEnabled() bool {
+	return r.Logging.Spec.FluentbitSpec.PositionDBMetrics != nil && r.Logging.Spec.FluentbitSpec.PositionDBMetrics.Enabled
+}
+
+// positionDBMetricsContainer gets the mounts of the fluent-bit container, so the paths stored in the databases
+// resolve the same way. Only the positiondb volume is writable, SQLite needs the shared memory file of the
+// write-ahead log next to the database.
+func (r *Reconciler) positionDBMetricsContainer(fluentbit *corev1.Container) *corev1.Container {
+	spec := r.Logging.Spec.FluentbitSpec
+	if !r.positionDBMetricsEnabled() {
+		return nil
+	}
+	metrics := spec.PositionDBMetrics
+	var mounts []corev1.VolumeMount
+	for _, m := range fluentbit.VolumeMounts {
+		m.ReadOnly = m.Name != TailPositionVolume
+		mounts = append(mounts, m)
+	}
+	return &corev1.Container{
+		Name:            PositionDBMetricsContainerName,
+		Image:           metrics.Image.RepositoryWithTag(),
+		ImagePullPolicy: corev1.PullPolicy(metrics.Image.PullPolicy),
+		Command:         []string{"python3", "-c", positionDBMetricsScript},
+		Env: []corev1.EnvVar{
+			{Name: "POSITIONDB_PATH", Value: positionDBPath},
+			{Name: "METRICS_PORT", Value: strconv.Itoa(int(metrics.Port))},
+		},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          positionDBMetricsPortName,
+				ContainerPort: metrics.Port,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		Resources:    metrics.Resources,
+		VolumeMounts: mounts,
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:                spec.Security.SecurityContext.RunAsUser,
+			RunAsNonRoot:             spec.Security.SecurityContext.RunAsNonRoot,
+			ReadOnlyRootFilesystem:   spec.Security.SecurityContext.ReadOnlyRootFilesystem,
+			AllowPrivilegeEscalation: spec.Security.SecurityContext.AllowPrivilegeEscalation,
+			Privileged:               spec.Security.SecurityContext.Privileged,
+			SELinuxOptions:           spec.Security.SecurityContext.SELinuxOptions,
+			Capabilities:             spec.Security.SecurityContext.Capabilities,
+			SeccompProfile:           spec.Security.SecurityContext.SeccompProfile,
+		},
+	}
+}
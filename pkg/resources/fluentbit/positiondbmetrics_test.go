@@ -0,0 +1,60 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestPositionDBMetricsContainer(t *testing.T) {
+	r := &Reconciler{Logging: &v1beta1.Logging{Spec: v1beta1.LoggingSpec{FluentbitSpec: &v1beta1.FluentbitSpec{
+		Security:          &v1beta1.Security{SecurityContext: &corev1.SecurityContext{}},
+		PositionDBMetrics: &v1beta1.PositionDBMetrics{Enabled: true, Port: 2022},
+	}}}}
+	fluentbit := &corev1.Container{VolumeMounts: []corev1.VolumeMount{
+		{Name: "varlogs", MountPath: "/var/log/", ReadOnly: true},
+		{Name: "extravolumemount0", MountPath: "/data"},
+		{Name: TailPositionVolume, MountPath: positionDBPath},
+	}}
+
+	c := r.positionDBMetricsContainer(fluentbit)
+	if c == nil {
+		t.Fatal("the sidecar is not added")
+	}
+	want := []corev1.VolumeMount{
+		{Name: "varlogs", MountPath: "/var/log/", ReadOnly: true},
+		{Name: "extravolumemount0", MountPath: "/data", ReadOnly: true},
+		{Name: TailPositionVolume, MountPath: positionDBPath},
+	}
+	if !reflect.DeepEqual(c.VolumeMounts, want) {
+		t.Errorf("mounts = %+v, want %+v", c.VolumeMounts, want)
+	}
+	if fluentbit.VolumeMounts[1].ReadOnly {
+		t.Error("the mounts of fluent-bit are modified")
+	}
+	if len(c.Ports) != 1 || c.Ports[0].ContainerPort != 2022 {
+		t.Errorf("unexpected ports %+v", c.Ports)
+	}
+
+	r.Logging.Spec.FluentbitSpec.PositionDBMetrics.Enabled = false
+	if c := r.positionDBMetricsContainer(fluentbit); c != nil {
+		t.Errorf("unexpected sidecar %+v", c)
+	}
+}
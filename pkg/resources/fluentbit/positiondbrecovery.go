@@ -0,0 +1,110 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PositionDBRecoveryContainerName is the name of the init container setting the corrupt position databases aside.
+// Its termination message is the number of databases recovered.
+const PositionDBRecoveryContainerName = "positiondb-recovery"
+
+// positionDBPath is where the positiondb volume is mounted
+const positionDBPath = "/tail-db"
+
+// positionDBRecoveryScript checks the header of the databases and of their write-ahead logs, and that the databases
+// are made of whole pages. Empty files are left to SQLite. The number of databases recovered is written to
+// $TERMINATION_LOG, the termination message of the container unless set.
+const positionDBRecoveryScript = `suffix=".corrupt-$(date -u +%Y%m%dT%H%M%SZ)"
+recovered=0
+
+valid_db() {
+  file="$1"
+  size=$(wc -c < "$file")
+  [ "$size" -eq 0 ] && return 0
+  [ "$(head -c 15 "$file")" = "SQLite format 3" ] || return 1
+  set -- $(od -An -tu1 -j 15 -N 3 "$file")
+  [ "$#" -eq 3 ] && [ "$1" -eq 0 ] || return 1
+  page_size=$(( $2 * 256 + $3 ))
+  [ "$page_size" -eq 1 ] && page_size=65536
+  [ "$page_size" -ge 512 ] && [ $(( page_size & (page_size - 1) )) -eq 0 ] && [ $(( size % page_size )) -eq 0 ]
+}
+
+valid_wal() {
+  [ ! -s "$1" ] && return 0
+  [ "$(wc -c < "$1")" -ge 32 ] || return 1
+  magic=$(od -An -tu1 -N 4 "$1" | tr -s ' ')
+  [ "$magic" = " 55 127 6 130" ] || [ "$magic" = " 55 127 6 131" ]
+}
+
+for db in "$POSITIONDB_PATH"/*.db; do
+  [ -f "$db" ] || continue
+  valid_db "$db" && valid_wal "$db-wal" && continue
+  for f in "$db" "$db-wal" "$db-shm"; do
+    [ -e "$f" ] || continue
+    if [ "$POSITIONDB_RECOVERY_ACTION" = reset ]; then
+      rm -f "$f"
+    else
+      mv "$f" "$f$suffix"
+    fi
+  done
+  recovered=$(( recovered + 1 ))
+  echo "recovered corrupt position database $db ($POSITIONDB_RECOVERY_ACTION)"
+done
+printf '%d' "$recovered" > "${TERMINATION_LOG:-/dev/termination-log}"
+`
+
+func (r *Reconciler) positionDBRecoveryContainer() *corev1.Container {
+	spec := r.Logging.Spec.FluentbitSpec
+	if spec.PositionDBRecovery == nil || !spec.PositionDBRecovery.Enabled {
+		return nil
+	}
+	recovery := spec.PositionDBRecovery
+	return &corev1.Container{
+		Name:            PositionDBRecoveryContainerName,
+		Image:           recovery.Image.RepositoryWithTag(),
+		ImagePullPolicy: corev1.PullPolicy(recovery.Image.PullPolicy),
+		Command:         []string{"sh", "-c", positionDBRecoveryScript},
+		Env: []corev1.EnvVar{
+			{Name: "POSITIONDB_PATH", Value: positionDBPath},
+			{Name: "POSITIONDB_RECOVERY_ACTION", Value: recovery.Action},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      TailPositionVolume,
+				MountPath: positionDBPath,
+			},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:                spec.Security.SecurityContext.RunAsUser,
+			RunAsNonRoot:             spec.Security.SecurityContext.RunAsNonRoot,
+			ReadOnlyRootFilesystem:   spec.Security.SecurityContext.ReadOnlyRootFilesystem,
+			AllowPrivilegeEscalation: spec.Security.SecurityContext.AllowPrivilegeEscalation,
+			Privileged:               spec.Security.SecurityContext.Privileged,
+			SELinuxOptions:           spec.Security.SecurityContext.SELinuxOptions,
+			Capabilities:             spec.Security.SecurityContext.Capabilities,
+			SeccompProfile:           spec.Security.SecurityContext.SeccompProfile,
+		},
+	}
+}
+
+// ParsePositionDBRecoveryResult parses the termination message of the position database recovery container
+func ParsePositionDBRecoveryResult(message string) (recovered int64, err error) {
+	_, err = fmt.Sscanf(message, "%d", &recovered)
+	return
+}
@@ -0,0 +1,164 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestParsePositionDBRecoveryResult(t *testing.T) {
+	tests := []struct {
+		message string
+		want    int64
+		wantErr bool
+	}{
+		{message: "0", want: 0},
+		{message: "3", want: 3},
+		{message: "", wantErr: true},
+		{message: "Error: permission denied", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.message, func(t *testing.T) {
+			got, err := ParsePositionDBRecoveryResult(tt.message)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePositionDBRecoveryResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePositionDBRecoveryResult() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// sqliteFile returns a database of the given size with a header of 4096 byte pages
+func sqliteFile(size int) []byte {
+	db := make([]byte, size)
+	copy(db, "SQLite format 3\x00\x10\x00")
+	return db
+}
+
+func TestPositionDBRecoveryScript(t *testing.T) {
+	for _, tool := range []string{"sh", "od", "head", "wc", "tr"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is not available", tool)
+		}
+	}
+	wal := append([]byte{55, 127, 6, 130}, make([]byte, 28)...)
+	tests := []struct {
+		name          string
+		action        string
+		files         map[string][]byte
+		wantRecovered string
+		wantFiles     []string
+	}{
+		{
+			name: "valid",
+			files: map[string][]byte{
+				"tail.db":     sqliteFile(8192),
+				"tail.db-wal": wal,
+				"empty.db":    {},
+			},
+			wantRecovered: "0",
+			wantFiles:     []string{"empty.db", "tail.db", "tail.db-wal"},
+		},
+		{
+			name:   "renamed",
+			action: v1beta1.PositionDBRecoveryRename,
+			files: map[string][]byte{
+				"garbage.db":       []byte("not a database"),
+				"partial.db":       sqliteFile(5000),
+				"wal.db":           sqliteFile(4096),
+				"wal.db-wal":       []byte("truncated"),
+				"wal.db-shm":       {1},
+				"unrelated.backup": []byte("kept"),
+			},
+			wantRecovered: "3",
+			wantFiles:     []string{"garbage.db.corrupt", "partial.db.corrupt", "unrelated.backup", "wal.db-shm.corrupt", "wal.db-wal.corrupt", "wal.db.corrupt"},
+		},
+		{
+			name:   "reset",
+			action: v1beta1.PositionDBRecoveryReset,
+			files: map[string][]byte{
+				"garbage.db":  []byte("not a database"),
+				"tail.db":     sqliteFile(4096),
+				"tail.db-wal": {},
+			},
+			wantRecovered: "1",
+			wantFiles:     []string{"tail.db", "tail.db-wal"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			positionDB := filepath.Join(dir, "tail-db")
+			if err := os.Mkdir(positionDB, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(positionDB, name), content, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			terminationLog := filepath.Join(dir, "termination-log")
+			cmd := exec.Command("sh", "-c", positionDBRecoveryScript)
+			cmd.Env = append(os.Environ(),
+				"POSITIONDB_PATH="+positionDB,
+				"POSITIONDB_RECOVERY_ACTION="+tt.action,
+				"TERMINATION_LOG="+terminationLog)
+			var output bytes.Buffer
+			cmd.Stdout = &output
+			cmd.Stderr = &output
+			if err := cmd.Run(); err != nil {
+				t.Fatalf("%v: %s", err, output.String())
+			}
+
+			recovered, err := os.ReadFile(terminationLog)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(recovered) != tt.wantRecovered {
+				t.Errorf("recovered = %s, want %s", recovered, tt.wantRecovered)
+			}
+			entries, err := os.ReadDir(positionDB)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var files []string
+			for _, e := range entries {
+				name := e.Name()
+				// Leave out the timestamp of the renamed files
+				if i := strings.Index(name, ".corrupt-"); i >= 0 {
+					name = name[:i] + ".corrupt"
+				}
+				files = append(files, name)
+			}
+			sort.Strings(files)
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("files = %v, want %v", files, tt.wantFiles)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"regexp"
+	"strings"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// processor is a filter rendered with the tag of its input as the match pattern
+type processor struct {
+	Match  string
+	Name   string
+	Params []v1beta1.FluentbitParam
+}
+
+// processors resolves the inputs of the processors to their tags. The parameters are rendered one per line, so they
+// cannot contain line breaks, and the match pattern is owned by the input.
+func processors(spec *v1beta1.FluentbitSpec) ([]processor, error) {
+	tags := map[string]string{"tail": spec.InputTail.Tag}
+	if spec.SystemdInput != nil {
+		tags["systemd"] = spec.SystemdInput.Tag
+		if tags["systemd"] == "" {
+			tags["systemd"] = "host.systemd.*"
+		}
+	}
+	for _, tailer := range spec.HostTailers {
+		tags[tailer.Name] = tailer.Tag
+		if tailer.Tag == "" {
+			tags[tailer.Name] = "host." + tailer.Name
+		}
+	}
+	result := make([]processor, 0, len(spec.Processors))
+	for i, p := range spec.Processors {
+		tag, ok := tags[p.Input]
+		if !ok {
+			return nil, errors.Errorf("unknown input %q of processor %d", p.Input, i)
+		}
+		if p.Name == "" || strings.ContainsAny(p.Name, " \t\r\n") {
+			return nil, errors.Errorf("invalid filter name %q of processor %d", p.Name, i)
+		}
+		for _, param := range p.Params {
+			if param.Key == "" || strings.ContainsAny(param.Key, " \t\r\n") || strings.ContainsAny(param.Value, "\r\n") {
+				return nil, errors.Errorf("invalid parameter %q of processor %d", param.Key, i)
+			}
+			if key := strings.ToLower(param.Key); key == "match" || key == "match_regex" {
+				return nil, errors.Errorf("the match pattern of processor %d is set by its input", i)
+			}
+		}
+		result = append(result, processor{Match: tag, Name: p.Name, Params: p.Params})
+	}
+	return result, nil
+}
+
+// streamTagRegex captures the tag the records of a stream are ingested again with
+var streamTagRegex = regexp.MustCompile(`(?i)\bWITH\s*\([^)]*\btag\s*=\s*'([^']+)'`)
+
+// streamAttribution is the kubernetes metadata of the records of a stream, the label router of the aggregator
+// routes by it. Label is the key of the source label.
+type streamAttribution struct {
+	Tag       string
+	Namespace string
+	Container string
+	Label     string
+}
+
+// streamAttributions returns the metadata of the streams ingested again, the ones without a tag are not forwarded
+func streamAttributions(tasks []v1beta1.FluentbitStreamTask, namespace string) []streamAttribution {
+	var result []streamAttribution
+	for _, t := range tasks {
+		match := streamTagRegex.FindStringSubmatch(t.Exec)
+		if match == nil {
+			continue
+		}
+		result = append(result, streamAttribution{
+			Tag:       match[1],
+			Namespace: namespace,
+			Container: t.Name,
+			Label:     v1beta1.SourceLabel,
+		})
+	}
+	return result
+}
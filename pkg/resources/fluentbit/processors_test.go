@@ -0,0 +1,105 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestProcessors(t *testing.T) {
+	grep := []v1beta1.FluentbitParam{{Key: "Exclude", Value: "log ^DEBUG"}, {Key: "Exclude", Value: "log ^TRACE"}}
+	tests := []struct {
+		name      string
+		processor v1beta1.FluentbitProcessor
+		wantMatch string
+		wantErr   bool
+	}{
+		{name: "container logs", processor: v1beta1.FluentbitProcessor{Input: "tail", Name: "grep", Params: grep}, wantMatch: "kubernetes.*"},
+		{name: "systemd", processor: v1beta1.FluentbitProcessor{Input: "systemd", Name: "grep"}, wantMatch: "host.systemd.*"},
+		{name: "host tailer", processor: v1beta1.FluentbitProcessor{Input: "audit", Name: "grep"}, wantMatch: "host.audit"},
+		{name: "host tailer with tag", processor: v1beta1.FluentbitProcessor{Input: "kubelet", Name: "grep"}, wantMatch: "node.kubelet"},
+		{name: "unknown input", processor: v1beta1.FluentbitProcessor{Input: "missing", Name: "grep"}, wantErr: true},
+		{name: "missing name", processor: v1beta1.FluentbitProcessor{Input: "tail"}, wantErr: true},
+		{name: "match", processor: v1beta1.FluentbitProcessor{Input: "tail", Name: "grep", Params: []v1beta1.FluentbitParam{{Key: "Match", Value: "*"}}}, wantErr: true},
+		{name: "newline", processor: v1beta1.FluentbitProcessor{Input: "tail", Name: "grep", Params: []v1beta1.FluentbitParam{{Key: "Regex", Value: "log a\n    Match *"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &v1beta1.FluentbitSpec{
+				InputTail:    v1beta1.InputTail{Tag: "kubernetes.*"},
+				SystemdInput: &v1beta1.InputSystemd{},
+				HostTailers:  []v1beta1.FluentbitHostTailer{{Name: "audit"}, {Name: "kubelet", Tag: "node.kubelet"}},
+				Processors:   []v1beta1.FluentbitProcessor{tt.processor},
+			}
+			got, err := processors(spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("processors() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			want := []processor{{Match: tt.wantMatch, Name: tt.processor.Name, Params: tt.processor.Params}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("processors() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestStreamTasks(t *testing.T) {
+	tasks, err := streamTasks([]v1beta1.FluentbitStreamTask{
+		{Name: "errors", Exec: "CREATE STREAM errors WITH (tag='agg.errors') AS SELECT COUNT(*) FROM TAG:'kubernetes.*' WINDOW TUMBLING (60 SECOND);"},
+		{Name: "debug", Exec: " SELECT * FROM TAG:'kubernetes.*' WHERE level = 'debug' "},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tasks[1].Exec != "SELECT * FROM TAG:'kubernetes.*' WHERE level = 'debug';" {
+		t.Errorf("the statement is not terminated: %q", tasks[1].Exec)
+	}
+	want := []streamAttribution{{Tag: "agg.errors", Namespace: "logging", Container: "errors", Label: v1beta1.SourceLabel}}
+	if got := streamAttributions(tasks, "logging"); !reflect.DeepEqual(got, want) {
+		t.Errorf("streamAttributions() = %+v, want %+v", got, want)
+	}
+
+	config, err := generateConfig(fluentBitConfig{StreamTasks: tasks, Streams: want})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"Add         kubernetes_namespace_name logging",
+		"Add         kubernetes_labels_logging.banzaicloud.io/source stream",
+		"Nest_under  kubernetes\n",
+	} {
+		if !strings.Contains(config, expected) {
+			t.Errorf("expected %q in config:\n%s", expected, config)
+		}
+	}
+
+	for _, invalid := range [][]v1beta1.FluentbitStreamTask{
+		{{Name: "", Exec: "SELECT * FROM STREAM:tail.0;"}},
+		{{Name: "a", Exec: "SELECT *\nFROM STREAM:tail.0;"}},
+		{{Name: "a", Exec: "SELECT * FROM STREAM:tail.0;"}, {Name: "a", Exec: "SELECT * FROM STREAM:tail.0;"}},
+	} {
+		if _, err := streamTasks(invalid); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"context"
+	"sort"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+const profileLabel = "logging.banzaicloud.io/agent-profile"
+
+// forProfile returns a reconciler rendering the daemonset and config of the given profile
+func (r *Reconciler) forProfile(profile v1beta1.FluentbitAgentProfile) *Reconciler {
+	logging := r.Logging.DeepCopy()
+	spec := logging.Spec.FluentbitSpec
+	spec.NodeSelector = profile.NodeSelector
+	spec.Tolerations = profile.Tolerations
+	spec.Affinity = nil
+	if len(profile.Resources.Limits) > 0 || len(profile.Resources.Requests) > 0 {
+		spec.Resources = profile.Resources
+	}
+	if profile.MemBufLimit != "" {
+		spec.InputTail.MemBufLimit = profile.MemBufLimit
+	}
+	if profile.StorageBacklogMemLimit != "" {
+		spec.BufferStorage.StorageBacklogMemLimit = profile.StorageBacklogMemLimit
+	}
+	spec.Profiles = nil
+
+	return &Reconciler{
+		Logging:                   logging,
+		GenericResourceReconciler: r.GenericResourceReconciler,
+		fluentdDataProvider:       r.fluentdDataProvider,
+		profile:                   profile.Name,
+		logClass:                  r.logClass,
+	}
+}
+
+// profileName suffixes the name of the per-profile resources
+func (r *Reconciler) profileName(name string) string {
+	if r.profile == "" {
+		return name
+	}
+	return name + "-" + r.profile
+}
+
+// profileExclusion keeps the default daemonset off the nodes matched by any of the profiles.
+// A node is excluded if it has all the labels of a profile. The profiles selecting by a single label
+// are merged into one NotIn requirement per label, those selecting by several labels turn into one
+// term per combination of their labels as the terms of a node selector are ORed, which is bounded by
+// MaxFluentbitProfileExclusionTerms when the logging is defaulted.
+func profileExclusion(affinity *corev1.Affinity, profiles []v1beta1.FluentbitAgentProfile) *corev1.Affinity {
+	if len(profiles) == 0 {
+		return affinity
+	}
+	excluded := make(map[string][]string)
+	var multiLabel []v1beta1.FluentbitAgentProfile
+	for _, profile := range profiles {
+		if len(profile.NodeSelector) > 1 {
+			multiLabel = append(multiLabel, profile)
+			continue
+		}
+		for k, v := range profile.NodeSelector {
+			excluded[k] = append(excluded[k], v)
+		}
+	}
+	keys := make([]string, 0, len(excluded))
+	for k := range excluded {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var common corev1.NodeSelectorTerm
+	for _, k := range keys {
+		values := excluded[k]
+		sort.Strings(values)
+		common.MatchExpressions = append(common.MatchExpressions, corev1.NodeSelectorRequirement{
+			Key:      k,
+			Operator: corev1.NodeSelectorOpNotIn,
+			Values:   values,
+		})
+	}
+
+	terms := []corev1.NodeSelectorTerm{common}
+	for _, profile := range multiLabel {
+		var next []corev1.NodeSelectorTerm
+		for _, term := range terms {
+			for _, k := range sortedKeys(profile.NodeSelector) {
+				next = append(next, withRequirement(term, corev1.NodeSelectorRequirement{
+					Key:      k,
+					Operator: corev1.NodeSelectorOpNotIn,
+					Values:   []string{profile.NodeSelector[k]},
+				}))
+			}
+		}
+		terms = next
+	}
+
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	} else {
+		affinity = affinity.DeepCopy()
+	}
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required != nil && len(required.NodeSelectorTerms) > 0 {
+		var combined []corev1.NodeSelectorTerm
+		for _, userTerm := range required.NodeSelectorTerms {
+			for _, term := range terms {
+				combined = append(combined, withRequirement(userTerm, term.MatchExpressions...))
+			}
+		}
+		terms = combined
+	}
+	affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{
+		NodeSelectorTerms: terms,
+	}
+	return affinity
+}
+
+func withRequirement(term corev1.NodeSelectorTerm, requirements ...corev1.NodeSelectorRequirement) corev1.NodeSelectorTerm {
+	result := *term.DeepCopy()
+	result.MatchExpressions = append(result.MatchExpressions, requirements...)
+	return result
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// daemonSetSelector selects the pods of the daemonset. The default daemonset has to leave out the pods of the
+// profiles, which carry its labels as well.
+func (r *Reconciler) daemonSetSelector() *metav1.LabelSelector {
+	selector := &metav1.LabelSelector{MatchLabels: util.MergeLabels(r.Logging.Spec.FluentbitSpec.Labels, r.getFluentBitLabels())}
+	if r.profile == "" && len(r.Logging.Spec.FluentbitSpec.Profiles) > 0 {
+		selector.MatchExpressions = []metav1.LabelSelectorRequirement{{
+			Key:      profileLabel,
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		}}
+	}
+	return selector
+}
+
+// staleProfiles returns reconcilers removing the daemonsets, config secrets and autoscalers of the profiles
+// no longer present in the spec, found by the profile label of the objects.
+func (r *Reconciler) staleProfiles() ([]resources.Resource, error) {
+	selector, err := labels.Parse(profileLabel)
+	if err != nil {
+		return nil, err
+	}
+	selector = selector.Add(labelRequirements(generateLoggingRefLabels(r.Logging.GetName()))...).
+		Add(labelRequirements(map[string]string{"app.kubernetes.io/name": "fluentbit"})...)
+	opts := &client.ListOptions{Namespace: r.Logging.Spec.ControlNamespace, LabelSelector: selector}
+
+	stale := make(map[string]bool)
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := r.Client.List(context.TODO(), daemonSets, opts); err != nil {
+		return nil, errors.WrapIf(err, "failed to list fluentbit profile daemonsets")
+	}
+	for _, ds := range daemonSets.Items {
+		stale[ds.Labels[profileLabel]] = true
+	}
+	secrets := &corev1.SecretList{}
+	if err := r.Client.List(context.TODO(), secrets, opts); err != nil {
+		return nil, errors.WrapIf(err, "failed to list fluentbit profile secrets")
+	}
+	for _, s := range secrets.Items {
+		stale[s.Labels[profileLabel]] = true
+	}
+	for _, profile := range r.Logging.Spec.FluentbitSpec.Profiles {
+		delete(stale, profile.Name)
+	}
+
+	var result []resources.Resource
+	names := make([]string, 0, len(stale))
+	for name := range stale {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := r.forProfile(v1beta1.FluentbitAgentProfile{Name: name})
+		daemonSetMeta := p.FluentbitObjectMeta(p.profileName(fluentbitDaemonSetName))
+		secretMeta := p.FluentbitObjectMeta(p.profileName(fluentBitSecretConfigName))
+		vpa, _ := resources.VerticalPodAutoscaler(daemonSetMeta, "DaemonSet", containerName, nil)
+		result = append(result,
+			absent(&appsv1.DaemonSet{ObjectMeta: daemonSetMeta}),
+			absent(&corev1.Secret{ObjectMeta: secretMeta}),
+			absent(vpa),
+		)
+	}
+	return result, nil
+}
+
+func absent(o runtime.Object) resources.Resource {
+	return func() (runtime.Object, reconciler.DesiredState, error) {
+		return o, reconciler.StateAbsent, nil
+	}
+}
+
+func labelRequirements(set map[string]string) []labels.Requirement {
+	var requirements []labels.Requirement
+	for _, k := range sortedKeys(set) {
+		requirement, err := labels.NewRequirement(k, selection.Equals, []string{set[k]})
+		if err != nil {
+			continue
+		}
+		requirements = append(requirements, *requirement)
+	}
+	return requirements
+}
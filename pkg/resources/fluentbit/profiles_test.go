@@ -0,0 +1,172 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentbit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func notIn(key string, values ...string) corev1.NodeSelectorRequirement {
+	return corev1.NodeSelectorRequirement{Key: key, Operator: corev1.NodeSelectorOpNotIn, Values: values}
+}
+
+func TestProfileExclusion(t *testing.T) {
+	tests := []struct {
+		name     string
+		affinity *corev1.Affinity
+		profiles []v1beta1.FluentbitAgentProfile
+		want     []corev1.NodeSelectorTerm
+	}{
+		{
+			name: "single label profiles are merged into one term",
+			profiles: []v1beta1.FluentbitAgentProfile{
+				{Name: "gpu", NodeSelector: map[string]string{"pool": "gpu"}},
+				{Name: "edge", NodeSelector: map[string]string{"pool": "edge"}},
+				{Name: "spot", NodeSelector: map[string]string{"spot": "true"}},
+			},
+			want: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{notIn("pool", "edge", "gpu"), notIn("spot", "true")}},
+			},
+		},
+		{
+			name: "multi label profiles add a term per label",
+			profiles: []v1beta1.FluentbitAgentProfile{
+				{Name: "gpu", NodeSelector: map[string]string{"pool": "gpu"}},
+				{Name: "edge", NodeSelector: map[string]string{"zone": "edge", "arch": "arm64"}},
+			},
+			want: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{notIn("pool", "gpu"), notIn("arch", "arm64")}},
+				{MatchExpressions: []corev1.NodeSelectorRequirement{notIn("pool", "gpu"), notIn("zone", "edge")}},
+			},
+		},
+		{
+			name: "user terms are combined with the exclusion",
+			affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "os", Operator: corev1.NodeSelectorOpIn, Values: []string{"linux"}}}},
+				}},
+			}},
+			profiles: []v1beta1.FluentbitAgentProfile{
+				{Name: "gpu", NodeSelector: map[string]string{"pool": "gpu"}},
+			},
+			want: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "os", Operator: corev1.NodeSelectorOpIn, Values: []string{"linux"}},
+					notIn("pool", "gpu"),
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := profileExclusion(tt.affinity, tt.profiles).NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("profileExclusion() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDaemonSetSelector(t *testing.T) {
+	logging := &v1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: v1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			FluentbitSpec: &v1beta1.FluentbitSpec{
+				Profiles: []v1beta1.FluentbitAgentProfile{{Name: "gpu", NodeSelector: map[string]string{"pool": "gpu"}}},
+			},
+		},
+	}
+	r := &Reconciler{Logging: logging}
+
+	selector := r.daemonSetSelector()
+	if _, ok := selector.MatchLabels[profileLabel]; ok {
+		t.Errorf("default selector matches a profile: %v", selector.MatchLabels)
+	}
+	if len(selector.MatchExpressions) != 1 || selector.MatchExpressions[0].Key != profileLabel ||
+		selector.MatchExpressions[0].Operator != metav1.LabelSelectorOpDoesNotExist {
+		t.Errorf("default selector does not leave out the profile pods: %v", selector.MatchExpressions)
+	}
+
+	profileSelector := r.forProfile(logging.Spec.FluentbitSpec.Profiles[0]).daemonSetSelector()
+	if profileSelector.MatchLabels[profileLabel] != "gpu" || len(profileSelector.MatchExpressions) != 0 {
+		t.Errorf("unexpected profile selector: %v", profileSelector)
+	}
+}
+
+func TestStaleProfiles(t *testing.T) {
+	logging := &v1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: v1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			FluentbitSpec: &v1beta1.FluentbitSpec{
+				Profiles: []v1beta1.FluentbitAgentProfile{{Name: "gpu", NodeSelector: map[string]string{"pool": "gpu"}}},
+			},
+		},
+	}
+	r := &Reconciler{Logging: logging}
+	objects := []runtime.Object{
+		&appsv1.DaemonSet{ObjectMeta: r.FluentbitObjectMeta(fluentbitDaemonSetName)},
+	}
+	for _, profile := range []string{"gpu", "edge"} {
+		p := r.forProfile(v1beta1.FluentbitAgentProfile{Name: profile})
+		objects = append(objects,
+			&appsv1.DaemonSet{ObjectMeta: p.FluentbitObjectMeta(p.profileName(fluentbitDaemonSetName))},
+			&corev1.Secret{ObjectMeta: p.FluentbitObjectMeta(p.profileName(fluentBitSecretConfigName))},
+		)
+	}
+	other := r.forProfile(v1beta1.FluentbitAgentProfile{Name: "other"})
+	otherMeta := other.FluentbitObjectMeta(other.profileName(fluentbitDaemonSetName))
+	otherMeta.Labels = generateLoggingRefLabels("other")
+	otherMeta.Labels[profileLabel] = "other"
+	objects = append(objects, &appsv1.DaemonSet{ObjectMeta: otherMeta})
+
+	r.GenericResourceReconciler = reconciler.NewGenericReconciler(
+		fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithRuntimeObjects(objects...).Build(),
+		log.Log, reconciler.ReconcilerOpts{})
+
+	stale, err := r.staleProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, resource := range stale {
+		o, state, err := resource()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state != reconciler.StateAbsent {
+			t.Errorf("stale object is not removed: %v", state)
+		}
+		got = append(got, o.(metav1.Object).GetName())
+	}
+	want := []string{"test-fluentbit-edge", "test-fluentbit-edge", "test-fluentbit-edge"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("staleProfiles() = %v, want %v", got, want)
+	}
+}
@@ -18,19 +18,29 @@ import (
 	"emperror.dev/errors"
 	"github.com/banzaicloud/operator-tools/pkg/merge"
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
 )
 
 func (r *Reconciler) clusterRole() (runtime.Object, reconciler.DesiredState, error) {
 	if *r.Logging.Spec.FluentbitSpec.Security.RoleBasedAccessControlCreate {
-		clusterRoleResources := []string{"pods", "namespaces"}
+		clusterRoleResources := []string{"pods"}
+		if !r.Logging.Spec.FluentbitSpec.Security.IsMinimalRBAC() {
+			// the kubernetes filter of fluentbit does not read namespaces
+			clusterRoleResources = append(clusterRoleResources, "namespaces")
+		}
 		if r.Logging.Spec.FluentbitSpec.FilterKubernetes.UseKubelet == "On" {
 			clusterRoleResources = append(clusterRoleResources, "nodes", "nodes/proxy")
 		}
+		meta := r.FluentbitObjectMetaClusterScope(clusterRoleName)
+		meta.Labels = util.MergeLabels(meta.Labels, r.Logging.Spec.FluentbitSpec.Security.RBACLabels())
 		return &rbacv1.ClusterRole{
-			ObjectMeta: r.FluentbitObjectMetaClusterScope(clusterRoleName),
+			ObjectMeta: meta,
 			Rules: []rbacv1.PolicyRule{
 				{
 					APIGroups: []string{""},
@@ -80,6 +90,11 @@ func (r *Reconciler) serviceAccount() (runtime.Object, reconciler.DesiredState,
 
 		return desired, reconciler.StatePresent, nil
 	} else {
+		if r.Logging.Spec.FluentbitSpec.Security.CreateRBAC == v1beta1.CreateRBACDisabled {
+			if err := resources.ValidateServiceAccount(r.Client, r.Logging.Spec.ControlNamespace, r.getServiceAccount()); err != nil {
+				return nil, reconciler.StatePresent, err
+			}
+		}
 		desired := &corev1.ServiceAccount{
 			ObjectMeta: r.FluentbitObjectMeta(defaultServiceAccountName),
 		}
@@ -26,17 +26,26 @@ import (
 
 func (r *Reconciler) serviceMetrics() (runtime.Object, reconciler.DesiredState, error) {
 	if r.Logging.Spec.FluentbitSpec.Metrics != nil {
+		ports := []corev1.ServicePort{
+			{
+				Protocol:   corev1.ProtocolTCP,
+				Name:       "http-metrics",
+				Port:       r.Logging.Spec.FluentbitSpec.Metrics.Port,
+				TargetPort: intstr.IntOrString{IntVal: r.Logging.Spec.FluentbitSpec.Metrics.Port},
+			},
+		}
+		if r.positionDBMetricsEnabled() {
+			ports = append(ports, corev1.ServicePort{
+				Protocol:   corev1.ProtocolTCP,
+				Name:       positionDBMetricsPortName,
+				Port:       r.Logging.Spec.FluentbitSpec.PositionDBMetrics.Port,
+				TargetPort: intstr.IntOrString{IntVal: r.Logging.Spec.FluentbitSpec.PositionDBMetrics.Port},
+			})
+		}
 		return &corev1.Service{
 			ObjectMeta: r.FluentbitObjectMeta(fluentbitServiceName + "-monitor"),
 			Spec: corev1.ServiceSpec{
-				Ports: []corev1.ServicePort{
-					{
-						Protocol:   corev1.ProtocolTCP,
-						Name:       "http-metrics",
-						Port:       r.Logging.Spec.FluentbitSpec.Metrics.Port,
-						TargetPort: intstr.IntOrString{IntVal: r.Logging.Spec.FluentbitSpec.Metrics.Port},
-					},
-				},
+				Ports:     ports,
 				Selector:  r.getFluentBitLabels(),
 				Type:      corev1.ServiceTypeClusterIP,
 				ClusterIP: "None",
@@ -56,21 +65,31 @@ func (r *Reconciler) monitorServiceMetrics() (runtime.Object, reconciler.Desired
 				objectMetadata.Labels[k] = v
 			}
 		}
+		endpoints := []v1.Endpoint{{
+			Port:                 "http-metrics",
+			Path:                 r.Logging.Spec.FluentbitSpec.Metrics.Path,
+			HonorLabels:          r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.HonorLabels,
+			RelabelConfigs:       r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.Relabelings,
+			MetricRelabelConfigs: r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.MetricsRelabelings,
+			Scheme:               r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.Scheme,
+			TLSConfig:            r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.TLSConfig,
+		}}
+		if r.positionDBMetricsEnabled() {
+			endpoints = append(endpoints, v1.Endpoint{
+				Port:                 positionDBMetricsPortName,
+				Path:                 "/metrics",
+				HonorLabels:          r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.HonorLabels,
+				RelabelConfigs:       r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.Relabelings,
+				MetricRelabelConfigs: r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.MetricsRelabelings,
+			})
+		}
 		return &v1.ServiceMonitor{
 			ObjectMeta: objectMetadata,
 			Spec: v1.ServiceMonitorSpec{
 				JobLabel:        "",
 				TargetLabels:    nil,
 				PodTargetLabels: nil,
-				Endpoints: []v1.Endpoint{{
-					Port:                 "http-metrics",
-					Path:                 r.Logging.Spec.FluentbitSpec.Metrics.Path,
-					HonorLabels:          r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.HonorLabels,
-					RelabelConfigs:       r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.Relabelings,
-					MetricRelabelConfigs: r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.MetricsRelabelings,
-					Scheme:               r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.Scheme,
-					TLSConfig:            r.Logging.Spec.FluentbitSpec.Metrics.ServiceMonitorConfig.TLSConfig,
-				}},
+				Endpoints:       endpoints,
 				Selector: v12.LabelSelector{
 					MatchLabels: util.MergeLabels(r.Logging.Spec.FluentbitSpec.Labels, r.getFluentBitLabels(), generateLoggingRefLabels(r.Logging.ObjectMeta.GetName())),
 				},
@@ -18,14 +18,18 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"strings"
 
 	"emperror.dev/errors"
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
 )
 
 type ConfigCheckResult struct {
@@ -34,13 +38,119 @@ type ConfigCheckResult struct {
 	Message string
 }
 
-func (r *Reconciler) appConfigSecret() (runtime.Object, reconciler.DesiredState, error) {
-	data := make(map[string][]byte)
-	data[AppConfigKey] = []byte(*r.config)
-	return &corev1.Secret{
-		ObjectMeta: r.FluentdObjectMeta(AppSecretConfigName, ComponentFluentd),
-		Data:       data,
-	}, reconciler.StatePresent, nil
+// appConfigChunkLabel marks the secrets holding chunks of the generated configuration
+const appConfigChunkLabel = "logging.banzaicloud.io/app-config-chunk"
+
+// appConfigSecrets returns the secrets holding the generated configuration, a single one unless chunking is enabled
+func (r *Reconciler) appConfigSecrets() ([]runtime.Object, reconciler.DesiredState, error) {
+	var objects []runtime.Object
+	for i, chunk := range r.appConfigChunks() {
+		meta := r.FluentdObjectMeta(appConfigSecretName(i), ComponentFluentd)
+		if r.appConfigChunked() {
+			meta.Labels[appConfigChunkLabel] = "true"
+		}
+		objects = append(objects, &corev1.Secret{
+			ObjectMeta: meta,
+			Data: map[string][]byte{
+				appConfigChunkKey(i): []byte(chunk),
+			},
+		})
+	}
+	return objects, reconciler.StatePresent, nil
+}
+
+// staleAppConfigSecrets returns the chunk secrets left over after the configuration shrunk or chunking was disabled
+func (r *Reconciler) staleAppConfigSecrets() ([]runtime.Object, reconciler.DesiredState, error) {
+	labels := r.Logging.GetFluentdLabels(ComponentFluentd)
+	labels[appConfigChunkLabel] = "true"
+	existing := &corev1.SecretList{}
+	if err := r.Client.List(context.TODO(), existing, client.InNamespace(r.Logging.Spec.ControlNamespace), client.MatchingLabels(labels)); err != nil {
+		return nil, reconciler.StateAbsent, errors.WrapIf(err, "failed to list app config chunk secrets")
+	}
+	desired := make(map[string]bool)
+	for i := range r.appConfigChunks() {
+		desired[r.Logging.QualifiedName(appConfigSecretName(i))] = true
+	}
+	var objects []runtime.Object
+	for i := range existing.Items {
+		if !desired[existing.Items[i].Name] {
+			objects = append(objects, &existing.Items[i])
+		}
+	}
+	return objects, reconciler.StateAbsent, nil
+}
+
+func (r *Reconciler) appConfigChunked() bool {
+	chunking := r.Logging.Spec.FluentdSpec.AppConfigChunking
+	return chunking != nil && chunking.Enabled
+}
+
+// appConfigChunks splits the generated configuration into chunks not larger than the configured size
+func (r *Reconciler) appConfigChunks() []string {
+	if !r.appConfigChunked() {
+		return []string{*r.config}
+	}
+	maxSize := r.Logging.Spec.FluentdSpec.AppConfigChunking.MaxChunkSize
+	if maxSize <= 0 {
+		maxSize = v1beta1.DefaultAppConfigMaxChunkSize
+	}
+	return chunkConfig(*r.config, maxSize)
+}
+
+// chunkConfig groups the top level directives of the configuration into chunks, keeping their order
+func chunkConfig(config string, maxSize int) []string {
+	var chunks []string
+	var current strings.Builder
+	for _, directive := range splitTopLevelDirectives(config) {
+		if current.Len() > 0 && current.Len()+len(directive) > maxSize {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		current.WriteString(directive)
+	}
+	if current.Len() > 0 || len(chunks) == 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// splitTopLevelDirectives cuts the configuration before each directive opened at the first column
+func splitTopLevelDirectives(config string) []string {
+	var directives []string
+	start := 0
+	for pos := 0; pos < len(config); {
+		end := strings.IndexByte(config[pos:], '\n')
+		if end < 0 {
+			end = len(config)
+		} else {
+			end += pos + 1
+		}
+		line := config[pos:end]
+		if pos > start && strings.HasPrefix(line, "<") && !strings.HasPrefix(line, "</") {
+			directives = append(directives, config[start:pos])
+			start = pos
+		}
+		pos = end
+	}
+	if start < len(config) {
+		directives = append(directives, config[start:])
+	}
+	return directives
+}
+
+func appConfigSecretName(chunk int) string {
+	if chunk == 0 {
+		return AppSecretConfigName
+	}
+	return fmt.Sprintf("%s-%d", AppSecretConfigName, chunk)
+}
+
+// appConfigChunkKey names the chunks so that the include glob of fluentd picks them up in order
+func appConfigChunkKey(chunk int) string {
+	if chunk == 0 {
+		return AppConfigKey
+	}
+	return fmt.Sprintf("%s.%03d", AppConfigKey, chunk)
 }
 
 func (r *Reconciler) configHash() (string, error) {
@@ -58,13 +168,15 @@ func (r *Reconciler) configCheck(ctx context.Context) (*ConfigCheckResult, error
 		return nil, err
 	}
 
-	checkSecret, err := r.newCheckSecret(hashKey)
+	checkSecrets, err := r.newCheckSecrets(hashKey)
 	if err != nil {
 		return nil, err
 	}
-	err = r.Client.Create(ctx, checkSecret)
-	if err != nil && !apierrors.IsAlreadyExists(err) {
-		return nil, errors.WrapIf(err, "failed to create secret for fluentd configcheck")
+	for _, checkSecret := range checkSecrets {
+		err = r.Client.Create(ctx, checkSecret)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return nil, errors.WrapIf(err, "failed to create secret for fluentd configcheck")
+		}
 	}
 
 	checkOutputSecret, err := r.newCheckOutputSecret(hashKey)
@@ -144,19 +256,11 @@ func (r *Reconciler) configCheckCleanup(currentHash string) (removedHashes []str
 		if configHash == currentHash {
 			continue
 		}
-		newSecret, err := r.newCheckSecret(configHash)
-		if err != nil {
+		if err := r.deleteCheckSecrets(configHash); err != nil {
 			multierr = errors.Combine(multierr,
-				errors.Wrapf(err, "failed to create config check secret %s", configHash))
+				errors.Wrapf(err, "failed to remove config check secret %s", configHash))
 			continue
 		}
-		if err := r.Client.Delete(context.TODO(), newSecret); err != nil {
-			if !apierrors.IsNotFound(err) {
-				multierr = errors.Combine(multierr,
-					errors.Wrapf(err, "failed to remove config check secret %s", configHash))
-				continue
-			}
-		}
 		checkOutputSecret, err := r.newCheckOutputSecret(configHash)
 		if err != nil {
 			multierr = errors.Combine(multierr,
@@ -182,17 +286,93 @@ func (r *Reconciler) configCheckCleanup(currentHash string) (removedHashes []str
 	return
 }
 
-func (r *Reconciler) newCheckSecret(hashKey string) (*corev1.Secret, error) {
+// newCheckSecrets returns the secrets of the configcheck pod, the generated configuration is chunked the same way
+// as for the fluentd pods. The first secret holds the first chunk along with the rest of the configuration files.
+func (r *Reconciler) newCheckSecrets(hashKey string) ([]*corev1.Secret, error) {
 	data, err := r.generateConfigSecret()
 	if err != nil {
 		return nil, err
 	}
-	data[ConfigCheckKey] = []byte(*r.config)
+	chunks := r.appConfigChunks()
+	data[configCheckChunkKey(0)] = []byte(chunks[0])
 	data["fluent.conf"] = []byte(fluentdConfigCheckTemplate)
-	return &corev1.Secret{
-		ObjectMeta: r.FluentdObjectMeta(fmt.Sprintf("fluentd-configcheck-%s", hashKey), ComponentConfigCheck),
+	secrets := []*corev1.Secret{{
+		ObjectMeta: r.FluentdObjectMeta(checkSecretName(hashKey, 0), ComponentConfigCheck),
 		Data:       data,
-	}, nil
+	}}
+	for i := 1; i < len(chunks); i++ {
+		secrets = append(secrets, &corev1.Secret{
+			ObjectMeta: r.FluentdObjectMeta(checkSecretName(hashKey, i), ComponentConfigCheck),
+			Data: map[string][]byte{
+				configCheckChunkKey(i): []byte(chunks[i]),
+			},
+		})
+	}
+	return secrets, nil
+}
+
+// deleteCheckSecrets removes the secrets of the configcheck of the configuration, along with its chunks
+func (r *Reconciler) deleteCheckSecrets(hashKey string) error {
+	existing := &corev1.SecretList{}
+	if err := r.Client.List(context.TODO(), existing,
+		client.InNamespace(r.Logging.Spec.ControlNamespace),
+		client.MatchingLabels(r.Logging.GetFluentdLabels(ComponentConfigCheck))); err != nil {
+		return errors.WrapIf(err, "failed to list config check secrets")
+	}
+	name := r.Logging.QualifiedName(checkSecretName(hashKey, 0))
+	for i := range existing.Items {
+		if existing.Items[i].Name != name && !strings.HasPrefix(existing.Items[i].Name, name+"-") {
+			continue
+		}
+		if err := r.Client.Delete(context.TODO(), &existing.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkSecretName(hashKey string, chunk int) string {
+	if chunk == 0 {
+		return fmt.Sprintf("fluentd-configcheck-%s", hashKey)
+	}
+	return fmt.Sprintf("fluentd-configcheck-%s-%d", hashKey, chunk)
+}
+
+// configCheckChunkKey names the chunks so that the include glob of the configcheck picks them up in order
+func configCheckChunkKey(chunk int) string {
+	if chunk == 0 {
+		return ConfigCheckKey
+	}
+	return fmt.Sprintf("%s.%03d", ConfigCheckKey, chunk)
+}
+
+// checkConfigVolume returns the volume of the configcheck pod, projecting the secrets of all the chunks
+func (r *Reconciler) checkConfigVolume(hashKey string) corev1.Volume {
+	chunks := r.appConfigChunks()
+	if len(chunks) == 1 {
+		return corev1.Volume{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: r.Logging.QualifiedName(checkSecretName(hashKey, 0)),
+				},
+			},
+		}
+	}
+	sources := make([]corev1.VolumeProjection, 0, len(chunks))
+	for i := range chunks {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: r.Logging.QualifiedName(checkSecretName(hashKey, i))},
+			},
+		})
+	}
+	return corev1.Volume{
+		Name: "config",
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+		},
+	}
 }
 
 func (r *Reconciler) newCheckOutputSecret(hashKey string) (*corev1.Secret, error) {
@@ -218,20 +398,14 @@ func (r *Reconciler) newCheckPod(hashKey string) *corev1.Pod {
 			Affinity:           r.Logging.Spec.FluentdSpec.Affinity,
 			PriorityClassName:  r.Logging.Spec.FluentdSpec.PodPriorityClassName,
 			SecurityContext: &corev1.PodSecurityContext{
-				RunAsNonRoot: r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsNonRoot,
-				FSGroup:      r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.FSGroup,
-				RunAsUser:    r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsUser,
-				RunAsGroup:   r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsGroup,
+				RunAsNonRoot:   r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsNonRoot,
+				FSGroup:        r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.FSGroup,
+				RunAsUser:      r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsUser,
+				RunAsGroup:     r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsGroup,
+				SeccompProfile: r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.SeccompProfile,
 			},
 			Volumes: []corev1.Volume{
-				{
-					Name: "config",
-					VolumeSource: corev1.VolumeSource{
-						Secret: &corev1.SecretVolumeSource{
-							SecretName: r.Logging.QualifiedName(fmt.Sprintf("fluentd-configcheck-%s", hashKey)),
-						},
-					},
-				},
+				r.checkConfigVolume(hashKey),
 				{
 					Name: "output-secret",
 					VolumeSource: corev1.VolumeSource{
@@ -270,6 +444,8 @@ func (r *Reconciler) newCheckPod(hashKey string) *corev1.Pod {
 						Privileged:               r.Logging.Spec.FluentdSpec.Security.SecurityContext.Privileged,
 						RunAsNonRoot:             r.Logging.Spec.FluentdSpec.Security.SecurityContext.RunAsNonRoot,
 						SELinuxOptions:           r.Logging.Spec.FluentdSpec.Security.SecurityContext.SELinuxOptions,
+						Capabilities:             r.Logging.Spec.FluentdSpec.Security.SecurityContext.Capabilities,
+						SeccompProfile:           r.Logging.Spec.FluentdSpec.Security.SecurityContext.SeccompProfile,
 					},
 					Resources: r.Logging.Spec.FluentdSpec.ConfigCheckResources,
 				},
@@ -279,6 +455,9 @@ func (r *Reconciler) newCheckPod(hashKey string) *corev1.Pod {
 	if r.Logging.Spec.FluentdSpec.ConfigCheckAnnotations != nil {
 		pod.Annotations = r.Logging.Spec.FluentdSpec.ConfigCheckAnnotations
 	}
+	if r.Logging.Spec.ServiceMesh.IsEnabled() {
+		pod.Annotations = util.MergeLabels(pod.Annotations, r.Logging.Spec.ServiceMesh.JobAnnotations())
+	}
 	if r.Logging.Spec.FluentdSpec.TLS.Enabled {
 		tlsVolume := corev1.Volume{
 			Name: "fluentd-tls",
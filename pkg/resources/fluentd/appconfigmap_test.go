@@ -0,0 +1,125 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testConfig = `<source>
+  @type forward
+</source>
+<match **>
+  @type label_router
+  <route>
+    @label @a
+  </route>
+</match>
+<label @a>
+  <match **>
+    @type null
+  </match>
+</label>
+`
+
+func TestSplitTopLevelDirectives(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name:   "empty",
+			config: "",
+			want:   nil,
+		},
+		{
+			name:   "single",
+			config: "<source>\n  @type forward\n</source>\n",
+			want:   []string{"<source>\n  @type forward\n</source>\n"},
+		},
+		{
+			name:   "nested directives stay with their parent",
+			config: testConfig,
+			want: []string{
+				"<source>\n  @type forward\n</source>\n",
+				"<match **>\n  @type label_router\n  <route>\n    @label @a\n  </route>\n</match>\n",
+				"<label @a>\n  <match **>\n    @type null\n  </match>\n</label>\n",
+			},
+		},
+		{
+			name:   "leading lines and a missing final newline are kept",
+			config: "# generated\n\n<source>\n</source>\n<match **>\n</match>",
+			want:   []string{"# generated\n\n", "<source>\n</source>\n", "<match **>\n</match>"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitTopLevelDirectives(tt.config); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitTopLevelDirectives() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkConfig(t *testing.T) {
+	directives := splitTopLevelDirectives(testConfig)
+	tests := []struct {
+		name    string
+		config  string
+		maxSize int
+		want    []string
+	}{
+		{
+			name:    "empty",
+			config:  "",
+			maxSize: 10,
+			want:    []string{""},
+		},
+		{
+			name:    "fits into one chunk",
+			config:  testConfig,
+			maxSize: len(testConfig),
+			want:    []string{testConfig},
+		},
+		{
+			name:    "directives are grouped up to the limit",
+			config:  testConfig,
+			maxSize: len(directives[0]) + len(directives[1]),
+			want:    []string{directives[0] + directives[1], directives[2]},
+		},
+		{
+			name:    "a directive larger than the limit gets its own chunk",
+			config:  testConfig,
+			maxSize: 1,
+			want:    directives,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := chunkConfig(tt.config, tt.maxSize)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunkConfig() = %q, want %q", got, tt.want)
+			}
+			if joined := strings.Join(got, ""); joined != tt.config {
+				t.Errorf("chunks do not add up to the configuration: %q", joined)
+			}
+		})
+	}
+}
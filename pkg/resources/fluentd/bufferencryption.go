@@ -0,0 +1,156 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	bufferEncryptionContainerName = "buffer-encryption"
+	// the buffer volume is mounted here in the encryption sidecar, the encrypted chunks are kept in a subdirectory
+	// to leave the existing content of the volume (e.g. lost+found) alone
+	bufferEncryptedPath = "/buffers-encrypted"
+	bufferKeyPath       = "/buffer-encryption-key"
+)
+
+// bufferEncryptionScript initializes the encrypted directory on the first start and mounts its plaintext view.
+// Chunk names are kept in plaintext for the buffer metrics and the drain watcher.
+// In drainer jobs the mount is released once fluentd has stopped, so that the job can complete.
+const bufferEncryptionScript = `set -e
+cipher=` + bufferEncryptedPath + `/encrypted
+if [ ! -f "$cipher/gocryptfs.conf" ]; then
+  mkdir -p "$cipher"
+  gocryptfs -init -plaintextnames -passfile ` + bufferKeyPath + `/key "$cipher"
+fi
+if [ "$RELEASE_WITH_FLUENTD" != "true" ]; then
+  exec gocryptfs -fg -allow_other -passfile ` + bufferKeyPath + `/key "$cipher" ` + bufferPath + `
+fi
+gocryptfs -fg -allow_other -passfile ` + bufferKeyPath + `/key "$cipher" ` + bufferPath + ` &
+until pgrep -x fluentd >/dev/null; do sleep 1; done
+while pgrep -x fluentd >/dev/null; do sleep 5; done
+fusermount -u ` + bufferPath + `
+wait
+`
+
+// bufferEncryptionMounted blocks the start of the following containers until the plaintext view is mounted
+const bufferEncryptionMounted = `until grep -q " ` + bufferPath + ` fuse" /proc/mounts; do sleep 1; done`
+
+func (r *Reconciler) bufferEncryptionEnabled() bool {
+	e := r.Logging.Spec.FluentdSpec.BufferVolumeEncryption
+	return e != nil && e.Enabled
+}
+
+// applyBufferEncryption moves the buffer volume of the fluentd container to the encryption sidecar, fluentd gets the
+// plaintext view through mount propagation. The sidecar is the first container, so that its postStart hook holds
+// the start of fluentd until the view is mounted. Its preStop hook keeps the view mounted until fluentd has stopped.
+func (r *Reconciler) applyBufferEncryption(podSpec *corev1.PodSpec, drainer bool) error {
+	if !r.bufferEncryptionEnabled() {
+		return nil
+	}
+	encryption := r.Logging.Spec.FluentdSpec.BufferVolumeEncryption
+	if encryption.KeySecret.Name == "" || encryption.KeySecret.Key == "" {
+		return errors.New("bufferVolumeEncryption.keySecret is required")
+	}
+	if encryption.Image.Repository == "" {
+		return errors.New("bufferVolumeEncryption.image.repository is required")
+	}
+
+	var fluentd *corev1.Container
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == containerName {
+			fluentd = &podSpec.Containers[i]
+		}
+	}
+	if fluentd == nil {
+		return errors.New("fluentd container not found")
+	}
+	var bufferMount *corev1.VolumeMount
+	for i, m := range fluentd.VolumeMounts {
+		if m.MountPath == bufferPath {
+			bufferMount = fluentd.VolumeMounts[i].DeepCopy()
+			fluentd.VolumeMounts = append(fluentd.VolumeMounts[:i], fluentd.VolumeMounts[i+1:]...)
+			break
+		}
+	}
+	if bufferMount == nil {
+		return errors.New("buffer volume mount of the fluentd container not found")
+	}
+
+	plainVolume := r.Logging.QualifiedName("buffer-plain")
+	hostToContainer := corev1.MountPropagationHostToContainer
+	bidirectional := corev1.MountPropagationBidirectional
+	fluentd.VolumeMounts = append(fluentd.VolumeMounts, corev1.VolumeMount{
+		Name:             plainVolume,
+		MountPath:        bufferPath,
+		MountPropagation: &hostToContainer,
+	})
+
+	bufferMount.MountPath = bufferEncryptedPath
+	sidecar := corev1.Container{
+		Name:            bufferEncryptionContainerName,
+		Image:           encryption.Image.RepositoryWithTag(),
+		ImagePullPolicy: corev1.PullPolicy(encryption.Image.PullPolicy),
+		Command:         []string{"sh", "-c", bufferEncryptionScript},
+		Env: []corev1.EnvVar{
+			{Name: "RELEASE_WITH_FLUENTD", Value: strconv.FormatBool(drainer)},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			*bufferMount,
+			{Name: plainVolume, MountPath: bufferPath, MountPropagation: &bidirectional},
+			{Name: r.Logging.QualifiedName("buffer-encryption-key"), MountPath: bufferKeyPath, ReadOnly: true},
+		},
+		Lifecycle: &corev1.Lifecycle{
+			PostStart: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{Command: []string{"sh", "-c", bufferEncryptionMounted}},
+			},
+			PreStop: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{Command: []string{"sh", "-c", "while pgrep -x fluentd >/dev/null; do sleep 1; done"}},
+			},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			// Required by FUSE and the bidirectional mount propagation
+			Privileged: utils.BoolPointer(true),
+		},
+		Resources: encryption.Resources,
+	}
+	podSpec.Containers = append([]corev1.Container{sidecar}, podSpec.Containers...)
+
+	podSpec.Volumes = append(podSpec.Volumes,
+		corev1.Volume{
+			Name: plainVolume,
+			VolumeSource: corev1.VolumeSource{
+				// Anything written before the view is mounted stays in memory
+				EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+			},
+		},
+		corev1.Volume{
+			Name: r.Logging.QualifiedName("buffer-encryption-key"),
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: encryption.KeySecret.Name,
+					Items:      []corev1.KeyToPath{{Key: encryption.KeySecret.Key, Path: "key"}},
+				},
+			},
+		},
+	)
+	// The sidecar sees when fluentd has stopped, the view has to stay mounted until fluentd has flushed its buffers
+	podSpec.ShareProcessNamespace = utils.BoolPointer(true)
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// BufferRepairContainerName is the name of the init container quarantining the corrupt buffer chunks.
+// Its termination message is "<quarantined chunks> <quarantined bytes>".
+const BufferRepairContainerName = "buffer-repair"
+
+// bufferQuarantineDir is where the corrupt chunks are moved, outside of the buffer paths of the outputs
+const bufferQuarantineDir = bufferPath + "/quarantine"
+
+// bufferRepairScript checks every chunk of the file buffers with the msgpack library of fluentd.
+// The metadata files start with a header followed by the msgpack encoded metadata, "s" is the number of records.
+const bufferRepairScript = `require 'fileutils'
+require 'msgpack'
+
+root = ENV.fetch('BUFFER_PATH')
+quarantine_root = '` + bufferQuarantineDir + `'
+quarantine = File.join(quarantine_root, Time.now.utc.strftime('%Y%m%dT%H%M%SZ'))
+
+def read_meta(path)
+  data = File.binread(path)
+  if data.start_with?("\xC1\x00".b)
+    size = data[2, 4].unpack1('N')
+    return nil if size.nil? || data.bytesize < 6 + size
+    data = data[6, size]
+  end
+  meta = MessagePack.unpack(data)
+  meta.is_a?(Hash) ? meta : nil
+rescue StandardError
+  nil
+end
+
+def valid_chunk?(path, meta)
+  File.open(path, 'rb') do |io|
+    # Compressed chunks are not decoded
+    return true if io.read(2) == "\x1F\x8B".b
+    io.rewind
+    records = 0
+    MessagePack::Unpacker.new(io).each { records += 1 }
+    records >= meta.fetch('s', 0).to_i
+  end
+rescue StandardError
+  false
+end
+
+chunks = 0
+bytes = 0
+Dir.glob(File.join(root, '**', '*.buffer')).each do |chunk|
+  next if chunk.start_with?(quarantine_root + '/')
+  meta = read_meta(chunk + '.meta') if File.exist?(chunk + '.meta')
+  next if meta && valid_chunk?(chunk, meta)
+
+  FileUtils.mkdir_p(quarantine)
+  [chunk, chunk + '.meta'].select { |f| File.exist?(f) }.each do |f|
+    bytes += File.size(f)
+    # The suffix keeps the quarantined chunks out of the buffer file count of the readiness check
+    FileUtils.mv(f, File.join(quarantine, File.basename(f) + '.quarantined'))
+  end
+  chunks += 1
+  puts "quarantined corrupt buffer chunk #{chunk}"
+end
+File.write('/dev/termination-log', "#{chunks} #{bytes}")
+`
+
+func (r *Reconciler) bufferRepairContainer() *corev1.Container {
+	spec := r.Logging.Spec.FluentdSpec
+	// Encrypted chunks cannot be checked on the volume
+	if spec.BufferVolumeRepair == nil || !spec.BufferVolumeRepair.Enabled || r.bufferEncryptionEnabled() {
+		return nil
+	}
+	return &corev1.Container{
+		Name:            BufferRepairContainerName,
+		Image:           spec.Image.RepositoryWithTag(),
+		ImagePullPolicy: corev1.PullPolicy(spec.Image.PullPolicy),
+		Command:         []string{"ruby", "-e", bufferRepairScript},
+		Env: []corev1.EnvVar{
+			{Name: "BUFFER_PATH", Value: bufferPath},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      r.Logging.QualifiedName(v1beta1.DefaultFluentdBufferStorageVolumeName),
+				MountPath: bufferPath,
+			},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:                spec.Security.SecurityContext.RunAsUser,
+			RunAsGroup:               spec.Security.SecurityContext.RunAsGroup,
+			ReadOnlyRootFilesystem:   spec.Security.SecurityContext.ReadOnlyRootFilesystem,
+			AllowPrivilegeEscalation: spec.Security.SecurityContext.AllowPrivilegeEscalation,
+			Privileged:               spec.Security.SecurityContext.Privileged,
+			RunAsNonRoot:             spec.Security.SecurityContext.RunAsNonRoot,
+			SELinuxOptions:           spec.Security.SecurityContext.SELinuxOptions,
+			Capabilities:             spec.Security.SecurityContext.Capabilities,
+			SeccompProfile:           spec.Security.SecurityContext.SeccompProfile,
+		},
+		Resources: spec.Resources,
+	}
+}
+
+// ParseBufferRepairResult parses the termination message of the buffer repair container
+func ParseBufferRepairResult(message string) (chunks, bytes int64, err error) {
+	_, err = fmt.Sscanf(message, "%d %d", &chunks, &bytes)
+	return
+}
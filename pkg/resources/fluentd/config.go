@@ -17,7 +17,7 @@ package fluentd
 var fluentdConfigCheckTemplate = `
 # include other config files
 @include /fluentd/etc/input.conf
-@include /fluentd/etc/generated.conf
+@include /fluentd/etc/generated.conf*
 @include /fluentd/etc/devnull.conf
 @include /fluentd/etc/fluentlog.conf
 `
@@ -51,13 +51,30 @@ var fluentdInputTemplate = `
 <source>
     @type prometheus
     port {{ .Monitor.Port }}
+{{- if gt .Workers 1 }}
+    # Every worker listens on port + worker id, the first one serves the metrics of all workers, which are
+    # told apart by their worker_id label
+    metrics_path /worker-metrics
+    aggregated_metrics_path {{ .Monitor.Path }}
+{{- else }}
     metrics_path {{ .Monitor.Path }}
+{{- end }}
 </source>
 <source>
     @type prometheus_monitor
+{{- if gt .Workers 1 }}
+    <labels>
+        worker_id ${worker_id}
+    </labels>
+{{- end }}
 </source>
 <source>
     @type prometheus_output_monitor
+{{- if gt .Workers 1 }}
+    <labels>
+        worker_id ${worker_id}
+    </labels>
+{{- end }}
 </source>
 {{ end }}
 `
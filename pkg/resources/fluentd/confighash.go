@@ -0,0 +1,89 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ConfigHashAnnotation holds the hash of the data of the config secrets, the secrets are only written when it changes
+const ConfigHashAnnotation = "logging.banzaicloud.io/config-hash"
+
+var configSecretUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "logging_config_secret_updates_total",
+	Help: "Number of config secret reconciles of the logging by whether the rendered content changed",
+}, []string{"logging", "secret", "result"})
+
+func init() {
+	metrics.Registry.MustRegister(configSecretUpdates)
+}
+
+// reconcileConfigSecret reconciles a config secret only if the hash of its content differs from the one recorded
+// on the existing secret, which spares the api server the writes and the watchers the events of a rewrite.
+func (r *Reconciler) reconcileConfigSecret(object runtime.Object, state reconciler.DesiredState) (*reconcile.Result, error) {
+	desired, ok := object.(*corev1.Secret)
+	if !ok || state != reconciler.StatePresent {
+		return r.ReconcileResource(object, state)
+	}
+	hash := secretDataHash(desired.Data)
+	if desired.Annotations == nil {
+		desired.Annotations = make(map[string]string)
+	}
+	desired.Annotations[ConfigHashAnnotation] = hash
+
+	labels := prometheus.Labels{"logging": r.Logging.Name, "secret": desired.Name}
+	existing := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.WrapIfWithDetails(err, "failed to load config secret", "secret", desired.Name, "namespace", desired.Namespace)
+	}
+	if err == nil && existing.Annotations[ConfigHashAnnotation] == hash && secretDataHash(existing.Data) == hash &&
+		reflect.DeepEqual(existing.Labels, desired.Labels) {
+		labels["result"] = "unchanged"
+		configSecretUpdates.With(labels).Inc()
+		return nil, nil
+	}
+	labels["result"] = "changed"
+	configSecretUpdates.With(labels).Inc()
+	return r.ReconcileResource(desired, state)
+}
+
+func secretDataHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%d:", k, len(data[k]))
+		_, _ = h.Write(data[k])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -23,6 +23,8 @@ import (
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
 )
 
 type fluentdConfig struct {
@@ -84,6 +86,9 @@ func (r *Reconciler) generateConfigSecret() (map[string][]byte, error) {
 		"input.conf":   []byte(inputConfig),
 		"devnull.conf": []byte(fluentdOutputTemplate),
 	}
+	if config := model.TLSProfileOpenSSLConfig(r.Logging.Spec.TLSProfile); config != "" {
+		configs[openSSLConfigKey] = []byte(config)
+	}
 	return configs, nil
 }
 
@@ -0,0 +1,121 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestGenerateConfigMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int32
+		want    string
+	}{
+		{
+			name:    "single worker",
+			workers: 1,
+			want: `
+<source>
+    @type prometheus
+    port 24231
+    metrics_path /metrics
+</source>
+<source>
+    @type prometheus_monitor
+</source>
+<source>
+    @type prometheus_output_monitor
+</source>
+`,
+		},
+		{
+			name:    "every worker is labeled",
+			workers: 2,
+			want: `
+<source>
+    @type prometheus
+    port 24231
+    # Every worker listens on port + worker id, the first one serves the metrics of all workers, which are
+    # told apart by their worker_id label
+    metrics_path /worker-metrics
+    aggregated_metrics_path /metrics
+</source>
+<source>
+    @type prometheus_monitor
+    <labels>
+        worker_id ${worker_id}
+    </labels>
+</source>
+<source>
+    @type prometheus_output_monitor
+    <labels>
+        worker_id ${worker_id}
+    </labels>
+</source>
+`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			input := fluentdConfig{LogLevel: "info", Workers: tt.workers}
+			input.Monitor.Enabled = true
+			input.Monitor.Port = 24231
+			input.Monitor.Path = "/metrics"
+			config, err := generateConfig(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.TrimSpace(config[strings.Index(config, "<source>"):])
+			if got != strings.TrimSpace(tt.want) {
+				t.Errorf("generateConfig() metrics =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateConfigSecretTLSProfile(t *testing.T) {
+	r := &Reconciler{Logging: &v1beta1.Logging{Spec: v1beta1.LoggingSpec{FluentdSpec: &v1beta1.FluentdSpec{}}}}
+	configs, err := r.generateConfigSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := configs[openSSLConfigKey]; ok {
+		t.Errorf("unexpected %s without a TLS profile", openSSLConfigKey)
+	}
+	if container := r.withOpenSSLConfig(corev1.Container{}); len(container.Env) > 0 {
+		t.Errorf("unexpected env without a TLS profile: %v", container.Env)
+	}
+
+	r.Logging.Spec.TLSProfile = v1beta1.TLSProfileFIPS
+	configs, err = r.generateConfigSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(configs[openSSLConfigKey]), "Groups = prime256v1:secp384r1:secp521r1") {
+		t.Errorf("unexpected %s:\n%s", openSSLConfigKey, configs[openSSLConfigKey])
+	}
+	want := []corev1.EnvVar{{Name: "OPENSSL_CONF", Value: "/fluentd/etc/openssl.cnf"}}
+	if container := r.withOpenSSLConfig(corev1.Container{}); !reflect.DeepEqual(container.Env, want) {
+		t.Errorf("env = %v, want %v", container.Env, want)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	"bytes"
+	"context"
+	"text/template"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+const dashboardName = "fluentd-dashboard"
+
+// The template uses << >> delimiters, as {{ }} is used by the legends of grafana
+var dashboardTemplate = `{
+  "title": "Logging / << .Logging >> / Aggregator",
+  "uid": "<< .UID >>",
+  "tags": ["logging", "<< .Logging >>"],
+  "timezone": "browser",
+  "refresh": "30s",
+  "schemaVersion": 27,
+  "time": {"from": "now-6h", "to": "now"},
+  "panels": [
+    {
+      "title": "Throughput (records/s)",
+      "type": "timeseries",
+      "gridPos": {"h": 8, "w": 12, "x": 0, "y": 0},
+      "targets": [
+        {"expr": "sum by (pod) (rate(fluentd_output_status_emit_records{<< .Selector >>}[5m]))", "legendFormat": "{{pod}}"}
+      ]
+    },
+    {
+      "title": "Buffer utilization",
+      "type": "timeseries",
+      "gridPos": {"h": 8, "w": 12, "x": 12, "y": 0},
+      "fieldConfig": {"defaults": {"unit": "percentunit", "max": 1, "min": 0}},
+      "targets": [
+        {"expr": "1 - node_filesystem_avail_bytes{<< .BufferSelector >>} / node_filesystem_size_bytes{<< .BufferSelector >>}", "legendFormat": "{{pod}}"},
+        {"expr": "sum by (pod) (fluentd_output_status_buffer_total_bytes{<< .Selector >>})", "legendFormat": "{{pod}} bytes"}
+      ]
+    },
+    {
+      "title": "Retries and errors (/s)",
+      "type": "timeseries",
+      "gridPos": {"h": 8, "w": 12, "x": 0, "y": 8},
+      "targets": [
+        {"expr": "sum by (type) (rate(fluentd_output_status_retry_count{<< .Selector >>}[5m]))", "legendFormat": "retries {{type}}"},
+        {"expr": "sum by (type) (rate(fluentd_output_status_num_errors{<< .Selector >>}[5m]))", "legendFormat": "errors {{type}}"}
+      ]
+    },
+    {
+      "title": "Drain jobs",
+      "type": "timeseries",
+      "gridPos": {"h": 8, "w": 12, "x": 12, "y": 8},
+      "targets": [
+        {"expr": "sum by (state) (logging_drain_jobs{logging=\"<< .Logging >>\"})", "legendFormat": "{{state}}"}
+      ]
+    }
+  ]
+}
+`
+
+// dashboards renders the grafana dashboard of the aggregator when monitoring.dashboards is enabled
+func (r *Reconciler) dashboards() (runtime.Object, reconciler.DesiredState, error) {
+	meta := r.FluentdObjectMeta(dashboardName, ComponentFluentd)
+	monitoring := r.Logging.Spec.Monitoring
+	if monitoring == nil || !monitoring.Dashboards.Enabled {
+		return &corev1.ConfigMap{ObjectMeta: meta}, reconciler.StateAbsent, nil
+	}
+	meta.Namespace = monitoring.Dashboards.Namespace
+	meta.Labels = util.MergeLabels(meta.Labels, monitoring.Dashboards.Labels)
+
+	namespace := r.Logging.Spec.ControlNamespace
+	input := map[string]string{
+		"Logging":        r.Logging.Name,
+		"UID":            "logging-" + string(r.Logging.UID),
+		"Selector":       `namespace=\"` + namespace + `\",service=\"` + r.Logging.QualifiedName(ServiceName+"-metrics") + `\"`,
+		"BufferSelector": `namespace=\"` + namespace + `\",service=\"` + r.Logging.QualifiedName(ServiceName+"-buffer-metrics") + `\",mountpoint=\"` + bufferPath + `\"`,
+	}
+	if len(input["UID"]) > 40 {
+		// grafana limits the length of the dashboard uid
+		input["UID"] = input["UID"][:40]
+	}
+	tmpl, err := template.New("dashboard").Delims("<<", ">>").Parse(dashboardTemplate)
+	if err != nil {
+		return nil, reconciler.StatePresent, errors.WrapIf(err, "failed to parse dashboard template")
+	}
+	output := new(bytes.Buffer)
+	if err := tmpl.Execute(output, input); err != nil {
+		return nil, reconciler.StatePresent, errors.WrapIf(err, "failed to render dashboard")
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: meta,
+		Data: map[string]string{
+			r.Logging.QualifiedName("aggregator.json"): output.String(),
+		},
+	}, reconciler.StatePresent, nil
+}
+
+// staleDashboards returns the dashboard ConfigMaps of the logging left in other namespaces than the configured one,
+// after the namespace of the dashboards is changed or they are disabled
+func (r *Reconciler) staleDashboards() ([]runtime.Object, reconciler.DesiredState, error) {
+	existing := &corev1.ConfigMapList{}
+	if err := r.Client.List(context.TODO(), existing, client.MatchingLabels(v1beta1.GenerateLoggingRefLabels(r.Logging.Name))); err != nil {
+		return nil, reconciler.StateAbsent, errors.WrapIf(err, "failed to list dashboard configmaps")
+	}
+	var namespace string
+	if monitoring := r.Logging.Spec.Monitoring; monitoring != nil && monitoring.Dashboards.Enabled {
+		namespace = monitoring.Dashboards.Namespace
+	}
+	var objects []runtime.Object
+	for i := range existing.Items {
+		cm := &existing.Items[i]
+		if cm.Name == r.Logging.QualifiedName(dashboardName) && cm.Namespace != namespace {
+			objects = append(objects, cm)
+		}
+	}
+	return objects, reconciler.StateAbsent, nil
+}
@@ -0,0 +1,84 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestStaleDashboards(t *testing.T) {
+	logging := &v1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: v1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			FluentdSpec:      &v1beta1.FluentdSpec{},
+			Monitoring:       &v1beta1.Monitoring{Dashboards: v1beta1.Dashboards{Enabled: true, Namespace: "grafana"}},
+		},
+	}
+	configMap := func(namespace, name, loggingRef string) client.Object {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    v1beta1.GenerateLoggingRefLabels(loggingRef),
+		}}
+	}
+	dashboard := logging.QualifiedName(dashboardName)
+	r := &Reconciler{
+		Logging: logging,
+		GenericResourceReconciler: reconciler.NewGenericReconciler(
+			fake.NewClientBuilder().WithObjects(
+				configMap("grafana", dashboard, "test"),
+				configMap("logging", dashboard, "test"),
+				configMap("monitoring", dashboard, "test"),
+				configMap("observability", dashboard, "other"),
+				configMap("logging", logging.QualifiedName("fluentd-app-config"), "test"),
+			).Build(), log.Log, reconciler.ReconcilerOpts{}),
+	}
+
+	stale := func() []string {
+		objects, state, err := r.staleDashboards()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state != reconciler.StateAbsent {
+			t.Errorf("state = %v", state)
+		}
+		var namespaces []string
+		for _, o := range objects {
+			namespaces = append(namespaces, o.(*corev1.ConfigMap).Namespace)
+		}
+		sort.Strings(namespaces)
+		return namespaces
+	}
+	if got, want := stale(), []string{"logging", "monitoring"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stale dashboards in %v, want %v", got, want)
+	}
+
+	logging.Spec.Monitoring.Dashboards.Enabled = false
+	if got, want := stale(), []string{"grafana", "logging", "monitoring"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stale dashboards in %v, want %v", got, want)
+	}
+}
@@ -35,6 +35,15 @@ func NewDataProvider(client client.Client) *DataProvider {
 }
 
 func (p *DataProvider) GetReplicaCount(ctx context.Context, logging *v1beta1.Logging) (*int32, error) {
+	if logging.Spec.FluentdSpec != nil && logging.Spec.FluentdSpec.IsDeployment() {
+		deployment := &v1.Deployment{}
+		om := logging.FluentdObjectMeta(DeploymentName, ComponentFluentd)
+		err := p.client.Get(ctx, types.NamespacedName{Namespace: om.Namespace, Name: om.Name}, deployment)
+		if err != nil {
+			return nil, errors.WrapIf(client.IgnoreNotFound(err), "getting fluentd deployment")
+		}
+		return deployment.Spec.Replicas, nil
+	}
 	sts := &v1.StatefulSet{}
 	om := logging.FluentdObjectMeta(StatefulSetName, ComponentFluentd)
 	err := p.client.Get(ctx, types.NamespacedName{Namespace: om.Namespace, Name: om.Name}, sts)
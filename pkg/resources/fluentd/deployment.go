@@ -0,0 +1,93 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/operator-tools/pkg/merge"
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// deployment runs fluentd as a Deployment when requested: the pod template is the same as the statefulset's,
+// but the buffer volume is mounted directly into every replica instead of through PVC templates. A PVC buffer
+// volume becomes a generic ephemeral volume, so each replica gets its own claim, removed along with the pod.
+func (r *Reconciler) deployment() (runtime.Object, reconciler.DesiredState, error) {
+	if !r.Logging.Spec.FluentdSpec.IsDeployment() {
+		return &appsv1.Deployment{
+			ObjectMeta: r.FluentdObjectMeta(DeploymentName, ComponentFluentd),
+		}, reconciler.StateAbsent, nil
+	}
+
+	sts := r.statefulsetSpec()
+
+	r.Logging.Spec.FluentdSpec.BufferStorageVolume.WithDefaultHostPath(
+		fmt.Sprintf(v1beta1.HostPath, r.Logging.Name, r.Logging.QualifiedName(v1beta1.DefaultFluentdBufferStorageVolumeName)),
+	)
+	bufferVolumeName := r.Logging.QualifiedName(v1beta1.DefaultFluentdBufferStorageVolumeName)
+	err := r.Logging.Spec.FluentdSpec.BufferStorageVolume.ApplyVolumeForPodSpec(bufferVolumeName, containerName, bufferPath, &sts.Template.Spec)
+	if err != nil {
+		return nil, reconciler.StatePresent, err
+	}
+	if pvc := r.Logging.Spec.FluentdSpec.BufferStorageVolume.PersistentVolumeClaim; pvc != nil {
+		for i, v := range sts.Template.Spec.Volumes {
+			if v.Name == bufferVolumeName {
+				sts.Template.Spec.Volumes[i].VolumeSource = corev1.VolumeSource{
+					Ephemeral: &corev1.EphemeralVolumeSource{
+						VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+							ObjectMeta: metav1.ObjectMeta{
+								Labels: r.Logging.GetFluentdLabels(ComponentFluentd),
+							},
+							Spec: pvc.PersistentVolumeClaimSpec,
+						},
+					},
+				}
+			}
+		}
+	}
+	for _, n := range r.Logging.Spec.FluentdSpec.ExtraVolumes {
+		if err := n.ApplyVolumeForPodSpec(&sts.Template.Spec); err != nil {
+			return nil, reconciler.StatePresent, err
+		}
+	}
+	r.applySpiffe(&sts.Template.Spec, false)
+	if err := r.applyBufferEncryption(&sts.Template.Spec, false); err != nil {
+		return nil, reconciler.StatePresent, err
+	}
+
+	desired := &appsv1.Deployment{
+		ObjectMeta: r.FluentdObjectMeta(DeploymentName, ComponentFluentd),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: sts.Replicas,
+			Selector: sts.Selector,
+			Template: sts.Template,
+		},
+	}
+
+	desired.Annotations = util.MergeLabels(desired.Annotations, r.Logging.Spec.FluentdSpec.StatefulSetAnnotations)
+
+	if err := merge.Merge(desired, r.Logging.Spec.FluentdSpec.DeploymentOverrides); err != nil {
+		return desired, reconciler.StatePresent, errors.WrapIf(err, "unable to merge overrides to base object")
+	}
+
+	return desired, reconciler.StatePresent, nil
+}
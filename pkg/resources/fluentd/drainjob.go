@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,7 +27,7 @@ import (
 func (r *Reconciler) drainerJobFor(pvc corev1.PersistentVolumeClaim) (*batchv1.Job, error) {
 	bufVolName := r.Logging.QualifiedName(r.Logging.Spec.FluentdSpec.BufferStorageVolume.PersistentVolumeClaim.PersistentVolumeSource.ClaimName)
 
-	fluentdContainer := fluentContainer(withoutFluentOutLogrotate(r.Logging.Spec.FluentdSpec))
+	fluentdContainer := r.withOpenSSLConfig(fluentContainer(withoutFluentOutLogrotate(r.Logging.Spec.FluentdSpec)))
 	fluentdContainer.VolumeMounts = append(fluentdContainer.VolumeMounts, corev1.VolumeMount{
 		Name:      bufVolName,
 		MountPath: bufferPath,
@@ -43,7 +44,7 @@ func (r *Reconciler) drainerJobFor(pvc corev1.PersistentVolumeClaim) (*batchv1.J
 		Template: corev1.PodTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
 				Labels:      r.Logging.GetFluentdLabels(ComponentDrainer),
-				Annotations: r.Logging.Spec.FluentdSpec.Scaling.Drain.Annotations,
+				Annotations: util.MergeLabels(r.Logging.Spec.FluentdSpec.Scaling.Drain.Annotations, r.Logging.Spec.ServiceMesh.JobAnnotations()),
 			},
 			Spec: corev1.PodSpec{
 				Volumes:                   r.generateVolume(),
@@ -55,11 +56,15 @@ func (r *Reconciler) drainerJobFor(pvc corev1.PersistentVolumeClaim) (*batchv1.J
 				Affinity:                  r.Logging.Spec.FluentdSpec.Affinity,
 				TopologySpreadConstraints: r.Logging.Spec.FluentdSpec.TopologySpreadConstraints,
 				PriorityClassName:         r.Logging.Spec.FluentdSpec.PodPriorityClassName,
+				// the drainer does not receive traffic, so it stays off the host network to avoid port conflicts
+				DNSPolicy: r.Logging.Spec.FluentdSpec.DNSPolicy,
+				DNSConfig: r.Logging.Spec.FluentdSpec.DNSConfig,
 				SecurityContext: &corev1.PodSecurityContext{
-					RunAsNonRoot: r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsNonRoot,
-					FSGroup:      r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.FSGroup,
-					RunAsUser:    r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsUser,
-					RunAsGroup:   r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsGroup,
+					RunAsNonRoot:   r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsNonRoot,
+					FSGroup:        r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.FSGroup,
+					RunAsUser:      r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsUser,
+					RunAsGroup:     r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsGroup,
+					SeccompProfile: r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.SeccompProfile,
 				},
 				RestartPolicy: corev1.RestartPolicyNever,
 			},
@@ -79,6 +84,10 @@ func (r *Reconciler) drainerJobFor(pvc corev1.PersistentVolumeClaim) (*batchv1.J
 			return nil, err
 		}
 	}
+	r.applySpiffe(&spec.Template.Spec, true)
+	if err := r.applyBufferEncryption(&spec.Template.Spec, true); err != nil {
+		return nil, err
+	}
 	return &batchv1.Job{
 		ObjectMeta: r.FluentdObjectMeta(StatefulSetName+pvc.Name[strings.LastIndex(pvc.Name, "-"):]+"-drainer", ComponentDrainer),
 		Spec:       spec,
@@ -29,6 +29,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -46,6 +47,7 @@ const (
 	ConfigKey             = "fluent.conf"
 	AppConfigKey          = "fluentd.conf"
 	StatefulSetName       = "fluentd"
+	DeploymentName        = "fluentd"
 	PodSecurityPolicyName = "fluentd"
 	ServiceName           = "fluentd"
 	OutputSecretName      = "fluentd-output"
@@ -67,6 +69,8 @@ type Reconciler struct {
 	*reconciler.GenericResourceReconciler
 	config  *string
 	secrets *secret.MountSecrets
+	// tlsChecksum is the checksum of the generated server certificate, used to roll the pods on rotation
+	tlsChecksum string
 }
 
 type Desire struct {
@@ -99,16 +103,19 @@ func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
 	ctx := context.Background()
 	patchBase := client.MergeFrom(r.Logging.DeepCopy())
 
-	for _, res := range []resources.Resource{
+	resourceList := []resources.Resource{
 		r.serviceAccount,
 		r.role,
 		r.roleBinding,
 		r.clusterRole,
 		r.clusterRoleBinding,
+	}
+	resourceList = append(resourceList, resources.PodSecurityPolicyResources(r.Client,
 		r.clusterPodSecurityPolicy,
 		r.pspRole,
 		r.pspRoleBinding,
-	} {
+	)...)
+	for _, res := range resourceList {
 		o, state, err := res()
 		if err != nil {
 			return nil, errors.WrapIf(err, "failed to create desired object")
@@ -184,7 +191,7 @@ func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
 	if err != nil {
 		return nil, errors.WrapIf(err, "failed to create output secret")
 	}
-	result, err := r.ReconcileResource(outputSecret, outputSecretDesiredState)
+	result, err := r.reconcileConfigSecret(outputSecret, outputSecretDesiredState)
 	if err != nil {
 		return nil, errors.WrapIf(err, "failed to reconcile resource")
 	}
@@ -205,18 +212,70 @@ func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
 			return result, nil
 		}
 	}
+	// Generate or request TLS certificates if configured
+	for _, res := range []func() ([]runtime.Object, reconciler.DesiredState, error){
+		r.tlsSecrets,
+		r.staleTLSPeerSecrets,
+		r.tlsCertificates,
+		r.spiffeHelperConfig,
+	} {
+		objects, state, err := res()
+		if err != nil {
+			return nil, errors.WrapIf(err, "failed to create TLS resources")
+		}
+		for _, obj := range objects {
+			result, err := r.ReconcileResource(obj, state)
+			if err != nil {
+				return nil, errors.WrapIf(err, "failed to reconcile resource")
+			}
+			if result != nil {
+				return result, nil
+			}
+		}
+	}
+	configSecret, state, err := r.secretConfig()
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to create desired object")
+	}
+	if result, err := r.reconcileConfigSecret(configSecret, state); err != nil {
+		return nil, errors.WrapIf(err, "failed to reconcile resource")
+	} else if result != nil {
+		return result, nil
+	}
+	for _, res := range []func() ([]runtime.Object, reconciler.DesiredState, error){
+		r.appConfigSecrets,
+		r.staleAppConfigSecrets,
+	} {
+		objects, state, err := res()
+		if err != nil {
+			return nil, errors.WrapIf(err, "failed to create app config secrets")
+		}
+		for _, obj := range objects {
+			result, err := r.reconcileConfigSecret(obj, state)
+			if err != nil {
+				return nil, errors.WrapIf(err, "failed to reconcile resource")
+			}
+			if result != nil {
+				return result, nil
+			}
+		}
+	}
 	for _, res := range []resources.Resource{
-		r.secretConfig,
-		r.appConfigSecret,
 		r.statefulset,
+		r.deployment,
+		r.verticalPodAutoscaler,
 		r.service,
 		r.headlessService,
+		r.httpInputService,
+		r.httpInputIngress,
+		r.networkPolicy,
 		r.serviceMetrics,
 		r.monitorServiceMetrics,
 		r.serviceBufferMetrics,
 		r.monitorBufferServiceMetrics,
 		r.prometheusRules,
 		r.bufferVolumePrometheusRules,
+		r.dashboards,
 	} {
 		o, state, err := res()
 		if err != nil {
@@ -234,6 +293,20 @@ func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
 		}
 	}
 
+	staleDashboards, state, err := r.staleDashboards()
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range staleDashboards {
+		result, err := r.ReconcileResource(obj, state)
+		if err != nil {
+			return nil, errors.WrapIf(err, "failed to reconcile resource")
+		}
+		if result != nil {
+			return result, nil
+		}
+	}
+
 	if res, err := r.reconcileDrain(ctx); res != nil || err != nil {
 		return res, err
 	}
@@ -242,7 +315,7 @@ func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
 }
 
 func (r *Reconciler) reconcileDrain(ctx context.Context) (*reconcile.Result, error) {
-	if r.Logging.Spec.FluentdSpec.DisablePvc || !r.Logging.Spec.FluentdSpec.Scaling.Drain.Enabled {
+	if r.Logging.Spec.FluentdSpec.DisablePvc || r.Logging.Spec.FluentdSpec.IsDeployment() || !r.Logging.Spec.FluentdSpec.Scaling.Drain.Enabled {
 		r.Log.Info("fluentd buffer draining is disabled")
 		return nil, nil
 	}
@@ -386,11 +459,13 @@ func RegisterWatches(builder *builder.Builder) *builder.Builder {
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.Service{}).
 		Owns(&appsv1.StatefulSet{}).
+		Owns(&appsv1.Deployment{}).
 		Owns(&rbacv1.ClusterRole{}).
 		Owns(&rbacv1.ClusterRoleBinding{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&batchv1.Job{}).
-		Owns(&corev1.PersistentVolumeClaim{})
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&networkingv1.NetworkPolicy{})
 }
 
 var drainableRequirement = requirementMust(labels.NewRequirement("logging.banzaicloud.io/drain", selection.NotEquals, []string{"no"}))
@@ -0,0 +1,125 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const httpInputServiceName = ServiceName + "-http-input"
+
+func (r *Reconciler) httpInputService() (runtime.Object, reconciler.DesiredState, error) {
+	spec := r.Logging.Spec.FluentdSpec.HTTPInput
+	if spec == nil || !spec.Enabled {
+		return &corev1.Service{
+			ObjectMeta: r.FluentdObjectMeta(httpInputServiceName, ComponentFluentd),
+		}, reconciler.StateAbsent, nil
+	}
+	serviceType := spec.ServiceType
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeClusterIP
+	}
+	desired := &corev1.Service{
+		ObjectMeta: r.FluentdObjectMeta(httpInputServiceName, ComponentFluentd),
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http-input",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       spec.Port,
+					TargetPort: intstr.FromInt(int(spec.Port)),
+				},
+			},
+			Selector: r.Logging.GetFluentdLabels(ComponentFluentd),
+			Type:     serviceType,
+		},
+	}
+
+	beforeUpdateHook := reconciler.DesiredStateHook(func(current runtime.Object) error {
+		s, ok := current.(*corev1.Service)
+		if !ok {
+			return errors.Errorf("failed to cast service object %+v", current)
+		}
+		desired.Spec.ClusterIP = s.Spec.ClusterIP
+		if serviceType != corev1.ServiceTypeClusterIP && len(s.Spec.Ports) == len(desired.Spec.Ports) {
+			// Keep the node port allocated by the API server
+			desired.Spec.Ports[0].NodePort = s.Spec.Ports[0].NodePort
+		}
+		return nil
+	})
+
+	return desired, beforeUpdateHook, nil
+}
+
+func (r *Reconciler) httpInputIngress() (runtime.Object, reconciler.DesiredState, error) {
+	spec := r.Logging.Spec.FluentdSpec.HTTPInput
+	if spec == nil || !spec.Enabled || spec.Ingress == nil {
+		return &networkingv1.Ingress{
+			ObjectMeta: r.FluentdObjectMeta(httpInputServiceName, ComponentFluentd),
+		}, reconciler.StateAbsent, nil
+	}
+	ingressSpec := spec.Ingress
+	path := ingressSpec.Path
+	if path == "" {
+		path = "/"
+	}
+	pathType := networkingv1.PathTypePrefix
+
+	meta := r.FluentdObjectMeta(httpInputServiceName, ComponentFluentd)
+	meta.Annotations = ingressSpec.Annotations
+	desired := &networkingv1.Ingress{
+		ObjectMeta: meta,
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: ingressSpec.Host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     path,
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: meta.Name,
+											Port: networkingv1.ServiceBackendPort{Number: spec.Port},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if ingressSpec.ClassName != "" {
+		desired.Spec.IngressClassName = &ingressSpec.ClassName
+	}
+	if ingressSpec.TLSSecretName != "" {
+		desired.Spec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      []string{ingressSpec.Host},
+				SecretName: ingressSpec.TLSSecretName,
+			},
+		}
+	}
+	return desired, reconciler.StatePresent, nil
+}
@@ -0,0 +1,123 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func (r *Reconciler) networkPolicy() (runtime.Object, reconciler.DesiredState, error) {
+	spec := r.Logging.Spec.NetworkPolicy
+	if spec == nil || !spec.Enabled {
+		return &networkingv1.NetworkPolicy{
+			ObjectMeta: r.FluentdObjectMeta(ServiceName, ComponentFluentd),
+		}, reconciler.StateAbsent, nil
+	}
+
+	tcp, udp := corev1.ProtocolTCP, corev1.ProtocolUDP
+	forwardPort := intstr.FromInt(24240)
+
+	// Logs are accepted only from the agents of this logging and the configured sources
+	sources := []networkingv1.NetworkPolicyPeer{
+		{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app.kubernetes.io/name":       "fluentbit",
+					"app.kubernetes.io/managed-by": r.Logging.Name,
+				},
+			},
+		},
+	}
+	if r.Logging.Spec.AuditLog != nil {
+		sources = append(sources, networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app.kubernetes.io/name":       "audit-webhook",
+					"app.kubernetes.io/managed-by": r.Logging.Name,
+				},
+			},
+		})
+	}
+	for _, cidr := range spec.IngressCIDRs {
+		sources = append(sources, networkingv1.NetworkPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+		})
+	}
+	ingress := []networkingv1.NetworkPolicyIngressRule{
+		{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &tcp, Port: &forwardPort},
+				{Protocol: &udp, Port: &forwardPort},
+			},
+			From: sources,
+		},
+	}
+	if httpInput := r.Logging.Spec.FluentdSpec.HTTPInput; httpInput != nil && httpInput.Enabled {
+		// The records are authenticated by their token, the clients are outside of the cluster
+		httpInputPort := intstr.FromInt(int(httpInput.Port))
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &tcp, Port: &httpInputPort},
+			},
+		})
+	}
+	if metricsPorts := r.metricsPorts(); len(metricsPorts) > 0 {
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			Ports: metricsPorts,
+		})
+	}
+
+	desired := &networkingv1.NetworkPolicy{
+		ObjectMeta: r.FluentdObjectMeta(ServiceName, ComponentFluentd),
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: r.Logging.GetFluentdLabels(ComponentFluentd),
+			},
+			Ingress:     ingress,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+
+	if len(spec.Egress) > 0 {
+		dnsPort := intstr.FromInt(53)
+		desired.Spec.Egress = append([]networkingv1.NetworkPolicyEgressRule{
+			{
+				Ports: []networkingv1.NetworkPolicyPort{
+					{Protocol: &udp, Port: &dnsPort},
+					{Protocol: &tcp, Port: &dnsPort},
+				},
+			},
+		}, spec.Egress...)
+		desired.Spec.PolicyTypes = append(desired.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+	}
+
+	return desired, reconciler.StatePresent, nil
+}
+
+// metricsPorts returns the ports scraped by prometheus which are left open for any source
+func (r *Reconciler) metricsPorts() []networkingv1.NetworkPolicyPort {
+	tcp := corev1.ProtocolTCP
+	var ports []networkingv1.NetworkPolicyPort
+	for _, p := range r.metricsPortNumbers() {
+		port := intstr.FromInt(int(p))
+		ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &port})
+	}
+	return ports
+}
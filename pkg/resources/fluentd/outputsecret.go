@@ -16,7 +16,9 @@ package fluentd
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"sort"
 
 	"emperror.dev/errors"
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
@@ -27,16 +29,25 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 )
 
-func (r *Reconciler) markSecrets(secrets *secret.MountSecrets) ([]runtime.Object, reconciler.DesiredState, error) {
+// secretWatchAnnotation returns the annotation key that makes the controller reconcile the logging on secret changes
+func (r *Reconciler) secretWatchAnnotation() string {
 	var loggingRef string
 	if r.Logging.Spec.LoggingRef != "" {
 		loggingRef = r.Logging.Spec.LoggingRef
 	} else {
 		loggingRef = "default"
 	}
-	annotationKey := fmt.Sprintf("logging.banzaicloud.io/%s", loggingRef)
+	return fmt.Sprintf("logging.banzaicloud.io/%s", loggingRef)
+}
+
+func (r *Reconciler) markSecrets(secrets *secret.MountSecrets) ([]runtime.Object, reconciler.DesiredState, error) {
+	annotationKey := r.secretWatchAnnotation()
 	var markedSecrets []runtime.Object
 	for _, secret := range *secrets {
+		if secret.Name == "" {
+			// Mounted from a ConfigMap or ClusterTrustBundle
+			continue
+		}
 		secretItem := &corev1.Secret{}
 		err := r.Client.Get(context.TODO(), types.NamespacedName{
 			Name:      secret.Name,
@@ -70,3 +81,22 @@ func (r *Reconciler) outputSecret(secrets *secret.MountSecrets, mountPath string
 	}
 	return fluentOutputSecret, reconciler.StatePresent, nil
 }
+
+// outputSecretsChecksum returns the checksum of the secrets mounted for the outputs,
+// so that rotated credentials roll the fluentd pods instead of waiting for a restart
+func (r *Reconciler) outputSecretsChecksum() string {
+	if r.secrets == nil || len(*r.secrets) == 0 {
+		return ""
+	}
+	secrets := make(secret.MountSecrets, len(*r.secrets))
+	copy(secrets, *r.secrets)
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].MappedKey < secrets[j].MappedKey
+	})
+	h := sha256.New()
+	for _, s := range secrets {
+		_, _ = h.Write([]byte(s.MappedKey))
+		_, _ = h.Write(s.Value)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -33,7 +33,8 @@ func (r *Reconciler) clusterPodSecurityPolicy() (runtime.Object, reconciler.Desi
 					"emptyDir",
 					"secret",
 					"hostPath",
-					"persistentVolumeClaim"},
+					"persistentVolumeClaim",
+					"ephemeral"},
 				SELinux: policyv1beta1.SELinuxStrategyOptions{
 					Rule: policyv1beta1.SELinuxStrategyRunAsAny,
 				},
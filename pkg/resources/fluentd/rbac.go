@@ -18,15 +18,37 @@ import (
 	"emperror.dev/errors"
 	"github.com/banzaicloud/operator-tools/pkg/merge"
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
 )
 
+func (r *Reconciler) rbacObjectMeta(name string) metav1.ObjectMeta {
+	meta := r.FluentdObjectMeta(name, ComponentFluentd)
+	meta.Labels = util.MergeLabels(meta.Labels, r.Logging.Spec.FluentdSpec.Security.RBACLabels())
+	return meta
+}
+
+func (r *Reconciler) rbacObjectMetaClusterScope(name string) metav1.ObjectMeta {
+	meta := r.FluentdObjectMetaClusterScope(name, ComponentFluentd)
+	meta.Labels = util.MergeLabels(meta.Labels, r.Logging.Spec.FluentdSpec.Security.RBACLabels())
+	return meta
+}
+
+// needsRole returns false in minimal mode as fluentd itself does not access configmaps and secrets through the API
+func (r *Reconciler) needsRole() bool {
+	return *r.Logging.Spec.FluentdSpec.Security.RoleBasedAccessControlCreate && !r.Logging.Spec.FluentdSpec.Security.IsMinimalRBAC()
+}
+
 func (r *Reconciler) role() (runtime.Object, reconciler.DesiredState, error) {
-	if *r.Logging.Spec.FluentdSpec.Security.RoleBasedAccessControlCreate {
+	if r.needsRole() {
 		return &rbacv1.Role{
-			ObjectMeta: r.FluentdObjectMeta(roleName, ComponentFluentd),
+			ObjectMeta: r.rbacObjectMeta(roleName),
 			Rules: []rbacv1.PolicyRule{
 				{
 					APIGroups: []string{""},
@@ -42,7 +64,7 @@ func (r *Reconciler) role() (runtime.Object, reconciler.DesiredState, error) {
 }
 
 func (r *Reconciler) roleBinding() (runtime.Object, reconciler.DesiredState, error) {
-	if *r.Logging.Spec.FluentdSpec.Security.RoleBasedAccessControlCreate {
+	if r.needsRole() {
 		return &rbacv1.RoleBinding{
 			ObjectMeta: r.FluentdObjectMeta(roleBindingName, ComponentFluentd),
 			RoleRef: rbacv1.RoleRef{
@@ -76,7 +98,7 @@ func (r *Reconciler) isEnhanceK8sFilter() bool {
 func (r *Reconciler) clusterRole() (runtime.Object, reconciler.DesiredState, error) {
 	if *r.Logging.Spec.FluentdSpec.Security.RoleBasedAccessControlCreate && r.isEnhanceK8sFilter() {
 		return &rbacv1.ClusterRole{
-			ObjectMeta: r.FluentdObjectMetaClusterScope(clusterRoleName, ComponentFluentd),
+			ObjectMeta: r.rbacObjectMetaClusterScope(clusterRoleName),
 			Rules: []rbacv1.PolicyRule{
 				{
 					APIGroups: []string{""},
@@ -149,6 +171,11 @@ func (r *Reconciler) serviceAccount() (runtime.Object, reconciler.DesiredState,
 
 		return desired, reconciler.StatePresent, nil
 	} else {
+		if r.Logging.Spec.FluentdSpec.Security.CreateRBAC == v1beta1.CreateRBACDisabled {
+			if err := resources.ValidateServiceAccount(r.Client, r.Logging.Spec.ControlNamespace, r.getServiceAccount()); err != nil {
+				return nil, reconciler.StatePresent, err
+			}
+		}
 		desired := &corev1.ServiceAccount{
 			ObjectMeta: r.FluentdObjectMeta(defaultServiceAccountName, ComponentFluentd),
 		}
@@ -186,6 +186,11 @@ func (r *Reconciler) monitorBufferServiceMetrics() (runtime.Object, reconciler.D
 }
 
 func (r *Reconciler) headlessService() (runtime.Object, reconciler.DesiredState, error) {
+	if r.Logging.Spec.FluentdSpec.IsDeployment() {
+		return &corev1.Service{
+			ObjectMeta: r.FluentdObjectMeta(ServiceName+"-headless", ComponentFluentd),
+		}, reconciler.StateAbsent, nil
+	}
 	desired := &corev1.Service{
 		ObjectMeta: r.FluentdObjectMeta(ServiceName+"-headless", ComponentFluentd),
 		Spec: corev1.ServiceSpec{
@@ -210,3 +215,19 @@ func (r *Reconciler) headlessService() (runtime.Object, reconciler.DesiredState,
 	}
 	return desired, reconciler.StatePresent, nil
 }
+
+// metricsPortNumbers returns the ports exposing fluentd and buffer volume metrics
+func (r *Reconciler) metricsPortNumbers() []int32 {
+	var ports []int32
+	if r.Logging.Spec.FluentdSpec.Metrics != nil {
+		ports = append(ports, r.Logging.Spec.FluentdSpec.Metrics.Port)
+	}
+	if r.Logging.Spec.FluentdSpec.BufferVolumeMetrics != nil {
+		port := int32(defaultBufferVolumeMetricsPort)
+		if r.Logging.Spec.FluentdSpec.BufferVolumeMetrics.Port != 0 {
+			port = r.Logging.Spec.FluentdSpec.BufferVolumeMetrics.Port
+		}
+		ports = append(ports, port)
+	}
+	return ports
+}
@@ -15,10 +15,16 @@
 package fluentd
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"strings"
 
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/resources"
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	"github.com/banzaicloud/logging-operator/pkg/resources/templates"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/operator-tools/pkg/merge"
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
 	util "github.com/banzaicloud/operator-tools/pkg/utils"
 	"github.com/spf13/cast"
@@ -29,6 +35,12 @@ import (
 )
 
 func (r *Reconciler) statefulset() (runtime.Object, reconciler.DesiredState, error) {
+	if r.Logging.Spec.FluentdSpec.IsDeployment() {
+		return &appsv1.StatefulSet{
+			ObjectMeta: r.FluentdObjectMeta(StatefulSetName, ComponentFluentd),
+		}, reconciler.StateAbsent, nil
+	}
+
 	spec := r.statefulsetSpec()
 
 	r.Logging.Spec.FluentdSpec.BufferStorageVolume.WithDefaultHostPath(
@@ -52,6 +64,10 @@ func (r *Reconciler) statefulset() (runtime.Object, reconciler.DesiredState, err
 			return nil, reconciler.StatePresent, err
 		}
 	}
+	r.applySpiffe(&spec.Template.Spec, false)
+	if err := r.applyBufferEncryption(&spec.Template.Spec, false); err != nil {
+		return nil, reconciler.StatePresent, err
+	}
 
 	desired := &appsv1.StatefulSet{
 		ObjectMeta: r.FluentdObjectMeta(StatefulSetName, ComponentFluentd),
@@ -60,6 +76,10 @@ func (r *Reconciler) statefulset() (runtime.Object, reconciler.DesiredState, err
 
 	desired.Annotations = util.MergeLabels(desired.Annotations, r.Logging.Spec.FluentdSpec.StatefulSetAnnotations)
 
+	if err := merge.Merge(desired, r.Logging.Spec.FluentdSpec.StatefulSetOverrides); err != nil {
+		return desired, reconciler.StatePresent, errors.WrapIf(err, "unable to merge overrides to base object")
+	}
+
 	return desired, reconciler.StatePresent, nil
 }
 
@@ -68,14 +88,21 @@ func (r *Reconciler) statefulsetSpec() *appsv1.StatefulSetSpec {
 	if c := r.volumeMountHackContainer(); c != nil {
 		initContainers = append(initContainers, *c)
 	}
+	if c := r.bufferRepairContainer(); c != nil {
+		initContainers = append(initContainers, *c)
+	}
 
 	containers := []corev1.Container{
-		fluentContainer(r.Logging.Spec.FluentdSpec),
-		*newConfigMapReloader(r.Logging.Spec.FluentdSpec),
+		r.withOpenSSLConfig(fluentContainer(r.Logging.Spec.FluentdSpec)),
+	}
+	if r.Logging.Spec.FluentdSpec.ConfigReloadStrategy != v1beta1.ConfigReloadStrategyRestart {
+		containers = append(containers, *newConfigMapReloader(r.Logging.Spec.FluentdSpec))
 	}
 	if c := r.bufferMetricsSidecarContainer(); c != nil {
 		containers = append(containers, *c)
 	}
+	initContainers = append(initContainers, r.Logging.Spec.FluentdSpec.ExtraInitContainers...)
+	containers = append(containers, r.Logging.Spec.FluentdSpec.ExtraContainers...)
 
 	sts := &appsv1.StatefulSetSpec{
 		PodManagementPolicy: appsv1.PodManagementPolicyType(r.Logging.Spec.FluentdSpec.Scaling.PodManagementPolicy),
@@ -97,11 +124,15 @@ func (r *Reconciler) statefulsetSpec() *appsv1.StatefulSetSpec {
 				PriorityClassName:         r.Logging.Spec.FluentdSpec.PodPriorityClassName,
 				DNSPolicy:                 r.Logging.Spec.FluentdSpec.DNSPolicy,
 				DNSConfig:                 r.Logging.Spec.FluentdSpec.DNSConfig,
+				HostNetwork:               r.Logging.Spec.FluentdSpec.HostNetwork,
+				// Unset unless configured or required by flushOnTerminate
+				TerminationGracePeriodSeconds: r.Logging.Spec.FluentdSpec.TerminationGracePeriodSeconds,
 				SecurityContext: &corev1.PodSecurityContext{
-					RunAsNonRoot: r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsNonRoot,
-					FSGroup:      r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.FSGroup,
-					RunAsUser:    r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsUser,
-					RunAsGroup:   r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsGroup},
+					RunAsNonRoot:   r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsNonRoot,
+					FSGroup:        r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.FSGroup,
+					RunAsUser:      r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsUser,
+					RunAsGroup:     r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.RunAsGroup,
+					SeccompProfile: r.Logging.Spec.FluentdSpec.Security.PodSecurityContext.SeccompProfile},
 			},
 		},
 		ServiceName: r.Logging.QualifiedName(ServiceName + "-headless"),
@@ -134,10 +165,13 @@ func fluentContainer(spec *v1beta1.FluentdSpec) corev1.Container {
 			Privileged:               spec.Security.SecurityContext.Privileged,
 			RunAsNonRoot:             spec.Security.SecurityContext.RunAsNonRoot,
 			SELinuxOptions:           spec.Security.SecurityContext.SELinuxOptions,
+			Capabilities:             spec.Security.SecurityContext.Capabilities,
+			SeccompProfile:           spec.Security.SecurityContext.SeccompProfile,
 		},
 		Env:            envVars,
 		LivenessProbe:  spec.LivenessProbe,
 		ReadinessProbe: generateReadinessCheck(spec),
+		Lifecycle:      generateLifecycle(spec),
 	}
 
 	if spec.FluentOutLogrotate != nil && spec.FluentOutLogrotate.Enabled {
@@ -152,17 +186,64 @@ func fluentContainer(spec *v1beta1.FluentdSpec) corev1.Container {
 	return container
 }
 
+// generateLifecycle returns the configured hooks, completed with the flush of the buffers if requested
+func generateLifecycle(spec *v1beta1.FluentdSpec) *corev1.Lifecycle {
+	lifecycle := spec.Lifecycle.DeepCopy()
+	if !spec.FlushOnTerminate || (lifecycle != nil && lifecycle.PreStop != nil) {
+		return lifecycle
+	}
+	if lifecycle == nil {
+		lifecycle = &corev1.Lifecycle{}
+	}
+	// Fluentd needs some of the grace period to stop after the hook
+	wait := int64(0)
+	if spec.TerminationGracePeriodSeconds != nil && *spec.TerminationGracePeriodSeconds > 10 {
+		wait = *spec.TerminationGracePeriodSeconds - 10
+	}
+	lifecycle.PreStop = &corev1.LifecycleHandler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"/bin/sh", "-c", strings.Join([]string{
+				`ruby -rnet/http -e 'Net::HTTP.get(URI("http://127.0.0.1:24444/api/plugins.flushBuffers"))' || true`,
+				fmt.Sprintf("DEADLINE=$(( $(date +%%s) + %d ))", wait),
+				`while [ "$(date +%s)" -lt "$DEADLINE" ] && [ -n "$(find $BUFFER_PATH -name '*.buffer' -not -path '*/quarantine/*' | head -n 1)" ]; do sleep 1; done`,
+			}, "\n")},
+		},
+	}
+	return lifecycle
+}
+
 func (r *Reconciler) generatePodMeta() metav1.ObjectMeta {
 	meta := metav1.ObjectMeta{
 		Labels: r.Logging.GetFluentdLabels(ComponentFluentd),
 	}
 	if r.Logging.Spec.FluentdSpec.Annotations != nil {
-		meta.Annotations = r.Logging.Spec.FluentdSpec.Annotations
+		meta.Annotations = util.MergeLabels(r.Logging.Spec.FluentdSpec.Annotations)
+	}
+	if r.Logging.Spec.FluentdSpec.ConfigReloadStrategy == v1beta1.ConfigReloadStrategyRestart && r.config != nil {
+		h := sha256.New()
+		_, _ = h.Write([]byte(*r.config))
+		meta = templates.Annotate(meta, "checksum/config", fmt.Sprintf("%x", h.Sum(nil)))
+	}
+	if r.Logging.Spec.ServiceMesh.IsEnabled() {
+		meta.Annotations = util.MergeLabels(meta.Annotations, r.Logging.Spec.ServiceMesh.PodAnnotations(r.metricsPortNumbers()...))
+	}
+	if r.tlsChecksum != "" {
+		meta = templates.Annotate(meta, "checksum/tls", r.tlsChecksum)
+	}
+	if config := model.TLSProfileOpenSSLConfig(r.Logging.Spec.TLSProfile); config != "" {
+		meta = templates.Annotate(meta, "checksum/openssl", fmt.Sprintf("%x", sha256.Sum256([]byte(config))))
+	}
+	if checksum := r.outputSecretsChecksum(); checksum != "" {
+		meta = templates.Annotate(meta, "checksum/output-secrets", checksum)
 	}
 	return meta
 }
 
 func newConfigMapReloader(spec *v1beta1.FluentdSpec) *corev1.Container {
+	rpcMethod := "config.reload"
+	if spec.ConfigReloadStrategy == v1beta1.ConfigReloadStrategyGracefulReload {
+		rpcMethod = "config.gracefulReload"
+	}
 	return &corev1.Container{
 		Name:            "config-reloader",
 		ImagePullPolicy: corev1.PullPolicy(spec.ConfigReloaderImage.PullPolicy),
@@ -171,7 +252,7 @@ func newConfigMapReloader(spec *v1beta1.FluentdSpec) *corev1.Container {
 		Args: []string{
 			"-volume-dir=/fluentd/etc",
 			"-volume-dir=/fluentd/app-config/",
-			"-webhook-url=http://127.0.0.1:24444/api/config.reload",
+			"-webhook-url=http://127.0.0.1:24444/api/" + rpcMethod,
 		},
 		VolumeMounts: []corev1.VolumeMount{
 			{
@@ -215,6 +296,13 @@ func generatePorts(spec *v1beta1.FluentdSpec) []corev1.ContainerPort {
 			Protocol:      "TCP",
 		})
 	}
+	if spec.HTTPInput != nil && spec.HTTPInput.Enabled {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          "http-input",
+			ContainerPort: spec.HTTPInput.Port,
+			Protocol:      "TCP",
+		})
+	}
 	return ports
 }
 
@@ -242,24 +330,46 @@ func generateVolumeMounts(spec *v1beta1.FluentdSpec) []corev1.VolumeMount {
 	return res
 }
 
-func (r *Reconciler) generateVolume() (v []corev1.Volume) {
-	v = []corev1.Volume{
-		{
-			Name: "config",
+// appConfigVolume projects all the chunks of the generated configuration into the same directory
+func (r *Reconciler) appConfigVolume() corev1.Volume {
+	chunks := r.appConfigChunks()
+	if len(chunks) == 1 {
+		return corev1.Volume{
+			Name: "app-config",
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
-					SecretName: r.Logging.QualifiedName(SecretConfigName),
+					SecretName: r.Logging.QualifiedName(AppSecretConfigName),
 				},
 			},
+		}
+	}
+	sources := make([]corev1.VolumeProjection, 0, len(chunks))
+	for i := range chunks {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: r.Logging.QualifiedName(appConfigSecretName(i))},
+			},
+		})
+	}
+	return corev1.Volume{
+		Name: "app-config",
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources},
 		},
+	}
+}
+
+func (r *Reconciler) generateVolume() (v []corev1.Volume) {
+	v = []corev1.Volume{
 		{
-			Name: "app-config",
+			Name: "config",
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
-					SecretName: r.Logging.QualifiedName(AppSecretConfigName),
+					SecretName: r.Logging.QualifiedName(SecretConfigName),
 				},
 			},
 		},
+		r.appConfigVolume(),
 		{
 			Name: "output-secret",
 			VolumeSource: corev1.VolumeSource{
@@ -369,3 +479,12 @@ func generateReadinessCheck(spec *v1beta1.FluentdSpec) *corev1.Probe {
 	}
 	return nil
 }
+
+func (r *Reconciler) verticalPodAutoscaler() (runtime.Object, reconciler.DesiredState, error) {
+	targetKind := "StatefulSet"
+	if r.Logging.Spec.FluentdSpec.IsDeployment() {
+		targetKind = "Deployment"
+	}
+	o, state := resources.VerticalPodAutoscaler(r.FluentdObjectMeta(StatefulSetName, ComponentFluentd), targetKind, containerName, r.Logging.Spec.FluentdSpec.VPA)
+	return o, state, nil
+}
@@ -0,0 +1,350 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/certs"
+	"github.com/banzaicloud/logging-operator/pkg/resources/spiffe"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+const (
+	TLSCASecretName = "fluentd-tls-ca"
+	// TLSPeerCASecretName is the CA issuing the client certificates of the forward peers, it is replaced when a peer
+	// is removed to revoke the certificate of that peer
+	TLSPeerCASecretName = "fluentd-tls-peer-ca"
+	// TLSPeerSecretPrefix prefixes the names of the client certificate secrets issued for the forward peers
+	TLSPeerSecretPrefix = "fluentd-peer-"
+
+	// tlsPeerLabel marks the client certificate secrets of the forward peers with the name of the peer
+	tlsPeerLabel = "logging.banzaicloud.io/forward-peer"
+	// tlsPeersAnnotation lists the forward peers the peer CA issued certificates for
+	tlsPeersAnnotation = "logging.banzaicloud.io/forward-peers"
+
+	tlsCACertKey = "ca.crt"
+	tlsCAKeyKey  = "ca.key"
+	tlsCertKey   = "tls.crt"
+	tlsKeyKey    = "tls.key"
+)
+
+// tlsSecrets maintains the CA and the certificates used between the agents and fluentd when tls.autoGenerate is set.
+// Existing certificates are kept until they get close to their expiry or their CA is replaced.
+func (r *Reconciler) tlsSecrets() ([]runtime.Object, reconciler.DesiredState, error) {
+	if !r.Logging.Spec.FluentdSpec.TLS.AutoGenerate {
+		return nil, reconciler.StatePresent, nil
+	}
+	now := time.Now()
+
+	caSecret, err := r.tlsSecret(r.Logging.QualifiedName(TLSCASecretName))
+	if err != nil {
+		return nil, reconciler.StatePresent, err
+	}
+	ca, err := certs.ParseKeyPair(caSecret.Data[tlsCACertKey], caSecret.Data[tlsCAKeyKey])
+	if err != nil || certs.NeedsRenewal(ca.CertPEM, nil, now, certs.RenewBefore) {
+		ca, err = certs.NewCA(r.Logging.QualifiedName("fluentd-ca"), certs.CAValidity)
+		if err != nil {
+			return nil, reconciler.StatePresent, errors.WrapIf(err, "failed to generate CA")
+		}
+	}
+	caSecret.Data = map[string][]byte{
+		tlsCACertKey: ca.CertPEM,
+		tlsCAKeyKey:  ca.KeyPEM,
+	}
+
+	// The forward peers are issued their certificates by a CA of their own, fluentd trusts both CAs
+	var peerObjects []runtime.Object
+	serverCABundle := ca.CertPEM
+	if peers := r.Logging.Spec.FluentdSpec.TLS.ForwardPeers; len(peers) > 0 {
+		peerCASecret, peerCA, err := r.tlsPeerCA(peers, now)
+		if err != nil {
+			return nil, reconciler.StatePresent, err
+		}
+		peerObjects = append(peerObjects, peerCASecret)
+		serverCABundle = append(append([]byte{}, ca.CertPEM...), peerCA.CertPEM...)
+		for _, peer := range peers {
+			peerSecret, err := r.tlsCertificateSecret(peerCA, ca.CertPEM, r.Logging.QualifiedName(TLSPeerSecretPrefix+peer),
+				peer, nil, x509.ExtKeyUsageClientAuth, now)
+			if err != nil {
+				return nil, reconciler.StatePresent, err
+			}
+			peerSecret.Labels[tlsPeerLabel] = peer
+			peerObjects = append(peerObjects, peerSecret)
+		}
+	}
+
+	serverSecret, err := r.tlsCertificateSecret(ca, serverCABundle, r.Logging.Spec.FluentdSpec.TLS.SecretName,
+		r.Logging.QualifiedName(ServiceName), r.tlsServerDNSNames(), x509.ExtKeyUsageServerAuth, now)
+	if err != nil {
+		return nil, reconciler.StatePresent, err
+	}
+	// Fluentd loads the trusted CAs at startup as well, roll it when the peer CA is replaced
+	r.tlsChecksum = fmt.Sprintf("%x", sha256.Sum256(append(append([]byte{}, serverSecret.Data[tlsCertKey]...), serverSecret.Data[tlsCACertKey]...)))
+
+	objects := []runtime.Object{caSecret, serverSecret}
+	if clientSecretName := r.tlsClientSecretName(); clientSecretName != "" {
+		clientSecret, err := r.tlsCertificateSecret(ca, ca.CertPEM, clientSecretName,
+			r.Logging.QualifiedName("fluentbit"), nil, x509.ExtKeyUsageClientAuth, now)
+		if err != nil {
+			return nil, reconciler.StatePresent, err
+		}
+		objects = append(objects, clientSecret)
+	}
+	return append(objects, peerObjects...), reconciler.StatePresent, nil
+}
+
+// tlsPeerCA returns the CA of the forward peers. It is replaced when a peer it issued a certificate for is removed,
+// which invalidates the certificate of the removed peer, the remaining peers are issued new certificates.
+func (r *Reconciler) tlsPeerCA(peers []string, now time.Time) (*corev1.Secret, *certs.KeyPair, error) {
+	current := make(map[string]bool, len(peers))
+	for _, peer := range peers {
+		if errs := validation.IsDNS1123Label(peer); len(errs) > 0 {
+			return nil, nil, errors.Errorf("invalid forward peer %q: %s", peer, strings.Join(errs, ", "))
+		}
+		current[peer] = true
+	}
+	secret, err := r.tlsSecret(r.Logging.QualifiedName(TLSPeerCASecretName))
+	if err != nil {
+		return nil, nil, err
+	}
+	removed := false
+	for _, peer := range strings.Split(secret.Annotations[tlsPeersAnnotation], ",") {
+		removed = removed || peer != "" && !current[peer]
+	}
+	ca, err := certs.ParseKeyPair(secret.Data[tlsCACertKey], secret.Data[tlsCAKeyKey])
+	if err != nil || removed || certs.NeedsRenewal(ca.CertPEM, nil, now, certs.RenewBefore) {
+		ca, err = certs.NewCA(r.Logging.QualifiedName("fluentd-peer-ca"), certs.CAValidity)
+		if err != nil {
+			return nil, nil, errors.WrapIf(err, "failed to generate forward peer CA")
+		}
+	}
+	secret.Data = map[string][]byte{
+		tlsCACertKey: ca.CertPEM,
+		tlsCAKeyKey:  ca.KeyPEM,
+	}
+	sorted := append([]string{}, peers...)
+	sort.Strings(sorted)
+	secret.Annotations = map[string]string{tlsPeersAnnotation: strings.Join(sorted, ",")}
+	return secret, ca, nil
+}
+
+// staleTLSPeerSecrets returns the client certificate secrets of the removed forward peers, and the peer CA once
+// there are no peers left
+func (r *Reconciler) staleTLSPeerSecrets() ([]runtime.Object, reconciler.DesiredState, error) {
+	current := make(map[string]bool)
+	if r.Logging.Spec.FluentdSpec.TLS.AutoGenerate {
+		for _, peer := range r.Logging.Spec.FluentdSpec.TLS.ForwardPeers {
+			current[peer] = true
+		}
+	}
+	existing := &corev1.SecretList{}
+	if err := r.Client.List(context.TODO(), existing, client.InNamespace(r.Logging.Spec.ControlNamespace),
+		client.MatchingLabels(r.Logging.GetFluentdLabels(ComponentFluentd))); err != nil {
+		return nil, reconciler.StateAbsent, errors.WrapIf(err, "failed to list forward peer secrets")
+	}
+	var objects []runtime.Object
+	for i := range existing.Items {
+		if peer, ok := existing.Items[i].Labels[tlsPeerLabel]; ok && !current[peer] {
+			objects = append(objects, &existing.Items[i])
+		}
+	}
+	if len(current) == 0 {
+		objects = append(objects, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      r.Logging.QualifiedName(TLSPeerCASecretName),
+			Namespace: r.Logging.Spec.ControlNamespace,
+		}})
+	}
+	return objects, reconciler.StateAbsent, nil
+}
+
+// tlsCertificateSecret issues a certificate signed by the given CA unless the existing one is still valid, caBundle
+// is stored as the CA the certificate of the other side is verified against
+func (r *Reconciler) tlsCertificateSecret(ca *certs.KeyPair, caBundle []byte, name, commonName string, dnsNames []string, usage x509.ExtKeyUsage, now time.Time) (*corev1.Secret, error) {
+	secret, err := r.tlsSecret(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(secret.Data[tlsKeyKey]) > 0 && !certs.NeedsRenewal(secret.Data[tlsCertKey], ca, now, certs.RenewBefore) {
+		secret.Data[tlsCACertKey] = caBundle
+		return secret, nil
+	}
+	cert, err := ca.Sign(commonName, dnsNames, usage, certs.CertificateValidity)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to issue certificate", "secret", name)
+	}
+	secret.Data = map[string][]byte{
+		tlsCACertKey: caBundle,
+		tlsCertKey:   cert.CertPEM,
+		tlsKeyKey:    cert.KeyPEM,
+	}
+	return secret, nil
+}
+
+// tlsSecret returns the desired secret for the given name, carrying over the data and annotations of the existing one
+func (r *Reconciler) tlsSecret(name string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: r.FluentdObjectMeta("", ComponentFluentd),
+	}
+	secret.ObjectMeta.Name = name
+
+	existing := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: secret.Namespace}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.WrapIfWithDetails(err, "failed to load secret", "secret", name, "namespace", secret.Namespace)
+	}
+	secret.Data = existing.Data
+	secret.Annotations = existing.Annotations
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	return secret, nil
+}
+
+// tlsCertificates requests the server and client certificates from cert-manager when tls.certManager is set
+func (r *Reconciler) tlsCertificates() ([]runtime.Object, reconciler.DesiredState, error) {
+	certManager := r.Logging.Spec.FluentdSpec.TLS.CertManager
+	if certManager == nil {
+		return nil, reconciler.StatePresent, nil
+	}
+	serverSecretName := r.Logging.Spec.FluentdSpec.TLS.SecretName
+	objects := []runtime.Object{
+		r.certificate(certManager, serverSecretName, r.Logging.QualifiedName(ServiceName), r.tlsServerDNSNames(), "server auth"),
+	}
+	if clientSecretName := r.tlsClientSecretName(); clientSecretName != "" {
+		objects = append(objects, r.certificate(certManager, clientSecretName, r.Logging.QualifiedName("fluentbit"), nil, "client auth"))
+	}
+
+	// The issued secret appears asynchronously, the pods are rolled once it is renewed
+	serverSecret, err := r.tlsSecret(serverSecretName)
+	if err != nil {
+		return nil, reconciler.StatePresent, err
+	}
+	if crt, ok := serverSecret.Data[tlsCertKey]; ok {
+		r.tlsChecksum = fmt.Sprintf("%x", sha256.Sum256(crt))
+	}
+	return objects, reconciler.StatePresent, nil
+}
+
+// spiffeHelperConfig is the configuration of the spiffe-helper containers of fluentd and of the agents
+func (r *Reconciler) spiffeHelperConfig() ([]runtime.Object, reconciler.DesiredState, error) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: r.FluentdObjectMeta(spiffe.ConfigMapName, ComponentFluentd),
+	}
+	s := r.Logging.Spec.FluentdSpec.TLS.Spiffe
+	if s == nil {
+		return []runtime.Object{configMap}, reconciler.StateAbsent, nil
+	}
+	configMap.Data = map[string]string{
+		spiffe.ConfigKey: spiffe.HelperConfig(s),
+	}
+	return []runtime.Object{configMap}, reconciler.StatePresent, nil
+}
+
+// applySpiffe sources the TLS directory of fluentd from SPIRE, the configuration is reloaded when the SVID is renewed
+func (r *Reconciler) applySpiffe(podSpec *corev1.PodSpec, drainer bool) {
+	s := r.Logging.Spec.FluentdSpec.TLS.Spiffe
+	if s == nil {
+		return
+	}
+	spiffe.Apply(podSpec, s, "fluentd-tls", r.Logging.QualifiedName(spiffe.ConfigMapName), !drainer)
+	for i := range podSpec.Containers {
+		if c := &podSpec.Containers[i]; c.Name == "config-reloader" {
+			c.Args = append(c.Args, "-volume-dir=/fluentd/tls/")
+			c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: "fluentd-tls", MountPath: "/fluentd/tls/"})
+		}
+	}
+}
+
+func (r *Reconciler) certificate(certManager *v1beta1.CertManagerTLS, secretName, commonName string, dnsNames []string, usage string) runtime.Object {
+	meta := r.FluentdObjectMeta("", ComponentFluentd)
+	cert := &unstructured.Unstructured{}
+	cert.SetAPIVersion("cert-manager.io/v1")
+	cert.SetKind("Certificate")
+	cert.SetName(secretName)
+	cert.SetNamespace(meta.Namespace)
+	cert.SetLabels(meta.Labels)
+	cert.SetOwnerReferences(meta.OwnerReferences)
+
+	issuerRef := map[string]interface{}{
+		"name": certManager.IssuerRef.Name,
+		"kind": certManager.IssuerRef.Kind,
+	}
+	if certManager.IssuerRef.Group != "" {
+		issuerRef["group"] = certManager.IssuerRef.Group
+	}
+	spec := map[string]interface{}{
+		"secretName": secretName,
+		"commonName": commonName,
+		"usages":     []interface{}{"digital signature", "key encipherment", usage},
+		"issuerRef":  issuerRef,
+		"secretTemplate": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				r.secretWatchAnnotation(): "watched",
+			},
+		},
+	}
+	if len(dnsNames) > 0 {
+		names := make([]interface{}, 0, len(dnsNames))
+		for _, n := range dnsNames {
+			names = append(names, n)
+		}
+		spec["dnsNames"] = names
+	}
+	cert.Object["spec"] = spec
+	return cert
+}
+
+func (r *Reconciler) tlsServerDNSNames() []string {
+	headless := r.Logging.QualifiedName(ServiceName + "-headless")
+	service := r.Logging.QualifiedName(ServiceName)
+	namespace := r.Logging.Spec.ControlNamespace
+	return []string{
+		service,
+		fmt.Sprintf("%s.%s", service, namespace),
+		fmt.Sprintf("%s.%s.svc", service, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace),
+		fmt.Sprintf("*.%s.%s.svc", headless, namespace),
+		fmt.Sprintf("*.%s.%s.svc.cluster.local", headless, namespace),
+	}
+}
+
+// tlsClientSecretName returns the name of the fluentbit client certificate secret if it has to be provisioned
+func (r *Reconciler) tlsClientSecretName() string {
+	if r.Logging.Spec.FluentbitSpec == nil || r.Logging.Spec.FluentbitSpec.TLS == nil {
+		return ""
+	}
+	if name := r.Logging.Spec.FluentbitSpec.TLS.SecretName; name != r.Logging.Spec.FluentdSpec.TLS.SecretName {
+		return name
+	}
+	return ""
+}
@@ -0,0 +1,194 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/certs"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func newTLSTestReconciler(peers ...string) *Reconciler {
+	logging := &v1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub"},
+		Spec: v1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			FluentdSpec: &v1beta1.FluentdSpec{
+				TLS: v1beta1.FluentdTLS{
+					Enabled:      true,
+					SecretName:   "hub-fluentd-tls",
+					AutoGenerate: true,
+					ForwardPeers: peers,
+				},
+			},
+		},
+	}
+	return &Reconciler{
+		Logging: logging,
+		GenericResourceReconciler: reconciler.NewGenericReconciler(
+			fake.NewClientBuilder().Build(), log.Log, reconciler.ReconcilerOpts{}),
+	}
+}
+
+// applyTLS stores the desired TLS secrets and deletes the stale ones like the reconciler does
+func applyTLS(t *testing.T, r *Reconciler) {
+	objects, _, err := r.tlsSecrets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range objects {
+		desired := o.(*corev1.Secret)
+		existing := &corev1.Secret{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
+		if err == nil {
+			desired.ResourceVersion = existing.ResourceVersion
+			err = r.Client.Update(context.TODO(), desired)
+		} else {
+			err = r.Client.Create(context.TODO(), desired)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	stale, _, err := r.staleTLSPeerSecrets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range stale {
+		if err := r.Client.Delete(context.TODO(), o.(client.Object)); client.IgnoreNotFound(err) != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func getSecret(t *testing.T, r *Reconciler, name string) *corev1.Secret {
+	s := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "logging"}, s); err != nil {
+		t.Fatalf("failed to get secret %s: %s", name, err)
+	}
+	return s
+}
+
+func peerSecretNames(t *testing.T, r *Reconciler) []string {
+	list := &corev1.SecretList{}
+	if err := r.Client.List(context.TODO(), list); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range list.Items {
+		if _, ok := s.Labels[tlsPeerLabel]; ok {
+			names = append(names, s.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseCA(t *testing.T, s *corev1.Secret) *certs.KeyPair {
+	ca, err := certs.ParseKeyPair(s.Data[tlsCACertKey], s.Data[tlsCAKeyKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca
+}
+
+func TestTLSForwardPeers(t *testing.T) {
+	r := newTLSTestReconciler("edge-1", "edge-2")
+	applyTLS(t, r)
+
+	ca := parseCA(t, getSecret(t, r, "hub-fluentd-tls-ca"))
+	peerCASecret := getSecret(t, r, "hub-fluentd-tls-peer-ca")
+	peerCA := parseCA(t, peerCASecret)
+	if got := peerCASecret.Annotations[tlsPeersAnnotation]; got != "edge-1,edge-2" {
+		t.Errorf("peers of the CA = %q", got)
+	}
+	if want := []string{"hub-fluentd-peer-edge-1", "hub-fluentd-peer-edge-2"}; !reflect.DeepEqual(peerSecretNames(t, r), want) {
+		t.Errorf("peer secrets = %v, want %v", peerSecretNames(t, r), want)
+	}
+	edge2 := getSecret(t, r, "hub-fluentd-peer-edge-2")
+	if certs.NeedsRenewal(edge2.Data[tlsCertKey], peerCA, time.Now(), 0) {
+		t.Error("peer certificate is not issued by the peer CA")
+	}
+	if !bytes.Equal(edge2.Data[tlsCACertKey], ca.CertPEM) {
+		t.Error("peers do not verify the server against the CA of fluentd")
+	}
+	server := getSecret(t, r, "hub-fluentd-tls")
+	if want := append(append([]byte{}, ca.CertPEM...), peerCA.CertPEM...); !bytes.Equal(server.Data[tlsCACertKey], want) {
+		t.Error("fluentd does not trust both the agent and the peer CA")
+	}
+	checksum := r.tlsChecksum
+
+	// Nothing changes while the peers stay the same
+	applyTLS(t, r)
+	if !bytes.Equal(getSecret(t, r, "hub-fluentd-peer-edge-2").Data[tlsCertKey], edge2.Data[tlsCertKey]) || r.tlsChecksum != checksum {
+		t.Error("certificates are reissued without a change")
+	}
+
+	// Removing a peer deletes its secret and replaces the peer CA
+	r.Logging.Spec.FluentdSpec.TLS.ForwardPeers = []string{"edge-2"}
+	applyTLS(t, r)
+	if want := []string{"hub-fluentd-peer-edge-2"}; !reflect.DeepEqual(peerSecretNames(t, r), want) {
+		t.Errorf("peer secrets = %v, want %v", peerSecretNames(t, r), want)
+	}
+	newPeerCA := parseCA(t, getSecret(t, r, "hub-fluentd-tls-peer-ca"))
+	if bytes.Equal(newPeerCA.CertPEM, peerCA.CertPEM) {
+		t.Fatal("peer CA is not replaced after a peer was removed")
+	}
+	if !certs.NeedsRenewal(edge2.Data[tlsCertKey], newPeerCA, time.Now(), 0) {
+		t.Error("certificates of the old peer CA are still trusted")
+	}
+	newEdge2 := getSecret(t, r, "hub-fluentd-peer-edge-2")
+	if certs.NeedsRenewal(newEdge2.Data[tlsCertKey], newPeerCA, time.Now(), 0) {
+		t.Error("remaining peer is not issued a new certificate")
+	}
+	if r.tlsChecksum == checksum {
+		t.Error("fluentd is not rolled after the peer CA was replaced")
+	}
+	if parseCA(t, getSecret(t, r, "hub-fluentd-tls-ca")).Cert.SerialNumber.Cmp(ca.Cert.SerialNumber) != 0 {
+		t.Error("the CA of the agents is replaced")
+	}
+
+	// Without peers the peer CA is removed as well
+	r.Logging.Spec.FluentdSpec.TLS.ForwardPeers = nil
+	applyTLS(t, r)
+	if names := peerSecretNames(t, r); len(names) != 0 {
+		t.Errorf("peer secrets left: %v", names)
+	}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: "hub-fluentd-tls-peer-ca", Namespace: "logging"}, &corev1.Secret{})
+	if err == nil {
+		t.Error("peer CA is left after the last peer was removed")
+	}
+}
+
+func TestTLSInvalidForwardPeer(t *testing.T) {
+	r := newTLSTestReconciler("Edge_1")
+	if _, _, err := r.tlsSecrets(); err == nil {
+		t.Error("invalid peer name is accepted")
+	}
+}
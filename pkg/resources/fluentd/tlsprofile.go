@@ -0,0 +1,37 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fluentd
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+)
+
+// openSSLConfigKey of the config secret holds the OpenSSL configuration of the TLS profile
+const openSSLConfigKey = "openssl.cnf"
+
+// withOpenSSLConfig points the OpenSSL library of the fluentd container to the configuration of the TLS profile.
+// It is only read when fluentd starts, the pods are rolled by the checksum annotation when it changes.
+func (r *Reconciler) withOpenSSLConfig(container corev1.Container) corev1.Container {
+	if model.TLSProfileOpenSSLConfig(r.Logging.Spec.TLSProfile) == "" {
+		return container
+	}
+	container.Env = append(append([]corev1.EnvVar{}, container.Env...), corev1.EnvVar{
+		Name:  "OPENSSL_CONF",
+		Value: "/fluentd/etc/" + openSSLConfigKey,
+	})
+	return container
+}
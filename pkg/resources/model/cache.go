@@ -0,0 +1,318 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"reflect"
+	"sort"
+	"sync"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// FlowCache keeps the flows built for Flow and ClusterFlow resources between reconciles, so that only the
+// changed ones are rebuilt. An entry is reused as long as the generation of the logging, of the flow and of
+// the outputs it references are the same and the secrets referenced by them resolve to the same values.
+type FlowCache struct {
+	mu      sync.Mutex
+	entries map[k8stypes.UID]flowCacheEntry
+	hits    int
+	misses  int
+}
+
+type flowCacheEntry struct {
+	key  string
+	flow *types.Flow
+}
+
+func NewFlowCache() *FlowCache {
+	return &FlowCache{
+		entries: make(map[k8stypes.UID]flowCacheEntry),
+	}
+}
+
+// Stats returns the number of cache hits and misses since the cache was created
+func (c *FlowCache) Stats() (hits int, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+func (c *FlowCache) get(uid k8stypes.UID, key string) *types.Flow {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[uid]
+	if !ok || entry.key != key {
+		c.misses++
+		return nil
+	}
+	c.hits++
+	// The caller may replace the matches and filters of the flow, the cached one is left intact
+	flow := *entry.flow
+	return &flow
+}
+
+func (c *FlowCache) put(uid k8stypes.UID, key string, flow *types.Flow) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stored := *flow
+	c.entries[uid] = flowCacheEntry{key: key, flow: &stored}
+}
+
+// prune drops the entries of the resources not present anymore
+func (c *FlowCache) prune(live map[k8stypes.UID]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for uid := range c.entries {
+		if !live[uid] {
+			delete(c.entries, uid)
+		}
+	}
+}
+
+// cachedFlow returns the flow from the cache if its key is unchanged, otherwise builds and caches it.
+// Flows built with errors are not cached, so that the errors are reported on every reconcile.
+func cachedFlow(cache *FlowCache, uid k8stypes.UID, key func() (string, error), build func() (*types.Flow, error)) (*types.Flow, error) {
+	if cache == nil {
+		return build()
+	}
+	k, err := key()
+	if err != nil {
+		return nil, err
+	}
+	if flow := cache.get(uid, k); flow != nil {
+		return flow, nil
+	}
+	flow, err := build()
+	if err == nil && flow != nil {
+		cache.put(uid, k, flow)
+	}
+	return flow, err
+}
+
+// flowKeys fingerprints the resources flows are built from. Outputs are usually shared by many flows, so their
+// fingerprints are computed once per render. Loading the referenced secrets also registers the mounted ones,
+// which keeps the mounted secrets complete when the cached flows are used.
+type flowKeys struct {
+	resources LoggingResources
+	secrets   SecretLoaderFactory
+	outputs   map[string]string
+}
+
+func newFlowKeys(resources LoggingResources, secrets SecretLoaderFactory) *flowKeys {
+	return &flowKeys{
+		resources: resources,
+		secrets:   secrets,
+		outputs:   make(map[string]string),
+	}
+}
+
+func (k *flowKeys) flow(flow v1beta1.Flow) (string, error) {
+	h, err := k.flowHash(flow.ObjectMeta, flow.Spec.Filters)
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range flow.Spec.GlobalOutputRefs {
+		if err := k.clusterOutput(h, ref); err != nil {
+			return "", err
+		}
+	}
+	for _, ref := range flow.Spec.LocalOutputRefs {
+		key := fmt.Sprintf("output/%s/%s", flow.Namespace, ref)
+		err := k.output(h, key, func() *cachedOutput {
+			if o := k.resources.Outputs.FindByNamespacedName(flow.Namespace, ref); o != nil {
+				return &cachedOutput{uid: o.UID, namespace: o.Namespace, spec: &o.Spec, findOutput: k.resources.Outputs.SpecFinder(o.Namespace)}
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+func (k *flowKeys) clusterFlow(flow v1beta1.ClusterFlow) (string, error) {
+	h, err := k.flowHash(flow.ObjectMeta, flow.Spec.Filters)
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range flow.Spec.GlobalOutputRefs {
+		if err := k.clusterOutput(h, ref); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+func (k *flowKeys) flowHash(meta metav1.ObjectMeta, filters []v1beta1.Filter) (hash.Hash, error) {
+	h := sha256.New()
+	logging := k.resources.Logging
+	fmt.Fprintf(h, "%s/%d/%s/%d", logging.UID, logging.Generation, meta.UID, meta.Generation)
+	if err := secretFingerprint(h, k.secrets.OutputSecretLoaderForNamespace(meta.Namespace), reflect.ValueOf(filters)); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (k *flowKeys) clusterOutput(h hash.Hash, ref string) error {
+	return k.output(h, "clusteroutput/"+ref, func() *cachedOutput {
+		if o := k.resources.ClusterOutputs.FindByName(ref); o != nil {
+			return &cachedOutput{uid: o.UID, namespace: o.Namespace, cluster: true, spec: &o.Spec.OutputSpec, findOutput: k.resources.ClusterOutputs.SpecFinder()}
+		}
+		return nil
+	})
+}
+
+func (k *flowKeys) output(h hash.Hash, key string, find func() *cachedOutput) error {
+	fingerprint, ok := k.outputs[key]
+	if !ok {
+		oh := sha256.New()
+		if err := outputFingerprint(oh, find(), k.secrets); err != nil {
+			return err
+		}
+		fingerprint = fmt.Sprintf("%x", oh.Sum(nil))
+		k.outputs[key] = fingerprint
+	}
+	fmt.Fprintf(h, "|%s:%s", key, fingerprint)
+	return nil
+}
+
+type cachedOutput struct {
+	uid        k8stypes.UID
+	namespace  string
+	cluster    bool
+	spec       *v1beta1.OutputSpec
+	findOutput OutputSpecFinder
+}
+
+// outputFingerprint hashes the output spec together with the failover and dead letter outputs chained to it.
+// The spec is hashed instead of the generation, as it may carry the merged global output settings.
+func outputFingerprint(h hash.Hash, output *cachedOutput, secrets SecretLoaderFactory) error {
+	if output == nil {
+		fmt.Fprint(h, "|missing")
+		return nil
+	}
+	if err := specFingerprint(h, string(output.uid), output.spec); err != nil {
+		return err
+	}
+	loader := secrets.OutputSecretLoaderForNamespace(output.namespace)
+	if output.cluster {
+		loader = secrets.ClusterOutputSecretLoaderForNamespace(output.namespace)
+	}
+	if err := secretFingerprint(h, loader, reflect.ValueOf(output.spec)); err != nil {
+		return err
+	}
+	for _, ref := range chainedOutputRefs(*output.spec) {
+		spec := output.findOutput(ref)
+		if spec == nil {
+			fmt.Fprintf(h, "|missing:%s", ref)
+			continue
+		}
+		if err := specFingerprint(h, ref, spec); err != nil {
+			return err
+		}
+		if err := secretFingerprint(h, loader, reflect.ValueOf(spec)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func specFingerprint(h hash.Hash, id string, spec *v1beta1.OutputSpec) error {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return errors.WrapIf(err, "failed to marshal output spec")
+	}
+	fmt.Fprintf(h, "|%s|%s", id, raw)
+	return nil
+}
+
+var (
+	secretType    = reflect.TypeOf(&secret.Secret{})
+	tlsSourceType = reflect.TypeOf(&v1beta1.TLSSource{})
+)
+
+// secretFingerprint loads every secret reachable from the value and hashes the loaded values
+func secretFingerprint(h hash.Hash, loader secret.SecretLoader, v reflect.Value) error {
+	if v.Type() == secretType {
+		if v.IsNil() {
+			return nil
+		}
+		value, err := loader.Load(v.Interface().(*secret.Secret))
+		if err != nil {
+			// The error surfaces when the flow is built
+			fmt.Fprint(h, "|unresolved")
+			return nil
+		}
+		fmt.Fprintf(h, "|%d:%s", len(value), value)
+		return nil
+	}
+	if v.Type() == tlsSourceType {
+		loader, ok := loader.(TLSSourceLoader)
+		if v.IsNil() || !ok {
+			return nil
+		}
+		if _, value, err := loader.LoadTLSSource(v.Interface().(*v1beta1.TLSSource)); err != nil {
+			fmt.Fprint(h, "|unresolved")
+		} else {
+			fmt.Fprintf(h, "|%d:%s", len(value), value)
+		}
+		return nil
+	}
+	switch v.Kind() { // nolint:exhaustive
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return secretFingerprint(h, loader, v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if err := secretFingerprint(h, loader, v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := secretFingerprint(h, loader, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			fmt.Fprintf(h, "|%v", k.Interface())
+			if err := secretFingerprint(h, loader, v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
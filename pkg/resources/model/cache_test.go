@@ -0,0 +1,141 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/filter"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/render"
+)
+
+type testSecretLoaderFactory struct{}
+
+func (testSecretLoaderFactory) OutputSecretLoaderForNamespace(namespace string) secret.SecretLoader {
+	return secret.NewSecretLoader(nil, namespace, "", &secret.MountSecrets{})
+}
+
+func (f testSecretLoaderFactory) ClusterOutputSecretLoaderForNamespace(namespace string) secret.SecretLoader {
+	return f.OutputSecretLoaderForNamespace(namespace)
+}
+
+func testResources(flows int) LoggingResources {
+	logging := v1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", UID: "logging", Generation: 1},
+		Spec: v1beta1.LoggingSpec{
+			ControlNamespace: "logging",
+			FluentdSpec:      &v1beta1.FluentdSpec{},
+		},
+	}
+	resources := LoggingResources{
+		Logging: logging,
+		ClusterOutputs: ClusterOutputs{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "null", Namespace: "logging", UID: "null", Generation: 1},
+				Spec: v1beta1.ClusterOutputSpec{
+					OutputSpec: v1beta1.OutputSpec{NullOutputConfig: output.NewNullOutputConfig()},
+				},
+			},
+		},
+	}
+	for i := 0; i < flows; i++ {
+		resources.Flows = append(resources.Flows, v1beta1.Flow{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("flow-%d", i),
+				Namespace:  fmt.Sprintf("ns-%d", i%50),
+				UID:        k8stypes.UID(fmt.Sprintf("flow-%d", i)),
+				Generation: 1,
+			},
+			Spec: v1beta1.FlowSpec{
+				Match: []v1beta1.Match{
+					{Select: &v1beta1.Select{Labels: map[string]string{"app": fmt.Sprintf("app-%d", i)}}},
+				},
+				Filters: []v1beta1.Filter{
+					{TagNormaliser: &filter.TagNormaliser{}},
+				},
+				GlobalOutputRefs: []string{"null"},
+			},
+		})
+	}
+	return resources
+}
+
+func renderSystem(t testing.TB, resources LoggingResources, cache *FlowCache) string {
+	system, err := CreateSystem(resources, testSecretLoaderFactory{}, cache, logr.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	renderer := render.FluentRender{Out: out, Indent: 2}
+	if err := renderer.Render(system); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestFlowCache(t *testing.T) {
+	resources := testResources(10)
+	cache := NewFlowCache()
+
+	expected := renderSystem(t, resources, nil)
+	if actual := renderSystem(t, resources, cache); actual != expected {
+		t.Fatalf("cached config differs from the uncached one:\n%s\n%s", actual, expected)
+	}
+	if actual := renderSystem(t, resources, cache); actual != expected {
+		t.Fatalf("config rendered from the cache differs from the uncached one:\n%s\n%s", actual, expected)
+	}
+	if hits, misses := cache.Stats(); hits != 10 || misses != 10 {
+		t.Fatalf("expected 10 hits and 10 misses, got %d hits and %d misses", hits, misses)
+	}
+
+	resources.Flows[0].Generation++
+	resources.Flows[0].Spec.GlobalOutputRefs = nil
+	expected = renderSystem(t, resources, nil)
+	if actual := renderSystem(t, resources, cache); actual != expected {
+		t.Fatalf("changed flow was not rebuilt:\n%s\n%s", actual, expected)
+	}
+	if hits, misses := cache.Stats(); hits != 19 || misses != 11 {
+		t.Fatalf("expected 19 hits and 11 misses, got %d hits and %d misses", hits, misses)
+	}
+
+	resources.Flows = resources.Flows[1:]
+	renderSystem(t, resources, cache)
+	if len(cache.entries) != 9 {
+		t.Fatalf("expected the removed flow to be pruned, got %d entries", len(cache.entries))
+	}
+}
+
+func BenchmarkCreateSystem(b *testing.B) {
+	resources := testResources(1000)
+	for name, cache := range map[string]*FlowCache{"uncached": nil, "cached": NewFlowCache()} {
+		cache := cache
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := CreateSystem(resources, testSecretLoaderFactory{}, cache, logr.Discard()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/filter"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+)
+
+const (
+	httpInputLabel = "@HTTP_INPUT"
+	httpInputID    = "main-http-input"
+)
+
+// registerHTTPInput adds the HTTP input of the logging, its records are authenticated and attributed before they are
+// routed to the flows
+func registerHTTPInput(builder *types.SystemBuilder, spec *v1beta1.HTTPInput, secretLoader secret.SecretLoader) error {
+	if spec.Token == nil || spec.Token.MountFrom != nil {
+		return errors.New("httpInput requires a token given as a value or valueFrom")
+	}
+	token, err := secretLoader.Load(spec.Token)
+	if err != nil {
+		return errors.WrapIf(err, "loading the token of the http input")
+	}
+	if token == "" {
+		return errors.New("the token of the http input is empty")
+	}
+
+	params := types.Params{
+		"bind":             "0.0.0.0",
+		"port":             strconv.Itoa(int(spec.Port)),
+		"add_http_headers": "true",
+	}
+	if spec.BodySizeLimit != "" {
+		params["body_size_limit"] = spec.BodySizeLimit
+	}
+	source := &types.GenericDirective{
+		PluginMeta: types.PluginMeta{
+			Type:      "http",
+			Directive: "source",
+			Id:        httpInputID,
+			Label:     httpInputLabel,
+		},
+		Params: params,
+	}
+
+	auth, err := bearerTokenFilter(httpInputID, token)
+	if err != nil {
+		return err
+	}
+	attribution, err := (&filter.RecordTransformer{
+		EnableRuby: true,
+		RemoveKeys: "HTTP_AUTHORIZATION",
+		Records: []filter.Record{
+			{"kubernetes": httpInputKubernetesRecord(spec)},
+		},
+	}).ToDirective(secretLoader, httpInputID+"-attribution")
+	if err != nil {
+		return err
+	}
+
+	return builder.RegisterLabeledInput(source, httpInputLabel, []types.Filter{auth, attribution})
+}
+
+// bearerTokenFilter drops the records of the http input received without the "Authorization: Bearer <token>" header
+func bearerTokenFilter(inputID, token string) (types.Filter, error) {
+	return (&filter.GrepConfig{
+		Regexp: []filter.RegexpSection{
+			{
+				Key:     "HTTP_AUTHORIZATION",
+				Pattern: "/^Bearer " + strings.ReplaceAll(regexp.QuoteMeta(token), "/", `\/`) + "$/",
+			},
+		},
+	}).ToDirective(nil, inputID+"-auth")
+}
+
+// httpInputKubernetesRecord returns the ruby expression of the kubernetes metadata the label router matches on.
+// Braces would end the placeholder of the record transformer, so the hashes are built with Hash[].
+func httpInputKubernetesRecord(spec *v1beta1.HTTPInput) string {
+	keys := make([]string, 0, len(spec.Labels))
+	for k := range spec.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var labels []string
+	for _, k := range keys {
+		labels = append(labels, rubyString(k), rubyString(spec.Labels[k]))
+	}
+	return fmt.Sprintf("${Hash[%s, %s, %s, Hash[%s]]}",
+		rubyString("namespace_name"), rubyString(spec.Namespace),
+		rubyString("labels"), strings.Join(labels, ", "))
+}
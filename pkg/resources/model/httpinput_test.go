@@ -0,0 +1,90 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+)
+
+func TestRegisterHTTPInput(t *testing.T) {
+	spec := &v1beta1.HTTPInput{
+		Enabled:   true,
+		Port:      9881,
+		Token:     &secret.Secret{Value: "s3cr3t/t.ken"},
+		Namespace: "functions",
+		Labels:    map[string]string{"tier": "api", "app": "billing"},
+	}
+	loader := testSecretLoaderFactory{}.OutputSecretLoaderForNamespace("logging")
+	builder := types.NewSystemBuilder(nil, nil, types.NewRouter("main", nil))
+	if err := registerHTTPInput(builder, spec, loader); err != nil {
+		t.Fatal(err)
+	}
+	system, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(system.Inputs) != 1 || len(system.InputFlows) != 1 {
+		t.Fatalf("expected a single labeled input, got %d inputs and %d flows", len(system.Inputs), len(system.InputFlows))
+	}
+	source := system.Inputs[0]
+	if meta := source.GetPluginMeta(); meta.Type != "http" || meta.Label != httpInputLabel {
+		t.Errorf("unexpected source %+v", meta)
+	}
+	wantParams := types.Params{"bind": "0.0.0.0", "port": "9881", "add_http_headers": "true"}
+	if !reflect.DeepEqual(source.GetParams(), wantParams) {
+		t.Errorf("source params = %v, want %v", source.GetParams(), wantParams)
+	}
+
+	flow := system.InputFlows[0]
+	if flow.FlowLabel != httpInputLabel || len(flow.Filters) != 2 || len(flow.Outputs) != 1 {
+		t.Fatalf("unexpected input flow %+v", flow)
+	}
+	auth := flow.Filters[0].GetSections()[0].GetParams()
+	if want := (types.Params{"key": "HTTP_AUTHORIZATION", "pattern": `/^Bearer s3cr3t\/t\.ken$/`}); !reflect.DeepEqual(auth, want) {
+		t.Errorf("auth = %v, want %v", auth, want)
+	}
+	attribution := flow.Filters[1]
+	if attribution.GetParams()["remove_keys"] != "HTTP_AUTHORIZATION" {
+		t.Errorf("the authorization header is kept: %v", attribution.GetParams())
+	}
+	wantRecord := `${Hash["namespace_name", "functions", "labels", Hash["app", "billing", "tier", "api"]]}`
+	if got := attribution.GetSections()[0].GetParams()["kubernetes"]; got != wantRecord {
+		t.Errorf("kubernetes record = %s, want %s", got, wantRecord)
+	}
+	if router := flow.Outputs[0]; router.GetPluginMeta().Id != httpInputID+"-router" || router.GetParams()["metrics"] != "false" {
+		t.Errorf("unexpected router %+v", router.GetPluginMeta())
+	}
+}
+
+func TestRegisterHTTPInputToken(t *testing.T) {
+	loader := testSecretLoaderFactory{}.OutputSecretLoaderForNamespace("logging")
+	for _, token := range []*secret.Secret{
+		nil,
+		{Value: ""},
+		{MountFrom: &secret.ValueFrom{}},
+	} {
+		builder := types.NewSystemBuilder(nil, nil, types.NewRouter("main", nil))
+		if err := registerHTTPInput(builder, &v1beta1.HTTPInput{Enabled: true, Token: token}, loader); err == nil {
+			t.Errorf("expected an error for token %+v", token)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+)
+
+// logClassResources provides the resources with the buffer defaults of the log classes merged into the outputs
+type logClassResources struct {
+	raw     LoggingResources
+	classes map[string]LoggingResources
+}
+
+func newLogClassResources(resources LoggingResources) *logClassResources {
+	return &logClassResources{raw: resources, classes: make(map[string]LoggingResources)}
+}
+
+// forClass returns the resources for the flows of the log class. The buffer settings of the class take precedence
+// over the global output settings.
+func (l *logClassResources) forClass(class string) LoggingResources {
+	if class == "" {
+		class = v1beta1.LogClassNormal
+	}
+	if resources, ok := l.classes[class]; ok {
+		return resources
+	}
+	logging := l.raw.Logging
+	var global *output.Buffer
+	if logging.Spec.GlobalOutputSettings != nil {
+		global = logging.Spec.GlobalOutputSettings.Buffer
+	}
+	resources := l.raw
+	if classBuffer := logging.Spec.LogClasses.Settings(class).Buffer; classBuffer != nil || global != nil {
+		resources = applyBufferDefaults(l.raw, classBuffer, global)
+	}
+	l.classes[class] = resources
+	return resources
+}
+
+// AgentLogClass returns the most critical log class of the flows. The agents forward the logs of every class through
+// the same output, so the logs of the most critical class must fit in its buffer.
+func AgentLogClass(resources LoggingResources) string {
+	class := v1beta1.LogClassBestEffort
+	use := func(c string) {
+		if c == "" {
+			c = v1beta1.LogClassNormal
+		}
+		if v1beta1.LogClassPriority(c) > v1beta1.LogClassPriority(class) {
+			class = c
+		}
+	}
+	if len(resources.Flows) == 0 && len(resources.ClusterFlows) == 0 || resources.Logging.Spec.DefaultFlowSpec != nil {
+		use(v1beta1.LogClassNormal)
+	}
+	for _, flow := range resources.Flows {
+		use(flow.Spec.LogClass)
+	}
+	for _, flow := range resources.ClusterFlows {
+		use(flow.Spec.LogClass)
+	}
+	return class
+}
@@ -0,0 +1,66 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+)
+
+func TestLogClassBufferPrecedence(t *testing.T) {
+	resources := testResources(1)
+	resources.Logging.Spec.GlobalOutputSettings = &v1beta1.GlobalOutputSettings{
+		Buffer: &output.Buffer{TotalLimitSize: "1G", ChunkLimitSize: "8M"},
+	}
+	resources.ClusterOutputs[0].Spec.OutputSpec = v1beta1.OutputSpec{
+		FileOutput: &output.FileOutputConfig{Path: "/tmp/logs", Buffer: &output.Buffer{OverflowAction: "throw_exception"}},
+	}
+	classes := newLogClassResources(resources)
+
+	normal := classes.forClass("").ClusterOutputs[0].Spec.FileOutput.Buffer
+	if normal.TotalLimitSize != "1G" || normal.OverflowAction != "throw_exception" || normal.RetryForever != nil {
+		t.Errorf("unexpected normal buffer: %+v", normal)
+	}
+
+	critical := classes.forClass(v1beta1.LogClassCritical).ClusterOutputs[0].Spec.FileOutput.Buffer
+	if critical.TotalLimitSize != "16G" || critical.ChunkLimitSize != "8M" || critical.OverflowAction != "throw_exception" {
+		t.Errorf("unexpected critical buffer: %+v", critical)
+	}
+	if critical.RetryForever == nil || !*critical.RetryForever {
+		t.Errorf("expected critical buffer to retry forever")
+	}
+
+	if resources.ClusterOutputs[0].Spec.FileOutput.Buffer.TotalLimitSize != "" {
+		t.Errorf("the original output must not be modified")
+	}
+}
+
+func TestAgentLogClass(t *testing.T) {
+	resources := testResources(2)
+	resources.Flows[0].Spec.LogClass = v1beta1.LogClassBestEffort
+	resources.Flows[1].Spec.LogClass = v1beta1.LogClassBestEffort
+	if class := AgentLogClass(resources); class != v1beta1.LogClassBestEffort {
+		t.Errorf("expected best-effort, got %s", class)
+	}
+	resources.Flows[1].Spec.LogClass = v1beta1.LogClassCritical
+	if class := AgentLogClass(resources); class != v1beta1.LogClassCritical {
+		t.Errorf("expected critical, got %s", class)
+	}
+	if class := AgentLogClass(testResources(0)); class != v1beta1.LogClassNormal {
+		t.Errorf("expected normal without flows, got %s", class)
+	}
+}
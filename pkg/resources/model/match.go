@@ -0,0 +1,309 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/filter"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+)
+
+// matchResultKey holds the result of the match statements evaluated in the flow until the record is filtered by it
+const matchResultKey = "__logging_flow_match"
+
+// matchStatement is a select or exclude of a flow
+type matchStatement struct {
+	exclude bool
+	term    v1beta1.MatchTerm
+	anyOf   []v1beta1.MatchTerm
+}
+
+// evaluatedInFlow tells whether the statement has conditions the router cannot evaluate
+func (s matchStatement) evaluatedInFlow() bool {
+	return len(s.term.Fields) > 0 || len(s.term.LabelExpressions) > 0 || len(s.anyOf) > 0
+}
+
+// regexps memoizes the regular expressions of the match statements. The ruby expression of the record_transformer is
+// evaluated for every record in the context of the same object, the regular expressions are kept in its instance
+// variables so that each of them is compiled once instead of for every record.
+type regexps struct {
+	count int
+}
+
+func (r *regexps) compile(expression string) string {
+	r.count++
+	return fmt.Sprintf("(@match_regexp_%d ||= %s)", r.count, expression)
+}
+
+// matchFilters returns the filters evaluating the match statements in the flow, if any of them has conditions the
+// router cannot evaluate. The router passes the logs selected by the metadata of the statements, the statements
+// are evaluated again in their order and the first one matching decides.
+//
+// The evaluation is a ruby expression run by the record_transformer for every record passed to the flow, which
+// costs considerably more than the routing by metadata.
+func matchFilters(flowID string, statements []matchStatement) ([]types.Filter, error) {
+	evaluated := false
+	for _, s := range statements {
+		evaluated = evaluated || s.evaluatedInFlow()
+	}
+	if !evaluated {
+		return nil, nil
+	}
+
+	re := &regexps{}
+	expression := "false"
+	for i := len(statements) - 1; i >= 0; i-- {
+		s := statements[i]
+		condition, err := termCondition(s.term, re)
+		if err != nil {
+			return nil, err
+		}
+		if len(s.anyOf) > 0 {
+			var terms []string
+			for _, t := range s.anyOf {
+				term, err := termCondition(t, re)
+				if err != nil {
+					return nil, err
+				}
+				terms = append(terms, "("+term+")")
+			}
+			condition = fmt.Sprintf("(%s) && (%s)", condition, strings.Join(terms, " || "))
+		}
+		expression = fmt.Sprintf("(%s) ? %t : %s", condition, !s.exclude, expression)
+	}
+
+	evaluate, err := (&filter.RecordTransformer{
+		EnableRuby: true,
+		Records: []filter.Record{
+			{matchResultKey: "${" + expression + "}"},
+		},
+	}).ToDirective(nil, flowID+":match")
+	if err != nil {
+		return nil, err
+	}
+	selected, err := (&filter.GrepConfig{
+		Regexp: []filter.RegexpSection{
+			{Key: matchResultKey, Pattern: `/\Atrue\z/`},
+		},
+	}).ToDirective(nil, flowID+":match-select")
+	if err != nil {
+		return nil, err
+	}
+	cleanup, err := (&filter.RecordTransformer{
+		RemoveKeys: matchResultKey,
+	}).ToDirective(nil, flowID+":match-cleanup")
+	if err != nil {
+		return nil, err
+	}
+	return []types.Filter{evaluate, selected, cleanup}, nil
+}
+
+// termCondition returns the ruby expression of the conjunction of the conditions of the term
+func termCondition(term v1beta1.MatchTerm, re *regexps) (string, error) {
+	var conditions []string
+	if len(term.Namespaces) > 0 {
+		conditions = append(conditions, rubyArray(term.Namespaces)+".include?("+recordDig("kubernetes", "namespace_name")+")")
+	}
+	keys := make([]string, 0, len(term.Labels))
+	for k := range term.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		conditions = append(conditions, labelDig(k)+" == "+rubyString(term.Labels[k]))
+	}
+	if len(term.Hosts) > 0 {
+		conditions = append(conditions, rubyArray(term.Hosts)+".include?("+recordDig("kubernetes", "host")+")")
+	}
+	if len(term.ContainerNames) > 0 {
+		conditions = append(conditions, rubyArray(term.ContainerNames)+".include?("+recordDig("kubernetes", "container_name")+")")
+	}
+	for _, e := range term.LabelExpressions {
+		condition, err := labelExpressionCondition(e, re)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+	}
+	for _, f := range term.Fields {
+		condition, err := fieldCondition(f, re)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+	}
+	if len(conditions) == 0 {
+		return "true", nil
+	}
+	return strings.Join(conditions, " && "), nil
+}
+
+func labelExpressionCondition(e v1beta1.LabelExpression, re *regexps) (string, error) {
+	if e.Key == "" {
+		return "", errors.New("the key of a label expression is empty")
+	}
+	label := labelDig(e.Key)
+	switch e.Operator {
+	case v1beta1.LabelExpressionIn, v1beta1.LabelExpressionNotIn, v1beta1.LabelExpressionRegexp:
+		if len(e.Values) == 0 {
+			return "", errors.Errorf("label expression %s of %q requires values", e.Operator, e.Key)
+		}
+	}
+	switch e.Operator {
+	case v1beta1.LabelExpressionIn:
+		return rubyArray(e.Values) + ".include?(" + label + ")", nil
+	case v1beta1.LabelExpressionNotIn:
+		return "!" + rubyArray(e.Values) + ".include?(" + label + ")", nil
+	case v1beta1.LabelExpressionExists:
+		return "!" + label + ".nil?", nil
+	case v1beta1.LabelExpressionDoesNotExist:
+		return label + ".nil?", nil
+	case v1beta1.LabelExpressionRegexp:
+		patterns := make([]string, len(e.Values))
+		for i, v := range e.Values {
+			patterns[i] = "Regexp.new(" + rubyString(v) + ")"
+		}
+		union := re.compile("Regexp.union(" + strings.Join(patterns, ", ") + ")")
+		return fmt.Sprintf("!%s.nil? && %s.match?(%s.to_s)", label, union, label), nil
+	default:
+		return "", errors.Errorf("unknown operator %q of label expression %q", e.Operator, e.Key)
+	}
+}
+
+func fieldCondition(f v1beta1.FieldMatch, re *regexps) (string, error) {
+	path, err := fieldPath(f.Key)
+	if err != nil {
+		return "", err
+	}
+	value := recordDig(path...) + ".to_s"
+	switch f.Operator {
+	case "", v1beta1.FieldMatchEquals:
+		return value + " == " + rubyString(f.Value), nil
+	case v1beta1.FieldMatchRegexp:
+		return re.compile("Regexp.new("+rubyString(f.Value)+")") + ".match?(" + value + ")", nil
+	case v1beta1.FieldMatchExists:
+		return "!" + value + ".empty?", nil
+	default:
+		return "", errors.Errorf("unknown operator %q of field %q", f.Operator, f.Key)
+	}
+}
+
+// fieldPath splits the key of a field into the keys of the nested hashes, array indices are returned as numbers
+func fieldPath(key string) ([]interface{}, error) {
+	if key == "" {
+		return nil, errors.New("the key of a field match is empty")
+	}
+	var path []interface{}
+	if !strings.HasPrefix(key, "$") {
+		for _, k := range strings.Split(key, ".") {
+			path = append(path, k)
+		}
+		return path, nil
+	}
+	rest := key[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "."):
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			path = append(path, rest[1:end+1])
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest, "']")
+			if end < 0 {
+				return nil, errors.Errorf("unterminated bracket in field key %q", key)
+			}
+			path = append(path, rest[2:end])
+			rest = rest[end+2:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, errors.Errorf("unterminated bracket in field key %q", key)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return nil, errors.Errorf("invalid index in field key %q", key)
+			}
+			path = append(path, index)
+			rest = rest[end+1:]
+		default:
+			return nil, errors.Errorf("invalid field key %q", key)
+		}
+	}
+	return path, nil
+}
+
+// withoutNamespaces drops the namespaces of the terms of a flow, its logs are selected from its namespace by the router
+func withoutNamespaces(terms []v1beta1.MatchTerm) []v1beta1.MatchTerm {
+	var result []v1beta1.MatchTerm
+	for _, t := range terms {
+		t.Namespaces = nil
+		result = append(result, t)
+	}
+	return result
+}
+
+func labelDig(label string) string {
+	return recordDig("kubernetes", "labels", label)
+}
+
+// recordDig returns the ruby expression of the value of the record at the path, nil if it is missing
+func recordDig(path ...interface{}) string {
+	keys := make([]string, len(path))
+	for i, p := range path {
+		if s, ok := p.(string); ok {
+			keys[i] = rubyString(s)
+		} else {
+			keys[i] = fmt.Sprint(p)
+		}
+	}
+	return "(record.dig(" + strings.Join(keys, ", ") + ") rescue nil)"
+}
+
+func rubyArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = rubyString(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// rubyString quotes the value as a ruby string. Characters other than letters, digits and a few punctuation marks
+// are escaped, so that the value can neither end the string, nor interpolate, nor start a placeholder or comment in
+// the configuration.
+func rubyString(value string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', strings.IndexByte("-_./:@,+=", c) >= 0:
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, `\x%02x`, c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,269 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+)
+
+func TestFieldPath(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    []interface{}
+		wantErr bool
+	}{
+		{key: "level", want: []interface{}{"level"}},
+		{key: "json.level", want: []interface{}{"json", "level"}},
+		{key: "$.json.level", want: []interface{}{"json", "level"}},
+		{key: "$['resource']['service.name']", want: []interface{}{"resource", "service.name"}},
+		{key: "$.spans[0]['trace.id']", want: []interface{}{"spans", 0, "trace.id"}},
+		{key: "", wantErr: true},
+		{key: "$['resource'", wantErr: true},
+		{key: "$[first]", wantErr: true},
+		{key: "$resource", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := fieldPath(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fieldPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fieldPath() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRubyString(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "app.kubernetes.io/name", want: `"app.kubernetes.io/name"`},
+		{value: `"} #{exit}`, want: `"\x22\x7d\x20\x23\x7bexit\x7d"`},
+		{value: "${tag}", want: `"\x24\x7btag\x7d"`},
+		{value: "^a\\b", want: `"\x5ea\x5cb"`},
+	}
+	for _, tt := range tests {
+		if got := rubyString(tt.value); got != tt.want {
+			t.Errorf("rubyString(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTermCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		term    v1beta1.MatchTerm
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			want: "true",
+		},
+		{
+			name: "metadata",
+			term: v1beta1.MatchTerm{
+				Namespaces:     []string{"shop"},
+				Labels:         map[string]string{"tier": "web", "app": "cart"},
+				ContainerNames: []string{"api"},
+			},
+			want: `["shop"].include?((record.dig("kubernetes", "namespace_name") rescue nil)) && ` +
+				`(record.dig("kubernetes", "labels", "app") rescue nil) == "cart" && ` +
+				`(record.dig("kubernetes", "labels", "tier") rescue nil) == "web" && ` +
+				`["api"].include?((record.dig("kubernetes", "container_name") rescue nil))`,
+		},
+		{
+			name: "label expressions",
+			term: v1beta1.MatchTerm{LabelExpressions: []v1beta1.LabelExpression{
+				{Key: "team", Operator: v1beta1.LabelExpressionNotIn, Values: []string{"qa"}},
+				{Key: "canary", Operator: v1beta1.LabelExpressionDoesNotExist},
+				{Key: "app", Operator: v1beta1.LabelExpressionRegexp, Values: []string{"^web", "^api"}},
+			}},
+			want: `!["qa"].include?((record.dig("kubernetes", "labels", "team") rescue nil)) && ` +
+				`(record.dig("kubernetes", "labels", "canary") rescue nil).nil? && ` +
+				`!(record.dig("kubernetes", "labels", "app") rescue nil).nil? && ` +
+				`(@match_regexp_1 ||= Regexp.union(Regexp.new("\x5eweb"), Regexp.new("\x5eapi")))` +
+				`.match?((record.dig("kubernetes", "labels", "app") rescue nil).to_s)`,
+		},
+		{
+			name: "fields",
+			term: v1beta1.MatchTerm{Fields: []v1beta1.FieldMatch{
+				{Key: "json.level", Value: "debug"},
+				{Key: "trace_id", Operator: v1beta1.FieldMatchExists},
+				{Key: "$['resource']['service.name']", Operator: v1beta1.FieldMatchRegexp, Value: "^checkout/"},
+			}},
+			want: `(record.dig("json", "level") rescue nil).to_s == "debug" && ` +
+				`!(record.dig("trace_id") rescue nil).to_s.empty? && ` +
+				`(@match_regexp_1 ||= Regexp.new("\x5echeckout/")).match?((record.dig("resource", "service.name") rescue nil).to_s)`,
+		},
+		{
+			name:    "regexp label expression without values",
+			term:    v1beta1.MatchTerm{LabelExpressions: []v1beta1.LabelExpression{{Key: "app", Operator: v1beta1.LabelExpressionRegexp}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown label operator",
+			term:    v1beta1.MatchTerm{LabelExpressions: []v1beta1.LabelExpression{{Key: "app", Operator: "Gt", Values: []string{"1"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown field operator",
+			term:    v1beta1.MatchTerm{Fields: []v1beta1.FieldMatch{{Key: "level", Operator: "prefix", Value: "d"}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := termCondition(tt.term, &regexps{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("termCondition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("termCondition() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// matchExpression returns the ruby expression of the filters evaluating the match statements
+func matchExpression(t *testing.T, filters []types.Filter) string {
+	if len(filters) != 3 {
+		t.Fatalf("expected the evaluate, select and cleanup filters, got %d", len(filters))
+	}
+	wantIDs := []string{"flow:app:flow:match", "flow:app:flow:match-select", "flow:app:flow:match-cleanup"}
+	wantTypes := []string{"record_transformer", "grep", "record_transformer"}
+	for i, f := range filters {
+		if meta := f.GetPluginMeta(); meta.Id != wantIDs[i] || meta.Type != wantTypes[i] {
+			t.Errorf("filter %d is %s %s, want %s %s", i, meta.Type, meta.Id, wantTypes[i], wantIDs[i])
+		}
+	}
+	if params := filters[0].GetParams(); params["enable_ruby"] != "true" {
+		t.Errorf("ruby is not enabled for the evaluation: %v", params)
+	}
+	if params := filters[1].GetSections()[0].GetParams(); params["key"] != matchResultKey || params["pattern"] != `/\Atrue\z/` {
+		t.Errorf("unexpected select: %v", params)
+	}
+	if params := filters[2].GetParams(); params["remove_keys"] != matchResultKey {
+		t.Errorf("unexpected cleanup: %v", params)
+	}
+	return filters[0].GetSections()[0].GetParams()[matchResultKey]
+}
+
+func TestMatchFilters(t *testing.T) {
+	tests := []struct {
+		name       string
+		statements []matchStatement
+		want       string
+	}{
+		{
+			name: "metadata only is left to the router",
+			statements: []matchStatement{
+				{exclude: true, term: v1beta1.MatchTerm{Labels: map[string]string{"app": "noisy"}}},
+				{term: v1beta1.MatchTerm{Hosts: []string{"node-1"}}},
+			},
+		},
+		{
+			name: "first matching statement decides",
+			statements: []matchStatement{
+				{exclude: true, term: v1beta1.MatchTerm{
+					Labels: map[string]string{"app": "noisy"},
+					Fields: []v1beta1.FieldMatch{{Key: "level", Value: "debug"}},
+				}},
+				{term: v1beta1.MatchTerm{Fields: []v1beta1.FieldMatch{{Key: "msg", Operator: v1beta1.FieldMatchRegexp, Value: "^GET"}}}},
+				{term: v1beta1.MatchTerm{LabelExpressions: []v1beta1.LabelExpression{
+					{Key: "app", Operator: v1beta1.LabelExpressionRegexp, Values: []string{"^api"}},
+				}}},
+			},
+			want: `${((record.dig("kubernetes", "labels", "app") rescue nil) == "noisy" && (record.dig("level") rescue nil).to_s == "debug") ? false : ` +
+				`((@match_regexp_2 ||= Regexp.new("\x5eGET")).match?((record.dig("msg") rescue nil).to_s)) ? true : ` +
+				`(!(record.dig("kubernetes", "labels", "app") rescue nil).nil? && ` +
+				`(@match_regexp_1 ||= Regexp.union(Regexp.new("\x5eapi"))).match?((record.dig("kubernetes", "labels", "app") rescue nil).to_s)) ? true : false}`,
+		},
+		{
+			name: "any of the terms",
+			statements: []matchStatement{
+				{
+					term: v1beta1.MatchTerm{Namespaces: []string{"shop"}},
+					anyOf: []v1beta1.MatchTerm{
+						{Labels: map[string]string{"tier": "frontend"}},
+						{ContainerNames: []string{"api"}},
+					},
+				},
+			},
+			want: `${((["shop"].include?((record.dig("kubernetes", "namespace_name") rescue nil))) && ` +
+				`(((record.dig("kubernetes", "labels", "tier") rescue nil) == "frontend") || ` +
+				`(["api"].include?((record.dig("kubernetes", "container_name") rescue nil))))) ? true : false}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := matchFilters("flow:app:flow", tt.statements)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" {
+				if len(filters) != 0 {
+					t.Errorf("unexpected filters: %v", filters)
+				}
+				return
+			}
+			if got := matchExpression(t, filters); got != tt.want {
+				t.Errorf("expression =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlowForFlowMatch(t *testing.T) {
+	flow := v1beta1.Flow{
+		ObjectMeta: metav1.ObjectMeta{Name: "flow", Namespace: "app"},
+		Spec: v1beta1.FlowSpec{
+			Match: []v1beta1.Match{
+				{Exclude: &v1beta1.Exclude{
+					Labels: map[string]string{"app": "noisy"},
+					Fields: []v1beta1.FieldMatch{{Key: "level", Value: "debug"}},
+				}},
+				{Select: &v1beta1.Select{Labels: map[string]string{"app": "noisy"}}},
+			},
+		},
+	}
+	result, err := FlowForFlow(flow, ClusterOutputs{}, Outputs{}, testSecretLoaderFactory{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The exclude with fields is left to the flow, the router only selects by the metadata
+	wantMatches := []types.FlowMatch{{Labels: map[string]string{"app": "noisy"}, Namespaces: []string{"app"}}}
+	if !reflect.DeepEqual(result.Matches, wantMatches) {
+		t.Errorf("router matches = %+v, want %+v", result.Matches, wantMatches)
+	}
+	matchExpression(t, result.Filters)
+
+	flow.Spec.Match[0].Exclude.Fields[0].Key = "$[level"
+	if _, err := FlowForFlow(flow, ClusterOutputs{}, Outputs{}, testSecretLoaderFactory{}); err == nil {
+		t.Error("expected an error for an invalid field key")
+	}
+}
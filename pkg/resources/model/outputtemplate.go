@@ -0,0 +1,100 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/logging-operator/pkg/mirror"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// applyOutputTemplate sets the format of the output from the template it references, unless the output sets its own
+func applyOutputTemplate(templates []v1beta1.OutputTemplate, spec *v1beta1.OutputSpec) (problems []string) {
+	if spec.Template == "" {
+		return nil
+	}
+	var template *v1beta1.OutputTemplate
+	for i := range templates {
+		if templates[i].Name == spec.Template {
+			template = &templates[i]
+			break
+		}
+	}
+	if template == nil {
+		return []string{fmt.Sprintf("unknown output template: %s", spec.Template)}
+	}
+
+	it := mirror.StructRange(*spec)
+	for it.Next() {
+		if it.Field().Type.Kind() != reflect.Ptr || it.Value().IsNil() || it.Field().Name == "TLSFrom" {
+			continue
+		}
+		field := it.Value().Elem().FieldByName("Format")
+		if !field.IsValid() || field.Type() != reflect.TypeOf(template.Format) {
+			problems = append(problems, fmt.Sprintf("output template %s cannot be applied to the %s output, it has no format section", spec.Template, jsonFieldName(it.Field())))
+			continue
+		}
+		if field.IsNil() && template.Format != nil {
+			field.Set(reflect.ValueOf(template.Format.DeepCopy()))
+		}
+	}
+	return problems
+}
+
+// applyOutputTemplatesToResources returns the resources with copies of the outputs that have the formats of their
+// templates applied, outputs referencing templates that cannot be applied are rejected
+func applyOutputTemplatesToResources(resources LoggingResources, logger logr.Logger) (LoggingResources, error) {
+	templates := resources.Logging.Spec.OutputTemplates
+	skip := resources.Logging.Spec.SkipInvalidResources
+	var errs error
+	reject := func(kind, namespace, name string, problems []string) {
+		err := errors.Errorf("%s %s/%s rejected: %s", kind, namespace, name, strings.Join(problems, ", "))
+		if skip {
+			logger.Error(err, "skipping output")
+			return
+		}
+		errs = errors.Append(errs, err)
+	}
+
+	clusterOutputs := make(ClusterOutputs, 0, len(resources.ClusterOutputs))
+	for _, o := range resources.ClusterOutputs {
+		o := *o.DeepCopy()
+		if problems := applyOutputTemplate(templates, &o.Spec.OutputSpec); len(problems) > 0 {
+			reject("clusteroutput", o.Namespace, o.Name, problems)
+			continue
+		}
+		clusterOutputs = append(clusterOutputs, o)
+	}
+	resources.ClusterOutputs = clusterOutputs
+
+	outputs := make(Outputs, 0, len(resources.Outputs))
+	for _, o := range resources.Outputs {
+		o := *o.DeepCopy()
+		if problems := applyOutputTemplate(templates, &o.Spec); len(problems) > 0 {
+			reject("output", o.Namespace, o.Name, problems)
+			continue
+		}
+		outputs = append(outputs, o)
+	}
+	resources.Outputs = outputs
+
+	return resources, errs
+}
@@ -0,0 +1,105 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+)
+
+func TestApplyOutputTemplate(t *testing.T) {
+	templates := []v1beta1.OutputTemplate{
+		{Name: "ltsv", Format: &output.Format{Type: "ltsv"}},
+	}
+	tests := []struct {
+		name         string
+		spec         v1beta1.OutputSpec
+		want         v1beta1.OutputSpec
+		wantProblems int
+	}{
+		{
+			name: "without template",
+			spec: v1beta1.OutputSpec{FileOutput: &output.FileOutputConfig{Path: "/tmp/logs"}},
+			want: v1beta1.OutputSpec{FileOutput: &output.FileOutputConfig{Path: "/tmp/logs"}},
+		},
+		{
+			name: "format of the template",
+			spec: v1beta1.OutputSpec{Template: "ltsv", FileOutput: &output.FileOutputConfig{Path: "/tmp/logs"}},
+			want: v1beta1.OutputSpec{Template: "ltsv", FileOutput: &output.FileOutputConfig{Path: "/tmp/logs", Format: &output.Format{Type: "ltsv"}}},
+		},
+		{
+			name: "own format of the output",
+			spec: v1beta1.OutputSpec{Template: "ltsv", FileOutput: &output.FileOutputConfig{Path: "/tmp/logs", Format: &output.Format{Type: "csv"}}},
+			want: v1beta1.OutputSpec{Template: "ltsv", FileOutput: &output.FileOutputConfig{Path: "/tmp/logs", Format: &output.Format{Type: "csv"}}},
+		},
+		{
+			name:         "unknown template",
+			spec:         v1beta1.OutputSpec{Template: "missing", FileOutput: &output.FileOutputConfig{Path: "/tmp/logs"}},
+			want:         v1beta1.OutputSpec{Template: "missing", FileOutput: &output.FileOutputConfig{Path: "/tmp/logs"}},
+			wantProblems: 1,
+		},
+		{
+			name:         "output without a format",
+			spec:         v1beta1.OutputSpec{Template: "ltsv", NullOutputConfig: output.NewNullOutputConfig()},
+			want:         v1beta1.OutputSpec{Template: "ltsv", NullOutputConfig: output.NewNullOutputConfig()},
+			wantProblems: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			problems := applyOutputTemplate(templates, &tt.spec)
+			if len(problems) != tt.wantProblems {
+				t.Errorf("problems = %v, want %d", problems, tt.wantProblems)
+			}
+			if !reflect.DeepEqual(tt.spec, tt.want) {
+				t.Errorf("spec = %+v, want %+v", tt.spec, tt.want)
+			}
+		})
+	}
+	if templates[0].Format.Type != "ltsv" {
+		t.Error("the template is modified")
+	}
+}
+
+func TestApplyOutputTemplatesToResources(t *testing.T) {
+	resources := testResources(0)
+	resources.Logging.Spec.OutputTemplates = []v1beta1.OutputTemplate{{Name: "ltsv", Format: &output.Format{Type: "ltsv"}}}
+	resources.Outputs = Outputs{
+		{Spec: v1beta1.OutputSpec{Template: "ltsv", FileOutput: &output.FileOutputConfig{Path: "/tmp/a"}}},
+		{Spec: v1beta1.OutputSpec{Template: "missing", FileOutput: &output.FileOutputConfig{Path: "/tmp/b"}}},
+	}
+
+	if _, err := applyOutputTemplatesToResources(resources, logr.Discard()); err == nil {
+		t.Error("expected an error for the output with an unknown template")
+	}
+
+	resources.Logging.Spec.SkipInvalidResources = true
+	applied, err := applyOutputTemplatesToResources(resources, logr.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied.Outputs) != 1 || applied.Outputs[0].Spec.FileOutput.Format == nil {
+		t.Errorf("expected only the templated output, got %+v", applied.Outputs)
+	}
+	if resources.Outputs[0].Spec.FileOutput.Format != nil {
+		t.Error("the original output is modified")
+	}
+}
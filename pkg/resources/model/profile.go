@@ -0,0 +1,35 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ApplyLoggingProfile merges the presets of the LoggingProfile referenced by the logging under its spec
+func ApplyLoggingProfile(ctx context.Context, c client.Reader, logging *v1beta1.Logging) error {
+	if logging.Spec.Profile == "" {
+		return nil
+	}
+	var profile v1beta1.LoggingProfile
+	if err := c.Get(ctx, client.ObjectKey{Name: logging.Spec.Profile}, &profile); err != nil {
+		return errors.WrapIfWithDetails(err, "failed to get logging profile", "profile", logging.Spec.Profile)
+	}
+	return errors.WrapIfWithDetails(logging.ApplyProfile(profile.Spec), "failed to apply logging profile", "profile", logging.Spec.Profile)
+}
@@ -0,0 +1,93 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+)
+
+func TestApplyLoggingProfile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	profile := &v1beta1.LoggingProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "high-throughput"},
+		Spec: v1beta1.LoggingProfileSpec{
+			FluentdSpec: &v1beta1.FluentdSpec{
+				Workers:    4,
+				LogLevel:   "warn",
+				DisablePvc: true,
+				NodeSelector: map[string]string{
+					"pool": "logging",
+				},
+			},
+			FluentbitSpec: &v1beta1.FluentbitSpec{LogLevel: "error"},
+			GlobalOutputSettings: &v1beta1.GlobalOutputSettings{
+				Buffer: &output.Buffer{ChunkLimitSize: "64M", TotalLimitSize: "8G"},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile).Build()
+
+	logging := &v1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: v1beta1.LoggingSpec{
+			Profile: "high-throughput",
+			FluentdSpec: &v1beta1.FluentdSpec{
+				Workers:      2,
+				NodeSelector: map[string]string{"zone": "a"},
+			},
+			GlobalOutputSettings: &v1beta1.GlobalOutputSettings{
+				Buffer: &output.Buffer{TotalLimitSize: "1G"},
+			},
+		},
+	}
+	if err := ApplyLoggingProfile(context.Background(), c, logging); err != nil {
+		t.Fatal(err)
+	}
+
+	fluentd := logging.Spec.FluentdSpec
+	if fluentd.Workers != 2 || fluentd.LogLevel != "warn" {
+		t.Errorf("expected the values of the logging over the preset: workers %d, log level %q", fluentd.Workers, fluentd.LogLevel)
+	}
+	if want := map[string]string{"pool": "logging", "zone": "a"}; !reflect.DeepEqual(fluentd.NodeSelector, want) {
+		t.Errorf("node selector = %v, want %v", fluentd.NodeSelector, want)
+	}
+	// A false value of the logging is the same as an unset one, it cannot revert the preset
+	if !fluentd.DisablePvc {
+		t.Error("expected the preset to set disablePvc")
+	}
+	if logging.Spec.FluentbitSpec != nil {
+		t.Error("the preset must not enable fluentbit")
+	}
+	if want := (&output.Buffer{ChunkLimitSize: "64M", TotalLimitSize: "1G"}); !reflect.DeepEqual(logging.Spec.GlobalOutputSettings.Buffer, want) {
+		t.Errorf("buffer = %+v, want %+v", logging.Spec.GlobalOutputSettings.Buffer, want)
+	}
+
+	logging.Spec.Profile = "missing"
+	if err := ApplyLoggingProfile(context.Background(), c, logging); err == nil {
+		t.Error("expected an error for a missing profile")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/filter"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+)
+
+const quotaThrottlePeriodSeconds = 60
+
+// NamespaceQuota holds the limits set on a namespace through annotations, zero means unlimited
+type NamespaceQuota struct {
+	MaxFlows     int
+	MaxOutputs   int
+	ThrottleRate int
+}
+
+// HasLimits reports whether any limit is set
+func (q NamespaceQuota) HasLimits() bool {
+	return q.MaxFlows > 0 || q.MaxOutputs > 0 || q.ThrottleRate > 0
+}
+
+// NamespaceQuotaFromAnnotations parses the quota annotations of a namespace
+func NamespaceQuotaFromAnnotations(annotations map[string]string) (quota NamespaceQuota, errs error) {
+	for annotation, value := range map[string]*int{
+		v1beta1.MaxFlowsAnnotation:     &quota.MaxFlows,
+		v1beta1.MaxOutputsAnnotation:   &quota.MaxOutputs,
+		v1beta1.ThrottleRateAnnotation: &quota.ThrottleRate,
+	} {
+		raw, ok := annotations[annotation]
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			errs = errors.Append(errs, errors.Errorf("invalid value %q of annotation %s, must be a non-negative integer", raw, annotation))
+			continue
+		}
+		*value = n
+	}
+	return
+}
+
+// quotaThrottleFilter caps the rate of the logs of a flow according to the quota of its namespace
+func quotaThrottleFilter(flowID string, quota NamespaceQuota) (types.Filter, error) {
+	throttle := &filter.Throttle{
+		GroupKey:                 "kubernetes.namespace_name",
+		GroupBucketPeriodSeconds: quotaThrottlePeriodSeconds,
+		GroupBucketLimit:         quota.ThrottleRate * quotaThrottlePeriodSeconds,
+	}
+	return throttle.ToDirective(secret.NewSecretLoader(nil, "", "", nil), flowID+":quota")
+}
@@ -45,6 +45,11 @@ func NewValidationReconciler(
 			})
 		}
 
+		var tlsMinVersion string
+		if settings := resources.Logging.Spec.GlobalOutputSettings; settings != nil {
+			tlsMinVersion = settings.TLSMinVersion
+		}
+
 		for i := range resources.ClusterOutputs {
 			output := &resources.ClusterOutputs[i]
 			registerForPatching(output)
@@ -57,7 +62,21 @@ func NewValidationReconciler(
 			}
 
 			output.Status.Problems = append(output.Status.Problems,
-				validateOutputSpec(output.Spec.OutputSpec, secrets.OutputSecretLoaderForNamespace(output.Namespace))...)
+				validateOutputSpec(output.Spec.OutputSpec, secrets.ClusterOutputSecretLoaderForNamespace(output.Namespace))...)
+			tlsSpec := output.Spec.DeepCopy().OutputSpec
+			applyTLSMinVersion(tlsMinVersion, &tlsSpec)
+			output.Status.Problems = append(output.Status.Problems,
+				applyTLSProfile(resources.Logging.Spec.TLSProfile, &tlsSpec)...)
+			output.Status.Problems = append(output.Status.Problems,
+				applyOutputTemplate(resources.Logging.Spec.OutputTemplates, &output.Spec.DeepCopy().OutputSpec)...)
+			for _, ref := range output.Spec.Failover {
+				if resources.ClusterOutputs.FindByName(ref) == nil {
+					output.Status.Problems = append(output.Status.Problems, fmt.Sprintf("dangling failover output reference: %s", ref))
+				}
+			}
+			if ref := output.Spec.DeadLetter; ref != "" && resources.ClusterOutputs.FindByName(ref) == nil {
+				output.Status.Problems = append(output.Status.Problems, fmt.Sprintf("dangling dead letter output reference: %s", ref))
+			}
 			output.Status.ProblemsCount = len(output.Status.Problems)
 		}
 
@@ -70,6 +89,20 @@ func NewValidationReconciler(
 
 			output.Status.Problems = append(output.Status.Problems,
 				validateOutputSpec(output.Spec, secrets.OutputSecretLoaderForNamespace(output.Namespace))...)
+			tlsSpec := output.Spec.DeepCopy()
+			applyTLSMinVersion(tlsMinVersion, tlsSpec)
+			output.Status.Problems = append(output.Status.Problems,
+				applyTLSProfile(resources.Logging.Spec.TLSProfile, tlsSpec)...)
+			output.Status.Problems = append(output.Status.Problems,
+				applyOutputTemplate(resources.Logging.Spec.OutputTemplates, output.Spec.DeepCopy())...)
+			for _, ref := range output.Spec.Failover {
+				if resources.Outputs.FindByNamespacedName(output.Namespace, ref) == nil {
+					output.Status.Problems = append(output.Status.Problems, fmt.Sprintf("dangling failover output reference: %s", ref))
+				}
+			}
+			if ref := output.Spec.DeadLetter; ref != "" && resources.Outputs.FindByNamespacedName(output.Namespace, ref) == nil {
+				output.Status.Problems = append(output.Status.Problems, fmt.Sprintf("dangling dead letter output reference: %s", ref))
+			}
 			output.Status.ProblemsCount = len(output.Status.Problems)
 		}
 
@@ -88,6 +121,11 @@ func NewValidationReconciler(
 				if output := resources.ClusterOutputs.FindByName(ref); output != nil {
 					flow.Status.Active = utils.BoolPointer(true)
 					output.Status.Active = utils.BoolPointer(true)
+					for _, failoverRef := range chainedOutputRefs(output.Spec.OutputSpec) {
+						if failover := resources.ClusterOutputs.FindByName(failoverRef); failover != nil {
+							failover.Status.Active = utils.BoolPointer(true)
+						}
+					}
 				} else {
 					flow.Status.Problems = append(flow.Status.Problems, fmt.Sprintf("dangling global output reference: %s", ref))
 				}
@@ -110,6 +148,11 @@ func NewValidationReconciler(
 				if output := resources.ClusterOutputs.FindByName(ref); output != nil {
 					flow.Status.Active = utils.BoolPointer(true)
 					output.Status.Active = utils.BoolPointer(true)
+					for _, failoverRef := range chainedOutputRefs(output.Spec.OutputSpec) {
+						if failover := resources.ClusterOutputs.FindByName(failoverRef); failover != nil {
+							failover.Status.Active = utils.BoolPointer(true)
+						}
+					}
 				} else {
 					flow.Status.Problems = append(flow.Status.Problems, fmt.Sprintf("dangling global output reference: %s", ref))
 				}
@@ -119,6 +162,11 @@ func NewValidationReconciler(
 				if output := resources.Outputs.FindByNamespacedName(flow.Namespace, ref); output != nil {
 					flow.Status.Active = utils.BoolPointer(true)
 					output.Status.Active = utils.BoolPointer(true)
+					for _, failoverRef := range chainedOutputRefs(output.Spec) {
+						if failover := resources.Outputs.FindByNamespacedName(flow.Namespace, failoverRef); failover != nil {
+							failover.Status.Active = utils.BoolPointer(true)
+						}
+					}
 				} else {
 					flow.Status.Problems = append(flow.Status.Problems, fmt.Sprintf("dangling local output reference: %s", ref))
 				}
@@ -126,6 +174,40 @@ func NewValidationReconciler(
 			flow.Status.ProblemsCount = len(flow.Status.Problems)
 		}
 
+		rejected := fmt.Sprintf("not accepted: cluster resources are only allowed from the %s namespace and %s",
+			resources.Logging.Spec.ControlNamespace, strings.Join(resources.Logging.Spec.AllowClusterResourcesFromNamespaces, ", "))
+		for i := range resources.RejectedClusterOutputs {
+			output := &resources.RejectedClusterOutputs[i]
+			registerForPatching(output)
+			output.Status.Active = utils.BoolPointer(false)
+			output.Status.Problems = []string{rejected}
+			output.Status.ProblemsCount = len(output.Status.Problems)
+		}
+		for i := range resources.RejectedClusterFlows {
+			flow := &resources.RejectedClusterFlows[i]
+			registerForPatching(flow)
+			flow.Status.Active = utils.BoolPointer(false)
+			flow.Status.Problems = []string{rejected}
+			flow.Status.ProblemsCount = len(flow.Status.Problems)
+		}
+
+		for i := range resources.RejectedOutputs {
+			output := &resources.RejectedOutputs[i]
+			registerForPatching(output)
+			output.Status.Active = utils.BoolPointer(false)
+			output.Status.Problems = []string{fmt.Sprintf("not accepted: the namespace is limited to %d outputs",
+				resources.NamespaceQuotas[output.Namespace].MaxOutputs)}
+			output.Status.ProblemsCount = len(output.Status.Problems)
+		}
+		for i := range resources.RejectedFlows {
+			flow := &resources.RejectedFlows[i]
+			registerForPatching(flow)
+			flow.Status.Active = utils.BoolPointer(false)
+			flow.Status.Problems = []string{fmt.Sprintf("not accepted: the namespace is limited to %d flows",
+				resources.NamespaceQuotas[flow.Namespace].MaxFlows)}
+			flow.Status.ProblemsCount = len(flow.Status.Problems)
+		}
+
 		var errs error
 		for _, req := range patchRequests {
 			if req.IsEmptyPatch() {
@@ -145,6 +227,9 @@ func validateOutputSpec(spec v1beta1.OutputSpec, secrets secret.SecretLoader) (p
 	var configuredFields []string
 	it := mirror.StructRange(spec)
 	for it.Next() {
+		if it.Field().Name == "TLSFrom" {
+			continue
+		}
 		if it.Field().Type.Kind() == reflect.Ptr && !it.Value().IsNil() {
 			configuredFields = append(configuredFields, jsonFieldName(it.Field()))
 			it := mirror.StructRange(it.Value().Elem().Interface())
@@ -163,7 +248,9 @@ func validateOutputSpec(spec v1beta1.OutputSpec, secrets secret.SecretLoader) (p
 	case 0:
 		problems = append(problems, "no output target configured")
 	case 1:
-		// OK
+		if _, err := applyTLSFrom(spec, secrets); err != nil {
+			problems = append(problems, err.Error())
+		}
 	default:
 		problems = append(problems, fmt.Sprintf("multiple output targets configured: %s", configuredFields))
 	}
@@ -188,3 +275,13 @@ func jsonFieldName(f reflect.StructField) string {
 	}
 	return f.Name
 }
+
+// chainedOutputRefs returns the failover and dead letter outputs of the output, in a new slice so that the spec
+// is never appended to
+func chainedOutputRefs(spec v1beta1.OutputSpec) []string {
+	refs := append([]string{}, spec.Failover...)
+	if spec.DeadLetter != "" {
+		refs = append(refs, spec.DeadLetter)
+	}
+	return refs
+}
@@ -0,0 +1,39 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func TestChainedOutputRefs(t *testing.T) {
+	// Spare capacity would be written to by a plain append
+	failover := make([]string, 1, 2)
+	failover[0] = "secondary"
+	spec := v1beta1.OutputSpec{Failover: failover, DeadLetter: "dead"}
+
+	if got, want := chainedOutputRefs(spec), []string{"secondary", "dead"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("chainedOutputRefs() = %v, want %v", got, want)
+	}
+	if spare := failover[:2][1]; spare != "" {
+		t.Errorf("the failover of the spec is appended to: %q", spare)
+	}
+	if got, want := chainedOutputRefs(v1beta1.OutputSpec{Failover: []string{"secondary"}}), []string{"secondary"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("chainedOutputRefs() without dead letter = %v, want %v", got, want)
+	}
+}
@@ -21,7 +21,10 @@ import (
 	"emperror.dev/errors"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 func NewLoggingResourceRepository(client client.Reader) *LoggingResourceRepository {
@@ -45,33 +48,119 @@ func (r LoggingResourceRepository) LoggingResourcesFor(ctx context.Context, logg
 	res.ClusterOutputs, err = r.ClusterOutputsFor(ctx, logging)
 	errs = errors.Append(errs, err)
 
-	watchNamespaces := logging.Spec.WatchNamespaces
-	if len(watchNamespaces) == 0 {
-		var nsList corev1.NamespaceList
-		if err := r.Client.List(ctx, &nsList); err != nil {
-			errs = errors.Append(errs, errors.WrapIf(err, "listing namespaces"))
-			return
+	if len(logging.Spec.AllowClusterResourcesFromNamespaces) > 0 && !logging.Spec.AllowClusterResourcesFromAllNamespaces {
+		var clusterFlows []v1beta1.ClusterFlow
+		for _, f := range res.ClusterFlows {
+			if clusterResourceAllowed(logging, f.Namespace) {
+				clusterFlows = append(clusterFlows, f)
+			} else {
+				res.RejectedClusterFlows = append(res.RejectedClusterFlows, f)
+			}
 		}
+		res.ClusterFlows = clusterFlows
 
-		for _, i := range nsList.Items {
-			watchNamespaces = append(watchNamespaces, i.Name)
+		var clusterOutputs ClusterOutputs
+		for _, o := range res.ClusterOutputs {
+			if clusterResourceAllowed(logging, o.Namespace) {
+				clusterOutputs = append(clusterOutputs, o)
+			} else {
+				res.RejectedClusterOutputs = append(res.RejectedClusterOutputs, o)
+			}
 		}
+		res.ClusterOutputs = clusterOutputs
+	}
+
+	watchNamespaces, err := r.WatchNamespacesFor(ctx, logging)
+	if err != nil {
+		errs = errors.Append(errs, err)
+		return
 	}
+	watchNamespaces = withoutExcluded(watchNamespaces, logging.Spec.ExcludeWatchNamespaces)
 	sort.Strings(watchNamespaces)
 
 	for _, ns := range watchNamespaces {
+		quota, valid, err := r.NamespaceQuotaFor(ctx, ns)
+		errs = errors.Append(errs, err)
+		if err == nil && !valid {
+			continue
+		}
+		if quota.HasLimits() {
+			if res.NamespaceQuotas == nil {
+				res.NamespaceQuotas = make(map[string]NamespaceQuota)
+			}
+			res.NamespaceQuotas[ns] = quota
+		}
+
 		flows, err := r.FlowsInNamespaceFor(ctx, ns, logging)
+		if quota.MaxFlows > 0 && len(flows) > quota.MaxFlows {
+			res.RejectedFlows = append(res.RejectedFlows, flows[quota.MaxFlows:]...)
+			flows = flows[:quota.MaxFlows]
+		}
 		res.Flows = append(res.Flows, flows...)
 		errs = errors.Append(errs, err)
 
 		outputs, err := r.OutputsInNamespaceFor(ctx, ns, logging)
+		if quota.MaxOutputs > 0 && len(outputs) > quota.MaxOutputs {
+			res.RejectedOutputs = append(res.RejectedOutputs, outputs[quota.MaxOutputs:]...)
+			outputs = outputs[:quota.MaxOutputs]
+		}
 		res.Outputs = append(res.Outputs, outputs...)
 		errs = errors.Append(errs, err)
 	}
+	sort.SliceStable(res.Flows, func(i, j int) bool {
+		return lessByPriority(res.Flows[i].Spec.Priority, res.Flows[j].Spec.Priority, &res.Flows[i], &res.Flows[j])
+	})
 
 	return
 }
 
+// WatchNamespacesFor returns the namespaces listed in watchNamespaces together with the ones matching watchNamespaceSelector,
+// or all namespaces when neither is set
+func (r LoggingResourceRepository) WatchNamespacesFor(ctx context.Context, logging v1beta1.Logging) ([]string, error) {
+	watchNamespaces := append([]string(nil), logging.Spec.WatchNamespaces...)
+	if len(watchNamespaces) > 0 && logging.Spec.WatchNamespaceSelector == nil {
+		return watchNamespaces, nil
+	}
+
+	var opts []client.ListOption
+	if logging.Spec.WatchNamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(logging.Spec.WatchNamespaceSelector)
+		if err != nil {
+			return nil, errors.WrapIf(err, "invalid watchNamespaceSelector")
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+	}
+	var nsList corev1.NamespaceList
+	if err := r.Client.List(ctx, &nsList, opts...); err != nil {
+		return nil, errors.WrapIf(err, "listing namespaces")
+	}
+	for _, i := range nsList.Items {
+		if !contains(watchNamespaces, i.Name) {
+			watchNamespaces = append(watchNamespaces, i.Name)
+		}
+	}
+	return watchNamespaces, nil
+}
+
+// NamespaceQuotaFor returns the quota set on the namespace through annotations. A namespace with malformed quota
+// annotations is logged and reported as invalid, so that it is skipped instead of failing the whole logging or
+// getting rid of its limits.
+func (r LoggingResourceRepository) NamespaceQuotaFor(ctx context.Context, namespace string) (quota NamespaceQuota, valid bool, err error) {
+	var ns corev1.Namespace
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return NamespaceQuota{}, true, nil
+		}
+		return NamespaceQuota{}, false, errors.WrapIfWithDetails(err, "getting namespace", "namespace", namespace)
+	}
+	quota, err = NamespaceQuotaFromAnnotations(ns.Annotations)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "skipping namespace with invalid quota", "namespace", namespace)
+		return NamespaceQuota{}, false, nil
+	}
+	return quota, true, nil
+}
+
 func (r LoggingResourceRepository) ClusterFlowsFor(ctx context.Context, logging v1beta1.Logging) ([]v1beta1.ClusterFlow, error) {
 	var list v1beta1.ClusterFlowList
 	if err := r.Client.List(ctx, &list, clusterResourceListOpts(logging)...); err != nil {
@@ -79,7 +168,7 @@ func (r LoggingResourceRepository) ClusterFlowsFor(ctx context.Context, logging
 	}
 
 	sort.Slice(list.Items, func(i, j int) bool {
-		return lessByNamespacedName(&list.Items[i], &list.Items[j])
+		return lessByPriority(list.Items[i].Spec.Priority, list.Items[j].Spec.Priority, &list.Items[i], &list.Items[j])
 	})
 
 	var res []v1beta1.ClusterFlow
@@ -117,7 +206,7 @@ func (r LoggingResourceRepository) FlowsInNamespaceFor(ctx context.Context, name
 	}
 
 	sort.Slice(list.Items, func(i, j int) bool {
-		return lessByNamespacedName(&list.Items[i], &list.Items[j])
+		return lessByPriority(list.Items[i].Spec.Priority, list.Items[j].Spec.Priority, &list.Items[i], &list.Items[j])
 	})
 
 	var res []v1beta1.Flow
@@ -150,12 +239,20 @@ func (r LoggingResourceRepository) OutputsInNamespaceFor(ctx context.Context, na
 
 func clusterResourceListOpts(logging v1beta1.Logging) []client.ListOption {
 	var opts []client.ListOption
-	if !logging.Spec.AllowClusterResourcesFromAllNamespaces {
+	// Resources from other namespaces are listed as well to be able to report them as rejected
+	if !logging.Spec.AllowClusterResourcesFromAllNamespaces && len(logging.Spec.AllowClusterResourcesFromNamespaces) == 0 {
 		opts = append(opts, client.InNamespace(logging.Spec.ControlNamespace))
 	}
 	return opts
 }
 
+// clusterResourceAllowed reports whether cluster resources are accepted from the namespace
+func clusterResourceAllowed(logging v1beta1.Logging, namespace string) bool {
+	return logging.Spec.AllowClusterResourcesFromAllNamespaces ||
+		namespace == logging.Spec.ControlNamespace ||
+		contains(logging.Spec.AllowClusterResourcesFromNamespaces, namespace)
+}
+
 func lessByNamespacedName(a, b interface {
 	GetNamespace() string
 	GetName() string
@@ -165,3 +262,36 @@ func lessByNamespacedName(a, b interface {
 	}
 	return a.GetName() < b.GetName()
 }
+
+// lessByPriority orders by descending priority, then by namespace and name
+func lessByPriority(priorityA, priorityB int32, a, b interface {
+	GetNamespace() string
+	GetName() string
+}) bool {
+	if priorityA != priorityB {
+		return priorityA > priorityB
+	}
+	return lessByNamespacedName(a, b)
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+func withoutExcluded(namespaces []string, excluded []string) []string {
+	if len(excluded) == 0 {
+		return namespaces
+	}
+	var res []string
+	for _, ns := range namespaces {
+		if !contains(excluded, ns) {
+			res = append(res, ns)
+		}
+	}
+	return res
+}
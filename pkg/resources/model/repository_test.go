@@ -0,0 +1,117 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+func newRepositoryClient(t *testing.T, objects ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+func TestLoggingResourcesForFlowPriority(t *testing.T) {
+	flow := func(namespace, name string, priority int32) client.Object {
+		return &v1beta1.Flow{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       v1beta1.FlowSpec{Priority: priority},
+		}
+	}
+	clusterFlow := func(name string, priority int32) client.Object {
+		return &v1beta1.ClusterFlow{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "logging"},
+			Spec:       v1beta1.ClusterFlowSpec{Priority: priority},
+		}
+	}
+	c := newRepositoryClient(t,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+		flow("a", "low", -1),
+		flow("a", "default", 0),
+		flow("b", "default", 0),
+		flow("b", "high", 10),
+		clusterFlow("default", 0),
+		clusterFlow("audit", 5),
+	)
+	logging := v1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       v1beta1.LoggingSpec{ControlNamespace: "logging"},
+	}
+
+	resources, err := NewLoggingResourceRepository(c).LoggingResourcesFor(context.Background(), logging)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var flows []string
+	for _, f := range resources.Flows {
+		flows = append(flows, f.Namespace+"/"+f.Name)
+	}
+	if want := []string{"b/high", "a/default", "b/default", "a/low"}; !reflect.DeepEqual(flows, want) {
+		t.Errorf("flows = %v, want %v", flows, want)
+	}
+	var clusterFlows []string
+	for _, f := range resources.ClusterFlows {
+		clusterFlows = append(clusterFlows, f.Name)
+	}
+	if want := []string{"audit", "default"}; !reflect.DeepEqual(clusterFlows, want) {
+		t.Errorf("clusterflows = %v, want %v", clusterFlows, want)
+	}
+}
+
+func TestLoggingResourcesForInvalidQuota(t *testing.T) {
+	c := newRepositoryClient(t,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a", Annotations: map[string]string{v1beta1.MaxFlowsAnnotation: "many"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "b", Annotations: map[string]string{v1beta1.MaxFlowsAnnotation: "1"}}},
+		&v1beta1.Flow{ObjectMeta: metav1.ObjectMeta{Name: "flow", Namespace: "a"}},
+		&v1beta1.Flow{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "b"}},
+		&v1beta1.Flow{ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: "b"}},
+	)
+	logging := v1beta1.Logging{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       v1beta1.LoggingSpec{ControlNamespace: "logging"},
+	}
+
+	resources, err := NewLoggingResourceRepository(c).LoggingResourcesFor(context.Background(), logging)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources.Flows) != 1 || resources.Flows[0].Namespace != "b" {
+		t.Errorf("expected only the first flow of the valid namespace, got %+v", resources.Flows)
+	}
+	if len(resources.RejectedFlows) != 1 {
+		t.Errorf("expected the second flow of the valid namespace to be rejected, got %+v", resources.RejectedFlows)
+	}
+	if _, ok := resources.NamespaceQuotas["a"]; ok {
+		t.Error("unexpected quota of the invalid namespace")
+	}
+}
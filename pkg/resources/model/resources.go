@@ -24,6 +24,14 @@ type LoggingResources struct {
 	Flows          []v1beta1.Flow
 	ClusterOutputs ClusterOutputs
 	ClusterFlows   []v1beta1.ClusterFlow
+	// Cluster resources found outside the namespaces they are allowed from
+	RejectedClusterOutputs ClusterOutputs
+	RejectedClusterFlows   []v1beta1.ClusterFlow
+	// Namespaced resources beyond the quota of their namespace
+	RejectedFlows   []v1beta1.Flow
+	RejectedOutputs Outputs
+	// Quotas of the watched namespaces that have any limit set
+	NamespaceQuotas map[string]NamespaceQuota
 }
 
 type ClusterOutputs []v1beta1.ClusterOutput
@@ -49,3 +57,24 @@ func (c Outputs) FindByNamespacedName(namespace string, name string) *v1beta1.Ou
 	}
 	return nil
 }
+
+// OutputSpecFinder looks up the spec of an output by name
+type OutputSpecFinder func(name string) *v1beta1.OutputSpec
+
+func (c ClusterOutputs) SpecFinder() OutputSpecFinder {
+	return func(name string) *v1beta1.OutputSpec {
+		if output := c.FindByName(name); output != nil {
+			return &output.Spec.OutputSpec
+		}
+		return nil
+	}
+}
+
+func (c Outputs) SpecFinder(namespace string) OutputSpecFinder {
+	return func(name string) *v1beta1.OutputSpec {
+		if output := c.FindByNamespacedName(namespace, name); output != nil {
+			return &output.Spec
+		}
+		return nil
+	}
+}
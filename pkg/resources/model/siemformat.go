@@ -0,0 +1,239 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/logging-operator/pkg/mirror"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/filter"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+)
+
+// siemMessageKey is the field the CEF or LEEF message is rendered into for the output to send
+const siemMessageKey = "siem_message"
+
+var (
+	defaultCEFExtensions = map[string]string{
+		"msg":               "log",
+		"dvchost":           "kubernetes.host",
+		"deviceProcessName": "kubernetes.container_name",
+		"cs1":               "kubernetes.namespace_name",
+		"cs2":               "kubernetes.pod_name",
+	}
+	defaultCEFStaticExtensions = map[string]string{
+		"cs1Label": "namespace",
+		"cs2Label": "pod",
+	}
+	defaultLEEFExtensions = map[string]string{
+		"msg":       "log",
+		"node":      "kubernetes.host",
+		"container": "kubernetes.container_name",
+		"namespace": "kubernetes.namespace_name",
+		"pod":       "kubernetes.pod_name",
+	}
+)
+
+// siemFormat returns the format section of the output plugin of the spec, if it is a cef or leef format
+func siemFormat(spec v1beta1.OutputSpec) (formatType string, siem *output.SIEMFormat, found bool) {
+	it := mirror.StructRange(spec)
+	for it.Next() {
+		if it.Field().Type.Kind() != reflect.Ptr || it.Value().IsNil() || it.Value().Elem().Kind() != reflect.Struct {
+			continue
+		}
+		field := it.Value().Elem().FieldByName("Format")
+		if !field.IsValid() {
+			continue
+		}
+		switch format := field.Interface().(type) {
+		case *output.Format:
+			if format != nil {
+				formatType, siem = format.Type, format.SIEM
+			}
+		case *output.FormatRfc5424:
+			if format != nil {
+				formatType, siem = format.Type, format.SIEM
+			}
+		}
+	}
+	return formatType, siem, formatType == output.FormatCEF || formatType == output.FormatLEEF
+}
+
+// applySIEMFormat replaces the cef or leef format of the output with one sending the message rendered by the
+// returned record_transformer filter. The spec is returned as is if the output has no such format.
+func applySIEMFormat(spec v1beta1.OutputSpec, id string) (v1beta1.OutputSpec, types.Filter, error) {
+	formatType, siem, found := siemFormat(spec)
+	if !found {
+		return spec, nil, nil
+	}
+	if siem == nil {
+		siem = &output.SIEMFormat{}
+	}
+	message, err := siemMessage(formatType, siem)
+	if err != nil {
+		return spec, nil, err
+	}
+
+	spec = *spec.DeepCopy()
+	it := mirror.StructRange(spec)
+	for it.Next() {
+		if it.Field().Type.Kind() != reflect.Ptr || it.Value().IsNil() || it.Value().Elem().Kind() != reflect.Struct {
+			continue
+		}
+		field := it.Value().Elem().FieldByName("Format")
+		if !field.IsValid() {
+			continue
+		}
+		switch format := field.Interface().(type) {
+		case *output.Format:
+			if format != nil {
+				*format = output.Format{Type: "single_value", AddNewline: format.AddNewline, MessageKey: siemMessageKey}
+			}
+		case *output.FormatRfc5424:
+			if format != nil {
+				format.Type, format.SIEM, format.LogField = "", nil, siemMessageKey
+			}
+		}
+	}
+
+	transformer, err := (&filter.RecordTransformer{
+		EnableRuby: true,
+		Records: []filter.Record{
+			{siemMessageKey: "${" + message + "}"},
+		},
+	}).ToDirective(nil, id+":format")
+	if err != nil {
+		return spec, nil, err
+	}
+	return spec, transformer, nil
+}
+
+// siemMessage returns the ruby expression of the CEF or LEEF message of the record
+func siemMessage(formatType string, siem *output.SIEMFormat) (string, error) {
+	vendor := valueOrDefault(siem.DeviceVendor, "Kubernetes")
+	product := valueOrDefault(siem.DeviceProduct, "logging-operator")
+	version := valueOrDefault(siem.DeviceVersion, "1.0")
+	eventID := recordField(valueOrDefault(siem.EventIDField, "kubernetes.container_name"))
+	name := recordField(valueOrDefault(siem.NameField, "kubernetes.container_name"))
+	severity := rubyString(valueOrDefault(siem.Severity, "5"))
+	if siem.SeverityField != "" {
+		severity = fmt.Sprintf("(%s || %s)", recordField(siem.SeverityField), severity)
+	}
+
+	var defaults map[string]string
+	var header []string
+	var escapeValue func(expression string) string
+	var separator string
+	fields := map[string]string{}
+	switch formatType {
+	case output.FormatCEF:
+		defaults = defaultCEFExtensions
+		escapeHeader := func(expression string) string {
+			return expression + `.to_s.gsub(/[\\|]/, "\\" => "\\\\", "|" => "\\|")`
+		}
+		escapeValue = func(expression string) string {
+			return expression + `.to_s.gsub(/[\\=\r\n]/, "\\" => "\\\\", "=" => "\\=", "\r" => "\\r", "\n" => "\\n")`
+		}
+		separator = " "
+		header = []string{
+			rubyString("CEF:0"),
+			escapeHeader(rubyString(vendor)),
+			escapeHeader(rubyString(product)),
+			escapeHeader(rubyString(version)),
+			escapeHeader(eventID),
+			escapeHeader(name),
+			escapeHeader(severity),
+		}
+		for k, v := range defaultCEFStaticExtensions {
+			fields[k] = escapeValue(rubyString(v))
+		}
+	case output.FormatLEEF:
+		defaults = defaultLEEFExtensions
+		escapeHeader := func(expression string) string {
+			return expression + `.to_s.tr("\r\n|", "   ")`
+		}
+		escapeValue = func(expression string) string {
+			return expression + `.to_s.tr("\r\n^", "   ")`
+		}
+		separator = "^"
+		header = []string{
+			rubyString("LEEF:2.0"),
+			escapeHeader(rubyString(vendor)),
+			escapeHeader(rubyString(product)),
+			escapeHeader(rubyString(version)),
+			escapeHeader(eventID),
+			rubyString(separator),
+		}
+		fields["sev"] = escapeValue(severity)
+	}
+
+	mappings := map[string]string{}
+	for k, v := range defaults {
+		mappings[k] = v
+	}
+	for k, v := range siem.Extensions {
+		mappings[k] = v
+	}
+	for k, v := range mappings {
+		if v == "" {
+			delete(fields, k)
+			continue
+		}
+		fields[k] = escapeValue(recordField(v))
+	}
+	for k, v := range siem.StaticExtensions {
+		fields[k] = escapeValue(rubyString(v))
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if k == "" || strings.ContainsAny(k, " =^|\\\r\n\t") {
+			return "", errors.Errorf("invalid %s extension key %q", formatType, k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	extensions := make([]string, len(keys))
+	for i, k := range keys {
+		extensions[i] = rubyString(k+"=") + " + " + fields[k]
+	}
+
+	return fmt.Sprintf("([%s].join(%s) + %s + [%s].join(%s))",
+		strings.Join(header, ", "), rubyString("|"), rubyString("|"),
+		strings.Join(extensions, ", "), rubyString(separator)), nil
+}
+
+// recordField returns the ruby expression of the field of the record, delimited by '.'
+func recordField(path string) string {
+	var keys []interface{}
+	for _, key := range strings.Split(path, ".") {
+		keys = append(keys, key)
+	}
+	return recordDig(keys...)
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,151 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+)
+
+func TestSIEMMessage(t *testing.T) {
+	// The default extensions are left out to keep the expected messages short
+	noDefaults := map[string]string{
+		"dvchost": "", "deviceProcessName": "", "cs1": "", "cs2": "",
+		"node": "", "container": "", "namespace": "", "pod": "",
+	}
+	const (
+		cefHeader  = `.to_s.gsub(/[\\|]/, "\\" => "\\\\", "|" => "\\|")`
+		cefValue   = `.to_s.gsub(/[\\=\r\n]/, "\\" => "\\\\", "=" => "\\=", "\r" => "\\r", "\n" => "\\n")`
+		leefHeader = `.to_s.tr("\r\n|", "   ")`
+		leefValue  = `.to_s.tr("\r\n^", "   ")`
+		container  = `(record.dig("kubernetes", "container_name") rescue nil)`
+	)
+	tests := []struct {
+		name       string
+		formatType string
+		siem       *output.SIEMFormat
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "cef",
+			formatType: output.FormatCEF,
+			siem:       &output.SIEMFormat{DeviceVendor: "Example|Corp", SeverityField: "level", Extensions: noDefaults},
+			want: `(["CEF:0", "Example\x7cCorp"` + cefHeader + `, "logging-operator"` + cefHeader + `, "1.0"` + cefHeader +
+				`, ` + container + cefHeader + `, ` + container + cefHeader + `, ((record.dig("level") rescue nil) || "5")` + cefHeader +
+				`].join("\x7c") + "\x7c" + ["cs1Label=" + "namespace"` + cefValue + `, "cs2Label=" + "pod"` + cefValue +
+				`, "msg=" + (record.dig("log") rescue nil)` + cefValue + `].join("\x20"))`,
+		},
+		{
+			name:       "leef",
+			formatType: output.FormatLEEF,
+			siem:       &output.SIEMFormat{Extensions: noDefaults, StaticExtensions: map[string]string{"cat": "k8s"}},
+			want: `(["LEEF:2.0", "Kubernetes"` + leefHeader + `, "logging-operator"` + leefHeader + `, "1.0"` + leefHeader +
+				`, ` + container + leefHeader + `, "\x5e"].join("\x7c") + "\x7c" + ["cat=" + "k8s"` + leefValue +
+				`, "msg=" + (record.dig("log") rescue nil)` + leefValue + `, "sev=" + "5"` + leefValue + `].join("\x5e"))`,
+		},
+		{
+			name:       "invalid extension key",
+			formatType: output.FormatCEF,
+			siem:       &output.SIEMFormat{Extensions: map[string]string{"bad key": "log"}},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := siemMessage(tt.formatType, tt.siem)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("siemMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("siemMessage() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplySIEMFormat(t *testing.T) {
+	newline := true
+	tests := []struct {
+		name       string
+		spec       v1beta1.OutputSpec
+		want       v1beta1.OutputSpec
+		wantFilter bool
+	}{
+		{
+			name: "other format",
+			spec: v1beta1.OutputSpec{HTTPOutput: &output.HTTPOutputConfig{Format: &output.Format{Type: "json"}}},
+			want: v1beta1.OutputSpec{HTTPOutput: &output.HTTPOutputConfig{Format: &output.Format{Type: "json"}}},
+		},
+		{
+			name: "cef",
+			spec: v1beta1.OutputSpec{HTTPOutput: &output.HTTPOutputConfig{
+				Format: &output.Format{Type: output.FormatCEF, AddNewline: &newline, SIEM: &output.SIEMFormat{DeviceVendor: "Example"}},
+			}},
+			want: v1beta1.OutputSpec{HTTPOutput: &output.HTTPOutputConfig{
+				Format: &output.Format{Type: "single_value", AddNewline: &newline, MessageKey: siemMessageKey},
+			}},
+			wantFilter: true,
+		},
+		{
+			name: "leef over syslog",
+			spec: v1beta1.OutputSpec{SyslogOutputConfig: &output.SyslogOutputConfig{
+				Host:   "qradar",
+				Format: &output.FormatRfc5424{Type: output.FormatLEEF},
+			}},
+			want: v1beta1.OutputSpec{SyslogOutputConfig: &output.SyslogOutputConfig{
+				Host:   "qradar",
+				Format: &output.FormatRfc5424{LogField: siemMessageKey},
+			}},
+			wantFilter: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.spec.DeepCopy()
+			got, filter, err := applySIEMFormat(tt.spec, "flow:app:flow:output:app:siem")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applySIEMFormat() spec = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(&tt.spec, original) {
+				t.Error("the original spec is modified")
+			}
+			if !tt.wantFilter {
+				if filter != nil {
+					t.Errorf("unexpected filter %+v", filter)
+				}
+				return
+			}
+			if meta := filter.GetPluginMeta(); meta.Type != "record_transformer" || meta.Id != "flow:app:flow:output:app:siem:format" {
+				t.Errorf("unexpected filter %+v", meta)
+			}
+			if params := filter.GetParams(); !reflect.DeepEqual(params, types.Params{"enable_ruby": "true"}) {
+				t.Errorf("unexpected filter params %v", params)
+			}
+			if _, ok := filter.GetSections()[0].GetParams()[siemMessageKey]; !ok {
+				t.Errorf("the message is not rendered into %s", siemMessageKey)
+			}
+		})
+	}
+}
@@ -16,9 +16,11 @@ package model
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 
 	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/mirror"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/common"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/input"
@@ -28,10 +30,23 @@ import (
 	"github.com/banzaicloud/operator-tools/pkg/secret"
 	"github.com/banzaicloud/operator-tools/pkg/utils"
 	"github.com/go-logr/logr"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 )
 
-func CreateSystem(resources LoggingResources, secrets SecretLoaderFactory, logger logr.Logger) (*types.System, error) {
+// CreateSystem builds the fluentd configuration model of the logging. Flows are reused from the cache when it is
+// given and the resources they are built from are unchanged.
+func CreateSystem(resources LoggingResources, secrets SecretLoaderFactory, cache *FlowCache, logger logr.Logger) (*types.System, error) {
 	logging := resources.Logging
+	resources, err := applyTLSProfileToResources(logging.Spec.TLSProfile, applyTLSMinVersionToResources(resources), logger)
+	if err != nil {
+		return nil, err
+	}
+	resources, err = applyOutputTemplatesToResources(resources, logger)
+	if err != nil {
+		return nil, err
+	}
+	classResources := newLogClassResources(resources)
+	resources = classResources.forClass(v1beta1.LogClassNormal)
 
 	var forwardInput *input.ForwardInputConfig
 	if logging.Spec.FluentdSpec.ForwardInputConfig != nil {
@@ -48,6 +63,9 @@ func CreateSystem(resources LoggingResources, secrets SecretLoaderFactory, logge
 			PrivateKeyPath: "/fluentd/tls/tls.key",
 			ClientCertAuth: true,
 		}
+		if profile, ok := tlsProfiles[logging.Spec.TLSProfile]; ok {
+			profile.applyTransport(forwardInput.Transport)
+		}
 		forwardInput.Security = &common.Security{
 			SelfHostname: "fluentd",
 			SharedKey:    logging.Spec.FluentdSpec.TLS.SharedKey,
@@ -76,8 +94,30 @@ func CreateSystem(resources LoggingResources, secrets SecretLoaderFactory, logge
 
 	builder := types.NewSystemBuilder(rootInput, globalFilters, router)
 
+	if httpInput := logging.Spec.FluentdSpec.HTTPInput; httpInput != nil && httpInput.Enabled {
+		err := registerHTTPInput(builder, httpInput, secrets.OutputSecretLoaderForNamespace(logging.Spec.ControlNamespace))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if testMessages := logging.Spec.FluentdSpec.TestMessages; testMessages != nil && testMessages.Enabled {
+		err := registerTestMessagesInput(builder, logging, secrets.OutputSecretLoaderForNamespace(logging.Spec.ControlNamespace))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	live := make(map[k8stypes.UID]bool)
+	keys := newFlowKeys(resources, secrets)
 	for _, flowCr := range resources.Flows {
-		flow, err := FlowForFlow(flowCr, resources.ClusterOutputs, resources.Outputs, secrets)
+		live[flowCr.UID] = true
+		flow, err := cachedFlow(cache, flowCr.UID, func() (string, error) {
+			return keys.flow(flowCr)
+		}, func() (*types.Flow, error) {
+			flowResources := classResources.forClass(flowCr.Spec.LogClass)
+			return FlowForFlow(flowCr, flowResources.ClusterOutputs, flowResources.Outputs, secrets)
+		})
 		if err != nil {
 			if logging.Spec.SkipInvalidResources {
 				logger.Error(err, "Flow contains errors.")
@@ -85,13 +125,28 @@ func CreateSystem(resources LoggingResources, secrets SecretLoaderFactory, logge
 				return nil, err
 			}
 		}
+		if flow == nil {
+			continue
+		}
+		if quota := resources.NamespaceQuotas[flowCr.Namespace]; quota.ThrottleRate > 0 {
+			throttle, err := quotaThrottleFilter(flow.FlowID, quota)
+			if err != nil {
+				return nil, err
+			}
+			flow.Filters = append([]types.Filter{throttle}, flow.Filters...)
+		}
 		err = builder.RegisterFlow(flow)
 		if err != nil {
 			return nil, err
 		}
 	}
 	for _, flowCr := range resources.ClusterFlows {
-		flow, err := FlowForClusterFlow(flowCr, resources.ClusterOutputs, secrets)
+		live[flowCr.UID] = true
+		flow, err := cachedFlow(cache, flowCr.UID, func() (string, error) {
+			return keys.clusterFlow(flowCr)
+		}, func() (*types.Flow, error) {
+			return FlowForClusterFlow(flowCr, classResources.forClass(flowCr.Spec.LogClass).ClusterOutputs, secrets)
+		})
 		if err != nil {
 			if logging.Spec.SkipInvalidResources {
 				logger.Error(err, "ClusterFlow contains errors.")
@@ -99,12 +154,29 @@ func CreateSystem(resources LoggingResources, secrets SecretLoaderFactory, logge
 				return nil, err
 			}
 		}
+		if flow == nil {
+			continue
+		}
+		excludeNamespaces(flow, logging.Spec.ExcludeWatchNamespaces)
 		err = builder.RegisterFlow(flow)
 		if err != nil {
 			return nil, err
 		}
 	}
+	if cache != nil {
+		cache.prune(live)
+	}
 	if resources.Logging.Spec.DefaultFlowSpec != nil {
+		if len(logging.Spec.ExcludeWatchNamespaces) > 0 {
+			// Logs of the excluded namespaces are routed to a null output to keep them out of the default flow
+			flow, err := flowForExcludedNamespaces(logging.Spec.ExcludeWatchNamespaces)
+			if err != nil {
+				return nil, err
+			}
+			if err := builder.RegisterFlow(flow); err != nil {
+				return nil, err
+			}
+		}
 		flow, err := FlowForDefaultFlow(resources.Logging, resources.ClusterOutputs, secrets)
 		if err != nil {
 			// TODO set flow status to error?
@@ -154,12 +226,96 @@ func CreateSystem(resources LoggingResources, secrets SecretLoaderFactory, logge
 			for _, output := range flow.Outputs {
 				unsetBufferPath(output)
 			}
+			for _, failover := range flow.FailoverFlows {
+				for _, output := range failover.Outputs {
+					unsetBufferPath(output)
+				}
+			}
 		}
 	}
 
 	return system, err
 }
 
+// excludeNamespaces makes the flow reject the logs of the given namespaces before evaluating its own matches
+func excludeNamespaces(flow *types.Flow, namespaces []string) {
+	if len(namespaces) == 0 {
+		return
+	}
+	flow.Matches = append([]types.FlowMatch{
+		{
+			Namespaces: namespaces,
+			Negate:     true,
+		},
+	}, flow.Matches...)
+}
+
+func flowForExcludedNamespaces(namespaces []string) (*types.Flow, error) {
+	flow, err := types.NewFlow([]types.FlowMatch{{Namespaces: namespaces}}, "excluded-namespaces", "excluded-namespaces", "")
+	if err != nil {
+		return nil, err
+	}
+	plugin, err := output.NewNullOutputConfig().ToDirective(nil, "excluded-namespaces")
+	if err != nil {
+		return nil, err
+	}
+	return flow.WithOutputs(plugin), nil
+}
+
+// applyBufferDefaults returns the resources with copies of the outputs that have the buffer defaults merged in,
+// the first defaults take precedence
+func applyBufferDefaults(resources LoggingResources, defaults ...*output.Buffer) LoggingResources {
+	clusterOutputs := make(ClusterOutputs, len(resources.ClusterOutputs))
+	for i := range resources.ClusterOutputs {
+		resources.ClusterOutputs[i].DeepCopyInto(&clusterOutputs[i])
+		for _, d := range defaults {
+			mergeBufferDefaults(d, &clusterOutputs[i].Spec.OutputSpec)
+		}
+	}
+	resources.ClusterOutputs = clusterOutputs
+
+	outputs := make(Outputs, len(resources.Outputs))
+	for i := range resources.Outputs {
+		resources.Outputs[i].DeepCopyInto(&outputs[i])
+		for _, d := range defaults {
+			mergeBufferDefaults(d, &outputs[i].Spec)
+		}
+	}
+	resources.Outputs = outputs
+
+	return resources
+}
+
+func mergeBufferDefaults(defaultBuffer *output.Buffer, spec *v1beta1.OutputSpec) {
+	if defaultBuffer == nil {
+		return
+	}
+	it := mirror.StructRange(*spec)
+	for it.Next() {
+		if it.Field().Type.Kind() != reflect.Ptr || it.Value().IsNil() {
+			continue
+		}
+		field := it.Value().Elem().FieldByName("Buffer")
+		if !field.IsValid() || field.Type() != reflect.TypeOf(defaultBuffer) {
+			continue
+		}
+		if field.IsNil() {
+			field.Set(reflect.ValueOf(&output.Buffer{}))
+		}
+		buffer := field.Elem()
+		defaults := reflect.ValueOf(defaultBuffer.DeepCopy()).Elem()
+		for i := 0; i < buffer.NumField(); i++ {
+			// buffer paths have to stay unique per output
+			if buffer.Type().Field(i).Name == "Path" {
+				continue
+			}
+			if buffer.Field(i).IsZero() {
+				buffer.Field(i).Set(defaults.Field(i))
+			}
+		}
+	}
+}
+
 func unsetBufferPath(directive types.Directive) {
 	if gd, _ := directive.(*types.GenericDirective); gd != nil && gd.Directive == "buffer" {
 		delete(gd.Params, "path")
@@ -170,8 +326,103 @@ func unsetBufferPath(directive types.Directive) {
 	}
 }
 
+// createOutput creates the output plugin and chains its failover and dead letter outputs: every output in the
+// chain relabels the events it gives up on to the label of the next one, the dead letter output being the last.
+// Outputs formatting the events as CEF or LEEF are moved into a label of their own rendering the message first.
+func createOutput(flow *types.Flow, spec v1beta1.OutputSpec, outputID string, findOutput OutputSpecFinder, secretLoader secret.SecretLoader) (types.Directive, error) {
+	spec, err := applyTLSFrom(spec, secretLoader)
+	if err != nil {
+		return nil, err
+	}
+	spec, formatFilter, err := applySIEMFormat(spec, outputID)
+	if err != nil {
+		return nil, err
+	}
+	plugin, err := plugins.CreateOutput(spec, outputID, secretLoader)
+	if err != nil {
+		return nil, err
+	}
+
+	type chainedOutput struct {
+		id  string
+		ref string
+	}
+	var chain []chainedOutput
+	for i, ref := range spec.Failover {
+		chain = append(chain, chainedOutput{id: FailoverPluginID(outputID, i, ref), ref: ref})
+	}
+	if spec.DeadLetter != "" {
+		chain = append(chain, chainedOutput{id: DeadLetterPluginID(outputID, spec.DeadLetter), ref: spec.DeadLetter})
+	}
+
+	current := plugin
+	for _, next := range chain {
+		nextSpec := findOutput(next.ref)
+		if nextSpec == nil {
+			return nil, errors.Errorf("referenced output not found: %s", next.ref)
+		}
+		tlsSpec, err := applyTLSFrom(*nextSpec, secretLoader)
+		if err != nil {
+			return nil, errors.WrapIff(err, "failed to create chained output %q", next.ref)
+		}
+		tlsSpec, nextFormatFilter, err := applySIEMFormat(tlsSpec, next.id)
+		if err != nil {
+			return nil, errors.WrapIff(err, "failed to create chained output %q", next.ref)
+		}
+		nextPlugin, err := plugins.CreateOutput(tlsSpec, next.id, secretLoader)
+		if err != nil {
+			return nil, errors.WrapIff(err, "failed to create chained output %q", next.ref)
+		}
+		nextFlow, err := types.NewFlow(nil, next.id, next.id, "")
+		if err != nil {
+			return nil, err
+		}
+		if nextFormatFilter != nil {
+			nextFlow.WithFilters(nextFormatFilter)
+		}
+		nextFlow.WithOutputs(nextPlugin)
+		flow.FailoverFlows = append(flow.FailoverFlows, nextFlow)
+
+		secondary, ok := current.(*types.OutputPlugin)
+		if !ok {
+			return nil, errors.Errorf("output %q does not support failover", outputID)
+		}
+		secondary.SubDirectives = append(secondary.SubDirectives, &types.GenericDirective{
+			PluginMeta: types.PluginMeta{
+				Type:      "relabel",
+				Directive: "secondary",
+				Label:     nextFlow.FlowLabel,
+			},
+		})
+		current = nextPlugin
+	}
+
+	if formatFilter != nil {
+		// The message is rendered into the events in a label of the output, the other outputs of the flow are
+		// left untouched
+		formatFlow, err := types.NewFlow(nil, outputID+":format", outputID+":format", "")
+		if err != nil {
+			return nil, err
+		}
+		formatFlow.WithFilters(formatFilter).WithOutputs(plugin)
+		flow.FailoverFlows = append(flow.FailoverFlows, formatFlow)
+		return &types.OutputPlugin{
+			PluginMeta: types.PluginMeta{
+				Type:      "relabel",
+				Directive: "match",
+				Tag:       "**",
+				Id:        outputID + ":format",
+				Label:     formatFlow.FlowLabel,
+			},
+		}, nil
+	}
+	return plugin, nil
+}
+
 type SecretLoaderFactory interface {
 	OutputSecretLoaderForNamespace(namespace string) secret.SecretLoader
+	// ClusterOutputSecretLoaderForNamespace also loads the secrets of the shared secret namespaces of the logging
+	ClusterOutputSecretLoaderForNamespace(namespace string) secret.SecretLoader
 }
 
 func filtersForFilters(flowID string, flowName string, secretLoader secret.SecretLoader, filters []v1beta1.Filter) ([]types.Filter, error) {
@@ -201,7 +452,7 @@ func FlowForError(outputRef string, clusterOutputs ClusterOutputs, secrets Secre
 	}
 
 	if clusterOutput := clusterOutputs.FindByName(outputRef); clusterOutput != nil {
-		plugin, err := plugins.CreateOutput(clusterOutput.Spec.OutputSpec, "main-fluentd-error", secrets.OutputSecretLoaderForNamespace(clusterOutput.Namespace))
+		plugin, err := createOutput(errorFlow, clusterOutput.Spec.OutputSpec, "main-fluentd-error", clusterOutputs.SpecFinder(), secrets.ClusterOutputSecretLoaderForNamespace(clusterOutput.Namespace))
 		if err != nil {
 			return nil, errors.WrapIff(err, "failed to create configured output %q", outputRef)
 		}
@@ -218,6 +469,7 @@ func FlowForFlow(flow v1beta1.Flow, clusterOutputs ClusterOutputs, outputs Outpu
 	}
 
 	var matches []types.FlowMatch
+	var statements []matchStatement
 	if flow.Spec.Match != nil {
 		for _, match := range flow.Spec.Match {
 			if match.Select != nil && match.Exclude != nil {
@@ -226,6 +478,16 @@ func FlowForFlow(flow v1beta1.Flow, clusterOutputs ClusterOutputs, outputs Outpu
 			}
 
 			if match.Select != nil {
+				statements = append(statements, matchStatement{
+					term: v1beta1.MatchTerm{
+						Labels:           match.Select.Labels,
+						Hosts:            match.Select.Hosts,
+						ContainerNames:   match.Select.ContainerNames,
+						Fields:           match.Select.Fields,
+						LabelExpressions: match.Select.LabelExpressions,
+					},
+					anyOf: withoutNamespaces(match.Select.AnyOf),
+				})
 				matches = append(matches, types.FlowMatch{
 					Labels:         match.Select.Labels,
 					ContainerNames: match.Select.ContainerNames,
@@ -235,6 +497,21 @@ func FlowForFlow(flow v1beta1.Flow, clusterOutputs ClusterOutputs, outputs Outpu
 				})
 			}
 			if match.Exclude != nil {
+				statements = append(statements, matchStatement{
+					exclude: true,
+					term: v1beta1.MatchTerm{
+						Labels:           match.Exclude.Labels,
+						Hosts:            match.Exclude.Hosts,
+						ContainerNames:   match.Exclude.ContainerNames,
+						Fields:           match.Exclude.Fields,
+						LabelExpressions: match.Exclude.LabelExpressions,
+					},
+					anyOf: withoutNamespaces(match.Exclude.AnyOf),
+				})
+				if statements[len(statements)-1].evaluatedInFlow() {
+					// Evaluated in the flow only, the router cannot tell which logs to exclude
+					continue
+				}
 				matches = append(matches, types.FlowMatch{
 					Labels:         match.Exclude.Labels,
 					ContainerNames: match.Exclude.ContainerNames,
@@ -254,7 +531,7 @@ func FlowForFlow(flow v1beta1.Flow, clusterOutputs ClusterOutputs, outputs Outpu
 		}
 	}
 
-	flowID := fmt.Sprintf("flow:%s:%s", flow.Namespace, flow.Name)
+	flowID := FlowID(flow)
 
 	result, err := types.NewFlow(matches, flowID, flow.Name, flow.Namespace)
 	if err != nil {
@@ -266,8 +543,8 @@ func FlowForFlow(flow v1beta1.Flow, clusterOutputs ClusterOutputs, outputs Outpu
 	var allOutputs []types.Output
 	for _, outputRef := range flow.Spec.GlobalOutputRefs {
 		if clusterOutput := clusterOutputs.FindByName(outputRef); clusterOutput != nil {
-			outputID := fmt.Sprintf("%s:clusteroutput:%s:%s", flowID, clusterOutput.Namespace, clusterOutput.Name)
-			plugin, err := plugins.CreateOutput(clusterOutput.Spec.OutputSpec, outputID, secrets.OutputSecretLoaderForNamespace(clusterOutput.Namespace))
+			outputID := ClusterOutputPluginID(flowID, *clusterOutput)
+			plugin, err := createOutput(result, clusterOutput.Spec.OutputSpec, outputID, clusterOutputs.SpecFinder(), secrets.ClusterOutputSecretLoaderForNamespace(clusterOutput.Namespace))
 			if err != nil {
 				errs = errors.Append(errs, errors.WrapIff(err, "failed to create configured output %q", outputRef))
 				continue
@@ -279,8 +556,8 @@ func FlowForFlow(flow v1beta1.Flow, clusterOutputs ClusterOutputs, outputs Outpu
 	}
 	for _, outputRef := range flow.Spec.LocalOutputRefs {
 		if output := outputs.FindByNamespacedName(flow.Namespace, outputRef); output != nil {
-			outputID := fmt.Sprintf("%s:output:%s:%s", flowID, output.Namespace, output.Name)
-			plugin, err := plugins.CreateOutput(output.Spec, outputID, secrets.OutputSecretLoaderForNamespace(output.Namespace))
+			outputID := OutputPluginID(flowID, *output)
+			plugin, err := createOutput(result, output.Spec, outputID, outputs.SpecFinder(output.Namespace), secrets.OutputSecretLoaderForNamespace(output.Namespace))
 			if err != nil {
 				errs = errors.Append(errs, errors.WrapIff(err, "failed to create configured output %q", outputRef))
 				continue
@@ -292,6 +569,10 @@ func FlowForFlow(flow v1beta1.Flow, clusterOutputs ClusterOutputs, outputs Outpu
 	}
 	result.WithOutputs(allOutputs...)
 
+	evaluation, err := matchFilters(flowID, statements)
+	errs = errors.Append(errs, err)
+	result.WithFilters(evaluation...)
+
 	filters, err := filtersForFilters(flowID, flow.Name, secrets.OutputSecretLoaderForNamespace(flow.Namespace), flow.Spec.Filters)
 	errs = errors.Append(errs, err)
 	result.WithFilters(filters...)
@@ -299,6 +580,40 @@ func FlowForFlow(flow v1beta1.Flow, clusterOutputs ClusterOutputs, outputs Outpu
 	return result, errs
 }
 
+// FlowID returns the id of the flow used in the generated configuration
+func FlowID(flow v1beta1.Flow) string {
+	return fmt.Sprintf("flow:%s:%s", flow.Namespace, flow.Name)
+}
+
+func ClusterFlowID(flow v1beta1.ClusterFlow) string {
+	return fmt.Sprintf("clusterflow:%s:%s", flow.Namespace, flow.Name)
+}
+
+// DefaultFlowID returns the id of the default flow of the logging
+func DefaultFlowID(logging v1beta1.Logging) string {
+	return fmt.Sprintf("logging:%s:%s", logging.Namespace, logging.Name)
+}
+
+// OutputPluginID returns the id of the plugin created for the output referenced by the flow
+func OutputPluginID(flowID string, output v1beta1.Output) string {
+	return fmt.Sprintf("%s:output:%s:%s", flowID, output.Namespace, output.Name)
+}
+
+// ClusterOutputPluginID returns the id of the plugin created for the clusteroutput referenced by the flow
+func ClusterOutputPluginID(flowID string, output v1beta1.ClusterOutput) string {
+	return fmt.Sprintf("%s:clusteroutput:%s:%s", flowID, output.Namespace, output.Name)
+}
+
+// FailoverPluginID returns the id of the plugin created for the i-th failover output of an output plugin
+func FailoverPluginID(outputID string, i int, ref string) string {
+	return fmt.Sprintf("%s:failover:%d:%s", outputID, i, ref)
+}
+
+// DeadLetterPluginID returns the id of the plugin created for the dead letter output of an output plugin
+func DeadLetterPluginID(outputID string, ref string) string {
+	return fmt.Sprintf("%s:deadletter:%s", outputID, ref)
+}
+
 func FlowForClusterFlow(flow v1beta1.ClusterFlow, clusterOutputs ClusterOutputs, secrets SecretLoaderFactory) (*types.Flow, error) {
 	if flow.Spec.Match != nil && flow.Spec.Selectors != nil {
 		return nil, errors.Errorf("match and selectors cannot be defined simultaneously for clusterflow %s",
@@ -306,6 +621,7 @@ func FlowForClusterFlow(flow v1beta1.ClusterFlow, clusterOutputs ClusterOutputs,
 	}
 
 	var matches []types.FlowMatch
+	var statements []matchStatement
 	if flow.Spec.Match != nil {
 		for _, match := range flow.Spec.Match {
 			if match.ClusterSelect != nil && match.ClusterExclude != nil {
@@ -314,6 +630,17 @@ func FlowForClusterFlow(flow v1beta1.ClusterFlow, clusterOutputs ClusterOutputs,
 			}
 
 			if match.ClusterSelect != nil {
+				statements = append(statements, matchStatement{
+					term: v1beta1.MatchTerm{
+						Namespaces:       match.ClusterSelect.Namespaces,
+						Labels:           match.ClusterSelect.Labels,
+						Hosts:            match.ClusterSelect.Hosts,
+						ContainerNames:   match.ClusterSelect.ContainerNames,
+						Fields:           match.ClusterSelect.Fields,
+						LabelExpressions: match.ClusterSelect.LabelExpressions,
+					},
+					anyOf: match.ClusterSelect.AnyOf,
+				})
 				matches = append(matches, types.FlowMatch{
 					Labels:         match.ClusterSelect.Labels,
 					ContainerNames: match.ClusterSelect.ContainerNames,
@@ -323,6 +650,22 @@ func FlowForClusterFlow(flow v1beta1.ClusterFlow, clusterOutputs ClusterOutputs,
 				})
 			}
 			if match.ClusterExclude != nil {
+				statements = append(statements, matchStatement{
+					exclude: true,
+					term: v1beta1.MatchTerm{
+						Namespaces:       match.ClusterExclude.Namespaces,
+						Labels:           match.ClusterExclude.Labels,
+						Hosts:            match.ClusterExclude.Hosts,
+						ContainerNames:   match.ClusterExclude.ContainerNames,
+						Fields:           match.ClusterExclude.Fields,
+						LabelExpressions: match.ClusterExclude.LabelExpressions,
+					},
+					anyOf: match.ClusterExclude.AnyOf,
+				})
+				if statements[len(statements)-1].evaluatedInFlow() {
+					// Evaluated in the flow only, the router cannot tell which logs to exclude
+					continue
+				}
 				matches = append(matches, types.FlowMatch{
 					Labels:         match.ClusterExclude.Labels,
 					ContainerNames: match.ClusterExclude.ContainerNames,
@@ -342,7 +685,7 @@ func FlowForClusterFlow(flow v1beta1.ClusterFlow, clusterOutputs ClusterOutputs,
 		}
 	}
 
-	flowID := fmt.Sprintf("clusterflow:%s:%s", flow.Namespace, flow.Name)
+	flowID := ClusterFlowID(flow)
 
 	result, err := types.NewFlow(matches, flowID, flow.Name, flow.Namespace)
 	if err != nil {
@@ -354,8 +697,8 @@ func FlowForClusterFlow(flow v1beta1.ClusterFlow, clusterOutputs ClusterOutputs,
 	var outputs []types.Output
 	for _, outputRef := range flow.Spec.GlobalOutputRefs {
 		if clusterOutput := clusterOutputs.FindByName(outputRef); clusterOutput != nil {
-			outputID := fmt.Sprintf("%s:clusteroutput:%s:%s", flowID, clusterOutput.Namespace, clusterOutput.Name)
-			plugin, err := plugins.CreateOutput(clusterOutput.Spec.OutputSpec, outputID, secrets.OutputSecretLoaderForNamespace(clusterOutput.Namespace))
+			outputID := ClusterOutputPluginID(flowID, *clusterOutput)
+			plugin, err := createOutput(result, clusterOutput.Spec.OutputSpec, outputID, clusterOutputs.SpecFinder(), secrets.ClusterOutputSecretLoaderForNamespace(clusterOutput.Namespace))
 			if err != nil {
 				errs = errors.Append(errs, errors.WrapIff(err, "failed to create configured output %q", outputRef))
 				continue
@@ -367,6 +710,10 @@ func FlowForClusterFlow(flow v1beta1.ClusterFlow, clusterOutputs ClusterOutputs,
 	}
 	result.WithOutputs(outputs...)
 
+	evaluation, err := matchFilters(flowID, statements)
+	errs = errors.Append(errs, err)
+	result.WithFilters(evaluation...)
+
 	filters, err := filtersForFilters(flowID, flow.Name, secrets.OutputSecretLoaderForNamespace(flow.Namespace), flow.Spec.Filters)
 	errs = errors.Append(errs, err)
 	result.WithFilters(filters...)
@@ -379,7 +726,7 @@ func FlowForDefaultFlow(logging v1beta1.Logging, clusterOutputs ClusterOutputs,
 		return nil, nil
 	}
 
-	flowID := fmt.Sprintf("logging:%s:%s", logging.Namespace, logging.Name)
+	flowID := DefaultFlowID(logging)
 
 	result, err := types.NewFlow([]types.FlowMatch{}, flowID, logging.Name, logging.Namespace)
 	if err != nil {
@@ -391,8 +738,8 @@ func FlowForDefaultFlow(logging v1beta1.Logging, clusterOutputs ClusterOutputs,
 	var outputs []types.Output
 	for _, outputRef := range logging.Spec.DefaultFlowSpec.GlobalOutputRefs {
 		if clusterOutput := clusterOutputs.FindByName(outputRef); clusterOutput != nil {
-			outputID := fmt.Sprintf("%s:clusteroutput:%s:%s", flowID, clusterOutput.Namespace, clusterOutput.Name)
-			plugin, err := plugins.CreateOutput(clusterOutput.Spec.OutputSpec, outputID, secrets.OutputSecretLoaderForNamespace(clusterOutput.Namespace))
+			outputID := ClusterOutputPluginID(flowID, *clusterOutput)
+			plugin, err := createOutput(result, clusterOutput.Spec.OutputSpec, outputID, clusterOutputs.SpecFinder(), secrets.ClusterOutputSecretLoaderForNamespace(clusterOutput.Namespace))
 			if err != nil {
 				errs = errors.Append(errs, errors.WrapIff(err, "failed to create configured output %q", outputRef))
 				continue
@@ -0,0 +1,82 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/filter"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+)
+
+const (
+	// TestMessagesSecretName is the secret of the control namespace holding the token the operator authenticates
+	// the test messages with, it is generated by the operator
+	TestMessagesSecretName = "fluentd-test-messages"
+	// TestMessagesTokenKey is the key of the token in the test messages secret
+	TestMessagesTokenKey = "token"
+
+	testMessagesLabel = "@TEST_MESSAGES"
+	testMessagesID    = "main-test-messages"
+)
+
+// registerTestMessagesInput adds the HTTP input the operator sends the test messages of the flows to. Records of
+// requests without the token of the test messages secret are dropped.
+func registerTestMessagesInput(builder *types.SystemBuilder, logging v1beta1.Logging, secretLoader secret.SecretLoader) error {
+	token, err := secretLoader.Load(&secret.Secret{
+		ValueFrom: &secret.ValueFrom{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: logging.QualifiedName(TestMessagesSecretName)},
+				Key:                  TestMessagesTokenKey,
+			},
+		},
+	})
+	if err != nil {
+		return errors.WrapIf(err, "loading the token of the test messages")
+	}
+	if token == "" {
+		return errors.New("the token of the test messages is empty")
+	}
+
+	source := &types.GenericDirective{
+		PluginMeta: types.PluginMeta{
+			Type:      "http",
+			Directive: "source",
+			Id:        testMessagesID,
+			Label:     testMessagesLabel,
+		},
+		Params: types.Params{
+			"bind":             "0.0.0.0",
+			"port":             strconv.Itoa(int(logging.Spec.FluentdSpec.TestMessages.Port)),
+			"add_http_headers": "true",
+		},
+	}
+	auth, err := bearerTokenFilter(testMessagesID, token)
+	if err != nil {
+		return err
+	}
+	removeToken, err := (&filter.RecordTransformer{
+		RemoveKeys: "HTTP_AUTHORIZATION",
+	}).ToDirective(secretLoader, testMessagesID+"-remove-token")
+	if err != nil {
+		return err
+	}
+	return builder.RegisterLabeledInput(source, testMessagesLabel, []types.Filter{auth, removeToken})
+}
@@ -0,0 +1,88 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+type clientSecretLoaderFactory struct {
+	client client.Client
+}
+
+func (f clientSecretLoaderFactory) OutputSecretLoaderForNamespace(namespace string) secret.SecretLoader {
+	return secret.NewSecretLoader(f.client, namespace, "", &secret.MountSecrets{})
+}
+
+func (f clientSecretLoaderFactory) ClusterOutputSecretLoaderForNamespace(namespace string) secret.SecretLoader {
+	return f.OutputSecretLoaderForNamespace(namespace)
+}
+
+func TestTestMessagesInput(t *testing.T) {
+	resources := testResources(0)
+	resources.Logging.Spec.FluentdSpec.TestMessages = &v1beta1.FluentdTestMessages{Enabled: true, Port: 9880}
+
+	secrets := clientSecretLoaderFactory{client: fake.NewClientBuilder().Build()}
+	if _, err := CreateSystem(resources, secrets, nil, logr.Discard()); err == nil {
+		t.Fatal("expected an error without the token secret")
+	}
+
+	secrets.client = fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-fluentd-test-messages", Namespace: "logging"},
+		Data:       map[string][]byte{TestMessagesTokenKey: []byte("a/b+c")},
+	}).Build()
+	system, err := CreateSystem(resources, secrets, nil, logr.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(system.Inputs) != 1 || len(system.InputFlows) != 1 {
+		t.Fatalf("expected a single labeled input, got %d inputs and %d input flows", len(system.Inputs), len(system.InputFlows))
+	}
+	source := system.Inputs[0]
+	if meta := source.GetPluginMeta(); meta.Type != "http" || meta.Id != testMessagesID || meta.Label != testMessagesLabel {
+		t.Errorf("unexpected test messages source %+v", meta)
+	}
+	if params := source.GetParams(); params["port"] != "9880" || params["add_http_headers"] != "true" {
+		t.Errorf("unexpected test messages source params %v", params)
+	}
+
+	filters := system.InputFlows[0].Filters
+	if len(filters) != 2 {
+		t.Fatalf("expected the auth and the token removal filters, got %d", len(filters))
+	}
+	if meta := filters[0].GetPluginMeta(); meta.Type != "grep" {
+		t.Fatalf("expected the grep filter first, got %s", meta.Type)
+	}
+	sections := filters[0].GetSections()
+	if len(sections) != 1 {
+		t.Fatalf("expected a single regexp section, got %d", len(sections))
+	}
+	if params := sections[0].GetParams(); params["key"] != "HTTP_AUTHORIZATION" || params["pattern"] != `/^Bearer a\/b\+c$/` {
+		t.Errorf("unexpected auth filter params %v", params)
+	}
+	if params := filters[1].GetParams(); params["remove_keys"] != "HTTP_AUTHORIZATION" {
+		t.Errorf("the token is not removed from the records: %v", params)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// TLSSourceLoader is implemented by the secret loaders that mount the sources of tlsFrom into fluentd
+type TLSSourceLoader interface {
+	// LoadTLSSource returns the path the PEM bundle of the source is mounted to, and the bundle itself
+	LoadTLSSource(source *v1beta1.TLSSource) (path string, value []byte, err error)
+}
+
+// applyTLSFrom returns a copy of the spec with the TLS fields of the output set to the mounted sources of tlsFrom
+func applyTLSFrom(spec v1beta1.OutputSpec, secretLoader secret.SecretLoader) (v1beta1.OutputSpec, error) {
+	if spec.TLSFrom == nil {
+		return spec, nil
+	}
+	loader, ok := secretLoader.(TLSSourceLoader)
+	if !ok {
+		return spec, errors.New("tlsFrom is not supported by the secret loader")
+	}
+	load := func(source *v1beta1.TLSSource) (*secret.Secret, error) {
+		if source == nil {
+			return nil, nil
+		}
+		path, _, err := loader.LoadTLSSource(source)
+		if err != nil {
+			return nil, err
+		}
+		return &secret.Secret{Value: path}, nil
+	}
+	ca, err := load(spec.TLSFrom.CABundle)
+	if err != nil {
+		return spec, errors.WrapIf(err, "failed to load the CA bundle of tlsFrom")
+	}
+	cert, err := load(spec.TLSFrom.ClientCert)
+	if err != nil {
+		return spec, errors.WrapIf(err, "failed to load the client certificate of tlsFrom")
+	}
+
+	spec = *spec.DeepCopy()
+	set := func(caField, certField **secret.Secret) error {
+		if ca != nil {
+			*caField = ca
+		}
+		if cert != nil {
+			if certField == nil {
+				return errors.New("tlsFrom.clientCert is not supported by the output")
+			}
+			*certField = cert
+		}
+		return nil
+	}
+	switch {
+	case spec.ForwardOutput != nil:
+		err = set(&spec.ForwardOutput.TlsCertPath, &spec.ForwardOutput.TlsClientCertPath)
+	case spec.LoggingOperatorForward != nil:
+		err = set(&spec.LoggingOperatorForward.CACert, &spec.LoggingOperatorForward.ClientCert)
+	case spec.HTTPOutput != nil:
+		err = set(&spec.HTTPOutput.TlsCACertPath, &spec.HTTPOutput.TlsClientCertPath)
+	case spec.ElasticsearchOutput != nil:
+		err = set(&spec.ElasticsearchOutput.SSLCACert, &spec.ElasticsearchOutput.SSLClientCert)
+	case spec.OpenSearchOutput != nil:
+		err = set(&spec.OpenSearchOutput.SSLCACert, &spec.OpenSearchOutput.SSLClientCert)
+	case spec.SplunkHecOutput != nil:
+		err = set(&spec.SplunkHecOutput.CAFile, &spec.SplunkHecOutput.ClientCert)
+	case spec.LokiOutput != nil:
+		err = set(&spec.LokiOutput.CaCert, &spec.LokiOutput.Cert)
+	case spec.KafkaOutputConfig != nil:
+		err = set(&spec.KafkaOutputConfig.SSLCACert, &spec.KafkaOutputConfig.SSLClientCert)
+	case spec.SyslogOutputConfig != nil:
+		err = set(&spec.SyslogOutputConfig.TrustedCaPath, nil)
+	default:
+		err = errors.New("tlsFrom is not supported by the output")
+	}
+	return spec, err
+}
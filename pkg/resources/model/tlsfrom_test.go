@@ -0,0 +1,143 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+)
+
+// tlsSourceLoader mounts the sources under /tls by the name of their configmap
+type tlsSourceLoader struct{}
+
+func (tlsSourceLoader) Load(*secret.Secret) (string, error) {
+	return "", nil
+}
+
+func (tlsSourceLoader) LoadTLSSource(source *v1beta1.TLSSource) (string, []byte, error) {
+	if source.ConfigMapKeyRef == nil {
+		return "", nil, errors.New("no configmap")
+	}
+	return "/tls/" + source.ConfigMapKeyRef.Name, nil, nil
+}
+
+func configMapSource(name string) *v1beta1.TLSSource {
+	return &v1beta1.TLSSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: name},
+		Key:                  "tls.crt",
+	}}
+}
+
+func TestApplyTLSFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    v1beta1.OutputSpec
+		loader  secret.SecretLoader
+		want    v1beta1.OutputSpec
+		wantErr bool
+	}{
+		{
+			name: "without tlsFrom",
+			spec: v1beta1.OutputSpec{HTTPOutput: &output.HTTPOutputConfig{Endpoint: "https://logs"}},
+			want: v1beta1.OutputSpec{HTTPOutput: &output.HTTPOutputConfig{Endpoint: "https://logs"}},
+		},
+		{
+			name: "CA bundle",
+			spec: v1beta1.OutputSpec{
+				HTTPOutput: &output.HTTPOutputConfig{Endpoint: "https://logs"},
+				TLSFrom:    &v1beta1.TLSFrom{CABundle: configMapSource("ca")},
+			},
+			want: v1beta1.OutputSpec{
+				HTTPOutput: &output.HTTPOutputConfig{Endpoint: "https://logs", TlsCACertPath: &secret.Secret{Value: "/tls/ca"}},
+				TLSFrom:    &v1beta1.TLSFrom{CABundle: configMapSource("ca")},
+			},
+		},
+		{
+			name: "CA bundle and client certificate",
+			spec: v1beta1.OutputSpec{
+				KafkaOutputConfig: &output.KafkaOutputConfig{Brokers: "kafka:9093"},
+				TLSFrom:           &v1beta1.TLSFrom{CABundle: configMapSource("ca"), ClientCert: configMapSource("client")},
+			},
+			want: v1beta1.OutputSpec{
+				KafkaOutputConfig: &output.KafkaOutputConfig{
+					Brokers:       "kafka:9093",
+					SSLCACert:     &secret.Secret{Value: "/tls/ca"},
+					SSLClientCert: &secret.Secret{Value: "/tls/client"},
+				},
+				TLSFrom: &v1beta1.TLSFrom{CABundle: configMapSource("ca"), ClientCert: configMapSource("client")},
+			},
+		},
+		{
+			name: "client certificate of an output without one",
+			spec: v1beta1.OutputSpec{
+				SyslogOutputConfig: &output.SyslogOutputConfig{Host: "syslog"},
+				TLSFrom:            &v1beta1.TLSFrom{ClientCert: configMapSource("client")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "output without TLS",
+			spec: v1beta1.OutputSpec{
+				NullOutputConfig: output.NewNullOutputConfig(),
+				TLSFrom:          &v1beta1.TLSFrom{CABundle: configMapSource("ca")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "source failing to load",
+			spec: v1beta1.OutputSpec{
+				HTTPOutput: &output.HTTPOutputConfig{Endpoint: "https://logs"},
+				TLSFrom:    &v1beta1.TLSFrom{CABundle: &v1beta1.TLSSource{}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "loader without TLS sources",
+			spec: v1beta1.OutputSpec{
+				HTTPOutput: &output.HTTPOutputConfig{Endpoint: "https://logs"},
+				TLSFrom:    &v1beta1.TLSFrom{CABundle: configMapSource("ca")},
+			},
+			loader:  testSecretLoaderFactory{}.OutputSecretLoaderForNamespace("app"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			loader := tt.loader
+			if loader == nil {
+				loader = tlsSourceLoader{}
+			}
+			original := tt.spec.DeepCopy()
+			got, err := applyTLSFrom(tt.spec, loader)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyTLSFrom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(&tt.spec, original) {
+				t.Error("the original spec is modified")
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyTLSFrom() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/common"
+)
+
+// tlsProfile constrains the TLS settings of the generated configuration
+type tlsProfile struct {
+	minVersion string
+	// allowed TLS 1.2 cipher suites in OpenSSL notation, empty if any is allowed
+	ciphers []string
+	// key exchange groups in OpenSSL notation
+	curves []string
+	// strict profiles reject the outputs requesting weaker settings instead of only setting the defaults
+	strict bool
+}
+
+var tlsProfiles = map[string]tlsProfile{
+	v1beta1.TLSProfileModern: {
+		minVersion: "TLSv1_3",
+		curves:     []string{"X25519", "prime256v1", "secp384r1"},
+		strict:     true,
+	},
+	v1beta1.TLSProfileIntermediate: {
+		minVersion: "TLSv1_2",
+		ciphers: []string{
+			"ECDHE-ECDSA-AES128-GCM-SHA256", "ECDHE-RSA-AES128-GCM-SHA256",
+			"ECDHE-ECDSA-AES256-GCM-SHA384", "ECDHE-RSA-AES256-GCM-SHA384",
+			"ECDHE-ECDSA-CHACHA20-POLY1305", "ECDHE-RSA-CHACHA20-POLY1305",
+			"DHE-RSA-AES128-GCM-SHA256", "DHE-RSA-AES256-GCM-SHA384",
+		},
+		curves: []string{"X25519", "prime256v1", "secp384r1"},
+	},
+	v1beta1.TLSProfileFIPS: {
+		minVersion: "TLSv1_2",
+		ciphers: []string{
+			"ECDHE-ECDSA-AES128-GCM-SHA256", "ECDHE-RSA-AES128-GCM-SHA256",
+			"ECDHE-ECDSA-AES256-GCM-SHA384", "ECDHE-RSA-AES256-GCM-SHA384",
+		},
+		curves: []string{"prime256v1", "secp384r1", "secp521r1"},
+		strict: true,
+	},
+}
+
+// tlsVersionRank orders the TLS versions in fluentd notation, SSLv23 negotiates any version
+var tlsVersionRank = map[string]int{
+	"SSLv23":  0,
+	"TLSv1":   1,
+	"TLSv1_1": 2,
+	"TLSv1_2": 3,
+	"TLSv1_3": 4,
+}
+
+// weakerVersion tells whether the version allows less than the minimum version of the profile
+func (p tlsProfile) weakerVersion(version string) bool {
+	rank, ok := tlsVersionRank[version]
+	return !ok || rank < tlsVersionRank[p.minVersion]
+}
+
+// weakerCiphers returns the ciphers not allowed by the profile
+func (p tlsProfile) weakerCiphers(ciphers string) (weaker []string) {
+	if len(p.ciphers) == 0 {
+		return nil
+	}
+	allowed := make(map[string]bool, len(p.ciphers))
+	for _, c := range p.ciphers {
+		allowed[c] = true
+	}
+	for _, c := range strings.Split(ciphers, ":") {
+		if c = strings.TrimSpace(c); c != "" && !allowed[c] {
+			weaker = append(weaker, c)
+		}
+	}
+	return
+}
+
+// applyTransport sets the version and ciphers of the fluentd TLS transport
+func (p tlsProfile) applyTransport(t *common.Transport) {
+	t.Version = p.minVersion
+	if len(p.ciphers) > 0 {
+		t.Ciphers = strings.Join(p.ciphers, ":")
+	}
+}
+
+// TLSProfileOpenSSLConfig returns the OpenSSL configuration constraining the protocol versions, ciphers and curves
+// of the TLS connections of fluentd to the profile, or an empty string for an unknown profile. Most plugins offer no
+// TLS settings, or no curve settings like the fluentd transport, but they use the defaults of this configuration
+// unless they override them.
+func TLSProfileOpenSSLConfig(profileName string) string {
+	profile, ok := tlsProfiles[profileName]
+	if !ok {
+		return ""
+	}
+	config := fmt.Sprintf(`openssl_conf = openssl_init
+
+[openssl_init]
+ssl_conf = ssl_sect
+
+[ssl_sect]
+system_default = system_default_sect
+
+[system_default_sect]
+MinProtocol = %s
+Groups = %s
+`, strings.Replace(profile.minVersion, "_", ".", 1), strings.Join(profile.curves, ":"))
+	if len(profile.ciphers) > 0 {
+		config += fmt.Sprintf("CipherString = %s\n", strings.Join(profile.ciphers, ":"))
+	}
+	return config
+}
+
+// applyTLSProfile sets the TLS settings left unset in the output to the profile and returns the settings weaker than
+// the profile. The settings are only checked for the outputs that support configuring them, the others are
+// constrained by the OpenSSL configuration of the profile.
+func applyTLSProfile(profileName string, spec *v1beta1.OutputSpec) (problems []string) {
+	profile, ok := tlsProfiles[profileName]
+	if !ok {
+		return nil
+	}
+	version := func(field string, v *string) {
+		if *v == "" {
+			*v = profile.minVersion
+		} else if profile.strict && profile.weakerVersion(*v) {
+			problems = append(problems, fmt.Sprintf("%s %s is weaker than the %s TLS profile allows", field, *v, profileName))
+		}
+	}
+	ciphers := func(field string, c *string) {
+		if *c == "" {
+			*c = strings.Join(profile.ciphers, ":")
+		} else if weaker := profile.weakerCiphers(*c); profile.strict && len(weaker) > 0 {
+			problems = append(problems, fmt.Sprintf("%s %s not allowed by the %s TLS profile", field, strings.Join(weaker, ":"), profileName))
+		}
+	}
+	insecure := func(field string, skipVerify bool) {
+		if profile.strict && skipVerify {
+			problems = append(problems, fmt.Sprintf("%s is not allowed by the %s TLS profile", field, profileName))
+		}
+	}
+
+	switch {
+	case spec.ForwardOutput != nil:
+		o := spec.ForwardOutput
+		version("tls_version", &o.TlsVersion)
+		ciphers("tls_ciphers", &o.TlsCiphers)
+		insecure("tls_insecure_mode", o.TlsInsecureMode)
+	case spec.LoggingOperatorForward != nil:
+		o := spec.LoggingOperatorForward
+		version("tlsVersion", &o.TlsVersion)
+		ciphers("tlsCiphers", &o.TlsCiphers)
+	case spec.HTTPOutput != nil:
+		o := spec.HTTPOutput
+		version("tls_version", &o.TlsVersion)
+		ciphers("tls_ciphers", &o.TlsCiphers)
+	case spec.ElasticsearchOutput != nil:
+		o := spec.ElasticsearchOutput
+		if o.SslVersion != "" && profile.strict && profile.weakerVersion(o.SslVersion) && o.SslMinVersion == "" {
+			problems = append(problems, fmt.Sprintf("ssl_version %s is weaker than the %s TLS profile allows", o.SslVersion, profileName))
+		}
+		// The minimum version only takes effect together with the maximum version
+		version("ssl_min_version", &o.SslMinVersion)
+		if o.SslMaxVersion == "" {
+			o.SslMaxVersion = "TLSv1_3"
+		}
+		insecure("ssl_verify: false", o.SslVerify != nil && !*o.SslVerify)
+	case spec.OpenSearchOutput != nil:
+		insecure("ssl_verify: false", spec.OpenSearchOutput.SslVerify != nil && !*spec.OpenSearchOutput.SslVerify)
+	case spec.SplunkHecOutput != nil:
+		o := spec.SplunkHecOutput
+		ciphers("ssl_ciphers", &o.SSLCiphers)
+		insecure("insecure_ssl", o.InsecureSSL != nil && *o.InsecureSSL)
+	case spec.GELFOutputConfig != nil:
+		o := spec.GELFOutputConfig
+		if o.TLS == nil || !*o.TLS {
+			break
+		}
+		if o.TLSOptions == nil {
+			o.TLSOptions = make(map[string]string)
+		}
+		v := o.TLSOptions["tls_version"]
+		version("tls_options.tls_version", &v)
+		o.TLSOptions["tls_version"] = v
+		if o.TLSOptions["all_ciphers"] == "true" && profile.strict && len(profile.ciphers) > 0 {
+			problems = append(problems, fmt.Sprintf("tls_options.all_ciphers is not allowed by the %s TLS profile", profileName))
+		}
+	case spec.KafkaOutputConfig != nil:
+		o := spec.KafkaOutputConfig
+		insecure("ssl_verify_hostname: false", o.SSLVerifyHostname != nil && !*o.SSLVerifyHostname)
+	case spec.LokiOutput != nil:
+		insecure("insecure_tls", spec.LokiOutput.InsecureTLS != nil && *spec.LokiOutput.InsecureTLS)
+	case spec.SyslogOutputConfig != nil:
+		o := spec.SyslogOutputConfig
+		insecure("insecure", o.Insecure != nil && *o.Insecure)
+		insecure("verify_fqdn: false", o.VerifyFqdn != nil && !*o.VerifyFqdn)
+	case spec.DatadogOutput != nil:
+		insecure("no_ssl_validation", spec.DatadogOutput.NoSslValidation)
+	case spec.OTLPOutput != nil:
+		insecure("tls_insecure_skip_verify", spec.OTLPOutput.TLSInsecureSkipVerify)
+	case spec.S3OutputConfig != nil:
+		insecure("ssl_verify_peer: false", spec.S3OutputConfig.SslVerifyPeer == "false")
+	}
+	return problems
+}
+
+// applyTLSProfileToResources returns the resources with copies of the outputs that have the TLS profile applied.
+// Outputs with weaker settings are dropped if invalid resources are skipped, otherwise an error is returned.
+func applyTLSProfileToResources(profile string, resources LoggingResources, logger logr.Logger) (LoggingResources, error) {
+	if _, ok := tlsProfiles[profile]; !ok {
+		return resources, nil
+	}
+	skip := resources.Logging.Spec.SkipInvalidResources
+	var errs error
+	reject := func(kind, namespace, name string, problems []string) {
+		err := errors.Errorf("%s %s/%s rejected: %s", kind, namespace, name, strings.Join(problems, ", "))
+		if skip {
+			logger.Error(err, "skipping output")
+			return
+		}
+		errs = errors.Append(errs, err)
+	}
+
+	clusterOutputs := make(ClusterOutputs, 0, len(resources.ClusterOutputs))
+	for _, o := range resources.ClusterOutputs {
+		o := *o.DeepCopy()
+		if problems := applyTLSProfile(profile, &o.Spec.OutputSpec); len(problems) > 0 {
+			reject("clusteroutput", o.Namespace, o.Name, problems)
+			continue
+		}
+		clusterOutputs = append(clusterOutputs, o)
+	}
+	resources.ClusterOutputs = clusterOutputs
+
+	outputs := make(Outputs, 0, len(resources.Outputs))
+	for _, o := range resources.Outputs {
+		o := *o.DeepCopy()
+		if problems := applyTLSProfile(profile, &o.Spec); len(problems) > 0 {
+			reject("output", o.Namespace, o.Name, problems)
+			continue
+		}
+		outputs = append(outputs, o)
+	}
+	resources.Outputs = outputs
+
+	return resources, errs
+}
+
+// applyTLSMinVersion sets the TLS version of the output to the minimum version of the global output settings,
+// unless the output sets it
+func applyTLSMinVersion(version string, spec *v1beta1.OutputSpec) {
+	if version == "" {
+		return
+	}
+	set := func(v *string) {
+		if *v == "" {
+			*v = version
+		}
+	}
+	switch {
+	case spec.ForwardOutput != nil:
+		set(&spec.ForwardOutput.TlsVersion)
+	case spec.LoggingOperatorForward != nil:
+		set(&spec.LoggingOperatorForward.TlsVersion)
+	case spec.HTTPOutput != nil:
+		set(&spec.HTTPOutput.TlsVersion)
+	case spec.ElasticsearchOutput != nil:
+		o := spec.ElasticsearchOutput
+		if o.SslVersion != "" {
+			break
+		}
+		// The minimum version only takes effect together with the maximum version
+		set(&o.SslMinVersion)
+		if o.SslMaxVersion == "" {
+			o.SslMaxVersion = "TLSv1_3"
+		}
+	case spec.GELFOutputConfig != nil:
+		o := spec.GELFOutputConfig
+		if o.TLS == nil || !*o.TLS {
+			break
+		}
+		if o.TLSOptions == nil {
+			o.TLSOptions = make(map[string]string)
+		}
+		if o.TLSOptions["tls_version"] == "" {
+			o.TLSOptions["tls_version"] = version
+		}
+	}
+}
+
+// applyTLSMinVersionToResources returns the resources with copies of the outputs that have the minimum TLS version
+// of the global output settings applied
+func applyTLSMinVersionToResources(resources LoggingResources) LoggingResources {
+	settings := resources.Logging.Spec.GlobalOutputSettings
+	if settings == nil || settings.TLSMinVersion == "" {
+		return resources
+	}
+	clusterOutputs := make(ClusterOutputs, len(resources.ClusterOutputs))
+	for i := range resources.ClusterOutputs {
+		resources.ClusterOutputs[i].DeepCopyInto(&clusterOutputs[i])
+		applyTLSMinVersion(settings.TLSMinVersion, &clusterOutputs[i].Spec.OutputSpec)
+	}
+	resources.ClusterOutputs = clusterOutputs
+
+	outputs := make(Outputs, len(resources.Outputs))
+	for i := range resources.Outputs {
+		resources.Outputs[i].DeepCopyInto(&outputs[i])
+		applyTLSMinVersion(settings.TLSMinVersion, &outputs[i].Spec)
+	}
+	resources.Outputs = outputs
+
+	return resources
+}
@@ -0,0 +1,161 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+)
+
+func TestApplyTLSProfile(t *testing.T) {
+	spec := v1beta1.OutputSpec{ForwardOutput: &output.ForwardOutput{}}
+	if problems := applyTLSProfile(v1beta1.TLSProfileFIPS, &spec); len(problems) > 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+	if spec.ForwardOutput.TlsVersion != "TLSv1_2" || spec.ForwardOutput.TlsCiphers == "" {
+		t.Errorf("expected the profile defaults, got %q %q", spec.ForwardOutput.TlsVersion, spec.ForwardOutput.TlsCiphers)
+	}
+
+	spec = v1beta1.OutputSpec{ForwardOutput: &output.ForwardOutput{
+		TlsVersion:      "TLSv1_1",
+		TlsCiphers:      "ECDHE-RSA-AES128-GCM-SHA256:ECDHE-RSA-CHACHA20-POLY1305",
+		TlsInsecureMode: true,
+	}}
+	if problems := applyTLSProfile(v1beta1.TLSProfileFIPS, spec.DeepCopy()); len(problems) != 3 {
+		t.Errorf("expected version, cipher and insecure mode problems, got %v", problems)
+	}
+	if problems := applyTLSProfile(v1beta1.TLSProfileIntermediate, spec.DeepCopy()); len(problems) > 0 {
+		t.Errorf("the intermediate profile must not reject outputs, got %v", problems)
+	}
+
+	spec = v1beta1.OutputSpec{HTTPOutput: &output.HTTPOutputConfig{TlsVersion: "TLSv1_2"}}
+	if problems := applyTLSProfile(v1beta1.TLSProfileModern, &spec); len(problems) != 1 {
+		t.Errorf("expected a version problem, got %v", problems)
+	}
+}
+
+func TestApplyTLSProfileToResources(t *testing.T) {
+	resources := testResources(0)
+	resources.Logging.Spec.TLSProfile = v1beta1.TLSProfileModern
+	resources.Outputs = Outputs{
+		{Spec: v1beta1.OutputSpec{HTTPOutput: &output.HTTPOutputConfig{TlsVersion: "TLSv1_2"}}},
+	}
+	if _, err := applyTLSProfileToResources(v1beta1.TLSProfileModern, resources, logr.Discard()); err == nil {
+		t.Errorf("expected the output to be rejected")
+	}
+
+	resources.Logging.Spec.SkipInvalidResources = true
+	applied, err := applyTLSProfileToResources(v1beta1.TLSProfileModern, resources, logr.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied.Outputs) != 0 || len(applied.ClusterOutputs) != 1 {
+		t.Errorf("expected the output to be skipped, got %d outputs and %d clusteroutputs", len(applied.Outputs), len(applied.ClusterOutputs))
+	}
+}
+
+func TestApplyTLSProfileOutputs(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name         string
+		spec         v1beta1.OutputSpec
+		wantProblems int
+	}{
+		{name: "kafka", spec: v1beta1.OutputSpec{KafkaOutputConfig: &output.KafkaOutputConfig{}}},
+		{name: "kafka without hostname verification", spec: v1beta1.OutputSpec{KafkaOutputConfig: &output.KafkaOutputConfig{SSLVerifyHostname: &disabled}}, wantProblems: 1},
+		{name: "loki", spec: v1beta1.OutputSpec{LokiOutput: &output.LokiOutput{InsecureTLS: &disabled}}},
+		{name: "insecure loki", spec: v1beta1.OutputSpec{LokiOutput: &output.LokiOutput{InsecureTLS: &enabled}}, wantProblems: 1},
+		{name: "insecure syslog", spec: v1beta1.OutputSpec{SyslogOutputConfig: &output.SyslogOutputConfig{Insecure: &enabled, VerifyFqdn: &disabled}}, wantProblems: 2},
+		{name: "insecure datadog", spec: v1beta1.OutputSpec{DatadogOutput: &output.DatadogOutput{NoSslValidation: true}}, wantProblems: 1},
+		{name: "insecure otlp", spec: v1beta1.OutputSpec{OTLPOutput: &output.OTLPOutput{TLSInsecureSkipVerify: true}}, wantProblems: 1},
+		{name: "insecure s3", spec: v1beta1.OutputSpec{S3OutputConfig: &output.S3OutputConfig{SslVerifyPeer: "false"}}, wantProblems: 1},
+		{name: "gelf without tls", spec: v1beta1.OutputSpec{GELFOutputConfig: &output.GELFOutputConfig{TLSOptions: map[string]string{"tls_version": "TLSv1"}}}},
+		{name: "gelf", spec: v1beta1.OutputSpec{GELFOutputConfig: &output.GELFOutputConfig{TLS: &enabled, TLSOptions: map[string]string{"tls_version": "TLSv1", "all_ciphers": "true"}}}, wantProblems: 2},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if problems := applyTLSProfile(v1beta1.TLSProfileFIPS, &tt.spec); len(problems) != tt.wantProblems {
+				t.Errorf("problems = %v, want %d", problems, tt.wantProblems)
+			}
+		})
+	}
+
+	spec := v1beta1.OutputSpec{GELFOutputConfig: &output.GELFOutputConfig{TLS: &enabled}}
+	if problems := applyTLSProfile(v1beta1.TLSProfileModern, &spec); len(problems) > 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+	if version := spec.GELFOutputConfig.TLSOptions["tls_version"]; version != "TLSv1_3" {
+		t.Errorf("expected the profile version, got %q", version)
+	}
+}
+
+func TestTLSProfileOpenSSLConfig(t *testing.T) {
+	if config := TLSProfileOpenSSLConfig(""); config != "" {
+		t.Errorf("unexpected config without a profile:\n%s", config)
+	}
+	config := TLSProfileOpenSSLConfig(v1beta1.TLSProfileFIPS)
+	for _, want := range []string{
+		"MinProtocol = TLSv1.2\n",
+		"Groups = prime256v1:secp384r1:secp521r1\n",
+		"CipherString = ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256:ECDHE-ECDSA-AES256-GCM-SHA384:ECDHE-RSA-AES256-GCM-SHA384\n",
+	} {
+		if !strings.Contains(config, want) {
+			t.Errorf("expected %q in\n%s", want, config)
+		}
+	}
+	if config := TLSProfileOpenSSLConfig(v1beta1.TLSProfileModern); !strings.Contains(config, "MinProtocol = TLSv1.3\n") || strings.Contains(config, "CipherString") {
+		t.Errorf("unexpected modern config:\n%s", config)
+	}
+}
+
+func TestApplyTLSMinVersionToResources(t *testing.T) {
+	enabled := true
+	resources := testResources(0)
+	resources.Logging.Spec.GlobalOutputSettings = &v1beta1.GlobalOutputSettings{TLSMinVersion: "TLSv1_3"}
+	resources.Outputs = Outputs{
+		{Spec: v1beta1.OutputSpec{HTTPOutput: &output.HTTPOutputConfig{}}},
+		{Spec: v1beta1.OutputSpec{HTTPOutput: &output.HTTPOutputConfig{TlsVersion: "TLSv1_2"}}},
+		{Spec: v1beta1.OutputSpec{ElasticsearchOutput: &output.ElasticsearchOutput{}}},
+		{Spec: v1beta1.OutputSpec{GELFOutputConfig: &output.GELFOutputConfig{TLS: &enabled}}},
+	}
+
+	applied := applyTLSMinVersionToResources(resources)
+	if v := applied.Outputs[0].Spec.HTTPOutput.TlsVersion; v != "TLSv1_3" {
+		t.Errorf("http tls_version = %q, want the global minimum", v)
+	}
+	if v := applied.Outputs[1].Spec.HTTPOutput.TlsVersion; v != "TLSv1_2" {
+		t.Errorf("http tls_version = %q, want the version of the output", v)
+	}
+	if es := applied.Outputs[2].Spec.ElasticsearchOutput; es.SslMinVersion != "TLSv1_3" || es.SslMaxVersion != "TLSv1_3" {
+		t.Errorf("elasticsearch ssl versions = %q-%q", es.SslMinVersion, es.SslMaxVersion)
+	}
+	if v := applied.Outputs[3].Spec.GELFOutputConfig.TLSOptions["tls_version"]; v != "TLSv1_3" {
+		t.Errorf("gelf tls_version = %q", v)
+	}
+	if resources.Outputs[0].Spec.HTTPOutput.TlsVersion != "" {
+		t.Error("the original output is modified")
+	}
+
+	// A strict profile still rejects the weaker version set by the output
+	if _, err := applyTLSProfileToResources(v1beta1.TLSProfileModern, applied, logr.Discard()); err == nil {
+		t.Error("expected the output with TLSv1_2 to be rejected")
+	}
+}
@@ -254,6 +254,9 @@ func (n *nodeAgentInstance) configSecret() (runtime.Object, reconciler.DesiredSt
 	}
 
 	if utils.PointerToBool(n.nodeAgent.FluentbitSpec.EnableUpstream) {
+		if n.logging.Spec.FluentdSpec != nil && n.logging.Spec.FluentdSpec.IsDeployment() {
+			return nil, nil, errors.New("upstream requires fluentd to run as a statefulset")
+		}
 		input.Upstream.Enabled = true
 		input.Upstream.Config.Name = "fluentd-upstream"
 
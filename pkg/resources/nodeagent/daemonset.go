@@ -20,6 +20,7 @@ import (
 	"strconv"
 
 	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/resources/spiffe"
 	"github.com/banzaicloud/logging-operator/pkg/resources/templates"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
 	"github.com/banzaicloud/operator-tools/pkg/merge"
@@ -70,10 +71,11 @@ func (n *nodeAgentInstance) daemonSet() (runtime.Object, reconciler.DesiredState
 					ServiceAccountName: n.getServiceAccount(),
 					Volumes:            n.generateVolume(),
 					SecurityContext: &corev1.PodSecurityContext{
-						FSGroup:      n.nodeAgent.FluentbitSpec.Security.PodSecurityContext.FSGroup,
-						RunAsNonRoot: n.nodeAgent.FluentbitSpec.Security.PodSecurityContext.RunAsNonRoot,
-						RunAsUser:    n.nodeAgent.FluentbitSpec.Security.PodSecurityContext.RunAsUser,
-						RunAsGroup:   n.nodeAgent.FluentbitSpec.Security.PodSecurityContext.RunAsGroup,
+						FSGroup:        n.nodeAgent.FluentbitSpec.Security.PodSecurityContext.FSGroup,
+						RunAsNonRoot:   n.nodeAgent.FluentbitSpec.Security.PodSecurityContext.RunAsNonRoot,
+						RunAsUser:      n.nodeAgent.FluentbitSpec.Security.PodSecurityContext.RunAsUser,
+						RunAsGroup:     n.nodeAgent.FluentbitSpec.Security.PodSecurityContext.RunAsGroup,
+						SeccompProfile: n.nodeAgent.FluentbitSpec.Security.PodSecurityContext.SeccompProfile,
 					},
 					Containers: []corev1.Container{
 						{
@@ -87,6 +89,8 @@ func (n *nodeAgentInstance) daemonSet() (runtime.Object, reconciler.DesiredState
 								AllowPrivilegeEscalation: n.nodeAgent.FluentbitSpec.Security.SecurityContext.AllowPrivilegeEscalation,
 								Privileged:               n.nodeAgent.FluentbitSpec.Security.SecurityContext.Privileged,
 								SELinuxOptions:           n.nodeAgent.FluentbitSpec.Security.SecurityContext.SELinuxOptions,
+								Capabilities:             n.nodeAgent.FluentbitSpec.Security.SecurityContext.Capabilities,
+								SeccompProfile:           n.nodeAgent.FluentbitSpec.Security.SecurityContext.SeccompProfile,
 							},
 						},
 					},
@@ -107,6 +111,11 @@ func (n *nodeAgentInstance) daemonSet() (runtime.Object, reconciler.DesiredState
 		return desired, reconciler.StatePresent, err
 	}
 
+	if n.logging.Spec.FluentdSpec != nil && n.logging.Spec.FluentdSpec.TLS.Spiffe != nil {
+		spiffe.Apply(&desired.Spec.Template.Spec, n.logging.Spec.FluentdSpec.TLS.Spiffe, "fluent-bit-tls", n.logging.QualifiedName(spiffe.ConfigMapName), true)
+		spiffe.RestartOnRenewal(&desired.Spec.Template.Spec, n.logging.Spec.FluentdSpec.TLS.Spiffe, "fluent-bit-tls", containerName, "fluent-bit")
+	}
+
 	err := merge.Merge(desired, n.nodeAgent.FluentbitSpec.DaemonSetOverrides)
 	if err != nil {
 		return desired, reconciler.StatePresent, errors.WrapIf(err, "unable to merge overrides to base object")
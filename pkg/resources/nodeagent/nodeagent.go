@@ -289,9 +289,35 @@ type nodeAgentInstance struct {
 	fluentdDataProvider fluentddataprovider.FluentdDataProvider
 }
 
+// applyNodeAgentImage sets the image of the fluent-bit container from the image spec of the node agent
+func applyNodeAgentImage(spec *v1beta1.NodeAgentFluentbit) {
+	image := spec.Image
+	if image.Repository == "" {
+		return
+	}
+	podSpec := &spec.DaemonSetOverrides.Spec.Template.Spec
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == containerName {
+			podSpec.Containers[i].Image = image.RepositoryWithTag()
+			if image.PullPolicy != "" {
+				podSpec.Containers[i].ImagePullPolicy = v1.PullPolicy(image.PullPolicy)
+			}
+		}
+	}
+	podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, image.ImagePullSecrets...)
+}
+
 // Reconcile reconciles the NodeAgent resource
 func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
-	for _, userDefinedAgent := range r.Logging.Spec.NodeAgents {
+	agents := r.Logging.Spec.NodeAgents
+	windowsAgent, err := windowsNodeAgent(r.Logging)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to render the windows node agent")
+	}
+	if windowsAgent != nil {
+		agents = append(append([]*v1beta1.NodeAgent{}, agents...), windowsAgent)
+	}
+	for _, userDefinedAgent := range agents {
 		var instance nodeAgentInstance
 		NodeAgentFluentbitDefaults, err := NodeAgentFluentbitDefaults(&userDefinedAgent)
 		if err != nil {
@@ -315,6 +341,13 @@ func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
 		if err != nil {
 			return nil, err
 		}
+		applyNodeAgentImage(NodeAgentFluentbitDefaults.FluentbitSpec)
+		if NodeAgentFluentbitDefaults.FluentbitSpec.Security != nil {
+			NodeAgentFluentbitDefaults.FluentbitSpec.Security.ApplyCreateRBAC()
+			if NodeAgentFluentbitDefaults.FluentbitSpec.Security.PodSecurityStandard != "" {
+				return nil, errors.WithDetails(v1beta1.ErrPodSecurityStandardHostPath, "nodeAgent", userDefinedAgent.Name)
+			}
+		}
 
 		instance = nodeAgentInstance{
 			nodeAgent:           NodeAgentFluentbitDefaults,
@@ -337,18 +370,23 @@ func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
 
 // Reconcile reconciles the nodeAgentInstance resource
 func (n *nodeAgentInstance) Reconcile() (*reconcile.Result, error) {
-	for _, factory := range []resources.Resource{
+	resourceList := []resources.Resource{
 		n.serviceAccount,
 		n.clusterRole,
 		n.clusterRoleBinding,
+	}
+	resourceList = append(resourceList, resources.PodSecurityPolicyResources(n.reconciler.Client,
 		n.clusterPodSecurityPolicy,
 		n.pspClusterRole,
 		n.pspClusterRoleBinding,
+	)...)
+	resourceList = append(resourceList,
 		n.configSecret,
 		n.daemonSet,
 		n.serviceMetrics,
 		n.monitorServiceMetrics,
-	} {
+	)
+	for _, factory := range resourceList {
 		o, state, err := factory()
 		if err != nil {
 			return nil, errors.WrapIf(err, "failed to create desired object")
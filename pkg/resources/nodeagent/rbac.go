@@ -18,19 +18,29 @@ import (
 	"emperror.dev/errors"
 	"github.com/banzaicloud/operator-tools/pkg/merge"
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
 )
 
 func (n *nodeAgentInstance) clusterRole() (runtime.Object, reconciler.DesiredState, error) {
 	if *n.nodeAgent.FluentbitSpec.Security.RoleBasedAccessControlCreate {
+		clusterRoleResources := []string{"pods"}
+		if !n.nodeAgent.FluentbitSpec.Security.IsMinimalRBAC() {
+			clusterRoleResources = append(clusterRoleResources, "namespaces")
+		}
+		meta := n.NodeAgentObjectMetaClusterScope(clusterRoleName)
+		meta.Labels = util.MergeLabels(meta.Labels, n.nodeAgent.FluentbitSpec.Security.RBACLabels())
 		return &rbacv1.ClusterRole{
-			ObjectMeta: n.NodeAgentObjectMetaClusterScope(clusterRoleName),
+			ObjectMeta: meta,
 			Rules: []rbacv1.PolicyRule{
 				{
 					APIGroups: []string{""},
-					Resources: []string{"pods", "namespaces"},
+					Resources: clusterRoleResources,
 					Verbs:     []string{"get", "list", "watch"},
 				},
 			},
@@ -76,6 +86,11 @@ func (n *nodeAgentInstance) serviceAccount() (runtime.Object, reconciler.Desired
 
 		return desired, reconciler.StatePresent, nil
 	} else {
+		if n.nodeAgent.FluentbitSpec.Security.CreateRBAC == v1beta1.CreateRBACDisabled {
+			if err := resources.ValidateServiceAccount(n.reconciler.Client, n.logging.Spec.ControlNamespace, n.getServiceAccount()); err != nil {
+				return nil, reconciler.StatePresent, err
+			}
+		}
 		desired := &corev1.ServiceAccount{
 			ObjectMeta: n.NodeAgentObjectMeta(defaultServiceAccountName),
 		}
@@ -0,0 +1,96 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodeagent
+
+import (
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/operator-tools/pkg/merge"
+	"github.com/banzaicloud/operator-tools/pkg/typeoverride"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+)
+
+const WindowsNodeAgentName = "windows"
+
+// windowsNodeAgent translates fluentbitSpec.windows into a node agent using the windows profile,
+// so that it forwards to the same aggregator with the same settings as the linux daemonset
+func windowsNodeAgent(logging *v1beta1.Logging) (*v1beta1.NodeAgent, error) {
+	if logging.Spec.FluentbitSpec == nil || logging.Spec.FluentbitSpec.Windows == nil {
+		return nil, nil
+	}
+	spec := logging.Spec.FluentbitSpec
+	windows := spec.Windows
+
+	container := v1.Container{
+		Name:      containerName,
+		Resources: windows.Resources,
+	}
+	if windows.Image.Repository != "" {
+		container.Image = windows.Image.RepositoryWithTag()
+		container.ImagePullPolicy = v1.PullPolicy(windows.Image.PullPolicy)
+	}
+	overrides := &typeoverride.DaemonSet{
+		Spec: typeoverride.DaemonSetSpec{
+			Template: typeoverride.PodTemplateSpec{
+				Spec: typeoverride.PodSpec{
+					Containers:       []v1.Container{container},
+					Tolerations:      windows.Tolerations,
+					NodeSelector:     windows.NodeSelector,
+					ImagePullSecrets: windows.Image.ImagePullSecrets,
+				},
+			},
+		},
+	}
+	if windows.DaemonSetOverrides != nil {
+		if err := merge.Merge(overrides, windows.DaemonSetOverrides); err != nil {
+			return nil, err
+		}
+	}
+
+	agent := &v1beta1.NodeAgent{
+		Name:    WindowsNodeAgentName,
+		Profile: "windows",
+		FluentbitSpec: &v1beta1.NodeAgentFluentbit{
+			DaemonSetOverrides:      overrides,
+			ServiceAccountOverrides: spec.ServiceAccountOverrides,
+			TLS:                     spec.TLS,
+			TargetHost:              spec.TargetHost,
+			TargetPort:              spec.TargetPort,
+			Flush:                   spec.Flush,
+			Grace:                   spec.Grace,
+			LogLevel:                spec.LogLevel,
+			CoroStackSize:           spec.CoroStackSize,
+			InputTail: v1beta1.InputTail{
+				Path: windows.Path,
+			},
+			FilterAws:               spec.FilterAws,
+			DisableKubernetesFilter: spec.DisableKubernetesFilter,
+			CustomConfigSecret:      spec.CustomConfigSecret,
+			PodPriorityClassName:    spec.PodPriorityClassName,
+			Network:                 spec.Network,
+			ForwardOptions:          spec.ForwardOptions,
+			EnableUpstream:          util.BoolPointer(spec.EnableUpstream),
+		},
+	}
+	if spec.Security != nil {
+		// The windows agent does not run privileged, only the RBAC settings are carried over
+		agent.FluentbitSpec.Security = &v1beta1.Security{
+			ServiceAccount:               spec.Security.ServiceAccount,
+			RoleBasedAccessControlCreate: spec.Security.RoleBasedAccessControlCreate,
+			CreateRBAC:                   spec.Security.CreateRBAC,
+		}
+	}
+	return agent, nil
+}
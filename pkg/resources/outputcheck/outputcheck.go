@@ -0,0 +1,366 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	util "github.com/banzaicloud/operator-tools/pkg/utils"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/logging-operator/pkg/resources/model"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// ComponentOutputCheck is the component label of the output check pods and secrets
+const ComponentOutputCheck = "fluentd-outputcheck"
+
+// Reconciler probes the endpoints of the outputs before the fluentd configuration is rolled out
+type Reconciler struct {
+	Logging *v1beta1.Logging
+	*reconciler.GenericResourceReconciler
+	resources model.LoggingResources
+}
+
+// New creates a new output check reconciler
+func New(client client.Client, logger logr.Logger, logging *v1beta1.Logging, resources model.LoggingResources, opts reconciler.ReconcilerOpts) *Reconciler {
+	return &Reconciler{
+		Logging:                   logging,
+		GenericResourceReconciler: reconciler.NewGenericReconciler(client, logger, opts),
+		resources:                 resources,
+	}
+}
+
+// Reconcile runs the check pod for the current endpoints of the outputs and records the results in their status.
+// Endpoints with an existing result are not probed again. Only a blocking check holds back the rest of the
+// reconcilers until the result is known, otherwise the completion of the check pod triggers the next reconcile.
+func (r *Reconciler) Reconcile() (*reconcile.Result, error) {
+	check := r.Logging.Spec.OutputCheck
+	if check == nil || !check.Enabled || r.Logging.Spec.FluentdSpec == nil {
+		return nil, nil
+	}
+	ctx := context.Background()
+
+	targets, err := r.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	hash, err := targetsHash(targets, check.TimeoutSeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	if passed, ok := r.Logging.Status.OutputCheckResults[hash]; ok {
+		if err := r.cleanup(ctx, hash); err != nil {
+			r.Log.Error(err, "failed to cleanup output check resources")
+		}
+		if !passed && check.Blocking {
+			return nil, errors.New("outputs failed the output check, see the status of the outputs")
+		}
+		return nil, nil
+	}
+
+	checkSecret, err := r.checkSecret(hash, targets)
+	if err != nil {
+		return nil, err
+	}
+	if result, err := r.ReconcileResource(checkSecret, reconciler.StatePresent); err != nil {
+		return nil, errors.WrapIf(err, "failed to reconcile output check secret")
+	} else if result != nil {
+		return result, nil
+	}
+
+	pod := r.checkPod(hash, len(targets))
+	err = r.Client.Get(ctx, client.ObjectKeyFromObject(pod), pod)
+	if apierrors.IsNotFound(err) {
+		if err := r.Client.Create(ctx, pod); err != nil {
+			return nil, errors.WrapIf(err, "failed to create output check pod")
+		}
+		return r.waitForResult(), nil
+	}
+	if err != nil {
+		return nil, errors.WrapIff(err, "failed to get output check pod %s:%s", pod.Namespace, pod.Name)
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded, corev1.PodFailed:
+	default:
+		r.Log.Info("still waiting for the output check result...")
+		return r.waitForResult(), nil
+	}
+
+	passed, err := r.recordResults(ctx, targets, terminationMessage(pod))
+	if err != nil {
+		return nil, err
+	}
+	patchBase := client.MergeFrom(r.Logging.DeepCopy())
+	r.Logging.Status.OutputCheckResults[hash] = passed
+	if err := r.Client.Status().Patch(ctx, r.Logging, patchBase); err != nil {
+		return nil, errors.WrapWithDetails(err, "failed to patch status", "logging", r.Logging)
+	}
+	if !check.Blocking {
+		return nil, nil
+	}
+	// explicitly ask for a requeue to short circuit the controller loop after the status update
+	return &reconcile.Result{Requeue: true}, nil
+}
+
+// waitForResult short circuits the controller loop while a blocking check is running
+func (r *Reconciler) waitForResult() *reconcile.Result {
+	if !r.Logging.Spec.OutputCheck.Blocking {
+		return nil
+	}
+	return &reconcile.Result{RequeueAfter: 10 * time.Second}
+}
+
+// targets returns the endpoints of the accepted outputs
+func (r *Reconciler) targets(ctx context.Context) ([]target, error) {
+	var targets []target
+	for _, o := range r.resources.ClusterOutputs {
+		t, err := outputTargets(ctx, r.Client, outputKey("ClusterOutput", o.Namespace, o.Name), o.Namespace, o.Spec.OutputSpec)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to collect the endpoints of clusteroutput", "name", o.Name)
+		}
+		targets = append(targets, t...)
+	}
+	for _, o := range r.resources.Outputs {
+		t, err := outputTargets(ctx, r.Client, outputKey("Output", o.Namespace, o.Name), o.Namespace, o.Spec)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to collect the endpoints of output", "name", o.Name, "namespace", o.Namespace)
+		}
+		targets = append(targets, t...)
+	}
+	return targets, nil
+}
+
+// recordResults updates the status of the outputs with the results of their endpoints and tells whether all passed
+func (r *Reconciler) recordResults(ctx context.Context, targets []target, message string) (bool, error) {
+	results := parseProbeResults(message)
+	statuses := make(map[string]*v1beta1.OutputCheckStatus)
+	now := metav1.Now()
+	passed := true
+	for i, t := range targets {
+		status, ok := statuses[t.Output]
+		if !ok {
+			status = &v1beta1.OutputCheckStatus{Reachable: true, CheckedAt: now}
+			statuses[t.Output] = status
+		}
+		result, ok := results[i]
+		if !ok {
+			result = probeResult{reason: fmt.Sprintf("%s: no result, the check pod failed", t.Address)}
+		}
+		if !result.ok {
+			passed = false
+			if status.Reachable {
+				status.Reachable = false
+				status.Message = result.reason
+			}
+		}
+	}
+
+	for i := range r.resources.ClusterOutputs {
+		o := &r.resources.ClusterOutputs[i]
+		if status, ok := statuses[outputKey("ClusterOutput", o.Namespace, o.Name)]; ok {
+			patch := client.MergeFrom(o.DeepCopy())
+			o.Status.Check = status
+			if err := r.Client.Status().Patch(ctx, o, patch); err != nil {
+				return false, errors.WrapIfWithDetails(err, "failed to update output check status", "clusteroutput", o.Name)
+			}
+		}
+	}
+	for i := range r.resources.Outputs {
+		o := &r.resources.Outputs[i]
+		if status, ok := statuses[outputKey("Output", o.Namespace, o.Name)]; ok {
+			patch := client.MergeFrom(o.DeepCopy())
+			o.Status.Check = status
+			if err := r.Client.Status().Patch(ctx, o, patch); err != nil {
+				return false, errors.WrapIfWithDetails(err, "failed to update output check status", "output", o.Name, "namespace", o.Namespace)
+			}
+		}
+	}
+	return passed, nil
+}
+
+// cleanup removes the check pods and secrets and the results of endpoints other than the current ones
+func (r *Reconciler) cleanup(ctx context.Context, currentHash string) error {
+	current := r.Logging.QualifiedName(checkName(currentHash))
+	labels := client.MatchingLabels(r.Logging.GetFluentdLabels(ComponentOutputCheck))
+	namespace := client.InNamespace(r.Logging.Spec.ControlNamespace)
+
+	var multierr error
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods, labels, namespace); err != nil {
+		return errors.WrapIf(err, "failed to list output check pods")
+	}
+	for i := range pods.Items {
+		if pods.Items[i].Name != current {
+			if err := r.Client.Delete(ctx, &pods.Items[i]); client.IgnoreNotFound(err) != nil {
+				multierr = errors.Combine(multierr, errors.WrapIff(err, "failed to remove output check pod %s", pods.Items[i].Name))
+			}
+		}
+	}
+	var secrets corev1.SecretList
+	if err := r.Client.List(ctx, &secrets, labels, namespace); err != nil {
+		return errors.Combine(multierr, errors.WrapIf(err, "failed to list output check secrets"))
+	}
+	for i := range secrets.Items {
+		if secrets.Items[i].Name != current {
+			if err := r.Client.Delete(ctx, &secrets.Items[i]); client.IgnoreNotFound(err) != nil {
+				multierr = errors.Combine(multierr, errors.WrapIff(err, "failed to remove output check secret %s", secrets.Items[i].Name))
+			}
+		}
+	}
+
+	if multierr == nil && len(r.Logging.Status.OutputCheckResults) > 1 {
+		patchBase := client.MergeFrom(r.Logging.DeepCopy())
+		for hash := range r.Logging.Status.OutputCheckResults {
+			if hash != currentHash {
+				delete(r.Logging.Status.OutputCheckResults, hash)
+			}
+		}
+		multierr = errors.WrapIf(r.Client.Status().Patch(ctx, r.Logging, patchBase), "failed to patch status")
+	}
+	return multierr
+}
+
+func (r *Reconciler) checkSecret(hash string, targets []target) (*corev1.Secret, error) {
+	data, err := json.Marshal(targets)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to marshal output check targets")
+	}
+	return &corev1.Secret{
+		ObjectMeta: r.Logging.FluentdObjectMeta(checkName(hash), ComponentOutputCheck),
+		Data: map[string][]byte{
+			targetsKey:     data,
+			probeScriptKey: []byte(probeScript),
+		},
+	}, nil
+}
+
+func (r *Reconciler) checkPod(hash string, targets int) *corev1.Pod {
+	spec := r.Logging.Spec.FluentdSpec
+	timeout := r.Logging.Spec.OutputCheck.TimeoutSeconds
+	// Every probe is bounded by the timeout, the deadline leaves time for pulling the image
+	deadline := int64(timeout*targets + 300)
+
+	pod := &corev1.Pod{
+		ObjectMeta: r.Logging.FluentdObjectMeta(checkName(hash), ComponentOutputCheck),
+		Spec: corev1.PodSpec{
+			RestartPolicy:         corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds: &deadline,
+			// The probe does not use the API, and the service account of fluentd may not exist yet
+			AutomountServiceAccountToken: util.BoolPointer(false),
+			NodeSelector:                 spec.NodeSelector,
+			Tolerations:                  spec.Tolerations,
+			Affinity:                     spec.Affinity,
+			PriorityClassName:            spec.PodPriorityClassName,
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot:   spec.Security.PodSecurityContext.RunAsNonRoot,
+				FSGroup:        spec.Security.PodSecurityContext.FSGroup,
+				RunAsUser:      spec.Security.PodSecurityContext.RunAsUser,
+				RunAsGroup:     spec.Security.PodSecurityContext.RunAsGroup,
+				SeccompProfile: spec.Security.PodSecurityContext.SeccompProfile,
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "outputcheck",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName: r.Logging.QualifiedName(checkName(hash)),
+						},
+					},
+				},
+			},
+			ImagePullSecrets: spec.Image.ImagePullSecrets,
+			Containers: []corev1.Container{
+				{
+					Name:            "outputcheck",
+					Image:           spec.Image.RepositoryWithTag(),
+					ImagePullPolicy: corev1.PullPolicy(spec.Image.PullPolicy),
+					Command:         []string{"ruby", probePath + "/" + probeScriptKey},
+					Env: []corev1.EnvVar{
+						{Name: "PROBE_TIMEOUT", Value: strconv.Itoa(timeout)},
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "outputcheck",
+							MountPath: probePath,
+						},
+					},
+					SecurityContext: &corev1.SecurityContext{
+						RunAsUser:                spec.Security.SecurityContext.RunAsUser,
+						RunAsGroup:               spec.Security.SecurityContext.RunAsGroup,
+						ReadOnlyRootFilesystem:   spec.Security.SecurityContext.ReadOnlyRootFilesystem,
+						AllowPrivilegeEscalation: spec.Security.SecurityContext.AllowPrivilegeEscalation,
+						Privileged:               spec.Security.SecurityContext.Privileged,
+						RunAsNonRoot:             spec.Security.SecurityContext.RunAsNonRoot,
+						SELinuxOptions:           spec.Security.SecurityContext.SELinuxOptions,
+						Capabilities:             spec.Security.SecurityContext.Capabilities,
+						SeccompProfile:           spec.Security.SecurityContext.SeccompProfile,
+					},
+					Resources: spec.ConfigCheckResources,
+				},
+			},
+		},
+	}
+	if spec.ConfigCheckAnnotations != nil {
+		pod.Annotations = spec.ConfigCheckAnnotations
+	}
+	if r.Logging.Spec.ServiceMesh.IsEnabled() {
+		pod.Annotations = util.MergeLabels(pod.Annotations, r.Logging.Spec.ServiceMesh.JobAnnotations())
+	}
+	return pod
+}
+
+func terminationMessage(pod *corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil {
+			return status.State.Terminated.Message
+		}
+	}
+	return ""
+}
+
+func targetsHash(targets []target, timeout int) (string, error) {
+	hasher := fnv.New32()
+	if err := json.NewEncoder(hasher).Encode(targets); err != nil {
+		return "", errors.WrapIf(err, "failed to calculate hash of the output check targets")
+	}
+	fmt.Fprint(hasher, timeout)
+	return fmt.Sprintf("%x", hasher.Sum32()), nil
+}
+
+func checkName(hash string) string {
+	return fmt.Sprintf("fluentd-outputcheck-%s", hash)
+}
+
+func outputKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
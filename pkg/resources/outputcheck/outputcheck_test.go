@@ -0,0 +1,88 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputcheck
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+)
+
+func TestOutputTargets(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "app"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}).Build()
+
+	tests := map[string]struct {
+		spec     v1beta1.OutputSpec
+		expected []target
+	}{
+		"elasticsearch": {
+			spec: v1beta1.OutputSpec{ElasticsearchOutput: &output.ElasticsearchOutput{
+				Host:     "es.app.svc",
+				Scheme:   "https",
+				User:     "elastic",
+				Password: &secret.Secret{MountFrom: &secret.ValueFrom{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "es"}, Key: "password"}}},
+			}},
+			expected: []target{{Output: "out", Type: probeHTTP, Address: "https://es.app.svc:9200/", Username: "elastic", Password: "secret"}},
+		},
+		"kafka": {
+			spec: v1beta1.OutputSpec{KafkaOutputConfig: &output.KafkaOutputConfig{Brokers: "kafka-0:9092, kafka-1:9092"}},
+			expected: []target{
+				{Output: "out", Type: probeKafka, Address: "kafka-0:9092"},
+				{Output: "out", Type: probeKafka, Address: "kafka-1:9092"},
+			},
+		},
+		"forward": {
+			spec:     v1beta1.OutputSpec{ForwardOutput: &output.ForwardOutput{FluentdServers: []output.FluentdServer{{Host: "aggregator"}}}},
+			expected: []target{{Output: "out", Type: probeTCP, Address: "aggregator:24224"}},
+		},
+		"not probed": {
+			spec: v1beta1.OutputSpec{NullOutputConfig: output.NewNullOutputConfig()},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			targets, err := outputTargets(context.Background(), c, "out", "app", test.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(targets, test.expected) {
+				t.Fatalf("expected %+v, got %+v", test.expected, targets)
+			}
+		})
+	}
+}
+
+func TestParseProbeResults(t *testing.T) {
+	results := parseProbeResults("0 ok\n1 fail kafka-1:9092: Connection refused - connect(2)\ngarbage\n")
+	expected := map[int]probeResult{
+		0: {ok: true},
+		1: {reason: "kafka-1:9092: Connection refused - connect(2)"},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, results)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputcheck
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+)
+
+const (
+	probeScriptKey = "probe.rb"
+	targetsKey     = "targets.json"
+	probePath      = "/outputcheck"
+)
+
+// probeScript runs in the fluentd image, so that the endpoints are probed with the same network access as fluentd.
+// It prints a line for every target into the termination message: "<index> ok" or "<index> fail <reason>".
+// The reasons are shortened to keep the message below its 4096 bytes limit.
+const probeScript = `require 'json'
+require 'net/http'
+require 'openssl'
+require 'socket'
+
+targets = JSON.parse(File.read(File.join(__dir__, '` + targetsKey + `')))
+timeout = Integer(ENV.fetch('PROBE_TIMEOUT', '10'))
+reason_size = [3900 / [targets.size, 1].max - 8, 16].max
+
+def split_address(address, default_port)
+  host, port = address.split(':')
+  [host, (port || default_port).to_i]
+end
+
+def probe_tcp(t, timeout)
+  host, port = split_address(t['address'], 0)
+  Socket.tcp(host, port, connect_timeout: timeout).close
+end
+
+def probe_kafka(t, timeout)
+  host, port = split_address(t['address'], 9092)
+  Socket.tcp(host, port, connect_timeout: timeout) do |s|
+    client_id = 'logging-operator'
+    # Metadata request v0 with an empty topic list, answered with the metadata of every topic
+    request = [3, 0, 1, client_id.bytesize].pack('s>s>l>s>') + client_id + [0].pack('l>')
+    s.write([request.bytesize].pack('l>') + request)
+    raise 'no metadata response' unless IO.select([s], nil, nil, timeout)
+    size = s.read(4)&.unpack1('l>')
+    raise 'invalid metadata response' if size.nil? || size < 4
+  end
+end
+
+def probe_http(t, timeout)
+  uri = URI(t['address'])
+  Net::HTTP.start(uri.host, uri.port, use_ssl: uri.scheme == 'https', verify_mode: OpenSSL::SSL::VERIFY_NONE,
+                  open_timeout: timeout, read_timeout: timeout) do |http|
+    request = Net::HTTP::Get.new(uri.request_uri)
+    request.basic_auth(t['username'], t['password']) if t['username']
+    response = http.request(request)
+    raise "authentication failed with status #{response.code}" if %w[401 403 407].include?(response.code)
+  end
+end
+
+results = targets.each_with_index.map do |t, i|
+  begin
+    send("probe_#{t['type']}", t, timeout)
+    "#{i} ok"
+  rescue StandardError, Timeout::Error => e
+    "#{i} fail #{t['address']}: #{e.message}".gsub(/\s+/, ' ')[0, reason_size]
+  end
+end
+File.write('/dev/termination-log', results.join("\n"))
+`
+
+// probeResult is the outcome of probing a target
+type probeResult struct {
+	ok     bool
+	reason string
+}
+
+// parseProbeResults parses the termination message of the probe, targets missing from it are not in the result
+func parseProbeResults(message string) map[int]probeResult {
+	results := make(map[int]probeResult)
+	scanner := bufio.NewScanner(strings.NewReader(message))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		index, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		result := probeResult{ok: fields[1] == "ok"}
+		if len(fields) == 3 {
+			result.reason = fields[2]
+		}
+		results[index] = result
+	}
+	return results
+}
@@ -0,0 +1,180 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputcheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+const (
+	probeTCP   = "tcp"
+	probeHTTP  = "http"
+	probeKafka = "kafka"
+)
+
+// target is an endpoint of an output probed by the check pod
+type target struct {
+	// Output is the kind, namespace and name of the output the endpoint belongs to
+	Output   string `json:"output"`
+	Type     string `json:"type"`
+	Address  string `json:"address"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// outputTargets returns the endpoints of the outputs that can be probed, other outputs are not checked
+func outputTargets(ctx context.Context, c client.Reader, outputKey, namespace string, spec v1beta1.OutputSpec) ([]target, error) {
+	load := func(s *secret.Secret) (string, error) {
+		return loadSecret(ctx, c, namespace, s)
+	}
+	var targets []target
+	add := func(probe, address string, username, password *secret.Secret) error {
+		t := target{Output: outputKey, Type: probe, Address: address}
+		var err error
+		if t.Username, err = load(username); err != nil {
+			return err
+		}
+		if t.Password, err = load(password); err != nil {
+			return err
+		}
+		targets = append(targets, t)
+		return nil
+	}
+	plain := func(s string) *secret.Secret {
+		if s == "" {
+			return nil
+		}
+		return &secret.Secret{Value: s}
+	}
+
+	var err error
+	switch {
+	case spec.ElasticsearchOutput != nil && spec.ElasticsearchOutput.Host != "":
+		o := spec.ElasticsearchOutput
+		err = add(probeHTTP, httpURL(o.Scheme, o.Host, o.Port, 9200), plain(o.User), o.Password)
+	case spec.OpenSearchOutput != nil && spec.OpenSearchOutput.Host != "":
+		o := spec.OpenSearchOutput
+		err = add(probeHTTP, httpURL(o.Scheme, o.Host, o.Port, 9200), plain(o.User), o.Password)
+	case spec.HTTPOutput != nil && spec.HTTPOutput.Endpoint != "":
+		o := spec.HTTPOutput
+		if o.Auth != nil {
+			err = add(probeHTTP, o.Endpoint, o.Auth.Username, o.Auth.Password)
+		} else {
+			err = add(probeHTTP, o.Endpoint, nil, nil)
+		}
+	case spec.LokiOutput != nil && spec.LokiOutput.Url != "":
+		err = add(probeHTTP, spec.LokiOutput.Url, spec.LokiOutput.Username, spec.LokiOutput.Password)
+	case spec.SplunkHecOutput != nil && spec.SplunkHecOutput.HecHost != "":
+		o := spec.SplunkHecOutput
+		protocol := o.Protocol
+		if protocol == "" {
+			protocol = "https"
+		}
+		err = add(probeHTTP, httpURL(protocol, o.HecHost, o.HecPort, 8088)+"services/collector/health", nil, nil)
+	case spec.KafkaOutputConfig != nil:
+		o := spec.KafkaOutputConfig
+		probe := probeKafka
+		if o.SSLCACert != nil || o.SSLClientCert != nil || o.Username != nil {
+			// Only plaintext brokers answer the metadata request of the probe, the others are connected to
+			probe = probeTCP
+		}
+		for _, broker := range strings.Split(o.Brokers, ",") {
+			if broker = strings.TrimSpace(broker); broker != "" {
+				if err = add(probe, broker, nil, nil); err != nil {
+					break
+				}
+			}
+		}
+	case spec.ForwardOutput != nil:
+		for _, server := range spec.ForwardOutput.FluentdServers {
+			if err = add(probeTCP, hostPort(server.Host, server.Port, 24224), nil, nil); err != nil {
+				break
+			}
+		}
+	case spec.LoggingOperatorForward != nil:
+		err = add(probeTCP, hostPort(spec.LoggingOperatorForward.Host, spec.LoggingOperatorForward.Port, 24240), nil, nil)
+	case spec.SyslogOutputConfig != nil && spec.SyslogOutputConfig.Transport != "udp":
+		err = add(probeTCP, hostPort(spec.SyslogOutputConfig.Host, spec.SyslogOutputConfig.Port, 514), nil, nil)
+	case spec.GELFOutputConfig != nil && spec.GELFOutputConfig.Protocol == "tcp":
+		err = add(probeTCP, hostPort(spec.GELFOutputConfig.Host, spec.GELFOutputConfig.Port, 12201), nil, nil)
+	case spec.NATSOutput != nil:
+		host := spec.NATSOutput.Host
+		if host == "" {
+			host = "localhost"
+		}
+		err = add(probeTCP, hostPort(host, spec.NATSOutput.Port, 4222), nil, nil)
+	case spec.RedisOutputConfig != nil:
+		host := spec.RedisOutputConfig.Host
+		if host == "" {
+			host = "localhost"
+		}
+		err = add(probeTCP, hostPort(host, spec.RedisOutputConfig.Port, 6379), nil, nil)
+	}
+	return targets, err
+}
+
+func httpURL(scheme, host string, port, defaultPort int) string {
+	if scheme == "" {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/", scheme, hostPort(host, port, defaultPort))
+}
+
+func hostPort(host string, port, defaultPort int) string {
+	if port == 0 {
+		port = defaultPort
+	}
+	return fmt.Sprintf("%s:%d", host, port)
+}
+
+// loadSecret returns the value of the secret, mounted secrets are read as well since the probe does not mount them
+func loadSecret(ctx context.Context, c client.Reader, namespace string, s *secret.Secret) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+	if s.Value != "" {
+		return s.Value, nil
+	}
+	ref := s.ValueFrom
+	if ref == nil {
+		ref = s.MountFrom
+	}
+	if ref == nil || ref.SecretKeyRef == nil {
+		return "", nil
+	}
+	name := ref.SecretKeyRef.Name
+	if i := strings.Index(name, "/"); i >= 0 {
+		// Qualified references are validated when the configuration is rendered
+		namespace, name = name[:i], name[i+1:]
+	}
+	var k8sSecret corev1.Secret
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &k8sSecret); err != nil {
+		return "", errors.WrapIfWithDetails(err, "failed to load secret", "namespace", namespace, "name", name)
+	}
+	value, ok := k8sSecret.Data[ref.SecretKeyRef.Key]
+	if !ok {
+		return "", errors.Errorf("key %q not found in secret %s/%s", ref.SecretKeyRef.Key, namespace, name)
+	}
+	return string(value), nil
+}
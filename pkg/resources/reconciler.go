@@ -15,9 +15,16 @@
 package resources
 
 import (
+	"context"
+
+	"emperror.dev/errors"
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -29,3 +36,21 @@ type Resource func() (runtime.Object, reconciler.DesiredState, error)
 
 // ResourceWithLog redeclaration of function with logging parameter and return type kubernetes Object
 type ResourceWithLog func(log logr.Logger) runtime.Object
+
+// ValidateServiceAccount checks that a service account not managed by the operator exists
+func ValidateServiceAccount(c client.Client, namespace, name string) error {
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, &corev1.ServiceAccount{})
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "referenced service account is not available", "namespace", namespace, "name", name)
+	}
+	return nil
+}
+
+// PodSecurityPolicyResources returns the given resources only if the cluster still serves
+// the PodSecurityPolicy API, which has been removed in Kubernetes 1.25
+func PodSecurityPolicyResources(c client.Client, res ...Resource) []Resource {
+	if _, err := c.RESTMapper().RESTMapping(schema.GroupKind{Group: "policy", Kind: "PodSecurityPolicy"}, "v1beta1"); err != nil {
+		return nil
+	}
+	return res
+}
@@ -0,0 +1,59 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// FieldOwner is the field manager of the fields applied by the operator
+const FieldOwner = "logging-operator"
+
+// NewServerSideApplyClient returns a client that turns the creates and updates of the resource reconcilers
+// into server-side applies. The operator owns only the fields it sets, so fields defaulted by the api server
+// or managed by other controllers are left alone. The fields it sets are taken over by force though: the replicas
+// of an autoscaled workload are only left to the autoscaler while they are unset in the logging, for example
+// fluentd.scaling.replicas, otherwise every update of the workload resets them.
+func NewServerSideApplyClient(c client.Client) client.Client {
+	return &serverSideApplyClient{Client: c}
+}
+
+type serverSideApplyClient struct {
+	client.Client
+}
+
+func (c *serverSideApplyClient) Create(ctx context.Context, obj client.Object, _ ...client.CreateOption) error {
+	return c.apply(ctx, obj)
+}
+
+func (c *serverSideApplyClient) Update(ctx context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	return c.apply(ctx, obj)
+}
+
+func (c *serverSideApplyClient) apply(ctx context.Context, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return errors.WrapIf(err, "failed to determine the kind of the object to apply")
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	// Applied configurations must not carry the version and the field ownership of the existing object
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+	return c.Client.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldOwner), client.ForceOwnership)
+}
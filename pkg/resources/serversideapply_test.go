@@ -0,0 +1,100 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// patchRecorder records the patches instead of sending them, the fake client does not support server-side apply
+type patchRecorder struct {
+	client.Client
+	patchType types.PatchType
+	body      map[string]interface{}
+	options   client.PatchOptions
+}
+
+func (r *patchRecorder) Patch(_ context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	r.patchType = patch.Type()
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	r.body = nil
+	if err := json.Unmarshal(data, &r.body); err != nil {
+		return err
+	}
+	r.options.ApplyOptions(opts)
+	return nil
+}
+
+func TestServerSideApplyClient(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	recorder := &patchRecorder{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+	c := NewServerSideApplyClient(recorder)
+
+	for name, write := range map[string]func(client.Object) error{
+		"create": func(obj client.Object) error { return c.Create(context.Background(), obj) },
+		"update": func(obj client.Object) error { return c.Update(context.Background(), obj) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			recorder.options = client.PatchOptions{}
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "fluentd",
+					Namespace:       "logging",
+					ResourceVersion: "42",
+					ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "kube-controller-manager"}},
+				},
+			}
+			if err := write(sts); err != nil {
+				t.Fatal(err)
+			}
+			if recorder.patchType != types.ApplyPatchType {
+				t.Errorf("patch type = %s, want %s", recorder.patchType, types.ApplyPatchType)
+			}
+			if recorder.options.FieldManager != FieldOwner || recorder.options.Force == nil || !*recorder.options.Force {
+				t.Errorf("unexpected patch options %+v", recorder.options)
+			}
+			if recorder.body["apiVersion"] != "apps/v1" || recorder.body["kind"] != "StatefulSet" {
+				t.Errorf("the kind of the object is not set: %v", recorder.body)
+			}
+			metadata := recorder.body["metadata"].(map[string]interface{})
+			if _, ok := metadata["resourceVersion"]; ok {
+				t.Errorf("the resource version is applied: %v", metadata)
+			}
+			if _, ok := metadata["managedFields"]; ok {
+				t.Errorf("the managed fields are applied: %v", metadata)
+			}
+			// Unset replicas are left to an autoscaler
+			if _, ok := recorder.body["spec"].(map[string]interface{})["replicas"]; ok {
+				t.Errorf("unset replicas are applied: %v", recorder.body["spec"])
+			}
+		})
+	}
+}
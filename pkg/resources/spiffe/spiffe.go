@@ -0,0 +1,153 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spiffe
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+const (
+	// ConfigMapName is the name of the spiffe-helper configuration, qualified with the name of the logging
+	ConfigMapName = "spiffe-helper"
+	// ConfigKey is the key of the spiffe-helper configuration in the ConfigMap
+	ConfigKey = "helper.conf"
+
+	socketVolumeName = "spiffe-workload-api"
+	socketPath       = "/spiffe-workload-api"
+	configVolumeName = "spiffe-helper-config"
+	configPath       = "/etc/spiffe-helper"
+	certPath         = "/certs"
+
+	// RestartContainerName is the name of the sidecar restarting the process reading the SVID on renewal
+	RestartContainerName = "spiffe-restart"
+)
+
+// restartScript stops the process once the SVID files change, their checksum is taken every 10 seconds
+const restartScript = `checksum() { cat "$CERT_DIR"/* 2>/dev/null | md5sum; }
+last=$(checksum)
+while sleep 10; do
+  current=$(checksum)
+  [ "$current" = "$last" ] && continue
+  sleep 2
+  last=$(checksum)
+  echo "SVID renewed, restarting $PROCESS_NAME"
+  pkill -TERM -x "$PROCESS_NAME"
+done
+`
+
+// HelperConfig renders the spiffe-helper configuration, the certificates are written with the names of the keys of
+// the TLS secrets they replace
+func HelperConfig(spec *v1beta1.SpiffeTLS) string {
+	return fmt.Sprintf(`agent_address = "%s/%s"
+cert_dir = "%s"
+svid_file_name = "tls.crt"
+svid_key_file_name = "tls.key"
+svid_bundle_file_name = "ca.crt"
+`, socketPath, spec.SocketName, certPath)
+}
+
+// Apply replaces the TLS secret volume of the pod with a memory volume the SVIDs are written to by spiffe-helper:
+// an init container fetches the first SVID before the pod starts, a sidecar renews it unless the pod runs to
+// completion. Pods without the TLS volume are left unchanged.
+func Apply(podSpec *corev1.PodSpec, spec *v1beta1.SpiffeTLS, tlsVolumeName, configMapName string, renew bool) {
+	var tlsVolume *corev1.Volume
+	for i := range podSpec.Volumes {
+		if podSpec.Volumes[i].Name == tlsVolumeName {
+			tlsVolume = &podSpec.Volumes[i]
+		}
+	}
+	if tlsVolume == nil {
+		return
+	}
+	tlsVolume.VolumeSource = corev1.VolumeSource{
+		EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+	}
+	hostPathType := corev1.HostPathDirectory
+	podSpec.Volumes = append(podSpec.Volumes,
+		corev1.Volume{
+			Name: socketVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: spec.SocketDir, Type: &hostPathType},
+			},
+		},
+		corev1.Volume{
+			Name: configVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: configMapName},
+				},
+			},
+		},
+	)
+
+	helper := func(name string, daemon bool) corev1.Container {
+		return corev1.Container{
+			Name:            name,
+			Image:           spec.Image.RepositoryWithTag(),
+			ImagePullPolicy: corev1.PullPolicy(spec.Image.PullPolicy),
+			Args:            []string{"-config", configPath + "/" + ConfigKey, fmt.Sprintf("-daemon-mode=%t", daemon)},
+			Resources:       spec.Resources,
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: socketVolumeName, MountPath: socketPath, ReadOnly: true},
+				{Name: configVolumeName, MountPath: configPath, ReadOnly: true},
+				{Name: tlsVolumeName, MountPath: certPath},
+			},
+		}
+	}
+	podSpec.InitContainers = append([]corev1.Container{helper("spiffe-helper-init", false)}, podSpec.InitContainers...)
+	if renew {
+		podSpec.Containers = append(podSpec.Containers, helper("spiffe-helper", true))
+	}
+}
+
+// RestartOnRenewal adds a sidecar stopping the named process of the container once the SVID is renewed, for
+// processes reading their certificates only when they start. The pod shares its process namespace and the kubelet
+// restarts the stopped container, the SVID is kept in the memory volume in the meantime. The sidecar runs with the
+// security context of the container to be allowed to signal it. Pods without the TLS volume are left unchanged.
+func RestartOnRenewal(podSpec *corev1.PodSpec, spec *v1beta1.SpiffeTLS, tlsVolumeName, containerName, processName string) {
+	var container *corev1.Container
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == containerName {
+			container = &podSpec.Containers[i]
+		}
+	}
+	hasVolume := false
+	for _, v := range podSpec.Volumes {
+		hasVolume = hasVolume || v.Name == tlsVolumeName
+	}
+	if container == nil || !hasVolume {
+		return
+	}
+	shareProcessNamespace := true
+	podSpec.ShareProcessNamespace = &shareProcessNamespace
+	podSpec.Containers = append(podSpec.Containers, corev1.Container{
+		Name:            RestartContainerName,
+		Image:           spec.RestartImage.RepositoryWithTag(),
+		ImagePullPolicy: corev1.PullPolicy(spec.RestartImage.PullPolicy),
+		Command:         []string{"sh", "-c", restartScript},
+		Env: []corev1.EnvVar{
+			{Name: "CERT_DIR", Value: certPath},
+			{Name: "PROCESS_NAME", Value: processName},
+		},
+		SecurityContext: container.SecurityContext.DeepCopy(),
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: tlsVolumeName, MountPath: certPath, ReadOnly: true},
+		},
+	})
+}
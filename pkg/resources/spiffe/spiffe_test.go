@@ -0,0 +1,156 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spiffe
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+var testSpec = &v1beta1.SpiffeTLS{
+	SocketDir:    "/run/spire/sockets",
+	SocketName:   "agent.sock",
+	Image:        v1beta1.ImageSpec{Repository: "spiffe-helper", Tag: "0.8.0", PullPolicy: "IfNotPresent"},
+	RestartImage: v1beta1.ImageSpec{Repository: "busybox", Tag: "1.36", PullPolicy: "IfNotPresent"},
+}
+
+func testPodSpec() *corev1.PodSpec {
+	runAsUser := int64(1000)
+	return &corev1.PodSpec{
+		Volumes: []corev1.Volume{{
+			Name:         "tls",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "tls"}},
+		}},
+		InitContainers: []corev1.Container{{Name: "init"}},
+		Containers: []corev1.Container{{
+			Name:            "app",
+			SecurityContext: &corev1.SecurityContext{RunAsUser: &runAsUser},
+			VolumeMounts:    []corev1.VolumeMount{{Name: "tls", MountPath: "/tls"}},
+		}},
+	}
+}
+
+func containerNames(containers []corev1.Container) []string {
+	var names []string
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestHelperConfig(t *testing.T) {
+	want := `agent_address = "/spiffe-workload-api/agent.sock"
+cert_dir = "/certs"
+svid_file_name = "tls.crt"
+svid_key_file_name = "tls.key"
+svid_bundle_file_name = "ca.crt"
+`
+	if got := HelperConfig(testSpec); got != want {
+		t.Errorf("HelperConfig() = %q, want %q", got, want)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name           string
+		renew          bool
+		wantInit       []string
+		wantContainers []string
+	}{
+		{
+			name:           "renewing sidecar",
+			renew:          true,
+			wantInit:       []string{"spiffe-helper-init", "init"},
+			wantContainers: []string{"app", "spiffe-helper"},
+		},
+		{
+			name:           "run to completion",
+			renew:          false,
+			wantInit:       []string{"spiffe-helper-init", "init"},
+			wantContainers: []string{"app"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			podSpec := testPodSpec()
+			Apply(podSpec, testSpec, "tls", "logging-spiffe-helper", tt.renew)
+
+			if got := containerNames(podSpec.InitContainers); !reflect.DeepEqual(got, tt.wantInit) {
+				t.Errorf("init containers = %v, want %v", got, tt.wantInit)
+			}
+			if got := containerNames(podSpec.Containers); !reflect.DeepEqual(got, tt.wantContainers) {
+				t.Errorf("containers = %v, want %v", got, tt.wantContainers)
+			}
+			wantTLS := corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}}
+			if !reflect.DeepEqual(podSpec.Volumes[0].VolumeSource, wantTLS) {
+				t.Errorf("TLS volume is not replaced: %+v", podSpec.Volumes[0].VolumeSource)
+			}
+			if len(podSpec.Volumes) != 3 || podSpec.Volumes[1].HostPath == nil || podSpec.Volumes[1].HostPath.Path != "/run/spire/sockets" ||
+				podSpec.Volumes[2].ConfigMap == nil || podSpec.Volumes[2].ConfigMap.Name != "logging-spiffe-helper" {
+				t.Errorf("unexpected volumes: %+v", podSpec.Volumes)
+			}
+			init := podSpec.InitContainers[0]
+			wantArgs := []string{"-config", "/etc/spiffe-helper/helper.conf", "-daemon-mode=false"}
+			if !reflect.DeepEqual(init.Args, wantArgs) || init.Image != "spiffe-helper:0.8.0" {
+				t.Errorf("unexpected init container: %+v", init)
+			}
+		})
+	}
+}
+
+func TestApplyWithoutTLSVolume(t *testing.T) {
+	podSpec := testPodSpec()
+	podSpec.Volumes = nil
+	want := podSpec.DeepCopy()
+	Apply(podSpec, testSpec, "tls", "logging-spiffe-helper", true)
+	RestartOnRenewal(podSpec, testSpec, "tls", "app", "app")
+	if !reflect.DeepEqual(podSpec, want) {
+		t.Errorf("pod without the TLS volume is changed: %+v", podSpec)
+	}
+}
+
+func TestRestartOnRenewal(t *testing.T) {
+	podSpec := testPodSpec()
+	Apply(podSpec, testSpec, "tls", "logging-spiffe-helper", true)
+	RestartOnRenewal(podSpec, testSpec, "tls", "app", "fluent-bit")
+
+	if podSpec.ShareProcessNamespace == nil || !*podSpec.ShareProcessNamespace {
+		t.Error("process namespace is not shared")
+	}
+	want := []string{"app", "spiffe-helper", RestartContainerName}
+	if got := containerNames(podSpec.Containers); !reflect.DeepEqual(got, want) {
+		t.Fatalf("containers = %v, want %v", got, want)
+	}
+	restart := podSpec.Containers[2]
+	if restart.Image != "busybox:1.36" {
+		t.Errorf("unexpected image %q", restart.Image)
+	}
+	if !reflect.DeepEqual(restart.SecurityContext, podSpec.Containers[0].SecurityContext) {
+		t.Errorf("security context %+v differs from the one of the restarted container", restart.SecurityContext)
+	}
+	wantEnv := []corev1.EnvVar{{Name: "CERT_DIR", Value: "/certs"}, {Name: "PROCESS_NAME", Value: "fluent-bit"}}
+	if !reflect.DeepEqual(restart.Env, wantEnv) {
+		t.Errorf("env = %+v, want %+v", restart.Env, wantEnv)
+	}
+	wantMounts := []corev1.VolumeMount{{Name: "tls", MountPath: "/certs", ReadOnly: true}}
+	if !reflect.DeepEqual(restart.VolumeMounts, wantMounts) {
+		t.Errorf("volume mounts = %+v, want %+v", restart.VolumeMounts, wantMounts)
+	}
+}
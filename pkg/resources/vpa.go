@@ -0,0 +1,79 @@
+// Copyright © 2019 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
+)
+
+// VerticalPodAutoscaler returns the VPA of the given workload, it is removed when the VPA is disabled.
+// The object is unstructured as the VPA types are not vendored, a missing VPA CRD is ignored on removal.
+func VerticalPodAutoscaler(meta metav1.ObjectMeta, targetKind, containerName string, spec *v1beta1.VerticalPodAutoscaler) (runtime.Object, reconciler.DesiredState) {
+	vpa := &unstructured.Unstructured{}
+	vpa.SetAPIVersion("autoscaling.k8s.io/v1")
+	vpa.SetKind("VerticalPodAutoscaler")
+	vpa.SetName(meta.Name)
+	vpa.SetNamespace(meta.Namespace)
+	if spec == nil || !spec.Enabled {
+		return vpa, reconciler.StateAbsent
+	}
+	vpa.SetLabels(meta.Labels)
+	vpa.SetOwnerReferences(meta.OwnerReferences)
+
+	updateMode := spec.UpdateMode
+	if updateMode == "" {
+		updateMode = "Auto"
+	}
+	containerPolicy := map[string]interface{}{
+		"containerName": containerName,
+	}
+	if len(spec.MinAllowed) > 0 {
+		containerPolicy["minAllowed"] = resourceListValue(spec.MinAllowed)
+	}
+	if len(spec.MaxAllowed) > 0 {
+		containerPolicy["maxAllowed"] = resourceListValue(spec.MaxAllowed)
+	}
+	if spec.ControlledValues != "" {
+		containerPolicy["controlledValues"] = spec.ControlledValues
+	}
+	vpa.Object["spec"] = map[string]interface{}{
+		"targetRef": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       targetKind,
+			"name":       meta.Name,
+		},
+		"updatePolicy": map[string]interface{}{
+			"updateMode": updateMode,
+		},
+		"resourcePolicy": map[string]interface{}{
+			"containerPolicies": []interface{}{containerPolicy},
+		},
+	}
+	return vpa, reconciler.StatePresent
+}
+
+func resourceListValue(resources corev1.ResourceList) map[string]interface{} {
+	value := make(map[string]interface{}, len(resources))
+	for name, quantity := range resources {
+		value[string(name)] = quantity.String()
+	}
+	return value
+}
@@ -43,6 +43,7 @@ func (t HostTailerConfig) VersionedFluentBitPathArgs(filePath string) []string {
 
 // EventTailerConfig is a configuration type for EventTailer
 type EventTailerConfig struct {
+	Image                 string
 	TailerAffix           string
 	ConfigurationFileName string
 	PositionVolumeName    string
@@ -78,6 +79,7 @@ var HostTailer = HostTailerConfig{
 
 // EventTailer configuration
 var EventTailer = EventTailerConfig{
+	Image:                 "banzaicloud/eventrouter:v0.1.0",
 	TailerAffix:           "event-tailer",
 	ConfigurationFileName: "config.json",
 	PositionVolumeName:    "event-tailer-position",
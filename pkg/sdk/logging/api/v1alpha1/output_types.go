@@ -15,6 +15,7 @@
 package v1alpha1
 
 import (
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -56,6 +57,19 @@ type OutputSpec struct {
 	SyslogOutputConfig           *output.SyslogOutputConfig           `json:"syslog,omitempty"`
 	GELFOutputConfig             *output.GELFOutputConfig             `json:"gelf,omitempty"`
 	SQSOutputConfig              *output.SQSOutputConfig              `json:"sqs,omitempty"`
+	OTLPOutput                   *output.OTLPOutput                   `json:"otlp,omitempty"`
+	LoggingOperatorForward       *output.LoggingOperatorForwardOutput `json:"loggingOperatorForward,omitempty"`
+	NATSOutput                   *output.NATSOutput                   `json:"nats,omitempty"`
+	// Outputs to fall back to, in order, once this output gives up retrying.
+	// Outputs reference Outputs in the same namespace, ClusterOutputs reference ClusterOutputs.
+	Failover []string `json:"failover,omitempty"`
+	// Output to divert the events to that could not be delivered by this output and its failovers.
+	// Referenced the same way as the failover outputs.
+	DeadLetter string `json:"deadLetter,omitempty"`
+	// CA bundle and client certificate of the output from sources other than Secrets
+	TLSFrom *v1beta1.TLSFrom `json:"tlsFrom,omitempty"`
+	// Name of an output template of the logging whose format is used by the output, unless it sets its own format
+	Template string `json:"template,omitempty"`
 }
 
 // OutputStatus defines the observed state of Output
@@ -63,6 +77,10 @@ type OutputStatus struct {
 	Active        *bool    `json:"active,omitempty"`
 	Problems      []string `json:"problems,omitempty"`
 	ProblemsCount int      `json:"problemsCount,omitempty"`
+	// Result of the last output check, see outputCheck of the logging
+	Check *v1beta1.OutputCheckStatus `json:"check,omitempty"`
+	// Output of the failover chain the events are delivered to, see failover
+	ActiveOutput string `json:"activeOutput,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -20,6 +20,7 @@
 package v1alpha1
 
 import (
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/api/v1beta1"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -477,6 +478,31 @@ func (in *OutputSpec) DeepCopyInto(out *OutputSpec) {
 		*out = new(output.SQSOutputConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.OTLPOutput != nil {
+		in, out := &in.OTLPOutput, &out.OTLPOutput
+		*out = new(output.OTLPOutput)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LoggingOperatorForward != nil {
+		in, out := &in.LoggingOperatorForward, &out.LoggingOperatorForward
+		*out = new(output.LoggingOperatorForwardOutput)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NATSOutput != nil {
+		in, out := &in.NATSOutput, &out.NATSOutput
+		*out = new(output.NATSOutput)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLSFrom != nil {
+		in, out := &in.TLSFrom, &out.TLSFrom
+		*out = new(v1beta1.TLSFrom)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputSpec.
@@ -502,6 +528,11 @@ func (in *OutputStatus) DeepCopyInto(out *OutputStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Check != nil {
+		in, out := &in.Check, &out.Check
+		*out = new(v1beta1.OutputCheckStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputStatus.
@@ -54,6 +54,12 @@ type ClusterSelect struct {
 	Labels         map[string]string `json:"labels,omitempty"`
 	Hosts          []string          `json:"hosts,omitempty"`
 	ContainerNames []string          `json:"container_names,omitempty"`
+	// Fields of the record the selected logs have to match, see FieldMatch
+	Fields []FieldMatch `json:"fields,omitempty"`
+	// Label requirements the selected logs have to match, see LabelExpression
+	LabelExpressions []LabelExpression `json:"labelExpressions,omitempty"`
+	// The selected logs have to match any of the terms as well, see MatchTerm
+	AnyOf []MatchTerm `json:"anyOf,omitempty"`
 }
 
 type ClusterExclude struct {
@@ -61,6 +67,12 @@ type ClusterExclude struct {
 	Labels         map[string]string `json:"labels,omitempty"`
 	Hosts          []string          `json:"hosts,omitempty"`
 	ContainerNames []string          `json:"container_names,omitempty"`
+	// Fields of the record the excluded logs have to match, see FieldMatch
+	Fields []FieldMatch `json:"fields,omitempty"`
+	// Label requirements the excluded logs have to match, see LabelExpression
+	LabelExpressions []LabelExpression `json:"labelExpressions,omitempty"`
+	// The excluded logs have to match any of the terms as well, see MatchTerm
+	AnyOf []MatchTerm `json:"anyOf,omitempty"`
 }
 
 // FlowSpec is the Kubernetes spec for Flows
@@ -73,6 +85,13 @@ type ClusterFlowSpec struct {
 	// Deprecated
 	OutputRefs       []string `json:"outputRefs,omitempty"`
 	GlobalOutputRefs []string `json:"globalOutputRefs,omitempty"`
+	// Class of the logs of the flow: critical, normal (default) or best-effort.
+	// The class sets the buffering of the outputs of the flow, see logging.logClasses.
+	// +kubebuilder:validation:Enum=critical;normal;best-effort
+	LogClass string `json:"logClass,omitempty"`
+	// ClusterFlows are rendered and routed after the flows, in descending order of their priority,
+	// ties are ordered by namespace and name
+	Priority int32 `json:"priority,omitempty"`
 }
 
 // +kubebuilder:object:root=true
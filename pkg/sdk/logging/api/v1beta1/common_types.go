@@ -15,6 +15,8 @@
 package v1beta1
 
 import (
+	"errors"
+
 	v1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -34,14 +36,19 @@ const (
 
 // ImageSpec struct hold information about image specification
 type ImageSpec struct {
-	Repository       string                        `json:"repository,omitempty"`
-	Tag              string                        `json:"tag,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	// Digest of the image (e.g. sha256:...), takes precedence over the tag when set
+	Digest           string                        `json:"digest,omitempty"`
 	PullPolicy       string                        `json:"pullPolicy,omitempty"`
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 }
 
 func (s ImageSpec) RepositoryWithTag() string {
 	res := s.Repository
+	if s.Digest != "" {
+		return res + "@" + s.Digest
+	}
 	if s.Tag != "" {
 		res += ":" + s.Tag
 	}
@@ -77,6 +84,84 @@ type Security struct {
 	PodSecurityPolicyCreate      bool                       `json:"podSecurityPolicyCreate,omitempty"`
 	SecurityContext              *corev1.SecurityContext    `json:"securityContext,omitempty"`
 	PodSecurityContext           *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// Render security contexts compliant with the given Pod Security Standard (baseline or restricted).
+	// Explicitly configured fields of the security contexts take precedence.
+	// Not supported for the fluent-bit agents, their hostPath volumes are only allowed by the privileged standard.
+	// +kubebuilder:validation:Enum=baseline;restricted
+	PodSecurityStandard string `json:"podSecurityStandard,omitempty"`
+	// Controls the RBAC resources created for the component:
+	// minimal renders only the rules required by the enabled features,
+	// aggregated labels the cluster roles for ClusterRole aggregation into the <logging>-logging-aggregated ClusterRole,
+	// disabled creates nothing and expects an existing `serviceAccount`.
+	// +kubebuilder:validation:Enum=minimal;aggregated;disabled
+	CreateRBAC string `json:"createRBAC,omitempty"`
+}
+
+const (
+	CreateRBACMinimal    = "minimal"
+	CreateRBACAggregated = "aggregated"
+	CreateRBACDisabled   = "disabled"
+
+	// AggregateRBACLabel is set on the generated roles when createRBAC is aggregated
+	AggregateRBACLabel = "logging.banzaicloud.io/aggregate-rbac"
+)
+
+// ApplyCreateRBAC turns off RBAC resource creation when createRBAC is disabled
+func (s *Security) ApplyCreateRBAC() {
+	if s.CreateRBAC == CreateRBACDisabled {
+		createRBAC := false
+		s.RoleBasedAccessControlCreate = &createRBAC
+	}
+}
+
+// IsMinimalRBAC returns true if only the rules required by the enabled features should be rendered
+func (s *Security) IsMinimalRBAC() bool {
+	return s.CreateRBAC == CreateRBACMinimal
+}
+
+// RBACLabels returns the additional labels of the generated roles
+func (s *Security) RBACLabels() map[string]string {
+	if s.CreateRBAC == CreateRBACAggregated {
+		return map[string]string{AggregateRBACLabel: "true"}
+	}
+	return nil
+}
+
+const (
+	PodSecurityStandardBaseline   = "baseline"
+	PodSecurityStandardRestricted = "restricted"
+)
+
+// ErrPodSecurityStandardHostPath is returned for the components mounting hostPath volumes, which neither the baseline
+// nor the restricted standard allows
+var ErrPodSecurityStandardHostPath = errors.New("podSecurityStandard is not supported for fluent-bit, its hostPath volumes " +
+	"are only allowed by the privileged standard of the namespace")
+
+// ApplyPodSecurityStandard fills the unset fields of the security contexts required by the configured standard
+func (s *Security) ApplyPodSecurityStandard() {
+	if s.PodSecurityStandard == "" {
+		return
+	}
+	if s.SecurityContext == nil {
+		s.SecurityContext = &corev1.SecurityContext{}
+	}
+	if s.PodSecurityContext == nil {
+		s.PodSecurityContext = &corev1.PodSecurityContext{}
+	}
+	if s.PodSecurityContext.SeccompProfile == nil {
+		s.PodSecurityContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+	if s.SecurityContext.AllowPrivilegeEscalation == nil {
+		allowPrivilegeEscalation := false
+		s.SecurityContext.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+	}
+	if s.SecurityContext.Capabilities == nil {
+		s.SecurityContext.Capabilities = &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}}
+	}
+	if s.PodSecurityStandard == PodSecurityStandardRestricted && s.PodSecurityContext.RunAsNonRoot == nil {
+		runAsNonRoot := true
+		s.PodSecurityContext.RunAsNonRoot = &runAsNonRoot
+	}
 }
 
 // ReadinessDefaultCheck Enable default readiness checks
@@ -92,3 +177,21 @@ type ReadinessDefaultCheck struct {
 	SuccessThreshold         int32 `json:"successThreshold,omitempty"`
 	FailureThreshold         int32 `json:"failureThreshold,omitempty"`
 }
+
+// +kubebuilder:object:generate=true
+
+// VerticalPodAutoscaler configures the VerticalPodAutoscaler object created for the workload, the VPA
+// controller has to be installed in the cluster
+type VerticalPodAutoscaler struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// How the recommendations are applied: Off, Initial, Recreate or Auto (default: Auto)
+	// +kubebuilder:validation:Enum=Off;Initial;Recreate;Auto
+	UpdateMode string `json:"updateMode,omitempty"`
+	// Lower bound of the recommended resources of the main container
+	MinAllowed corev1.ResourceList `json:"minAllowed,omitempty"`
+	// Upper bound of the recommended resources of the main container
+	MaxAllowed corev1.ResourceList `json:"maxAllowed,omitempty"`
+	// Whether both the requests and the limits (RequestsAndLimits) or only the requests (RequestsOnly) are scaled
+	// +kubebuilder:validation:Enum=RequestsAndLimits;RequestsOnly
+	ControlledValues string `json:"controlledValues,omitempty"`
+}
@@ -39,6 +39,13 @@ type FlowSpec struct {
 	OutputRefs       []string `json:"outputRefs,omitempty"`
 	GlobalOutputRefs []string `json:"globalOutputRefs,omitempty"`
 	LocalOutputRefs  []string `json:"localOutputRefs,omitempty"`
+	// Class of the logs of the flow: critical, normal (default) or best-effort.
+	// The class sets the buffering of the outputs of the flow, see logging.logClasses.
+	// +kubebuilder:validation:Enum=critical;normal;best-effort
+	LogClass string `json:"logClass,omitempty"`
+	// Flows are rendered and routed in descending order of their priority, ties are ordered by namespace and name.
+	// When a namespace has more flows than its quota allows, the ones with the lowest priority are rejected.
+	Priority int32 `json:"priority,omitempty"`
 }
 
 type Match struct {
@@ -50,14 +57,80 @@ type Select struct {
 	Labels         map[string]string `json:"labels,omitempty"`
 	Hosts          []string          `json:"hosts,omitempty"`
 	ContainerNames []string          `json:"container_names,omitempty"`
+	// Fields of the record the selected logs have to match, see FieldMatch
+	Fields []FieldMatch `json:"fields,omitempty"`
+	// Label requirements the selected logs have to match, see LabelExpression
+	LabelExpressions []LabelExpression `json:"labelExpressions,omitempty"`
+	// The selected logs have to match any of the terms as well, see MatchTerm
+	AnyOf []MatchTerm `json:"anyOf,omitempty"`
 }
 
 type Exclude struct {
 	Labels         map[string]string `json:"labels,omitempty"`
 	Hosts          []string          `json:"hosts,omitempty"`
 	ContainerNames []string          `json:"container_names,omitempty"`
+	// Fields of the record the excluded logs have to match, see FieldMatch
+	Fields []FieldMatch `json:"fields,omitempty"`
+	// Label requirements the excluded logs have to match, see LabelExpression
+	LabelExpressions []LabelExpression `json:"labelExpressions,omitempty"`
+	// The excluded logs have to match any of the terms as well, see MatchTerm
+	AnyOf []MatchTerm `json:"anyOf,omitempty"`
 }
 
+// FieldMatch matches a field of the record as it enters the flow, e.g. one set by the log itself, by the agent or by
+// the globalFilters of the logging. The filters of the flow run after its match statements, a field set by a parser
+// filter of the flow cannot be matched.
+//
+// The router of fluentd only knows about the kubernetes metadata of the logs, it routes them to the flow by the
+// metadata of the selects. Fields, label expressions and terms are checked at the start of the flow, where the match
+// statements are evaluated again in their order: the first statement matching a log decides whether it is selected
+// or excluded.
+type FieldMatch struct {
+	// Key of the field, dots separate nested keys (e.g. json.level), or a record accessor
+	// (e.g. $['resource']['service.name']) for keys containing dots
+	Key string `json:"key"`
+	// How the field is matched: exists (set to a non-empty value), equals or regexp (default: equals)
+	// +kubebuilder:validation:Enum=exists;equals;regexp
+	Operator string `json:"operator,omitempty"`
+	// Value or regular expression the field is matched against
+	Value string `json:"value,omitempty"`
+}
+
+// LabelExpression is a requirement on a kubernetes label of the logs
+type LabelExpression struct {
+	Key string `json:"key"`
+	// In, NotIn, Exists, DoesNotExist, or Regexp matching the label value against the regular expression
+	// in values
+	// +kubebuilder:validation:Enum=In;NotIn;Exists;DoesNotExist;Regexp
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+const (
+	LabelExpressionIn           = "In"
+	LabelExpressionNotIn        = "NotIn"
+	LabelExpressionExists       = "Exists"
+	LabelExpressionDoesNotExist = "DoesNotExist"
+	LabelExpressionRegexp       = "Regexp"
+)
+
+// MatchTerm is a conjunction of conditions, the terms in anyOf are alternatives
+type MatchTerm struct {
+	// Namespaces are ignored in the terms of flows, the logs of a flow are from its own namespace
+	Namespaces       []string          `json:"namespaces,omitempty"`
+	Labels           map[string]string `json:"labels,omitempty"`
+	Hosts            []string          `json:"hosts,omitempty"`
+	ContainerNames   []string          `json:"container_names,omitempty"`
+	Fields           []FieldMatch      `json:"fields,omitempty"`
+	LabelExpressions []LabelExpression `json:"labelExpressions,omitempty"`
+}
+
+const (
+	FieldMatchExists = "exists"
+	FieldMatchEquals = "equals"
+	FieldMatchRegexp = "regexp"
+)
+
 // Filter definition for FlowSpec
 type Filter struct {
 	StdOut              *filter.StdOutFilterConfig        `json:"stdout,omitempty"`
@@ -73,6 +146,8 @@ type Filter struct {
 	Grep                *filter.GrepConfig                `json:"grep,omitempty"`
 	Prometheus          *filter.PrometheusConfig          `json:"prometheus,omitempty"`
 	Throttle            *filter.Throttle                  `json:"throttle,omitempty"`
+	Sample              *filter.Sample                    `json:"sample,omitempty"`
+	Redact              *filter.Redact                    `json:"redact,omitempty"`
 	SumoLogic           *filter.SumoLogic                 `json:"sumologic,omitempty"`
 	EnhanceK8s          *filter.EnhanceK8s                `json:"enhanceK8s,omitempty"`
 	KubeEventsTimestamp *filter.KubeEventsTimestampConfig `json:"kube_events_timestamp,omitempty"`
@@ -83,6 +158,33 @@ type FlowStatus struct {
 	Active        *bool    `json:"active,omitempty"`
 	Problems      []string `json:"problems,omitempty"`
 	ProblemsCount int      `json:"problemsCount,omitempty"`
+	// Result of the last test message sent for the flow
+	TestMessage *TestMessageStatus `json:"testMessage,omitempty"`
+}
+
+// TestMessageAnnotation requests a test message to be sent through the flow or clusterflow. The message is sent once
+// for every new value of the annotation, the result is reported in the status of the flow. The message of a
+// clusterflow is attributed to the first namespace it selects, or to the control namespace.
+// Requires fluentd.testMessages to be enabled in the logging.
+const TestMessageAnnotation = "logging.banzaicloud.io/test-message"
+
+// TestMessageStatus is the result of a test message
+type TestMessageStatus struct {
+	// Value of the annotation the message was sent for
+	ID     string       `json:"id"`
+	SentAt *metav1.Time `json:"sentAt,omitempty"`
+	// Outputs of the flow and whether they received the message
+	Outputs []TestMessageOutputStatus `json:"outputs,omitempty"`
+	Problem string                    `json:"problem,omitempty"`
+}
+
+// TestMessageOutputStatus tells whether an output of the flow received the test message
+type TestMessageOutputStatus struct {
+	// Kind of the output, either Output or ClusterOutput
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Delivered is unset if the delivery cannot be verified because the metrics of fluentd are disabled
+	Delivered *bool `json:"delivered,omitempty"`
 }
 
 // +kubebuilder:object:root=true
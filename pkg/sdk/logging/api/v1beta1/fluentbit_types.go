@@ -15,10 +15,14 @@
 package v1beta1
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
 	"github.com/banzaicloud/operator-tools/pkg/typeoverride"
 	"github.com/banzaicloud/operator-tools/pkg/volume"
 	corev1 "k8s.io/api/core/v1"
@@ -62,13 +66,39 @@ type FluentbitSpec struct {
 	Security      *Security                   `json:"security,omitempty"`
 	// +docLink:"volume.KubernetesVolume,https://github.com/banzaicloud/operator-tools/tree/master/docs/types"
 	PositionDB volume.KubernetesVolume `json:"positiondb,omitempty"`
+	// Check the position databases before fluent-bit starts and set the corrupt ones aside, so that fluent-bit does
+	// not fail to start or lose track of the files after a node failure
+	PositionDBRecovery *PositionDBRecovery `json:"positiondbRecovery,omitempty"`
+	// Export the bytes of the tailed files not read yet according to the position databases
+	PositionDBMetrics *PositionDBMetrics `json:"positiondbMetrics,omitempty"`
+	// Reload the configuration in place when it changes instead of rolling the daemonset
+	ConfigHotReload *FluentbitConfigHotReload `json:"configHotReload,omitempty"`
 	// Deprecated, use positiondb
 	PosisionDBLegacy  *volume.KubernetesVolume `json:"position_db,omitempty"`
 	MountPath         string                   `json:"mountPath,omitempty"`
 	ExtraVolumeMounts []*VolumeMount           `json:"extraVolumeMounts,omitempty"`
-	InputTail         InputTail                `json:"inputTail,omitempty"`
-	FilterAws         *FilterAws               `json:"filterAws,omitempty"`
-	FilterModify      []FilterModify           `json:"filterModify,omitempty"`
+	// Additional containers injected into the fluentbit pods
+	ExtraContainers []corev1.Container `json:"extraContainers,omitempty"`
+	// Additional init containers injected into the fluentbit pods
+	ExtraInitContainers []corev1.Container `json:"extraInitContainers,omitempty"`
+	InputTail           InputTail          `json:"inputTail,omitempty"`
+	// Additional tail inputs for files on the node filesystem, their directories are mounted automatically
+	HostTailers []FluentbitHostTailer `json:"hostTailers,omitempty"`
+	// Collect the host logs from the systemd journal, the journal directories are mounted automatically
+	SystemdInput *InputSystemd  `json:"systemdInput,omitempty"`
+	FilterAws    *FilterAws     `json:"filterAws,omitempty"`
+	FilterModify []FilterModify `json:"filterModify,omitempty"`
+	// Custom multiline parsers rendered into parsers_multiline.conf, reference them by name in inputTail.multiline.parser
+	MultilineParsers []MultilineParser `json:"multilineParsers,omitempty"`
+	// Stream processor tasks rendered into streams.conf, aggregating or filtering the records before they are forwarded
+	StreamTasks []FluentbitStreamTask `json:"streamTasks,omitempty"`
+	// Filters applied to the records of a single input before the other filters, the per-input processors of
+	// fluent-bit expressed in the classic configuration format
+	Processors []FluentbitProcessor `json:"processors,omitempty"`
+	// Outputs fluent-bit ships the matching records to directly, without passing them through the aggregator
+	DirectOutputs []FluentbitDirectOutput `json:"directOutputs,omitempty"`
+	// Lua filters rendered in order after the builtin filters, the scripts are mounted from ConfigMaps
+	CustomLuaScripts []FilterLua `json:"customLuaScripts,omitempty"`
 	// Deprecated, use inputTail.parser
 	Parser string `json:"parser,omitempty"`
 	// Parameters for Kubernetes metadata filter
@@ -90,17 +120,135 @@ type FluentbitSpec struct {
 	DNSPolicy               corev1.DNSPolicy             `json:"dnsPolicy,omitempty"`
 	DNSConfig               *corev1.PodDNSConfig         `json:"dnsConfig,omitempty"`
 	HostNetwork             bool                         `json:"HostNetwork,omitempty"`
+	// Overrides merged into the generated daemonset as the last step
+	DaemonSetOverrides *typeoverride.DaemonSet `json:"daemonSet,omitempty"`
+	// Deploy a second daemonset for windows nodes forwarding to the same aggregator.
+	// When set, the default daemonset is restricted to linux nodes.
+	Windows *FluentbitWindows `json:"windows,omitempty"`
+	// Additional daemonsets for node groups with their own scheduling, resources and buffer limits.
+	// The default daemonset is kept off the nodes selected by the profiles. Prefer selecting the nodes of a profile
+	// by a single label, the exclusion of profiles with several labels multiplies the node selector terms.
+	// Adding the first or removing the last profile changes the selector of the default daemonset, which is
+	// immutable, see enableRecreateWorkloadOnImmutableFieldChange.
+	Profiles []FluentbitAgentProfile `json:"profiles,omitempty"`
+	// Create a VerticalPodAutoscaler for the fluent-bit daemonsets, including the ones of the profiles
+	VPA *VerticalPodAutoscaler `json:"vpa,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
 
+// FluentbitConfigHotReload mounts the configuration as a directory and deploys a sidecar calling the reload endpoint of
+// fluent-bit when it is updated. Requires fluent-bit 2.1 or newer, images with an older version tag are rejected.
+// The endpoint is served on 127.0.0.1:2020. Fluent-bit serves the metrics on the same HTTP server, which would
+// expose the endpoint to the network, so hot reload cannot be combined with metrics.
+// A custom config secret has to enable Hot_Reload and the HTTP server itself.
+type FluentbitConfigHotReload struct {
+	Enabled bool `json:"enabled"`
+	// Image of the reloader sidecar (default: jimmidyson/configmap-reload)
+	Image     ImageSpec                   `json:"image,omitempty"`
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// FluentbitHotReloadMinVersion is the first fluent-bit version serving the reload endpoint
+const FluentbitHotReloadMinVersion = "2.1.0"
+
+// validateFluentbitHotReload rejects hot reload with fluent-bit images older than 2.1, tags not holding a version
+// (e.g. latest or a digest) are accepted, and with the metrics enabled
+func validateFluentbitHotReload(spec *FluentbitSpec) error {
+	if spec.Metrics != nil {
+		return errors.New("fluentbit configHotReload cannot be combined with metrics, the reload endpoint would be " +
+			"served on the metrics port")
+	}
+	minVersion := semver.MustParse(FluentbitHotReloadMinVersion)
+	if v, err := semver.NewVersion(spec.Image.Tag); err == nil &&
+		(v.Major() < minVersion.Major() || v.Major() == minVersion.Major() && v.Minor() < minVersion.Minor()) {
+		return fmt.Errorf("fluentbit configHotReload requires fluent-bit %s or newer, the image is %s",
+			FluentbitHotReloadMinVersion, spec.Image.RepositoryWithTag())
+	}
+	return nil
+}
+
+// +kubebuilder:object:generate=true
+
+// PositionDBRecovery runs an init container checking the SQLite header and the page alignment of the position
+// databases and their write-ahead logs on the positiondb volume. Files of the corrupt databases are renamed with a
+// .corrupt-<timestamp> suffix or removed, fluent-bit creates the database again and the files are read according to
+// the readFromHead setting of the input.
+type PositionDBRecovery struct {
+	Enabled bool `json:"enabled"`
+	// What to do with a corrupt database: rename (default) or reset
+	// +kubebuilder:validation:Enum=rename;reset
+	Action string `json:"action,omitempty"`
+	// Image with a POSIX shell and od running the check (default: busybox)
+	Image ImageSpec `json:"image,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// PositionDBMetrics runs a sidecar reading the position databases on the positiondb volume and comparing the stored
+// offsets with the size of the tracked files. The lag is served on the port in the Prometheus text format as
+// fluentbit_positiondb_lag_bytes and fluentbit_positiondb_files per database. The port is added to the metrics service
+// and service monitor of fluent-bit when the metrics are enabled.
+type PositionDBMetrics struct {
+	Enabled bool `json:"enabled"`
+	// Port of the metrics endpoint (default: 2022)
+	Port int32 `json:"port,omitempty"`
+	// Image with python3 and its sqlite3 module (default: python:3.11-alpine)
+	Image     ImageSpec                   `json:"image,omitempty"`
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+const (
+	PositionDBRecoveryRename = "rename"
+	PositionDBRecoveryReset  = "reset"
+)
+
+// +kubebuilder:object:generate=true
+
 // FluentbitTLS defines the TLS configs
 type FluentbitTLS struct {
+	// Enable TLS towards the aggregator.
+	// When unset, it follows fluentd's forward input TLS settings and reuses its secret and shared key.
 	Enabled    *bool  `json:"enabled"`
 	SecretName string `json:"secretName,omitempty"`
 	SharedKey  string `json:"sharedKey,omitempty"`
 }
 
+// +kubebuilder:object:generate=true
+
+// FluentbitWindows defines the agent running on windows nodes
+type FluentbitWindows struct {
+	Image        ImageSpec                   `json:"image,omitempty"`
+	Resources    corev1.ResourceRequirements `json:"resources,omitempty"`
+	Tolerations  []corev1.Toleration         `json:"tolerations,omitempty"`
+	NodeSelector map[string]string           `json:"nodeSelector,omitempty"`
+	// Path of the container logs on the windows nodes (default: C:\var\log\containers\*.log)
+	Path string `json:"path,omitempty"`
+	// Overrides merged into the generated windows daemonset as the last step
+	DaemonSetOverrides *typeoverride.DaemonSet `json:"daemonSet,omitempty"`
+}
+
+// MaxFluentbitProfileExclusionTerms limits the node selector terms keeping the default fluentbit daemonset off the
+// nodes of the profiles
+const MaxFluentbitProfileExclusionTerms = 16
+
+// +kubebuilder:object:generate=true
+
+// FluentbitAgentProfile overrides the fluentbit settings on a group of nodes
+type FluentbitAgentProfile struct {
+	// Name of the profile, used as a suffix of the generated daemonset
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	Name string `json:"name"`
+	// Nodes of the profile, it must not be empty
+	NodeSelector map[string]string           `json:"nodeSelector"`
+	Tolerations  []corev1.Toleration         `json:"tolerations,omitempty"`
+	Resources    corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Overrides inputTail.Mem_Buf_Limit
+	MemBufLimit string `json:"memBufLimit,omitempty"`
+	// Overrides bufferStorage.storage.backlog.mem_limit
+	StorageBacklogMemLimit string `json:"storageBacklogMemLimit,omitempty"`
+}
+
 // FluentbitNetwork defines network configuration for fluentbit
 type FluentbitNetwork struct {
 	// Sets the timeout for connecting to an upstream (default: 10)
@@ -294,6 +442,114 @@ type FilterModify struct {
 	Conditions []FilterModifyCondition `json:"conditions,omitempty"`
 }
 
+// FluentbitHostTailer defines a tail input for files on the node
+type FluentbitHostTailer struct {
+	// Name of the tailer, used in the default tag and the position database
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	Name string `json:"name"`
+	// Absolute path of the files to tail on the host, wildcards are allowed in the last elements. The directory
+	// is mounted read-only under /host unless it is inside /var/log, it cannot be the root of the host.
+	// +kubebuilder:validation:Pattern=^/\S+$
+	Path string `json:"path"`
+	// Name of the parser applied to the lines
+	Parser string `json:"parser,omitempty"`
+	// Tag of the collected records (default: host.<name>)
+	Tag string `json:"tag,omitempty"`
+	// Multiline parsers applied to the lines, takes precedence over the parser
+	MultilineParser []string `json:"multilineParser,omitempty"`
+}
+
+// InputSystemd defines Fluentbit systemd input configuration
+type InputSystemd struct {
+	// Optional path to the Systemd journal directory, if not set, the plugin will use default paths to read local-only logs.
+	Path string `json:"path,omitempty"`
+	// Allows to perform a query over logs that contains a specific Journald key/value pairs, e.g: _SYSTEMD_UNIT=kubelet.service
+	SystemdFilter []string `json:"systemdFilter,omitempty"`
+	// Define the filter type when Systemd_Filter is specified multiple times. Allowed values are And and Or. (default: Or)
+	SystemdFilterType string `json:"systemdFilterType,omitempty"`
+	// Start reading new entries. Skip entries already stored in Journald. (default: Off)
+	ReadFromTail bool `json:"readFromTail,omitempty"`
+	// Remove the leading underscore of the Journald field (key). (default: Off)
+	StripUnderscores bool `json:"stripUnderscores,omitempty"`
+	// Set the maximum number of entries to read at once (default: 5000)
+	MaxEntries int32 `json:"maxEntries,omitempty"`
+	// Tag of the collected records, host.* avoids the kubernetes filter (default: host.systemd.*)
+	Tag string `json:"tag,omitempty"`
+	// Database file to keep track of the journald cursor (default: /tail-db/systemd.db)
+	DB string `json:"db,omitempty"`
+}
+
+// FluentbitStreamTask defines a task of the fluent-bit stream processor. Streams created WITH a tag are ingested
+// again and forwarded to the aggregator with the tag, e.g.
+// CREATE STREAM errors WITH (tag='agg.errors') AS SELECT COUNT(*) FROM TAG:'kubernetes.*' WINDOW TUMBLING (60 SECOND) WHERE level = 'error';
+// The records of the stream are attributed to the control namespace with the name of the task as the container name
+// and the logging.banzaicloud.io/source: stream label, flows can select them by these.
+type FluentbitStreamTask struct {
+	// Name of the task
+	Name string `json:"name"`
+	// The SQL statement of the task on a single line
+	Exec string `json:"exec"`
+}
+
+// FluentbitProcessor is a filter of the records of one input. It is matched by the tag of the input, so it runs
+// before the kubernetes metadata is added to the container logs.
+type FluentbitProcessor struct {
+	// Input of the records: tail for the container logs, systemd for the systemd input or the name of a host tailer
+	Input string `json:"input"`
+	// Name of the filter plugin, e.g. grep, modify, nest or throttle
+	Name string `json:"name"`
+	// Parameters of the filter in order, a key can be repeated
+	Params []FluentbitParam `json:"params,omitempty"`
+}
+
+// FluentbitParam is a parameter of a fluent-bit plugin
+type FluentbitParam struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// MultilineParser defines a custom multiline parser using regex rules
+type MultilineParser struct {
+	// Name of the parser, used in inputTail.multiline.parser
+	Name string `json:"name"`
+	// Type of the parser, only regex is supported by fluent-bit (default: regex)
+	Type string `json:"type,omitempty"`
+	// Name of a pre-defined parser that must be applied to the incoming content before applying the regex rule
+	Parser string `json:"parser,omitempty"`
+	// For an incoming structured message, specify the key that contains the data that should be processed by the regular expression and possibly concatenated
+	KeyContent string `json:"keyContent,omitempty"`
+	// Timeout in milliseconds to flush a non-terminated multiline buffer (default: 4000)
+	FlushTimeout int32 `json:"flushTimeout,omitempty"`
+	// Rules of the state machine, the first one must have the start_state state name
+	Rules []MultilineParserRule `json:"rules"`
+}
+
+// MultilineParserRule defines a state transition of a multiline parser
+type MultilineParserRule struct {
+	// Name of the state, the first rule must be start_state
+	StateName string `json:"stateName"`
+	// Regular expression matching the line
+	Regex string `json:"regex"`
+	// Name of the state to continue with
+	NextState string `json:"nextState"`
+}
+
+// FilterLua The Lua Filter allows you to modify the incoming records using custom Lua scripts.
+type FilterLua struct {
+	// ConfigMap key holding the Lua script, mounted under /fluent-bit/lua/<configmap>/<key>
+	Script corev1.ConfigMapKeySelector `json:"script"`
+	// Lua function name that will be triggered to do filtering
+	Call string `json:"call"`
+	// Tag pattern of the records to filter (default: *)
+	Match string `json:"match,omitempty"`
+	// If enabled, Fluent-bit will pass the timestamp as a Lua table with keys "sec" for seconds since epoch and "nsec" for nanoseconds.
+	TimeAsTable bool `json:"timeAsTable,omitempty"`
+	// If enabled, Lua script will be executed in protected mode. It prevents to crash when invalid Lua script is executed. (default: true)
+	ProtectedMode *bool `json:"protectedMode,omitempty"`
+	// If these keys are matched, the fields are converted to integer. If more than one key, delimit by space.
+	TypeIntKey string `json:"typeIntKey,omitempty"`
+}
+
 // FilterModifyRule The Modify Filter plugin allows you to change records using rules and conditions.
 type FilterModifyRule struct {
 	// Add a key/value pair with key KEY and value VALUE. If KEY already exists, this field is overwritten
@@ -402,3 +658,89 @@ type ForwardOptions struct {
 	// `storage.total_limit_size` Limit the maximum number of Chunks in the filesystem for the current output logical destination.
 	StorageTotalLimitSize string `json:"storage.total_limit_size,omitempty"`
 }
+
+// +kubebuilder:object:generate=true
+
+// FluentbitDirectOutput ships the records with matching tags from fluent-bit straight to the destination. The records
+// are not forwarded to the aggregator, unless keepForwarding is set. Exactly one destination has to be configured.
+type FluentbitDirectOutput struct {
+	// Name of the output, rendered as the alias of the output plugin
+	Name string `json:"name"`
+	// Tag pattern of the records, e.g. kubernetes.var.log.containers.*_payments_*
+	Match string `json:"match"`
+	// Forward the records to the aggregator as well
+	KeepForwarding bool                          `json:"keepForwarding,omitempty"`
+	Loki           *FluentbitDirectLoki          `json:"loki,omitempty"`
+	Elasticsearch  *FluentbitDirectElasticsearch `json:"elasticsearch,omitempty"`
+	S3             *FluentbitDirectS3            `json:"s3,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// FluentbitDirectLoki configures the loki output plugin of fluent-bit, secrets are read from the control namespace
+type FluentbitDirectLoki struct {
+	Host string `json:"host"`
+	// (default: 3100)
+	Port int `json:"port,omitempty"`
+	// Push endpoint (default: /loki/api/v1/push)
+	URI      string `json:"uri,omitempty"`
+	TenantID string `json:"tenant_id,omitempty"`
+	// Stream labels, e.g. job=fluent-bit, $kubernetes['namespace_name']
+	Labels string `json:"labels,omitempty"`
+	// Record keys turned into labels, e.g. $kubernetes['container_name']
+	LabelKeys string `json:"label_keys,omitempty"`
+	// Record keys removed from the line
+	RemoveKeys string `json:"remove_keys,omitempty"`
+	// Add the kubernetes labels of the pods as stream labels
+	AutoKubernetesLabels *bool `json:"auto_kubernetes_labels,omitempty"`
+	// Format of the line: json or key_value (default: json)
+	LineFormat string         `json:"line_format,omitempty"`
+	HTTPUser   *secret.Secret `json:"http_user,omitempty"`
+	HTTPPasswd *secret.Secret `json:"http_passwd,omitempty"`
+	TLS        *bool          `json:"tls,omitempty"`
+	TLSVerify  *bool          `json:"tls.verify,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// FluentbitDirectElasticsearch configures the es output plugin of fluent-bit, secrets are read from the control namespace
+type FluentbitDirectElasticsearch struct {
+	Host string `json:"host"`
+	// (default: 9200)
+	Port int `json:"port,omitempty"`
+	// (default: fluent-bit)
+	Index          string `json:"index,omitempty"`
+	LogstashFormat *bool  `json:"logstash_format,omitempty"`
+	// (default: logstash)
+	LogstashPrefix string `json:"logstash_prefix,omitempty"`
+	// Required by Elasticsearch 8
+	SuppressTypeName *bool          `json:"suppress_type_name,omitempty"`
+	ReplaceDots      *bool          `json:"replace_dots,omitempty"`
+	HTTPUser         *secret.Secret `json:"http_user,omitempty"`
+	HTTPPasswd       *secret.Secret `json:"http_passwd,omitempty"`
+	TLS              *bool          `json:"tls,omitempty"`
+	TLSVerify        *bool          `json:"tls.verify,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// FluentbitDirectS3 configures the s3 output plugin of fluent-bit. Credentials are taken from the environment, e.g.
+// from envVars or the IAM role of the service account.
+type FluentbitDirectS3 struct {
+	Bucket string `json:"bucket"`
+	Region string `json:"region"`
+	// Custom endpoint of S3 compatible storages
+	Endpoint string `json:"endpoint,omitempty"`
+	// Role assumed to write the objects
+	RoleARN string `json:"role_arn,omitempty"`
+	// Size of the objects, e.g. 50M (default: 100M)
+	TotalFileSize string `json:"total_file_size,omitempty"`
+	// Upload the object after this time even if it is smaller, e.g. 10m (default: 10m)
+	UploadTimeout string `json:"upload_timeout,omitempty"`
+	// Format of the object keys (default: /fluent-bit-logs/$TAG/%Y/%m/%d/%H/%M/%S)
+	S3KeyFormat string `json:"s3_key_format,omitempty"`
+	// gzip or arrow
+	Compression string `json:"compression,omitempty"`
+	// Directory of the buffered objects (default: /tmp/fluent-bit/s3)
+	StoreDir string `json:"store_dir,omitempty"`
+}
@@ -15,7 +15,12 @@
 package v1beta1
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/input"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
 	"github.com/banzaicloud/operator-tools/pkg/typeoverride"
 	"github.com/banzaicloud/operator-tools/pkg/volume"
 	corev1 "k8s.io/api/core/v1"
@@ -46,6 +51,10 @@ type FluentdSpec struct {
 	// +docLink:"volume.KubernetesVolume,https://github.com/banzaicloud/operator-tools/tree/master/docs/types"
 	BufferStorageVolume volume.KubernetesVolume `json:"bufferStorageVolume,omitempty"`
 	ExtraVolumes        []ExtraVolume           `json:"extraVolumes,omitempty"`
+	// Additional containers injected into the fluentd pods
+	ExtraContainers []corev1.Container `json:"extraContainers,omitempty"`
+	// Additional init containers injected into the fluentd pods, started after the ones managed by the operator
+	ExtraInitContainers []corev1.Container `json:"extraInitContainers,omitempty"`
 	// Deprecated, use bufferStorageVolume
 	FluentdPvcSpec            *volume.KubernetesVolume          `json:"fluentdPvcSpec,omitempty"`
 	VolumeMountChmod          bool                              `json:"volumeMountChmod,omitempty"`
@@ -69,8 +78,11 @@ type FluentdSpec struct {
 	BufferVolumeArgs          []string                          `json:"bufferVolumeArgs,omitempty"`
 	Security                  *Security                         `json:"security,omitempty"`
 	Scaling                   *FluentdScaling                   `json:"scaling,omitempty"`
-	Workers                   int32                             `json:"workers,omitempty"`
-	RootDir                   string                            `json:"rootDir,omitempty"`
+	// Number of fluentd workers. With more than one, the metrics of every worker carry a worker_id label and the
+	// metrics path serves the metrics of all workers. Prometheus filters of the flows have to add the
+	// worker_id label themselves with the ${worker_id} placeholder.
+	Workers int32  `json:"workers,omitempty"`
+	RootDir string `json:"rootDir,omitempty"`
 	// +kubebuilder:validation:enum=fatal,error,warn,info,debug,trace
 	LogLevel string `json:"logLevel,omitempty"`
 	// Ignore same log lines
@@ -88,6 +100,162 @@ type FluentdSpec struct {
 	ServiceAccountOverrides *typeoverride.ServiceAccount `json:"serviceAccount,omitempty"`
 	DNSPolicy               corev1.DNSPolicy             `json:"dnsPolicy,omitempty"`
 	DNSConfig               *corev1.PodDNSConfig         `json:"dnsConfig,omitempty"`
+	// Run fluentd in the host network namespace, e.g. to receive traffic on privileged host ports.
+	// dnsPolicy defaults to ClusterFirstWithHostNet in this case.
+	HostNetwork bool `json:"hostNetwork,omitempty"`
+	// Kind of workload running fluentd: statefulset or deployment (default: statefulset)
+	// A deployment has no PVC templates and no buffer draining. A PVC buffer volume is created for each replica
+	// as a generic ephemeral volume and is removed along with the pod, other buffer volumes are mounted as they are.
+	// +kubebuilder:validation:Enum=statefulset;deployment
+	WorkloadType string `json:"workloadType,omitempty"`
+	// How configuration changes are applied (default: reload)
+	// reload and gracefulReload call the RPC endpoint of fluentd from the config-reloader sidecar,
+	// restart rolls the fluentd pods whenever the configuration changes.
+	// +kubebuilder:validation:Enum=reload;gracefulReload;restart
+	ConfigReloadStrategy string `json:"configReloadStrategy,omitempty"`
+	// Overrides merged into the generated statefulset as the last step
+	StatefulSetOverrides *typeoverride.StatefulSet `json:"statefulSet,omitempty"`
+	// Overrides merged into the generated deployment as the last step (workloadType: deployment)
+	DeploymentOverrides *typeoverride.Deployment `json:"deployment,omitempty"`
+	// Split the generated configuration into multiple secrets to stay below the size limit of a single secret
+	AppConfigChunking *AppConfigChunking `json:"appConfigChunking,omitempty"`
+	// Accept test messages of flows over HTTP, see the logging.banzaicloud.io/test-message annotation of flows
+	TestMessages *FluentdTestMessages `json:"testMessages,omitempty"`
+	// Quarantine corrupt buffer chunks before fluentd starts, so that it does not crash on them after a node failure
+	BufferVolumeRepair *BufferVolumeRepair `json:"bufferVolumeRepair,omitempty"`
+	// Flush the buffers through the RPC endpoint of fluentd before the pod is terminated, and wait for the chunks to be
+	// sent until the termination grace period allows
+	FlushOnTerminate bool `json:"flushOnTerminate,omitempty"`
+	// Time given to the pod to terminate gracefully (default: 30, or 120 with flushOnTerminate)
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+	// Lifecycle hooks of the fluentd container, a preStop hook set here replaces the one of flushOnTerminate
+	Lifecycle *corev1.Lifecycle `json:"lifecycle,omitempty"`
+	// Create a VerticalPodAutoscaler for the fluentd statefulset or deployment
+	VPA *VerticalPodAutoscaler `json:"vpa,omitempty"`
+	// Encrypt the buffer chunks at rest on the buffer storage volume
+	BufferVolumeEncryption *BufferVolumeEncryption `json:"bufferVolumeEncryption,omitempty"`
+	// Accept logs pushed over HTTP by serverless functions or external systems, and route them like the logs
+	// collected in the cluster
+	HTTPInput *HTTPInput `json:"httpInput,omitempty"`
+	// Raw configuration inserted before and after the generated configuration of the flows
+	ExtraConfig *FluentdExtraConfig `json:"extraConfig,omitempty"`
+}
+
+const (
+	FluentdWorkloadTypeStatefulSet = "statefulset"
+	FluentdWorkloadTypeDeployment  = "deployment"
+
+	ConfigReloadStrategyReload         = "reload"
+	ConfigReloadStrategyGracefulReload = "gracefulReload"
+	ConfigReloadStrategyRestart        = "restart"
+)
+
+// IsDeployment returns true if fluentd should run as a Deployment instead of a StatefulSet
+func (f *FluentdSpec) IsDeployment() bool {
+	return f.WorkloadType == FluentdWorkloadTypeDeployment
+}
+
+// +kubebuilder:object:generate=true
+
+// AppConfigChunking splits the generated configuration at top level directives into secrets of bounded size.
+// The chunks are mounted into the same directory and included in order, chunks are added or removed as the
+// configuration grows or shrinks.
+type AppConfigChunking struct {
+	Enabled bool `json:"enabled"`
+	// Maximum size of a chunk in bytes, a single directive larger than this gets a chunk of its own (default: 524288)
+	MaxChunkSize int `json:"maxChunkSize,omitempty"`
+}
+
+const DefaultAppConfigMaxChunkSize = 512 * 1024
+
+// +kubebuilder:object:generate=true
+
+// BufferVolumeRepair runs an init container checking the buffer chunks and their metadata. Chunks that cannot be
+// decoded or hold less records than their metadata tells are moved into the quarantine directory of the buffer volume,
+// where they can be inspected. Compressed chunks are checked by their metadata only.
+type BufferVolumeRepair struct {
+	Enabled bool `json:"enabled"`
+}
+
+// +kubebuilder:object:generate=true
+
+// BufferVolumeEncryption keeps the buffer chunks encrypted on the buffer volume. A privileged sidecar mounts a gocryptfs
+// view of the encrypted directory of the volume for fluentd, the plaintext chunks never reach the volume.
+// Chunks written before the encryption was enabled are kept in plaintext and are not read anymore,
+// drain the buffers before enabling it. The buffer volume repair is skipped as the chunks cannot be read on the volume.
+type BufferVolumeEncryption struct {
+	Enabled bool `json:"enabled"`
+	// Secret in the control namespace holding the passphrase the encryption key is derived from
+	KeySecret corev1.SecretKeySelector `json:"keySecret"`
+	// Image providing sh, gocryptfs, fusermount and pgrep
+	Image     ImageSpec                   `json:"image"`
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// FluentdTestMessages enables an HTTP input the operator sends the test messages of flows and clusterflows to.
+// The operator authenticates with a bearer token it generates into the <logging>-fluentd-test-messages secret of
+// the control namespace, records without the token are dropped.
+// When the network policy of fluentd is enabled, the operator has to be allowed through its ingressCIDRs.
+type FluentdTestMessages struct {
+	Enabled bool `json:"enabled"`
+	// Port of the HTTP input (default: 9880)
+	Port int32 `json:"port,omitempty"`
+}
+
+const DefaultFluentdTestMessagesPort = 9880
+
+// +kubebuilder:object:generate=true
+
+// HTTPInput exposes an HTTP endpoint of fluentd accepting JSON records, e.g. a POST request to /<tag> with a JSON
+// object or array as its body. Records of requests without an "Authorization: Bearer <token>" header carrying the
+// token are dropped. The records are attributed to the namespace and labels set here and are routed by the flows
+// selecting them, global filters are not applied. The other request headers are kept in HTTP_<HEADER> fields.
+// When the network policy of fluentd is enabled, the port is opened for every source.
+type HTTPInput struct {
+	Enabled bool `json:"enabled"`
+	// Port of the HTTP input (default: 9881)
+	Port int32 `json:"port,omitempty"`
+	// Bearer token of the clients, a valueFrom secret is loaded from the control namespace
+	Token *secret.Secret `json:"token"`
+	// Namespace the records are attributed to, set as kubernetes.namespace_name
+	Namespace string `json:"namespace,omitempty"`
+	// Labels the records are attributed to, set as kubernetes.labels
+	Labels map[string]string `json:"labels,omitempty"`
+	// Maximum size of a request body, e.g. 16m (default: 32m)
+	BodySizeLimit string `json:"bodySizeLimit,omitempty"`
+	// Type of the service of the input (default: ClusterIP)
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+	// Expose the service of the input through an Ingress
+	Ingress *HTTPInputIngress `json:"ingress,omitempty"`
+}
+
+const DefaultFluentdHTTPInputPort = 9881
+
+// +kubebuilder:object:generate=true
+
+// FluentdExtraConfig holds raw fluentd configuration for features not covered by the API yet. The fragments are
+// part of the configuration checked before it is applied, so invalid fragments keep the previous configuration
+// running. They are not used together with flowConfigOverride.
+type FluentdExtraConfig struct {
+	// Inserted before the generated configuration, e.g. additional sources
+	Prepend string `json:"prepend,omitempty"`
+	// Inserted after the generated configuration, e.g. additional labels
+	Append string `json:"append,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// HTTPInputIngress defines the Ingress of the HTTP input
+type HTTPInputIngress struct {
+	ClassName   string            `json:"className,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Host        string            `json:"host"`
+	// Path prefix routed to the input, it becomes the prefix of the tag of the records (default: /)
+	Path string `json:"path,omitempty"`
+	// Secret holding the certificate of the host, the Ingress terminates TLS when it is set
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
@@ -133,6 +301,81 @@ type FluentdTLS struct {
 	Enabled    bool   `json:"enabled"`
 	SecretName string `json:"secretName,omitempty"`
 	SharedKey  string `json:"sharedKey,omitempty"`
+	// Let the operator maintain a self-signed CA and issue the server certificate for fluentd
+	// and the client certificate for fluentbit. Certificates are rotated before they expire.
+	// Only one of autoGenerate, certManager and spiffe can be set.
+	AutoGenerate bool `json:"autoGenerate,omitempty"`
+	// Let cert-manager issue the server certificate for fluentd and the client certificate for fluentbit
+	CertManager *CertManagerTLS `json:"certManager,omitempty"`
+	// Use the X.509 SVIDs of the pods, fetched from the SPIFFE Workload API of the SPIRE agents on the nodes,
+	// as the server certificate of fluentd and the client certificates of the agents
+	Spiffe *SpiffeTLS `json:"spiffe,omitempty"`
+	// Names of the clusters forwarding their logs to this aggregator with the loggingOperatorForward output, requires
+	// autoGenerate. The operator issues a client certificate for each peer into the <logging>-fluentd-peer-<peer>
+	// secret, to be copied to the peer cluster. The certificates are signed by a CA of the peers, which is replaced
+	// when a peer is removed: the secret of the removed peer is deleted, the others have to be copied again.
+	ForwardPeers []string `json:"forwardPeers,omitempty"`
+}
+
+// IsManaged returns true if the TLS certificates are provisioned by the operator, cert-manager or SPIRE
+func (t FluentdTLS) IsManaged() bool {
+	return t.AutoGenerate || t.CertManager != nil || t.Spiffe != nil
+}
+
+// validateProvider rejects the TLS config if more than one of autoGenerate, certManager and spiffe is set,
+// they would provision the same certificates, or if forward peers are listed without autoGenerate
+func (t FluentdTLS) validateProvider() error {
+	var providers []string
+	if t.AutoGenerate {
+		providers = append(providers, "autoGenerate")
+	}
+	if t.CertManager != nil {
+		providers = append(providers, "certManager")
+	}
+	if t.Spiffe != nil {
+		providers = append(providers, "spiffe")
+	}
+	if len(providers) > 1 {
+		return fmt.Errorf("fluentd tls %s are mutually exclusive", strings.Join(providers, ", "))
+	}
+	if len(t.ForwardPeers) > 0 && !t.AutoGenerate {
+		return errors.New("fluentd tls forwardPeers requires autoGenerate, the peer certificates are issued by the operator")
+	}
+	return nil
+}
+
+// +kubebuilder:object:generate=true
+
+// SpiffeTLS configures the spiffe-helper containers writing the SVID, its key and the trust bundle into the TLS
+// directory of fluentd and of the agents. An init container fetches the first SVID, a sidecar renews it.
+// Fluentd reloads its configuration on renewal. Fluent-bit reads the certificates only when it starts, so the agent
+// pods share their process namespace with a sidecar stopping fluent-bit on renewal, to be restarted by the kubelet.
+type SpiffeTLS struct {
+	// Directory of the SPIRE agent socket on the nodes (default: /run/spire/sockets)
+	SocketDir string `json:"socketDir,omitempty"`
+	// File name of the SPIRE agent socket (default: agent.sock)
+	SocketName string                      `json:"socketName,omitempty"`
+	Image      ImageSpec                   `json:"image,omitempty"`
+	Resources  corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Image of the sidecar restarting fluent-bit on renewal, it needs a shell, md5sum and pkill (default: busybox:1.36)
+	RestartImage ImageSpec `json:"restartImage,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// CertManagerTLS defines how to request certificates from cert-manager
+type CertManagerTLS struct {
+	IssuerRef CertManagerIssuerRef `json:"issuerRef"`
+}
+
+// +kubebuilder:object:generate=true
+
+// CertManagerIssuerRef references the cert-manager issuer to request certificates from
+type CertManagerIssuerRef struct {
+	Name string `json:"name"`
+	// Kind of the issuer (default: Issuer)
+	Kind  string `json:"kind,omitempty"`
+	Group string `json:"group,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
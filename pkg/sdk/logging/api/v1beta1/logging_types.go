@@ -17,14 +17,21 @@ package v1beta1
 import (
 	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	"github.com/banzaicloud/operator-tools/pkg/typeoverride"
+	"github.com/banzaicloud/operator-tools/pkg/types"
 	util "github.com/banzaicloud/operator-tools/pkg/utils"
 	"github.com/banzaicloud/operator-tools/pkg/volume"
 	"github.com/spf13/cast"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/extensions/extensionsconfig"
 )
 
 // +name:"LoggingSpec"
@@ -45,6 +52,8 @@ type LoggingSpec struct {
 	LoggingRef string `json:"loggingRef,omitempty"`
 	// Disable configuration check before applying new fluentd configuration.
 	FlowConfigCheckDisabled bool `json:"flowConfigCheckDisabled,omitempty"`
+	// Probe the endpoints of the outputs before applying new fluentd configuration.
+	OutputCheck *OutputCheck `json:"outputCheck,omitempty"`
 	// Skip Invalid Resources
 	SkipInvalidResources bool `json:"skipInvalidResources,omitempty"`
 	// Override generated config. This is a *raw* configuration string for troubleshooting purposes.
@@ -59,14 +68,28 @@ type LoggingSpec struct {
 	ErrorOutputRef string `json:"errorOutputRef,omitempty"`
 	// Global filters to apply on logs before any match or filter mechanism.
 	GlobalFilters []Filter `json:"globalFilters,omitempty"`
+	// Default settings merged into every rendered output, unless overridden in the output itself.
+	GlobalOutputSettings *GlobalOutputSettings `json:"globalOutputSettings,omitempty"`
+	// Namespaces holding shared credentials that ClusterOutputs may reference secrets and TLS source ConfigMaps from,
+	// using the <namespace>/<name> form as the name of the reference. Outputs can only reference their own namespace.
+	SecretNamespaces []string `json:"secretNamespaces,omitempty"`
 	// Limit namespaces to watch Flow and Output custom resources.
 	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+	// Namespaces excluded from watching and from the logs matched by ClusterFlows and the default flow, e.g. kube-system.
+	// Takes precedence over watchNamespaces and watchNamespaceSelector.
+	ExcludeWatchNamespaces []string `json:"excludeWatchNamespaces,omitempty"`
+	// WatchNamespaceSelector is a LabelSelector to find matching namespaces to watch as in WatchNamespaces.
+	// Namespaces selected are added to the ones listed in WatchNamespaces, configuration is re-rendered as they come and go.
+	WatchNamespaceSelector *metav1.LabelSelector `json:"watchNamespaceSelector,omitempty"`
 	// Namespace for cluster wide configuration resources like CLusterFlow and ClusterOutput.
 	// This should be a protected namespace from regular users.
 	// Resources like fluentbit and fluentd will run in this namespace as well.
 	ControlNamespace string `json:"controlNamespace"`
 	// Allow configuration of cluster resources from any namespace. Mutually exclusive with ControlNamespace restriction of Cluster resources
 	AllowClusterResourcesFromAllNamespaces bool `json:"allowClusterResourcesFromAllNamespaces,omitempty"`
+	// Namespaces ClusterFlows and ClusterOutputs are accepted from besides the ControlNamespace.
+	// Cluster resources created elsewhere are ignored and reported in their status.
+	AllowClusterResourcesFromNamespaces []string `json:"allowClusterResourcesFromNamespaces,omitempty"`
 	// NodeAgent Configuration
 	NodeAgents []*NodeAgent `json:"nodeAgents,omitempty"`
 	// EnableRecreateWorkloadOnImmutableFieldChange enables the operator to recreate the
@@ -74,13 +97,128 @@ type LoggingSpec struct {
 	// in case there is a change in an immutable field
 	// that otherwise couldn't be managed with a simple update.
 	EnableRecreateWorkloadOnImmutableFieldChange bool `json:"enableRecreateWorkloadOnImmutableFieldChange,omitempty"`
+	// EnableServerSideApply makes the operator apply the resources of the logging with server-side apply,
+	// owning only the fields it sets instead of updating the whole objects. The fields it sets are forced, leave
+	// the replicas unset for workloads scaled by an autoscaler.
+	EnableServerSideApply bool `json:"enableServerSideApply,omitempty"`
+	// DryRun renders the configuration and the resources of the logging without applying them.
+	// The rendered configuration and the changes that would be applied are written into the <logging>-dry-run Secret,
+	// as the configuration holds the output credentials.
+	DryRun bool `json:"dryRun,omitempty"`
+	// Suspend stops the reconciliation of the logging: no objects are updated and no configcheck pods or drain jobs are started.
+	// Reconciliation resumes once it is unset.
+	Suspend bool `json:"suspend,omitempty"`
+	// RequireImageDigests refuses to render the logging unless every image it deploys is pinned by digest, including
+	// the images of the extra containers, of the workload overrides and of the event tailer. The node agents have to
+	// set their image. The signatures of the images are not checked, enforce them with an admission policy.
+	RequireImageDigests bool `json:"requireImageDigests,omitempty"`
+	// NetworkPolicy restricting the traffic of the aggregator
+	NetworkPolicy *NetworkPolicy `json:"networkPolicy,omitempty"`
+	// Service mesh (Istio) integration of the fluentd and fluentbit pods
+	ServiceMesh *ServiceMesh `json:"serviceMesh,omitempty"`
+	// Deploy a Kubernetes event watcher printing the events as structured logs.
+	// Flows can select the events using the logging.banzaicloud.io/events label.
+	EventTailer *EventTailer `json:"eventTailer,omitempty"`
+	// Receive the audit events of the kube-apiserver through its audit webhook backend.
+	// Flows can select the events using the logging.banzaicloud.io/source: audit label.
+	AuditLog *AuditLog `json:"auditLog,omitempty"`
+	// Monitoring resources generated for the logging
+	Monitoring *Monitoring `json:"monitoring,omitempty"`
+	// Buffering of the log classes flows can be marked with
+	LogClasses *LogClasses `json:"logClasses,omitempty"`
+	// Constrain the TLS versions, ciphers and curves of fluentd: modern (TLS 1.3 only), intermediate (TLS 1.2 with
+	// forward secret AEAD ciphers) or fips (TLS 1.2 with FIPS 140-2 approved ciphers and NIST curves).
+	// Unset TLS settings of the forward input and the outputs default to the profile, outputs requesting weaker settings
+	// or disabling the certificate verification are rejected by the modern and fips profiles. The outputs without such
+	// settings, and the curves of all connections, are constrained by the OpenSSL configuration of fluentd.
+	// +kubebuilder:validation:Enum=modern;intermediate;fips
+	TLSProfile string `json:"tlsProfile,omitempty"`
+	// Message formats shared by the outputs, referenced by name with the template field of the Outputs and ClusterOutputs
+	OutputTemplates []OutputTemplate `json:"outputTemplates,omitempty"`
+	// Keep the last applied fluentd configurations in Secrets of the control namespace
+	ConfigHistory *ConfigHistory `json:"configHistory,omitempty"`
+	// Apply the fluentd configuration with the given hash (or hash prefix) from the config history, along with the
+	// output secrets mounted for it, instead of the rendered one, until it is unset
+	RollbackTo string `json:"rollbackTo,omitempty"`
+	// Roll back to the previous fluentd configuration if the output errors spike after a new one is applied
+	AutoRollback *AutoRollback `json:"autoRollback,omitempty"`
+	// Name of the LoggingProfile whose presets are merged under the fluentd, fluentbit and globalOutputSettings
+	// of this spec, the values set here take precedence
+	Profile string `json:"profile,omitempty"`
 }
 
 // LoggingStatus defines the observed state of Logging
 type LoggingStatus struct {
 	ConfigCheckResults map[string]bool `json:"configCheckResults,omitempty"`
+	// Results of the output checks by the hash of the probed endpoints
+	OutputCheckResults map[string]bool `json:"outputCheckResults,omitempty"`
+	// Conditions of the logging, e.g. Suspended
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Hash of the fluentd configuration last applied, see configHistory
+	AppliedConfigHash string `json:"appliedConfigHash,omitempty"`
+	// Configuration rolled back by autoRollback
+	AutoRollback *AutoRollbackStatus `json:"autoRollback,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// AutoRollback watches the errors and retries of the fluentd outputs for a while after a new configuration is
+// applied, and applies the previous configuration of the config history if they exceed the threshold.
+// The fluentd metrics and the config history have to be enabled.
+type AutoRollback struct {
+	// How long the outputs are watched after a configuration is applied (default: 300)
+	WindowSeconds int `json:"windowSeconds,omitempty"`
+	// Number of output errors and retries of all the fluentd pods within the window triggering the rollback (default: 100)
+	ErrorThreshold int `json:"errorThreshold,omitempty"`
 }
 
+// AutoRollbackStatus records a rollback performed by autoRollback
+type AutoRollbackStatus struct {
+	// Hash of the rolled back configuration, it is not applied again until the rendered configuration changes
+	FromConfigHash string `json:"fromConfigHash"`
+	// Hash of the configuration applied instead
+	ToConfigHash string `json:"toConfigHash"`
+}
+
+const (
+	DefaultAutoRollbackWindowSeconds  = 300
+	DefaultAutoRollbackErrorThreshold = 100
+)
+
+// +kubebuilder:object:generate=true
+
+// ConfigHistory keeps the applied fluentd configurations. Every configuration is stored in a Secret, as it holds the
+// output credentials, labeled with logging.banzaicloud.io/config-history, annotated with its hash, the time it was applied and
+// a summary of the changes to the previous one, along with the versions of the resources it was rendered from and the
+// output secrets mounted for it, which are restored with the configuration on a rollback.
+type ConfigHistory struct {
+	// Number of configurations kept (default: 10)
+	Limit int `json:"limit,omitempty"`
+}
+
+const (
+	// ConfigHistoryLabel selects the config history Secrets of a logging, its value is the name of the logging
+	ConfigHistoryLabel = "logging.banzaicloud.io/config-history"
+	// DefaultConfigHistoryLimit is the number of configurations kept by default
+	DefaultConfigHistoryLimit = 10
+)
+
+const (
+	// LoggingConditionSuspended is true while the reconciliation of the logging is suspended
+	LoggingConditionSuspended = "Suspended"
+	// LoggingConditionRollbackPerformed is true while autoRollback keeps a rolled back configuration out
+	LoggingConditionRollbackPerformed = "RollbackPerformed"
+	// LoggingConditionTopology describes the effective topology of the logging in its reason and message
+	LoggingConditionTopology = "Topology"
+)
+
+// TLS profiles of tlsProfile
+const (
+	TLSProfileModern       = "modern"
+	TLSProfileIntermediate = "intermediate"
+	TLSProfileFIPS         = "fips"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=loggings,scope=Cluster,categories=logging-all
@@ -106,6 +244,147 @@ type LoggingList struct {
 
 // +kubebuilder:object:generate=true
 
+// OutputCheck probes the endpoints of the outputs from a short-lived pod, similar to the configcheck, whenever they change.
+// TCP based outputs are connected to, HTTP based outputs get a request with their credentials and the metadata of the
+// Kafka brokers is fetched. The results are recorded in the status of the outputs. Certificates are not verified.
+type OutputCheck struct {
+	Enabled bool `json:"enabled"`
+	// Timeout of a single probe in seconds (default: 10)
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// Hold back the new configuration while any of the outputs fails the check
+	Blocking bool `json:"blocking,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// NetworkPolicy defines the network policy generated for the aggregator
+type NetworkPolicy struct {
+	// Create a NetworkPolicy that allows forward traffic to fluentd only from the fluentbit pods of the logging
+	Enabled bool `json:"enabled,omitempty"`
+	// Additional CIDRs (e.g. external log sources) allowed to reach the fluentd input
+	IngressCIDRs []string `json:"ingressCIDRs,omitempty"`
+	// Egress rules towards the output endpoints. Egress is not restricted unless at least one rule is set.
+	// DNS traffic is always allowed.
+	Egress []networkingv1.NetworkPolicyEgressRule `json:"egress,omitempty"`
+}
+
+// EventsLabel is set on the event watcher pods of the logging with the name of the logging
+const EventsLabel = "logging.banzaicloud.io/events"
+
+// +kubebuilder:object:generate=true
+
+// EventTailer defines the event watcher managed by the logging
+type EventTailer struct {
+	// Volume definition for tracking fluentbit file positions (optional)
+	PositionVolume volume.KubernetesVolume `json:"positionVolume,omitempty"`
+	// Override metadata of the created resources
+	WorkloadMetaBase *types.MetaBase `json:"workloadMetaOverrides,omitempty"`
+	// Override podSpec fields for the given statefulset
+	WorkloadBase *types.PodSpecBase `json:"workloadOverrides,omitempty"`
+	// Override container fields for the given statefulset
+	ContainerBase *types.ContainerBase `json:"containerOverrides,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// Monitoring defines the monitoring resources generated for the logging
+type Monitoring struct {
+	Dashboards Dashboards `json:"dashboards,omitempty"`
+}
+
+// Dashboards defines the Grafana dashboard ConfigMaps of the logging
+type Dashboards struct {
+	// Render the dashboards of the aggregator into ConfigMaps picked up by the Grafana sidecar
+	Enabled bool `json:"enabled,omitempty"`
+	// Namespace of the ConfigMaps (default: controlNamespace)
+	Namespace string `json:"namespace,omitempty"`
+	// Labels of the ConfigMaps (default: grafana_dashboard: "1")
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// AuditLog defines the webhook receiver of the kube-apiserver audit events
+type AuditLog struct {
+	Image     ImageSpec               `json:"image,omitempty"`
+	Replicas  *int32                  `json:"replicas,omitempty"`
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// HTTPS port of the receiver (default: 9880)
+	Port int32 `json:"port,omitempty"`
+	// Tag of the audit events (default: audit)
+	Tag string `json:"tag,omitempty"`
+	// Token of the kube-apiserver, letters and digits only. The webhook kubeconfig has to post the events to the
+	// https://<service>/<token> URL, the events posted to other paths are dropped. A valueFrom secret is loaded from
+	// the control namespace.
+	Token *secret.Secret `json:"token"`
+	// Secret holding the serving certificate (tls.crt, tls.key and ca.crt), generated by the operator by default.
+	// The kube-apiserver webhook kubeconfig has to trust its ca.crt.
+	SecretName string `json:"secretName,omitempty"`
+	// Generate the serving certificate into the secret
+	AutoGenerate *bool `json:"autoGenerate,omitempty"`
+}
+
+// SourceLabel is set on records not coming from containers, e.g. the audit events, to let flows select them
+const SourceLabel = "logging.banzaicloud.io/source"
+
+// Annotations of namespaces limiting what the namespace can register in the loggings. The Flows and Outputs of a
+// namespace with a value that is not a non-negative integer are left out of the configuration until it is fixed.
+const (
+	// MaxFlowsAnnotation limits the number of Flows of the namespace, the ones beyond the limit by name are not accepted
+	MaxFlowsAnnotation = "logging.banzaicloud.io/max-flows"
+	// MaxOutputsAnnotation limits the number of Outputs of the namespace, the ones beyond the limit by name are not accepted
+	MaxOutputsAnnotation = "logging.banzaicloud.io/max-outputs"
+	// ThrottleRateAnnotation caps the messages per second each Flow of the namespace processes
+	ThrottleRateAnnotation = "logging.banzaicloud.io/throttle-rate"
+)
+
+// +kubebuilder:object:generate=true
+
+// ServiceMesh defines how the logging pods participate in a service mesh
+type ServiceMesh struct {
+	// Inject the sidecar proxy into the fluentd and fluentbit pods
+	Enabled bool `json:"enabled,omitempty"`
+	// Rely on the mutual TLS of the mesh instead of the TLS settings of fluentd and fluentbit
+	DisableTLS bool `json:"disableTLS,omitempty"`
+}
+
+// IsEnabled returns true if the logging pods are part of the mesh
+func (s *ServiceMesh) IsEnabled() bool {
+	return s != nil && s.Enabled
+}
+
+// PodAnnotations returns the annotations for long running pods in the mesh.
+// The given ports are excluded from inbound interception and the application waits for the proxy to start.
+func (s *ServiceMesh) PodAnnotations(excludeInboundPorts ...int32) map[string]string {
+	if !s.IsEnabled() {
+		return nil
+	}
+	annotations := map[string]string{
+		"sidecar.istio.io/inject": "true",
+		"proxy.istio.io/config":   `{"holdApplicationUntilProxyStarts": true}`,
+	}
+	if len(excludeInboundPorts) > 0 {
+		ports := make([]string, 0, len(excludeInboundPorts))
+		for _, p := range excludeInboundPorts {
+			ports = append(ports, fmt.Sprint(p))
+		}
+		annotations["traffic.sidecar.istio.io/excludeInboundPorts"] = strings.Join(ports, ",")
+	}
+	return annotations
+}
+
+// JobAnnotations returns the annotations for pods that run to completion, these are kept out of the mesh
+func (s *ServiceMesh) JobAnnotations() map[string]string {
+	if !s.IsEnabled() {
+		return nil
+	}
+	return map[string]string{
+		"sidecar.istio.io/inject": "false",
+	}
+}
+
+// +kubebuilder:object:generate=true
+
 // DefaultFlowSpec is a Flow for logs that did not match any other Flow
 type DefaultFlowSpec struct {
 	Filters []Filter `json:"filters,omitempty"`
@@ -126,10 +405,24 @@ const (
 	DefaultFluentdDrainPauseImageTag            = "latest"
 	DefaultFluentdVolumeModeImageRepository     = "busybox"
 	DefaultFluentdVolumeModeImageTag            = "latest"
+	DefaultPositionDBMetricsImageRepository     = "python"
+	DefaultPositionDBMetricsImageTag            = "3.11-alpine"
+	DefaultPositionDBMetricsPort                = 2022
 	DefaultFluentdConfigReloaderImageRepository = "jimmidyson/configmap-reload"
 	DefaultFluentdConfigReloaderImageTag        = "v0.4.0"
 	DefaultFluentdBufferVolumeImageRepository   = "ghcr.io/banzaicloud/custom-runner"
 	DefaultFluentdBufferVolumeImageTag          = "0.1.0"
+	DefaultFluentdTLSSecretName                 = "fluentd-tls"
+	DefaultFluentbitTLSSecretName               = "fluentbit-tls"
+	DefaultAuditLogTLSSecretName                = "audit-tls"
+	DefaultOutputCheckTimeoutSeconds            = 10
+	DefaultFlushTerminationGracePeriod          = 120
+	DefaultSpiffeHelperImageRepository          = "ghcr.io/spiffe/spiffe-helper"
+	DefaultSpiffeHelperImageTag                 = "0.8.0"
+	DefaultSpiffeRestartImageRepository         = "busybox"
+	DefaultSpiffeRestartImageTag                = "1.36"
+	DefaultSpiffeSocketDir                      = "/run/spire/sockets"
+	DefaultSpiffeSocketName                     = "agent.sock"
 )
 
 // SetDefaults fills empty attributes
@@ -137,6 +430,14 @@ func (l *Logging) SetDefaults() error {
 	if !l.Spec.FlowConfigCheckDisabled && l.Status.ConfigCheckResults == nil {
 		l.Status.ConfigCheckResults = make(map[string]bool)
 	}
+	if l.Spec.OutputCheck != nil && l.Spec.OutputCheck.Enabled {
+		if l.Status.OutputCheckResults == nil {
+			l.Status.OutputCheckResults = make(map[string]bool)
+		}
+		if l.Spec.OutputCheck.TimeoutSeconds == 0 {
+			l.Spec.OutputCheck.TimeoutSeconds = DefaultOutputCheckTimeoutSeconds
+		}
+	}
 	if l.Spec.FluentdSpec != nil { // nolint:nestif
 		if l.Spec.FluentdSpec.FluentdPvcSpec != nil {
 			return errors.New("`fluentdPvcSpec` field is deprecated, use: `bufferStorageVolume`")
@@ -156,6 +457,7 @@ func (l *Logging) SetDefaults() error {
 		if l.Spec.FluentdSpec.Security == nil {
 			l.Spec.FluentdSpec.Security = &Security{}
 		}
+		l.Spec.FluentdSpec.Security.ApplyCreateRBAC()
 		if l.Spec.FluentdSpec.Security.RoleBasedAccessControlCreate == nil {
 			l.Spec.FluentdSpec.Security.RoleBasedAccessControlCreate = util.BoolPointer(true)
 		}
@@ -165,9 +467,19 @@ func (l *Logging) SetDefaults() error {
 		if l.Spec.FluentdSpec.Security.PodSecurityContext == nil {
 			l.Spec.FluentdSpec.Security.PodSecurityContext = &v1.PodSecurityContext{}
 		}
+		l.Spec.FluentdSpec.Security.ApplyPodSecurityStandard()
 		if l.Spec.FluentdSpec.Security.PodSecurityContext.FSGroup == nil {
 			l.Spec.FluentdSpec.Security.PodSecurityContext.FSGroup = util.IntPointer64(101)
 		}
+		if l.Spec.FluentdSpec.FlushOnTerminate && l.Spec.FluentdSpec.TerminationGracePeriodSeconds == nil {
+			l.Spec.FluentdSpec.TerminationGracePeriodSeconds = util.IntPointer64(DefaultFlushTerminationGracePeriod)
+		}
+		if l.Spec.FluentdSpec.TestMessages != nil && l.Spec.FluentdSpec.TestMessages.Port == 0 {
+			l.Spec.FluentdSpec.TestMessages.Port = DefaultFluentdTestMessagesPort
+		}
+		if l.Spec.FluentdSpec.HTTPInput != nil && l.Spec.FluentdSpec.HTTPInput.Port == 0 {
+			l.Spec.FluentdSpec.HTTPInput.Port = DefaultFluentdHTTPInputPort
+		}
 		if l.Spec.FluentdSpec.Metrics != nil {
 			if l.Spec.FluentdSpec.Metrics.Path == "" {
 				l.Spec.FluentdSpec.Metrics.Path = "/metrics"
@@ -255,6 +567,9 @@ func (l *Logging) SetDefaults() error {
 		if l.Spec.FluentdSpec.Port == 0 {
 			l.Spec.FluentdSpec.Port = 24240
 		}
+		if l.Spec.FluentdSpec.HostNetwork && l.Spec.FluentdSpec.DNSPolicy == "" {
+			l.Spec.FluentdSpec.DNSPolicy = v1.DNSClusterFirstWithHostNet
+		}
 		if l.Spec.FluentdSpec.Scaling == nil {
 			l.Spec.FluentdSpec.Scaling = new(FluentdScaling)
 		}
@@ -351,6 +666,50 @@ func (l *Logging) SetDefaults() error {
 				e.Volume = &volume.KubernetesVolume{}
 			}
 		}
+		if err := l.Spec.FluentdSpec.TLS.validateProvider(); err != nil {
+			return err
+		}
+		if l.Spec.FluentdSpec.TLS.IsManaged() {
+			l.Spec.FluentdSpec.TLS.Enabled = true
+			if l.Spec.FluentdSpec.TLS.SecretName == "" {
+				l.Spec.FluentdSpec.TLS.SecretName = l.QualifiedName(DefaultFluentdTLSSecretName)
+			}
+			if l.Spec.FluentdSpec.TLS.CertManager != nil && l.Spec.FluentdSpec.TLS.CertManager.IssuerRef.Kind == "" {
+				l.Spec.FluentdSpec.TLS.CertManager.IssuerRef.Kind = "Issuer"
+			}
+			if s := l.Spec.FluentdSpec.TLS.Spiffe; s != nil {
+				if s.SocketDir == "" {
+					s.SocketDir = DefaultSpiffeSocketDir
+				}
+				if s.SocketName == "" {
+					s.SocketName = DefaultSpiffeSocketName
+				}
+				if s.Image.Repository == "" {
+					s.Image.Repository = DefaultSpiffeHelperImageRepository
+				}
+				if s.Image.Tag == "" {
+					s.Image.Tag = DefaultSpiffeHelperImageTag
+				}
+				if s.Image.PullPolicy == "" {
+					s.Image.PullPolicy = "IfNotPresent"
+				}
+				if s.RestartImage.Repository == "" {
+					s.RestartImage.Repository = DefaultSpiffeRestartImageRepository
+				}
+				if s.RestartImage.Tag == "" {
+					s.RestartImage.Tag = DefaultSpiffeRestartImageTag
+				}
+				if s.RestartImage.PullPolicy == "" {
+					s.RestartImage.PullPolicy = "IfNotPresent"
+				}
+			}
+		}
+		if l.Spec.ServiceMesh.IsEnabled() && l.Spec.ServiceMesh.DisableTLS {
+			l.Spec.FluentdSpec.TLS.Enabled = false
+			l.Spec.FluentdSpec.TLS.AutoGenerate = false
+			l.Spec.FluentdSpec.TLS.CertManager = nil
+			l.Spec.FluentdSpec.TLS.Spiffe = nil
+		}
 	}
 
 	if l.Spec.FluentbitSpec != nil { // nolint:nestif
@@ -360,6 +719,14 @@ func (l *Logging) SetDefaults() error {
 		if l.Spec.FluentbitSpec.Parser != "" {
 			return errors.New("`parser` field is deprecated, use `inputTail.Parser`")
 		}
+		if l.AggregatorLess() {
+			if len(l.Spec.FluentbitSpec.DirectOutputs) == 0 {
+				return errors.New("fluentbit requires fluentd, a targetHost or directOutputs to ship the logs to")
+			}
+			if l.Spec.FluentbitSpec.EnableUpstream {
+				return errors.New("fluentbit upstream requires fluentd")
+			}
+		}
 		if l.Spec.FluentbitSpec.Image.Repository == "" {
 			l.Spec.FluentbitSpec.Image.Repository = DefaultFluentbitImageRepository
 		}
@@ -381,6 +748,33 @@ func (l *Logging) SetDefaults() error {
 		if l.Spec.FluentbitSpec.CoroStackSize == 0 {
 			l.Spec.FluentbitSpec.CoroStackSize = 24576
 		}
+		profiles := make(map[string]bool)
+		exclusionTerms := 1
+		for _, profile := range l.Spec.FluentbitSpec.Profiles {
+			if len(profile.NodeSelector) == 0 {
+				return fmt.Errorf("fluentbit profile %q has no nodeSelector", profile.Name)
+			}
+			if len(profile.NodeSelector) > 1 {
+				exclusionTerms *= len(profile.NodeSelector)
+				if exclusionTerms > MaxFluentbitProfileExclusionTerms {
+					return fmt.Errorf("the fluentbit profiles with several nodeSelector labels exclude the default daemonset "+
+						"from their nodes with more than %d node selector terms, select the nodes of profile %q by a single label",
+						MaxFluentbitProfileExclusionTerms, profile.Name)
+				}
+			}
+			if profiles[profile.Name] {
+				return fmt.Errorf("duplicate fluentbit profile %q", profile.Name)
+			}
+			profiles[profile.Name] = true
+		}
+		if l.Spec.FluentbitSpec.Windows != nil {
+			if l.Spec.FluentbitSpec.NodeSelector == nil {
+				l.Spec.FluentbitSpec.NodeSelector = make(map[string]string)
+			}
+			if _, ok := l.Spec.FluentbitSpec.NodeSelector[v1.LabelOSStable]; !ok {
+				l.Spec.FluentbitSpec.NodeSelector[v1.LabelOSStable] = "linux"
+			}
+		}
 		if l.Spec.FluentbitSpec.Resources.Limits == nil {
 			l.Spec.FluentbitSpec.Resources.Limits = v1.ResourceList{
 				v1.ResourceMemory: resource.MustParse("100M"),
@@ -405,6 +799,50 @@ func (l *Logging) SetDefaults() error {
 		if l.Spec.FluentbitSpec.InputTail.DB == nil {
 			l.Spec.FluentbitSpec.InputTail.DB = util.StringPointer("/tail-db/tail-containers-state.db")
 		}
+		if hotReload := l.Spec.FluentbitSpec.ConfigHotReload; hotReload != nil {
+			if hotReload.Enabled {
+				if err := validateFluentbitHotReload(l.Spec.FluentbitSpec); err != nil {
+					return err
+				}
+			}
+			if hotReload.Image.Repository == "" {
+				hotReload.Image.Repository = DefaultFluentdConfigReloaderImageRepository
+			}
+			if hotReload.Image.Tag == "" {
+				hotReload.Image.Tag = DefaultFluentdConfigReloaderImageTag
+			}
+			if hotReload.Image.PullPolicy == "" {
+				hotReload.Image.PullPolicy = "IfNotPresent"
+			}
+		}
+		if recovery := l.Spec.FluentbitSpec.PositionDBRecovery; recovery != nil {
+			if recovery.Action == "" {
+				recovery.Action = PositionDBRecoveryRename
+			}
+			if recovery.Image.Repository == "" {
+				recovery.Image.Repository = DefaultFluentdVolumeModeImageRepository
+			}
+			if recovery.Image.Tag == "" {
+				recovery.Image.Tag = DefaultFluentdVolumeModeImageTag
+			}
+			if recovery.Image.PullPolicy == "" {
+				recovery.Image.PullPolicy = "IfNotPresent"
+			}
+		}
+		if metrics := l.Spec.FluentbitSpec.PositionDBMetrics; metrics != nil {
+			if metrics.Port == 0 {
+				metrics.Port = DefaultPositionDBMetricsPort
+			}
+			if metrics.Image.Repository == "" {
+				metrics.Image.Repository = DefaultPositionDBMetricsImageRepository
+			}
+			if metrics.Image.Tag == "" {
+				metrics.Image.Tag = DefaultPositionDBMetricsImageTag
+			}
+			if metrics.Image.PullPolicy == "" {
+				metrics.Image.PullPolicy = "IfNotPresent"
+			}
+		}
 		if l.Spec.FluentbitSpec.InputTail.MemBufLimit == "" {
 			l.Spec.FluentbitSpec.InputTail.MemBufLimit = "5MB"
 		}
@@ -417,6 +855,7 @@ func (l *Logging) SetDefaults() error {
 		if l.Spec.FluentbitSpec.Security == nil {
 			l.Spec.FluentbitSpec.Security = &Security{}
 		}
+		l.Spec.FluentbitSpec.Security.ApplyCreateRBAC()
 		if l.Spec.FluentbitSpec.Security.RoleBasedAccessControlCreate == nil {
 			l.Spec.FluentbitSpec.Security.RoleBasedAccessControlCreate = util.BoolPointer(true)
 		}
@@ -426,6 +865,9 @@ func (l *Logging) SetDefaults() error {
 		if l.Spec.FluentbitSpec.Security.PodSecurityContext == nil {
 			l.Spec.FluentbitSpec.Security.PodSecurityContext = &v1.PodSecurityContext{}
 		}
+		if l.Spec.FluentbitSpec.Security.PodSecurityStandard != "" {
+			return ErrPodSecurityStandardHostPath
+		}
 		if l.Spec.FluentbitSpec.Metrics != nil {
 			if l.Spec.FluentbitSpec.Metrics.Path == "" {
 				l.Spec.FluentbitSpec.Metrics.Path = "/api/v1/metrics/prometheus"
@@ -520,14 +962,169 @@ func (l *Logging) SetDefaults() error {
 		if l.Spec.FluentbitSpec.TLS == nil {
 			l.Spec.FluentbitSpec.TLS = &FluentbitTLS{}
 		}
-		if l.Spec.FluentbitSpec.TLS.Enabled == nil {
+		if l.Spec.FluentdSpec != nil && l.Spec.FluentdSpec.TLS.IsManaged() && l.Spec.FluentbitSpec.TLS.SecretName == "" {
+			// Use a dedicated client certificate issued by the operator or cert-manager
+			l.Spec.FluentbitSpec.TLS.SecretName = l.QualifiedName(DefaultFluentbitTLSSecretName)
+		}
+		if l.Spec.ServiceMesh.IsEnabled() && l.Spec.ServiceMesh.DisableTLS {
 			l.Spec.FluentbitSpec.TLS.Enabled = util.BoolPointer(false)
 		}
+		if l.Spec.FluentbitSpec.TLS.Enabled == nil {
+			// Follow the forward input of fluentd unless TLS is configured explicitly for fluentbit
+			if l.Spec.FluentdSpec != nil && l.Spec.FluentdSpec.TLS.Enabled {
+				l.Spec.FluentbitSpec.TLS.Enabled = util.BoolPointer(true)
+				if l.Spec.FluentbitSpec.TLS.SecretName == "" {
+					l.Spec.FluentbitSpec.TLS.SecretName = l.Spec.FluentdSpec.TLS.SecretName
+				}
+				if l.Spec.FluentbitSpec.TLS.SharedKey == "" {
+					l.Spec.FluentbitSpec.TLS.SharedKey = l.Spec.FluentdSpec.TLS.SharedKey
+				}
+			} else {
+				l.Spec.FluentbitSpec.TLS.Enabled = util.BoolPointer(false)
+			}
+		}
+	}
+
+	if l.Spec.Monitoring != nil && l.Spec.Monitoring.Dashboards.Enabled {
+		if l.Spec.Monitoring.Dashboards.Namespace == "" {
+			l.Spec.Monitoring.Dashboards.Namespace = l.Spec.ControlNamespace
+		}
+		if l.Spec.Monitoring.Dashboards.Labels == nil {
+			l.Spec.Monitoring.Dashboards.Labels = map[string]string{"grafana_dashboard": "1"}
+		}
+	}
+	if l.Spec.AuditLog != nil {
+		if l.Spec.AuditLog.Image.Repository == "" {
+			l.Spec.AuditLog.Image.Repository = DefaultFluentbitImageRepository
+		}
+		if l.Spec.AuditLog.Image.Tag == "" {
+			l.Spec.AuditLog.Image.Tag = DefaultFluentbitImageTag
+		}
+		if l.Spec.AuditLog.Image.PullPolicy == "" {
+			l.Spec.AuditLog.Image.PullPolicy = "IfNotPresent"
+		}
+		if l.Spec.AuditLog.Replicas == nil {
+			l.Spec.AuditLog.Replicas = util.IntPointer(1)
+		}
+		if l.Spec.AuditLog.Port == 0 {
+			l.Spec.AuditLog.Port = 9880
+		}
+		if l.Spec.AuditLog.Tag == "" {
+			l.Spec.AuditLog.Tag = "audit"
+		}
+		if l.Spec.AuditLog.SecretName == "" {
+			l.Spec.AuditLog.SecretName = l.QualifiedName(DefaultAuditLogTLSSecretName)
+			if l.Spec.AuditLog.AutoGenerate == nil {
+				l.Spec.AuditLog.AutoGenerate = util.BoolPointer(true)
+			}
+		}
 	}
 
 	return nil
 }
 
+// Images returns the references of the images deployed for the logging, including the extra containers and the
+// images set in the workload overrides. The output check and the buffer repair pods run the fluentd image. The
+// node agents without an image of their own run the default of the operator, which is not returned.
+func (l *Logging) Images() []string {
+	var specs []ImageSpec
+	var images []string
+	if fluentd := l.Spec.FluentdSpec; fluentd != nil {
+		specs = append(specs,
+			fluentd.Image,
+			fluentd.ConfigReloaderImage,
+			fluentd.VolumeModImage,
+			fluentd.BufferVolumeImage,
+		)
+		if fluentd.Scaling != nil {
+			specs = append(specs, fluentd.Scaling.Drain.Image, fluentd.Scaling.Drain.PauseImage)
+		}
+		if e := fluentd.BufferVolumeEncryption; e != nil && e.Enabled {
+			specs = append(specs, e.Image)
+		}
+		// The spiffe-helper runs next to fluentd and the agents, the agents get the restart sidecar as well
+		if fluentd.TLS.Spiffe != nil {
+			specs = append(specs, fluentd.TLS.Spiffe.Image)
+			if l.Spec.FluentbitSpec != nil || len(l.Spec.NodeAgents) > 0 {
+				specs = append(specs, fluentd.TLS.Spiffe.RestartImage)
+			}
+		}
+		images = append(images, containerImages(fluentd.ExtraInitContainers)...)
+		images = append(images, containerImages(fluentd.ExtraContainers)...)
+		if fluentd.StatefulSetOverrides != nil {
+			images = append(images, podSpecImages(fluentd.StatefulSetOverrides.Spec.Template.Spec)...)
+		}
+		if fluentd.DeploymentOverrides != nil {
+			images = append(images, podSpecImages(fluentd.DeploymentOverrides.Spec.Template.Spec)...)
+		}
+	}
+	if fluentbit := l.Spec.FluentbitSpec; fluentbit != nil {
+		specs = append(specs, fluentbit.Image)
+		if w := fluentbit.Windows; w != nil {
+			if w.Image.Repository != "" {
+				specs = append(specs, w.Image)
+			}
+			if w.DaemonSetOverrides != nil {
+				images = append(images, podSpecImages(w.DaemonSetOverrides.Spec.Template.Spec)...)
+			}
+		}
+		if r := fluentbit.PositionDBRecovery; r != nil && r.Enabled {
+			specs = append(specs, r.Image)
+		}
+		if r := fluentbit.PositionDBMetrics; r != nil && r.Enabled {
+			specs = append(specs, r.Image)
+		}
+		if r := fluentbit.ConfigHotReload; r != nil && r.Enabled {
+			specs = append(specs, r.Image)
+		}
+		images = append(images, containerImages(fluentbit.ExtraInitContainers)...)
+		images = append(images, containerImages(fluentbit.ExtraContainers)...)
+		if fluentbit.DaemonSetOverrides != nil {
+			images = append(images, podSpecImages(fluentbit.DaemonSetOverrides.Spec.Template.Spec)...)
+		}
+	}
+	for _, agent := range l.Spec.NodeAgents {
+		if agent == nil || agent.FluentbitSpec == nil {
+			continue
+		}
+		if agent.FluentbitSpec.Image.Repository != "" {
+			specs = append(specs, agent.FluentbitSpec.Image)
+		}
+		if agent.FluentbitSpec.DaemonSetOverrides != nil {
+			images = append(images, podSpecImages(agent.FluentbitSpec.DaemonSetOverrides.Spec.Template.Spec)...)
+		}
+	}
+	if l.Spec.AuditLog != nil {
+		specs = append(specs, l.Spec.AuditLog.Image)
+	}
+	if l.Spec.EventTailer != nil {
+		image := extensionsconfig.EventTailer.Image
+		if l.Spec.EventTailer.ContainerBase != nil && l.Spec.EventTailer.ContainerBase.Image != "" {
+			image = l.Spec.EventTailer.ContainerBase.Image
+		}
+		images = append(images, image)
+	}
+	for _, spec := range specs {
+		images = append(images, spec.RepositoryWithTag())
+	}
+	return images
+}
+
+func podSpecImages(spec typeoverride.PodSpec) []string {
+	return append(containerImages(spec.InitContainers), containerImages(spec.Containers)...)
+}
+
+// containerImages returns the images set in the containers, the containers of the overrides may leave them unset
+func containerImages(containers []v1.Container) []string {
+	var images []string
+	for _, c := range containers {
+		if c.Image != "" {
+			images = append(images, c.Image)
+		}
+	}
+	return images
+}
+
 // SetDefaultsOnCopy makes a deep copy of the instance and sets defaults on the copy
 func (l *Logging) SetDefaultsOnCopy() (*Logging, error) {
 	if l == nil {
@@ -584,6 +1181,11 @@ func (l *Logging) GetFluentdLabels(component string) map[string]string {
 	)
 }
 
+// AggregatorLess tells whether fluent-bit runs without an aggregator, shipping the logs to its direct outputs only
+func (l *Logging) AggregatorLess() bool {
+	return l.Spec.FluentdSpec == nil && l.Spec.FluentbitSpec != nil && l.Spec.FluentbitSpec.TargetHost == ""
+}
+
 func GenerateLoggingRefLabels(loggingRef string) map[string]string {
 	return map[string]string{"app.kubernetes.io/managed-by": loggingRef}
 }
@@ -0,0 +1,101 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"fmt"
+
+	"github.com/banzaicloud/operator-tools/pkg/merge"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +name:"LoggingProfile"
+// +weight:"200"
+type _hugoLoggingProfile interface{} //nolint:deadcode,unused
+
+// +name:"LoggingProfile"
+// +version:"v1beta1"
+// +description:"LoggingProfile is a named preset of the aggregator and agent settings referenced by loggings"
+type _metaLoggingProfile interface{} //nolint:deadcode,unused
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=loggingprofiles,scope=Cluster,categories=logging-all
+// +kubebuilder:storageversion
+
+// LoggingProfile is a named preset, like "small", "high-throughput" or "edge", referenced by logging.spec.profile
+type LoggingProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec LoggingProfileSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+// LoggingProfileSpec holds the preset values. The values set explicitly in the logging take precedence, lists are
+// replaced as a whole. The presets only tune the components enabled in the logging, they do not enable them.
+//
+// A logging cannot reset a preset to false, 0 or an empty string, where its field is omitted when empty: such a
+// value cannot be told apart from an unset one. E.g. a preset setting fluentd.disablePvc cannot be reverted by a
+// logging, keep settings like this in the loggings instead of the profile.
+type LoggingProfileSpec struct {
+	// Preset of the fluentd aggregator: resources, workers, buffer volume, probes, scaling
+	FluentdSpec *FluentdSpec `json:"fluentd,omitempty"`
+	// Preset of the fluent-bit agent
+	FluentbitSpec *FluentbitSpec `json:"fluentbit,omitempty"`
+	// Preset of the buffer settings of the outputs
+	GlobalOutputSettings *GlobalOutputSettings `json:"globalOutputSettings,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LoggingProfileList contains a list of LoggingProfile
+type LoggingProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LoggingProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LoggingProfile{}, &LoggingProfileList{})
+}
+
+// ApplyProfile merges the preset values of the profile under the spec of the logging
+func (l *Logging) ApplyProfile(profile LoggingProfileSpec) error {
+	if l.Spec.FluentdSpec != nil && profile.FluentdSpec != nil {
+		merged := profile.FluentdSpec.DeepCopy()
+		if err := merge.Merge(merged, l.Spec.FluentdSpec); err != nil {
+			return fmt.Errorf("failed to merge the fluentd preset: %w", err)
+		}
+		l.Spec.FluentdSpec = merged
+	}
+	if l.Spec.FluentbitSpec != nil && profile.FluentbitSpec != nil {
+		merged := profile.FluentbitSpec.DeepCopy()
+		if err := merge.Merge(merged, l.Spec.FluentbitSpec); err != nil {
+			return fmt.Errorf("failed to merge the fluentbit preset: %w", err)
+		}
+		l.Spec.FluentbitSpec = merged
+	}
+	if profile.GlobalOutputSettings != nil {
+		merged := profile.GlobalOutputSettings.DeepCopy()
+		if l.Spec.GlobalOutputSettings != nil {
+			if err := merge.Merge(merged, l.Spec.GlobalOutputSettings); err != nil {
+				return fmt.Errorf("failed to merge the global output settings preset: %w", err)
+			}
+		}
+		l.Spec.GlobalOutputSettings = merged
+	}
+	return nil
+}
@@ -34,7 +34,10 @@ type NodeAgent struct {
 // +kubebuilder:object:generate=true
 
 type NodeAgentFluentbit struct {
-	Enabled                 *bool                        `json:"enabled,omitempty"`
+	Enabled *bool `json:"enabled,omitempty"`
+	// Image of fluent-bit, takes precedence over the image of the profile and of the daemonSet overrides.
+	// Set it to pin the image by digest, see requireImageDigests.
+	Image                   ImageSpec                    `json:"image,omitempty"`
 	DaemonSetOverrides      *typeoverride.DaemonSet      `json:"daemonSet,omitempty"`
 	ServiceAccountOverrides *typeoverride.ServiceAccount `json:"serviceAccount,omitempty"`
 	TLS                     *FluentbitTLS                `json:"tls,omitempty"`
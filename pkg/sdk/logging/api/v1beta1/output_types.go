@@ -16,6 +16,7 @@ package v1beta1
 
 import (
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -56,6 +57,138 @@ type OutputSpec struct {
 	SyslogOutputConfig           *output.SyslogOutputConfig           `json:"syslog,omitempty"`
 	GELFOutputConfig             *output.GELFOutputConfig             `json:"gelf,omitempty"`
 	SQSOutputConfig              *output.SQSOutputConfig              `json:"sqs,omitempty"`
+	OTLPOutput                   *output.OTLPOutput                   `json:"otlp,omitempty"`
+	LoggingOperatorForward       *output.LoggingOperatorForwardOutput `json:"loggingOperatorForward,omitempty"`
+	NATSOutput                   *output.NATSOutput                   `json:"nats,omitempty"`
+	// Outputs to fall back to, in order, once this output gives up retrying.
+	// Outputs reference Outputs in the same namespace, ClusterOutputs reference ClusterOutputs.
+	// Retries must be limited on the buffer (retry_forever: false) for the failover to take effect.
+	Failover []string `json:"failover,omitempty"`
+	// Output to divert the events to that could not be delivered by this output and its failovers,
+	// for example because of unrecoverable errors. Referenced the same way as the failover outputs.
+	DeadLetter string `json:"deadLetter,omitempty"`
+	// CA bundle and client certificate of the output from sources other than Secrets. They are mounted into fluentd
+	// and take the place of the corresponding TLS fields of the output.
+	TLSFrom *TLSFrom `json:"tlsFrom,omitempty"`
+	// Name of an output template of the logging whose format is used by the output, unless it sets its own format
+	Template string `json:"template,omitempty"`
+}
+
+// OutputTemplate is a named message format outputs can reference instead of repeating it
+type OutputTemplate struct {
+	Name   string         `json:"name"`
+	Format *output.Format `json:"format"`
+}
+
+// TLSFrom sets the public TLS material of an output, the client key still has to be set from a Secret
+type TLSFrom struct {
+	CABundle   *TLSSource `json:"caBundle,omitempty"`
+	ClientCert *TLSSource `json:"clientCert,omitempty"`
+}
+
+// TLSSource is a PEM bundle from a ConfigMap, like the ones distributed by trust-manager, or from ClusterTrustBundles
+type TLSSource struct {
+	// Key of a ConfigMap in the namespace of the output. ClusterOutputs can also reference the namespaces listed in
+	// secretNamespaces of the logging as <namespace>/<name>.
+	ConfigMapKeyRef    *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	ClusterTrustBundle *ClusterTrustBundleSource    `json:"clusterTrustBundle,omitempty"`
+}
+
+// ClusterTrustBundleSource selects ClusterTrustBundles (certificates.k8s.io/v1alpha1) by name, or by signer name and
+// labels. The bundles selected by signer are concatenated.
+type ClusterTrustBundleSource struct {
+	Name          string                `json:"name,omitempty"`
+	SignerName    string                `json:"signerName,omitempty"`
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+// GlobalOutputSettings defines the defaults for all outputs of a logging
+type GlobalOutputSettings struct {
+	// Buffer settings (sizes, retry policy, compression) used for every output buffer.
+	// Fields set in the buffer of an output take precedence.
+	Buffer *output.Buffer `json:"buffer,omitempty"`
+	// Minimum TLS version of the outputs that have a version setting (forward, http, elasticsearch and gelf) and do
+	// not set it themselves. The other outputs can be constrained with tlsProfile.
+	// +kubebuilder:validation:Enum=TLSv1_2;TLSv1_3
+	TLSMinVersion string `json:"tlsMinVersion,omitempty"`
+}
+
+// Log classes flows can be marked with using logClass
+const (
+	LogClassCritical   = "critical"
+	LogClassNormal     = "normal"
+	LogClassBestEffort = "best-effort"
+)
+
+// LogClasses overrides the buffering of the log classes. A class set here replaces its built-in defaults:
+// critical logs block and retry forever with large buffers, best-effort logs are dropped first with small buffers
+// and limited retries, normal logs use the output and global settings.
+type LogClasses struct {
+	Critical   *LogClassSettings `json:"critical,omitempty"`
+	Normal     *LogClassSettings `json:"normal,omitempty"`
+	BestEffort *LogClassSettings `json:"bestEffort,omitempty"`
+}
+
+// LogClassSettings defines the buffering of the logs of a class
+type LogClassSettings struct {
+	// Buffer settings of the outputs of the flows in the class.
+	// Fields set in the buffer of an output take precedence, the global output settings apply to the fields left unset.
+	Buffer *output.Buffer `json:"buffer,omitempty"`
+	// The storage.total_limit_size of the fluent-bit forward output. Fluent-bit buffers the logs of every class in the
+	// same output, so the limit of the most critical class used by the flows applies.
+	AgentStorageTotalLimitSize string `json:"agentStorageTotalLimitSize,omitempty"`
+}
+
+// Settings returns the settings of the class, the built-in defaults apply to the classes not configured
+func (c *LogClasses) Settings(class string) LogClassSettings {
+	var settings *LogClassSettings
+	if c != nil {
+		switch class {
+		case LogClassCritical:
+			settings = c.Critical
+		case LogClassBestEffort:
+			settings = c.BestEffort
+		case LogClassNormal, "":
+			settings = c.Normal
+		}
+	}
+	if settings != nil {
+		return *settings.DeepCopy()
+	}
+	retryForever := true
+	switch class {
+	case LogClassCritical:
+		return LogClassSettings{
+			Buffer: &output.Buffer{
+				TotalLimitSize: "16G",
+				OverflowAction: "block",
+				RetryForever:   &retryForever,
+			},
+		}
+	case LogClassBestEffort:
+		retryForever = false
+		return LogClassSettings{
+			Buffer: &output.Buffer{
+				TotalLimitSize: "512M",
+				OverflowAction: "drop_oldest_chunk",
+				RetryForever:   &retryForever,
+				RetryMaxTimes:  5,
+			},
+			AgentStorageTotalLimitSize: "512M",
+		}
+	}
+	return LogClassSettings{}
+}
+
+// LogClassPriority orders the classes from best-effort (0) to critical (2), unknown classes are handled as normal
+func LogClassPriority(class string) int {
+	switch class {
+	case LogClassBestEffort:
+		return 0
+	case LogClassCritical:
+		return 2
+	}
+	return 1
 }
 
 // OutputStatus defines the observed state of Output
@@ -63,6 +196,19 @@ type OutputStatus struct {
 	Active        *bool    `json:"active,omitempty"`
 	Problems      []string `json:"problems,omitempty"`
 	ProblemsCount int      `json:"problemsCount,omitempty"`
+	// Result of the last output check, see outputCheck of the logging
+	Check *OutputCheckStatus `json:"check,omitempty"`
+	// Output of the failover chain the events are delivered to: the output itself, one of its failover outputs or
+	// its dead letter output. It is the first one of the chain that is not retrying according to the fluentd metrics,
+	// reported when the fluentd metrics of the logging are enabled.
+	ActiveOutput string `json:"activeOutput,omitempty"`
+}
+
+// OutputCheckStatus is the result of probing the endpoints of an output
+type OutputCheckStatus struct {
+	Reachable bool        `json:"reachable"`
+	Message   string      `json:"message,omitempty"`
+	CheckedAt metav1.Time `json:"checkedAt"`
 }
 
 // +kubebuilder:object:root=true
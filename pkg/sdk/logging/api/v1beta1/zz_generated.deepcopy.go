@@ -23,13 +23,94 @@ import (
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/filter"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/input"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
 	"github.com/banzaicloud/operator-tools/pkg/typeoverride"
+	"github.com/banzaicloud/operator-tools/pkg/types"
 	"github.com/banzaicloud/operator-tools/pkg/volume"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppConfigChunking) DeepCopyInto(out *AppConfigChunking) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppConfigChunking.
+func (in *AppConfigChunking) DeepCopy() *AppConfigChunking {
+	if in == nil {
+		return nil
+	}
+	out := new(AppConfigChunking)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditLog) DeepCopyInto(out *AuditLog) {
+	*out = *in
+	in.Image.DeepCopyInto(&out.Image)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Token != nil {
+		in, out := &in.Token, &out.Token
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoGenerate != nil {
+		in, out := &in.AutoGenerate, &out.AutoGenerate
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditLog.
+func (in *AuditLog) DeepCopy() *AuditLog {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditLog)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoRollback) DeepCopyInto(out *AutoRollback) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoRollback.
+func (in *AutoRollback) DeepCopy() *AutoRollback {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoRollback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoRollbackStatus) DeepCopyInto(out *AutoRollbackStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoRollbackStatus.
+func (in *AutoRollbackStatus) DeepCopy() *AutoRollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoRollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BufferStorage) DeepCopyInto(out *BufferStorage) {
 	*out = *in
@@ -45,6 +126,70 @@ func (in *BufferStorage) DeepCopy() *BufferStorage {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BufferVolumeEncryption) DeepCopyInto(out *BufferVolumeEncryption) {
+	*out = *in
+	in.KeySecret.DeepCopyInto(&out.KeySecret)
+	in.Image.DeepCopyInto(&out.Image)
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BufferVolumeEncryption.
+func (in *BufferVolumeEncryption) DeepCopy() *BufferVolumeEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(BufferVolumeEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BufferVolumeRepair) DeepCopyInto(out *BufferVolumeRepair) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BufferVolumeRepair.
+func (in *BufferVolumeRepair) DeepCopy() *BufferVolumeRepair {
+	if in == nil {
+		return nil
+	}
+	out := new(BufferVolumeRepair)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerRef) DeepCopyInto(out *CertManagerIssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerRef.
+func (in *CertManagerIssuerRef) DeepCopy() *CertManagerIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerTLS) DeepCopyInto(out *CertManagerTLS) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerTLS.
+func (in *CertManagerTLS) DeepCopy() *CertManagerTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterExclude) DeepCopyInto(out *ClusterExclude) {
 	*out = *in
@@ -70,6 +215,25 @@ func (in *ClusterExclude) DeepCopyInto(out *ClusterExclude) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]FieldMatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelExpressions != nil {
+		in, out := &in.LabelExpressions, &out.LabelExpressions
+		*out = make([]LabelExpression, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AnyOf != nil {
+		in, out := &in.AnyOf, &out.AnyOf
+		*out = make([]MatchTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterExclude.
@@ -317,6 +481,25 @@ func (in *ClusterSelect) DeepCopyInto(out *ClusterSelect) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]FieldMatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelExpressions != nil {
+		in, out := &in.LabelExpressions, &out.LabelExpressions
+		*out = make([]LabelExpression, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AnyOf != nil {
+		in, out := &in.AnyOf, &out.AnyOf
+		*out = make([]MatchTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSelect.
@@ -329,6 +512,63 @@ func (in *ClusterSelect) DeepCopy() *ClusterSelect {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTrustBundleSource) DeepCopyInto(out *ClusterTrustBundleSource) {
+	*out = *in
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTrustBundleSource.
+func (in *ClusterTrustBundleSource) DeepCopy() *ClusterTrustBundleSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTrustBundleSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigHistory) DeepCopyInto(out *ConfigHistory) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigHistory.
+func (in *ConfigHistory) DeepCopy() *ConfigHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Dashboards) DeepCopyInto(out *Dashboards) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dashboards.
+func (in *Dashboards) DeepCopy() *Dashboards {
+	if in == nil {
+		return nil
+	}
+	out := new(Dashboards)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DefaultFlowSpec) DeepCopyInto(out *DefaultFlowSpec) {
 	*out = *in
@@ -361,6 +601,37 @@ func (in *DefaultFlowSpec) DeepCopy() *DefaultFlowSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventTailer) DeepCopyInto(out *EventTailer) {
+	*out = *in
+	in.PositionVolume.DeepCopyInto(&out.PositionVolume)
+	if in.WorkloadMetaBase != nil {
+		in, out := &in.WorkloadMetaBase, &out.WorkloadMetaBase
+		*out = new(types.MetaBase)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkloadBase != nil {
+		in, out := &in.WorkloadBase, &out.WorkloadBase
+		*out = new(types.PodSpecBase)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ContainerBase != nil {
+		in, out := &in.ContainerBase, &out.ContainerBase
+		*out = new(types.ContainerBase)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventTailer.
+func (in *EventTailer) DeepCopy() *EventTailer {
+	if in == nil {
+		return nil
+	}
+	out := new(EventTailer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Exclude) DeepCopyInto(out *Exclude) {
 	*out = *in
@@ -381,6 +652,25 @@ func (in *Exclude) DeepCopyInto(out *Exclude) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]FieldMatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelExpressions != nil {
+		in, out := &in.LabelExpressions, &out.LabelExpressions
+		*out = make([]LabelExpression, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AnyOf != nil {
+		in, out := &in.AnyOf, &out.AnyOf
+		*out = make([]MatchTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Exclude.
@@ -413,6 +703,21 @@ func (in *ExtraVolume) DeepCopy() *ExtraVolume {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldMatch) DeepCopyInto(out *FieldMatch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldMatch.
+func (in *FieldMatch) DeepCopy() *FieldMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Filter) DeepCopyInto(out *Filter) {
 	*out = *in
@@ -481,6 +786,16 @@ func (in *Filter) DeepCopyInto(out *Filter) {
 		*out = new(filter.Throttle)
 		**out = **in
 	}
+	if in.Sample != nil {
+		in, out := &in.Sample, &out.Sample
+		*out = new(filter.Sample)
+		**out = **in
+	}
+	if in.Redact != nil {
+		in, out := &in.Redact, &out.Redact
+		*out = new(filter.Redact)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SumoLogic != nil {
 		in, out := &in.SumoLogic, &out.SumoLogic
 		*out = new(filter.SumoLogic)
@@ -608,6 +923,27 @@ func (in *FilterKubernetes) DeepCopy() *FilterKubernetes {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilterLua) DeepCopyInto(out *FilterLua) {
+	*out = *in
+	in.Script.DeepCopyInto(&out.Script)
+	if in.ProtectedMode != nil {
+		in, out := &in.ProtectedMode, &out.ProtectedMode
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilterLua.
+func (in *FilterLua) DeepCopy() *FilterLua {
+	if in == nil {
+		return nil
+	}
+	out := new(FilterLua)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FilterModify) DeepCopyInto(out *FilterModify) {
 	*out = *in
@@ -885,6 +1221,11 @@ func (in *FlowStatus) DeepCopyInto(out *FlowStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.TestMessage != nil {
+		in, out := &in.TestMessage, &out.TestMessage
+		*out = new(TestMessageStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlowStatus.
@@ -913,30 +1254,232 @@ func (in *FluentOutLogrotate) DeepCopy() *FluentOutLogrotate {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FluentbitNetwork) DeepCopyInto(out *FluentbitNetwork) {
+func (in *FluentbitAgentProfile) DeepCopyInto(out *FluentbitAgentProfile) {
 	*out = *in
-	if in.ConnectTimeout != nil {
-		in, out := &in.ConnectTimeout, &out.ConnectTimeout
-		*out = new(uint32)
-		**out = **in
-	}
-	if in.ConnectTimeoutLogError != nil {
-		in, out := &in.ConnectTimeoutLogError, &out.ConnectTimeoutLogError
-		*out = new(bool)
-		**out = **in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.DNSPreferIPV4 != nil {
-		in, out := &in.DNSPreferIPV4, &out.DNSPreferIPV4
-		*out = new(bool)
-		**out = **in
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.Keepalive != nil {
-		in, out := &in.Keepalive, &out.Keepalive
-		*out = new(bool)
-		**out = **in
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentbitAgentProfile.
+func (in *FluentbitAgentProfile) DeepCopy() *FluentbitAgentProfile {
+	if in == nil {
+		return nil
 	}
-	if in.KeepaliveIdleTimeout != nil {
-		in, out := &in.KeepaliveIdleTimeout, &out.KeepaliveIdleTimeout
+	out := new(FluentbitAgentProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentbitConfigHotReload) DeepCopyInto(out *FluentbitConfigHotReload) {
+	*out = *in
+	in.Image.DeepCopyInto(&out.Image)
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentbitConfigHotReload.
+func (in *FluentbitConfigHotReload) DeepCopy() *FluentbitConfigHotReload {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentbitConfigHotReload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentbitDirectElasticsearch) DeepCopyInto(out *FluentbitDirectElasticsearch) {
+	*out = *in
+	if in.LogstashFormat != nil {
+		in, out := &in.LogstashFormat, &out.LogstashFormat
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SuppressTypeName != nil {
+		in, out := &in.SuppressTypeName, &out.SuppressTypeName
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReplaceDots != nil {
+		in, out := &in.ReplaceDots, &out.ReplaceDots
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HTTPUser != nil {
+		in, out := &in.HTTPUser, &out.HTTPUser
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPPasswd != nil {
+		in, out := &in.HTTPPasswd, &out.HTTPPasswd
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TLSVerify != nil {
+		in, out := &in.TLSVerify, &out.TLSVerify
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentbitDirectElasticsearch.
+func (in *FluentbitDirectElasticsearch) DeepCopy() *FluentbitDirectElasticsearch {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentbitDirectElasticsearch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentbitDirectLoki) DeepCopyInto(out *FluentbitDirectLoki) {
+	*out = *in
+	if in.AutoKubernetesLabels != nil {
+		in, out := &in.AutoKubernetesLabels, &out.AutoKubernetesLabels
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HTTPUser != nil {
+		in, out := &in.HTTPUser, &out.HTTPUser
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPPasswd != nil {
+		in, out := &in.HTTPPasswd, &out.HTTPPasswd
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TLSVerify != nil {
+		in, out := &in.TLSVerify, &out.TLSVerify
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentbitDirectLoki.
+func (in *FluentbitDirectLoki) DeepCopy() *FluentbitDirectLoki {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentbitDirectLoki)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentbitDirectOutput) DeepCopyInto(out *FluentbitDirectOutput) {
+	*out = *in
+	if in.Loki != nil {
+		in, out := &in.Loki, &out.Loki
+		*out = new(FluentbitDirectLoki)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Elasticsearch != nil {
+		in, out := &in.Elasticsearch, &out.Elasticsearch
+		*out = new(FluentbitDirectElasticsearch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(FluentbitDirectS3)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentbitDirectOutput.
+func (in *FluentbitDirectOutput) DeepCopy() *FluentbitDirectOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentbitDirectOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentbitDirectS3) DeepCopyInto(out *FluentbitDirectS3) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentbitDirectS3.
+func (in *FluentbitDirectS3) DeepCopy() *FluentbitDirectS3 {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentbitDirectS3)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentbitHostTailer) DeepCopyInto(out *FluentbitHostTailer) {
+	*out = *in
+	if in.MultilineParser != nil {
+		in, out := &in.MultilineParser, &out.MultilineParser
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentbitHostTailer.
+func (in *FluentbitHostTailer) DeepCopy() *FluentbitHostTailer {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentbitHostTailer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentbitNetwork) DeepCopyInto(out *FluentbitNetwork) {
+	*out = *in
+	if in.ConnectTimeout != nil {
+		in, out := &in.ConnectTimeout, &out.ConnectTimeout
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.ConnectTimeoutLogError != nil {
+		in, out := &in.ConnectTimeoutLogError, &out.ConnectTimeoutLogError
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DNSPreferIPV4 != nil {
+		in, out := &in.DNSPreferIPV4, &out.DNSPreferIPV4
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Keepalive != nil {
+		in, out := &in.Keepalive, &out.Keepalive
+		*out = new(bool)
+		**out = **in
+	}
+	if in.KeepaliveIdleTimeout != nil {
+		in, out := &in.KeepaliveIdleTimeout, &out.KeepaliveIdleTimeout
 		*out = new(uint32)
 		**out = **in
 	}
@@ -957,6 +1500,41 @@ func (in *FluentbitNetwork) DeepCopy() *FluentbitNetwork {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentbitParam) DeepCopyInto(out *FluentbitParam) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentbitParam.
+func (in *FluentbitParam) DeepCopy() *FluentbitParam {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentbitParam)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentbitProcessor) DeepCopyInto(out *FluentbitProcessor) {
+	*out = *in
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make([]FluentbitParam, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentbitProcessor.
+func (in *FluentbitProcessor) DeepCopy() *FluentbitProcessor {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentbitProcessor)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FluentbitSpec) DeepCopyInto(out *FluentbitSpec) {
 	*out = *in
@@ -1025,6 +1603,21 @@ func (in *FluentbitSpec) DeepCopyInto(out *FluentbitSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.PositionDB.DeepCopyInto(&out.PositionDB)
+	if in.PositionDBRecovery != nil {
+		in, out := &in.PositionDBRecovery, &out.PositionDBRecovery
+		*out = new(PositionDBRecovery)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PositionDBMetrics != nil {
+		in, out := &in.PositionDBMetrics, &out.PositionDBMetrics
+		*out = new(PositionDBMetrics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigHotReload != nil {
+		in, out := &in.ConfigHotReload, &out.ConfigHotReload
+		*out = new(FluentbitConfigHotReload)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PosisionDBLegacy != nil {
 		in, out := &in.PosisionDBLegacy, &out.PosisionDBLegacy
 		*out = new(volume.KubernetesVolume)
@@ -1041,7 +1634,33 @@ func (in *FluentbitSpec) DeepCopyInto(out *FluentbitSpec) {
 			}
 		}
 	}
+	if in.ExtraContainers != nil {
+		in, out := &in.ExtraContainers, &out.ExtraContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraInitContainers != nil {
+		in, out := &in.ExtraInitContainers, &out.ExtraInitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.InputTail.DeepCopyInto(&out.InputTail)
+	if in.HostTailers != nil {
+		in, out := &in.HostTailers, &out.HostTailers
+		*out = make([]FluentbitHostTailer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SystemdInput != nil {
+		in, out := &in.SystemdInput, &out.SystemdInput
+		*out = new(InputSystemd)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.FilterAws != nil {
 		in, out := &in.FilterAws, &out.FilterAws
 		*out = new(FilterAws)
@@ -1054,6 +1673,39 @@ func (in *FluentbitSpec) DeepCopyInto(out *FluentbitSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MultilineParsers != nil {
+		in, out := &in.MultilineParsers, &out.MultilineParsers
+		*out = make([]MultilineParser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StreamTasks != nil {
+		in, out := &in.StreamTasks, &out.StreamTasks
+		*out = make([]FluentbitStreamTask, len(*in))
+		copy(*out, *in)
+	}
+	if in.Processors != nil {
+		in, out := &in.Processors, &out.Processors
+		*out = make([]FluentbitProcessor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DirectOutputs != nil {
+		in, out := &in.DirectOutputs, &out.DirectOutputs
+		*out = make([]FluentbitDirectOutput, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CustomLuaScripts != nil {
+		in, out := &in.CustomLuaScripts, &out.CustomLuaScripts
+		*out = make([]FilterLua, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.FilterKubernetes = in.FilterKubernetes
 	if in.DisableKubernetesFilter != nil {
 		in, out := &in.DisableKubernetesFilter, &out.DisableKubernetesFilter
@@ -1092,6 +1744,28 @@ func (in *FluentbitSpec) DeepCopyInto(out *FluentbitSpec) {
 		*out = new(v1.PodDNSConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DaemonSetOverrides != nil {
+		in, out := &in.DaemonSetOverrides, &out.DaemonSetOverrides
+		*out = new(typeoverride.DaemonSet)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = new(FluentbitWindows)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]FluentbitAgentProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VPA != nil {
+		in, out := &in.VPA, &out.VPA
+		*out = new(VerticalPodAutoscaler)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentbitSpec.
@@ -1104,6 +1778,21 @@ func (in *FluentbitSpec) DeepCopy() *FluentbitSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentbitStreamTask) DeepCopyInto(out *FluentbitStreamTask) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentbitStreamTask.
+func (in *FluentbitStreamTask) DeepCopy() *FluentbitStreamTask {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentbitStreamTask)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FluentbitTLS) DeepCopyInto(out *FluentbitTLS) {
 	*out = *in
@@ -1125,31 +1814,82 @@ func (in *FluentbitTLS) DeepCopy() *FluentbitTLS {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FluentdDrainConfig) DeepCopyInto(out *FluentdDrainConfig) {
+func (in *FluentbitWindows) DeepCopyInto(out *FluentbitWindows) {
 	*out = *in
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
+	in.Image.DeepCopyInto(&out.Image)
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	in.Image.DeepCopyInto(&out.Image)
-	in.PauseImage.DeepCopyInto(&out.PauseImage)
+	if in.DaemonSetOverrides != nil {
+		in, out := &in.DaemonSetOverrides, &out.DaemonSetOverrides
+		*out = new(typeoverride.DaemonSet)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdDrainConfig.
-func (in *FluentdDrainConfig) DeepCopy() *FluentdDrainConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentbitWindows.
+func (in *FluentbitWindows) DeepCopy() *FluentbitWindows {
 	if in == nil {
 		return nil
 	}
-	out := new(FluentdDrainConfig)
+	out := new(FluentbitWindows)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FluentdScaling) DeepCopyInto(out *FluentdScaling) {
+func (in *FluentdDrainConfig) DeepCopyInto(out *FluentdDrainConfig) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Image.DeepCopyInto(&out.Image)
+	in.PauseImage.DeepCopyInto(&out.PauseImage)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdDrainConfig.
+func (in *FluentdDrainConfig) DeepCopy() *FluentdDrainConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentdDrainConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentdExtraConfig) DeepCopyInto(out *FluentdExtraConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdExtraConfig.
+func (in *FluentdExtraConfig) DeepCopy() *FluentdExtraConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentdExtraConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentdScaling) DeepCopyInto(out *FluentdScaling) {
 	*out = *in
 	in.Drain.DeepCopyInto(&out.Drain)
 }
@@ -1202,7 +1942,7 @@ func (in *FluentdSpec) DeepCopyInto(out *FluentdSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.TLS = in.TLS
+	in.TLS.DeepCopyInto(&out.TLS)
 	in.Image.DeepCopyInto(&out.Image)
 	in.BufferStorageVolume.DeepCopyInto(&out.BufferStorageVolume)
 	if in.ExtraVolumes != nil {
@@ -1212,6 +1952,20 @@ func (in *FluentdSpec) DeepCopyInto(out *FluentdSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ExtraContainers != nil {
+		in, out := &in.ExtraContainers, &out.ExtraContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraInitContainers != nil {
+		in, out := &in.ExtraInitContainers, &out.ExtraInitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.FluentdPvcSpec != nil {
 		in, out := &in.FluentdPvcSpec, &out.FluentdPvcSpec
 		*out = new(volume.KubernetesVolume)
@@ -1305,6 +2059,61 @@ func (in *FluentdSpec) DeepCopyInto(out *FluentdSpec) {
 		*out = new(v1.PodDNSConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.StatefulSetOverrides != nil {
+		in, out := &in.StatefulSetOverrides, &out.StatefulSetOverrides
+		*out = new(typeoverride.StatefulSet)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeploymentOverrides != nil {
+		in, out := &in.DeploymentOverrides, &out.DeploymentOverrides
+		*out = new(typeoverride.Deployment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AppConfigChunking != nil {
+		in, out := &in.AppConfigChunking, &out.AppConfigChunking
+		*out = new(AppConfigChunking)
+		**out = **in
+	}
+	if in.TestMessages != nil {
+		in, out := &in.TestMessages, &out.TestMessages
+		*out = new(FluentdTestMessages)
+		**out = **in
+	}
+	if in.BufferVolumeRepair != nil {
+		in, out := &in.BufferVolumeRepair, &out.BufferVolumeRepair
+		*out = new(BufferVolumeRepair)
+		**out = **in
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(v1.Lifecycle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VPA != nil {
+		in, out := &in.VPA, &out.VPA
+		*out = new(VerticalPodAutoscaler)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BufferVolumeEncryption != nil {
+		in, out := &in.BufferVolumeEncryption, &out.BufferVolumeEncryption
+		*out = new(BufferVolumeEncryption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPInput != nil {
+		in, out := &in.HTTPInput, &out.HTTPInput
+		*out = new(HTTPInput)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraConfig != nil {
+		in, out := &in.ExtraConfig, &out.ExtraConfig
+		*out = new(FluentdExtraConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdSpec.
@@ -1320,6 +2129,21 @@ func (in *FluentdSpec) DeepCopy() *FluentdSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FluentdTLS) DeepCopyInto(out *FluentdTLS) {
 	*out = *in
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerTLS)
+		**out = **in
+	}
+	if in.Spiffe != nil {
+		in, out := &in.Spiffe, &out.Spiffe
+		*out = new(SpiffeTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ForwardPeers != nil {
+		in, out := &in.ForwardPeers, &out.ForwardPeers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdTLS.
@@ -1332,6 +2156,21 @@ func (in *FluentdTLS) DeepCopy() *FluentdTLS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentdTestMessages) DeepCopyInto(out *FluentdTestMessages) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdTestMessages.
+func (in *FluentdTestMessages) DeepCopy() *FluentdTestMessages {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentdTestMessages)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ForwardOptions) DeepCopyInto(out *ForwardOptions) {
 	*out = *in
@@ -1347,6 +2186,80 @@ func (in *ForwardOptions) DeepCopy() *ForwardOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlobalOutputSettings) DeepCopyInto(out *GlobalOutputSettings) {
+	*out = *in
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(output.Buffer)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalOutputSettings.
+func (in *GlobalOutputSettings) DeepCopy() *GlobalOutputSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(GlobalOutputSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPInput) DeepCopyInto(out *HTTPInput) {
+	*out = *in
+	if in.Token != nil {
+		in, out := &in.Token, &out.Token
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(HTTPInputIngress)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPInput.
+func (in *HTTPInput) DeepCopy() *HTTPInput {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPInput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPInputIngress) DeepCopyInto(out *HTTPInputIngress) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPInputIngress.
+func (in *HTTPInputIngress) DeepCopy() *HTTPInputIngress {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPInputIngress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
 	*out = *in
@@ -1367,6 +2280,26 @@ func (in *ImageSpec) DeepCopy() *ImageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InputSystemd) DeepCopyInto(out *InputSystemd) {
+	*out = *in
+	if in.SystemdFilter != nil {
+		in, out := &in.SystemdFilter, &out.SystemdFilter
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InputSystemd.
+func (in *InputSystemd) DeepCopy() *InputSystemd {
+	if in == nil {
+		return nil
+	}
+	out := new(InputSystemd)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InputTail) DeepCopyInto(out *InputTail) {
 	*out = *in
@@ -1397,6 +2330,76 @@ func (in *InputTail) DeepCopy() *InputTail {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabelExpression) DeepCopyInto(out *LabelExpression) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelExpression.
+func (in *LabelExpression) DeepCopy() *LabelExpression {
+	if in == nil {
+		return nil
+	}
+	out := new(LabelExpression)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogClassSettings) DeepCopyInto(out *LogClassSettings) {
+	*out = *in
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(output.Buffer)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogClassSettings.
+func (in *LogClassSettings) DeepCopy() *LogClassSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(LogClassSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogClasses) DeepCopyInto(out *LogClasses) {
+	*out = *in
+	if in.Critical != nil {
+		in, out := &in.Critical, &out.Critical
+		*out = new(LogClassSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Normal != nil {
+		in, out := &in.Normal, &out.Normal
+		*out = new(LogClassSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BestEffort != nil {
+		in, out := &in.BestEffort, &out.BestEffort
+		*out = new(LogClassSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogClasses.
+func (in *LogClasses) DeepCopy() *LogClasses {
+	if in == nil {
+		return nil
+	}
+	out := new(LogClasses)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Logging) DeepCopyInto(out *Logging) {
 	*out = *in
@@ -1456,9 +2459,102 @@ func (in *LoggingList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingProfile) DeepCopyInto(out *LoggingProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingProfile.
+func (in *LoggingProfile) DeepCopy() *LoggingProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoggingProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingProfileList) DeepCopyInto(out *LoggingProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LoggingProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingProfileList.
+func (in *LoggingProfileList) DeepCopy() *LoggingProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoggingProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingProfileSpec) DeepCopyInto(out *LoggingProfileSpec) {
+	*out = *in
+	if in.FluentdSpec != nil {
+		in, out := &in.FluentdSpec, &out.FluentdSpec
+		*out = new(FluentdSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FluentbitSpec != nil {
+		in, out := &in.FluentbitSpec, &out.FluentbitSpec
+		*out = new(FluentbitSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GlobalOutputSettings != nil {
+		in, out := &in.GlobalOutputSettings, &out.GlobalOutputSettings
+		*out = new(GlobalOutputSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingProfileSpec.
+func (in *LoggingProfileSpec) DeepCopy() *LoggingProfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingProfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoggingSpec) DeepCopyInto(out *LoggingSpec) {
 	*out = *in
+	if in.OutputCheck != nil {
+		in, out := &in.OutputCheck, &out.OutputCheck
+		*out = new(OutputCheck)
+		**out = **in
+	}
 	if in.FluentbitSpec != nil {
 		in, out := &in.FluentbitSpec, &out.FluentbitSpec
 		*out = new(FluentbitSpec)
@@ -1481,93 +2577,311 @@ func (in *LoggingSpec) DeepCopyInto(out *LoggingSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.GlobalOutputSettings != nil {
+		in, out := &in.GlobalOutputSettings, &out.GlobalOutputSettings
+		*out = new(GlobalOutputSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretNamespaces != nil {
+		in, out := &in.SecretNamespaces, &out.SecretNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.WatchNamespaces != nil {
 		in, out := &in.WatchNamespaces, &out.WatchNamespaces
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ExcludeWatchNamespaces != nil {
+		in, out := &in.ExcludeWatchNamespaces, &out.ExcludeWatchNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WatchNamespaceSelector != nil {
+		in, out := &in.WatchNamespaceSelector, &out.WatchNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowClusterResourcesFromNamespaces != nil {
+		in, out := &in.AllowClusterResourcesFromNamespaces, &out.AllowClusterResourcesFromNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.NodeAgents != nil {
 		in, out := &in.NodeAgents, &out.NodeAgents
 		*out = make([]*NodeAgent, len(*in))
 		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(NodeAgent)
-				(*in).DeepCopyInto(*out)
-			}
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(NodeAgent)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(NetworkPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceMesh != nil {
+		in, out := &in.ServiceMesh, &out.ServiceMesh
+		*out = new(ServiceMesh)
+		**out = **in
+	}
+	if in.EventTailer != nil {
+		in, out := &in.EventTailer, &out.EventTailer
+		*out = new(EventTailer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AuditLog != nil {
+		in, out := &in.AuditLog, &out.AuditLog
+		*out = new(AuditLog)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(Monitoring)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LogClasses != nil {
+		in, out := &in.LogClasses, &out.LogClasses
+		*out = new(LogClasses)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OutputTemplates != nil {
+		in, out := &in.OutputTemplates, &out.OutputTemplates
+		*out = make([]OutputTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConfigHistory != nil {
+		in, out := &in.ConfigHistory, &out.ConfigHistory
+		*out = new(ConfigHistory)
+		**out = **in
+	}
+	if in.AutoRollback != nil {
+		in, out := &in.AutoRollback, &out.AutoRollback
+		*out = new(AutoRollback)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingSpec.
+func (in *LoggingSpec) DeepCopy() *LoggingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingStatus) DeepCopyInto(out *LoggingStatus) {
+	*out = *in
+	if in.ConfigCheckResults != nil {
+		in, out := &in.ConfigCheckResults, &out.ConfigCheckResults
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.OutputCheckResults != nil {
+		in, out := &in.OutputCheckResults, &out.OutputCheckResults
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AutoRollback != nil {
+		in, out := &in.AutoRollback, &out.AutoRollback
+		*out = new(AutoRollbackStatus)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingStatus.
+func (in *LoggingStatus) DeepCopy() *LoggingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Match) DeepCopyInto(out *Match) {
+	*out = *in
+	if in.Select != nil {
+		in, out := &in.Select, &out.Select
+		*out = new(Select)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = new(Exclude)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Match.
+func (in *Match) DeepCopy() *Match {
+	if in == nil {
+		return nil
+	}
+	out := new(Match)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatchTerm) DeepCopyInto(out *MatchTerm) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContainerNames != nil {
+		in, out := &in.ContainerNames, &out.ContainerNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]FieldMatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelExpressions != nil {
+		in, out := &in.LabelExpressions, &out.LabelExpressions
+		*out = make([]LabelExpression, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingSpec.
-func (in *LoggingSpec) DeepCopy() *LoggingSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatchTerm.
+func (in *MatchTerm) DeepCopy() *MatchTerm {
 	if in == nil {
 		return nil
 	}
-	out := new(LoggingSpec)
+	out := new(MatchTerm)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoggingStatus) DeepCopyInto(out *LoggingStatus) {
+func (in *Metrics) DeepCopyInto(out *Metrics) {
 	*out = *in
-	if in.ConfigCheckResults != nil {
-		in, out := &in.ConfigCheckResults, &out.ConfigCheckResults
-		*out = make(map[string]bool, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	in.ServiceMonitorConfig.DeepCopyInto(&out.ServiceMonitorConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metrics.
+func (in *Metrics) DeepCopy() *Metrics {
+	if in == nil {
+		return nil
 	}
+	out := new(Metrics)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingStatus.
-func (in *LoggingStatus) DeepCopy() *LoggingStatus {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Monitoring) DeepCopyInto(out *Monitoring) {
+	*out = *in
+	in.Dashboards.DeepCopyInto(&out.Dashboards)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Monitoring.
+func (in *Monitoring) DeepCopy() *Monitoring {
 	if in == nil {
 		return nil
 	}
-	out := new(LoggingStatus)
+	out := new(Monitoring)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Match) DeepCopyInto(out *Match) {
+func (in *MultilineParser) DeepCopyInto(out *MultilineParser) {
 	*out = *in
-	if in.Select != nil {
-		in, out := &in.Select, &out.Select
-		*out = new(Select)
-		(*in).DeepCopyInto(*out)
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]MultilineParserRule, len(*in))
+		copy(*out, *in)
 	}
-	if in.Exclude != nil {
-		in, out := &in.Exclude, &out.Exclude
-		*out = new(Exclude)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultilineParser.
+func (in *MultilineParser) DeepCopy() *MultilineParser {
+	if in == nil {
+		return nil
 	}
+	out := new(MultilineParser)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Match.
-func (in *Match) DeepCopy() *Match {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultilineParserRule) DeepCopyInto(out *MultilineParserRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultilineParserRule.
+func (in *MultilineParserRule) DeepCopy() *MultilineParserRule {
 	if in == nil {
 		return nil
 	}
-	out := new(Match)
+	out := new(MultilineParserRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Metrics) DeepCopyInto(out *Metrics) {
+func (in *NetworkPolicy) DeepCopyInto(out *NetworkPolicy) {
 	*out = *in
-	in.ServiceMonitorConfig.DeepCopyInto(&out.ServiceMonitorConfig)
+	if in.IngressCIDRs != nil {
+		in, out := &in.IngressCIDRs, &out.IngressCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = make([]networkingv1.NetworkPolicyEgressRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metrics.
-func (in *Metrics) DeepCopy() *Metrics {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicy.
+func (in *NetworkPolicy) DeepCopy() *NetworkPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(Metrics)
+	out := new(NetworkPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1601,6 +2915,7 @@ func (in *NodeAgentFluentbit) DeepCopyInto(out *NodeAgentFluentbit) {
 		*out = new(bool)
 		**out = **in
 	}
+	in.Image.DeepCopyInto(&out.Image)
 	if in.DaemonSetOverrides != nil {
 		in, out := &in.DaemonSetOverrides, &out.DaemonSetOverrides
 		*out = new(typeoverride.DaemonSet)
@@ -1731,6 +3046,37 @@ func (in *Output) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputCheck) DeepCopyInto(out *OutputCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputCheck.
+func (in *OutputCheck) DeepCopy() *OutputCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputCheckStatus) DeepCopyInto(out *OutputCheckStatus) {
+	*out = *in
+	in.CheckedAt.DeepCopyInto(&out.CheckedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputCheckStatus.
+func (in *OutputCheckStatus) DeepCopy() *OutputCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OutputList) DeepCopyInto(out *OutputList) {
 	*out = *in
@@ -1891,6 +3237,31 @@ func (in *OutputSpec) DeepCopyInto(out *OutputSpec) {
 		*out = new(output.SQSOutputConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.OTLPOutput != nil {
+		in, out := &in.OTLPOutput, &out.OTLPOutput
+		*out = new(output.OTLPOutput)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LoggingOperatorForward != nil {
+		in, out := &in.LoggingOperatorForward, &out.LoggingOperatorForward
+		*out = new(output.LoggingOperatorForwardOutput)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NATSOutput != nil {
+		in, out := &in.NATSOutput, &out.NATSOutput
+		*out = new(output.NATSOutput)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLSFrom != nil {
+		in, out := &in.TLSFrom, &out.TLSFrom
+		*out = new(TLSFrom)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputSpec.
@@ -1916,6 +3287,11 @@ func (in *OutputStatus) DeepCopyInto(out *OutputStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Check != nil {
+		in, out := &in.Check, &out.Check
+		*out = new(OutputCheckStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputStatus.
@@ -1928,6 +3304,59 @@ func (in *OutputStatus) DeepCopy() *OutputStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputTemplate) DeepCopyInto(out *OutputTemplate) {
+	*out = *in
+	if in.Format != nil {
+		in, out := &in.Format, &out.Format
+		*out = new(output.Format)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputTemplate.
+func (in *OutputTemplate) DeepCopy() *OutputTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PositionDBMetrics) DeepCopyInto(out *PositionDBMetrics) {
+	*out = *in
+	in.Image.DeepCopyInto(&out.Image)
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PositionDBMetrics.
+func (in *PositionDBMetrics) DeepCopy() *PositionDBMetrics {
+	if in == nil {
+		return nil
+	}
+	out := new(PositionDBMetrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PositionDBRecovery) DeepCopyInto(out *PositionDBRecovery) {
+	*out = *in
+	in.Image.DeepCopyInto(&out.Image)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PositionDBRecovery.
+func (in *PositionDBRecovery) DeepCopy() *PositionDBRecovery {
+	if in == nil {
+		return nil
+	}
+	out := new(PositionDBRecovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReadinessDefaultCheck) DeepCopyInto(out *ReadinessDefaultCheck) {
 	*out = *in
@@ -1993,6 +3422,25 @@ func (in *Select) DeepCopyInto(out *Select) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]FieldMatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelExpressions != nil {
+		in, out := &in.LabelExpressions, &out.LabelExpressions
+		*out = make([]LabelExpression, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AnyOf != nil {
+		in, out := &in.AnyOf, &out.AnyOf
+		*out = make([]MatchTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Select.
@@ -2005,6 +3453,21 @@ func (in *Select) DeepCopy() *Select {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceMesh) DeepCopyInto(out *ServiceMesh) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceMesh.
+func (in *ServiceMesh) DeepCopy() *ServiceMesh {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceMesh)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceMonitorConfig) DeepCopyInto(out *ServiceMonitorConfig) {
 	*out = *in
@@ -2054,6 +3517,149 @@ func (in *ServiceMonitorConfig) DeepCopy() *ServiceMonitorConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpiffeTLS) DeepCopyInto(out *SpiffeTLS) {
+	*out = *in
+	in.Image.DeepCopyInto(&out.Image)
+	in.Resources.DeepCopyInto(&out.Resources)
+	in.RestartImage.DeepCopyInto(&out.RestartImage)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpiffeTLS.
+func (in *SpiffeTLS) DeepCopy() *SpiffeTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(SpiffeTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSFrom) DeepCopyInto(out *TLSFrom) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(TLSSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCert != nil {
+		in, out := &in.ClientCert, &out.ClientCert
+		*out = new(TLSSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSFrom.
+func (in *TLSFrom) DeepCopy() *TLSFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSSource) DeepCopyInto(out *TLSSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterTrustBundle != nil {
+		in, out := &in.ClusterTrustBundle, &out.ClusterTrustBundle
+		*out = new(ClusterTrustBundleSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSSource.
+func (in *TLSSource) DeepCopy() *TLSSource {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestMessageOutputStatus) DeepCopyInto(out *TestMessageOutputStatus) {
+	*out = *in
+	if in.Delivered != nil {
+		in, out := &in.Delivered, &out.Delivered
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestMessageOutputStatus.
+func (in *TestMessageOutputStatus) DeepCopy() *TestMessageOutputStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TestMessageOutputStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestMessageStatus) DeepCopyInto(out *TestMessageStatus) {
+	*out = *in
+	if in.SentAt != nil {
+		in, out := &in.SentAt, &out.SentAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]TestMessageOutputStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestMessageStatus.
+func (in *TestMessageStatus) DeepCopy() *TestMessageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TestMessageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerticalPodAutoscaler) DeepCopyInto(out *VerticalPodAutoscaler) {
+	*out = *in
+	if in.MinAllowed != nil {
+		in, out := &in.MinAllowed, &out.MinAllowed
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.MaxAllowed != nil {
+		in, out := &in.MaxAllowed, &out.MaxAllowed
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerticalPodAutoscaler.
+func (in *VerticalPodAutoscaler) DeepCopy() *VerticalPodAutoscaler {
+	if in == nil {
+		return nil
+	}
+	out := new(VerticalPodAutoscaler)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeMount) DeepCopyInto(out *VolumeMount) {
 	*out = *in
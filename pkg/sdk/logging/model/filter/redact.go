@@ -0,0 +1,147 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+)
+
+// +name:"Redact"
+// +weight:"200"
+type _hugoRedact interface{} //nolint:deadcode,unused
+
+// +kubebuilder:object:generate=true
+// +docName:"Redact"
+// Masks sensitive data (credit card numbers, email addresses, IP addresses or custom patterns) in the selected record keys.
+// It is rendered as a [record_transformer](https://docs.fluentd.org/filter/record_transformer) filter with Ruby expressions enabled.
+type _docRedact interface{} //nolint:deadcode,unused
+
+// +name:"Redact"
+// +url:"https://docs.fluentd.org/filter/record_transformer"
+// +version:"more info"
+// +description:"Masks sensitive data in log records"
+// +status:"Testing"
+type _metaRedact interface{} //nolint:deadcode,unused
+
+var redactPatterns = map[string]string{
+	"credit_card": `\b(?:\d[ -]?){12,18}\d\b`,
+	"email":       `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+	"ipv4":        `\b(?:\d{1,3}\.){3}\d{1,3}\b`,
+}
+
+// +kubebuilder:object:generate=true
+type Redact struct {
+	// Record keys to redact (default: [message])
+	Keys []string `json:"keys,omitempty"`
+	// Built-in patterns to mask: credit_card, email, ipv4
+	Patterns []string `json:"patterns,omitempty"`
+	// Additional Ruby regular expressions to mask
+	CustomPatterns []string `json:"custom_patterns,omitempty"`
+	// Replacement string for the matched data (default: [REDACTED])
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// #### Example `Redact` filter configurations
+// ```yaml
+//apiVersion: logging.banzaicloud.io/v1beta1
+//kind: Flow
+//metadata:
+//  name: demo-flow
+//spec:
+//  filters:
+//    - redact:
+//        patterns:
+//          - email
+//  selectors: {}
+//  localOutputRefs:
+//    - demo-output
+// ```
+//
+// #### Fluentd Config Result
+// ```yaml
+//<filter **>
+//  @type record_transformer
+//  @id test_redact
+//  enable_ruby true
+//  <record>
+//    message ${record['message'].is_a?(String) ? record['message'].gsub(Regexp.new('(?:[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\\.[A-Za-z]{2,})'), '[REDACTED]') : record['message']}
+//  </record>
+//</filter>
+// ```
+type _expRedact interface{} //nolint:deadcode,unused
+
+func (r *Redact) ToDirective(secretLoader secret.SecretLoader, id string) (types.Directive, error) {
+	const pluginType = "record_transformer"
+	redact := &types.GenericDirective{
+		PluginMeta: types.PluginMeta{
+			Type:      pluginType,
+			Directive: "filter",
+			Tag:       "**",
+			Id:        id,
+		},
+		Params: types.Params{
+			"enable_ruby": "true",
+		},
+	}
+
+	var patterns []string
+	for _, name := range r.Patterns {
+		pattern, ok := redactPatterns[name]
+		if !ok {
+			return nil, errors.Errorf("unknown redact pattern %q", name)
+		}
+		patterns = append(patterns, pattern)
+	}
+	patterns = append(patterns, r.CustomPatterns...)
+	if len(patterns) == 0 {
+		return nil, errors.New("at least one pattern or custom pattern is required")
+	}
+	for i, pattern := range patterns {
+		patterns[i] = fmt.Sprintf("(?:%s)", pattern)
+	}
+	regexp := rubyQuote(strings.Join(patterns, "|"))
+
+	replacement := r.Replacement
+	if replacement == "" {
+		replacement = "[REDACTED]"
+	}
+
+	keys := r.Keys
+	if len(keys) == 0 {
+		keys = []string{"message"}
+	}
+	record := Record{}
+	for _, key := range keys {
+		value := fmt.Sprintf("record[%s]", rubyQuote(key))
+		record[key] = fmt.Sprintf("${%s.is_a?(String) ? %s.gsub(Regexp.new(%s), %s) : %s}",
+			value, value, regexp, rubyQuote(replacement), value)
+	}
+	if meta, err := record.ToDirective(secretLoader, ""); err != nil {
+		return nil, err
+	} else {
+		redact.SubDirectives = append(redact.SubDirectives, meta)
+	}
+	return redact, nil
+}
+
+// rubyQuote returns s as a single-quoted Ruby string literal
+func rubyQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
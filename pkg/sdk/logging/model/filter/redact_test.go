@@ -0,0 +1,50 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/filter"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/render"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	CONFIG := []byte(`
+keys:
+  - log
+patterns:
+  - ipv4
+custom_patterns:
+  - "secret=\\w+"
+replacement: "***"
+`)
+	expected := `
+<filter **>
+  @type record_transformer
+  @id test
+  enable_ruby true
+  <record>
+    log ${record['log'].is_a?(String) ? record['log'].gsub(Regexp.new('(?:\\b(?:\\d{1,3}\\.){3}\\d{1,3}\\b)|(?:secret=\\w+)'), '***') : record['log']}
+  </record>
+</filter>
+`
+	redact := &filter.Redact{}
+	require.NoError(t, yaml.Unmarshal(CONFIG, redact))
+	test := render.NewOutputPluginTest(t, redact)
+	test.DiffResult(expected)
+}
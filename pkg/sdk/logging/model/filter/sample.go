@@ -0,0 +1,107 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"emperror.dev/errors"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+)
+
+// +kubebuilder:object:generate=true
+// +docName:"[Sampling Filter](https://github.com/tagomoris/fluent-plugin-sampling-filter)"
+// Keeps only every Nth record or a percentage of the records and drops the rest, so high-volume logs can be
+// down-sampled before shipping.
+type _docSample interface{} //nolint:deadcode,unused
+
+// +name:"Sample"
+// +url:"https://github.com/tagomoris/fluent-plugin-sampling-filter"
+// +version:"1.2.0"
+// +description:"Keep only every Nth record and drop the rest"
+// +status:"Testing"
+type _metaSample interface{} //nolint:deadcode,unused
+
+// +kubebuilder:object:generate=true
+type Sample struct {
+	// Keep one record out of every `interval` records
+	// +kubebuilder:validation:Minimum=1
+	Interval int `json:"interval,omitempty"`
+	// Keep this percentage of the records instead of setting the interval. It is rendered as an interval, so it has
+	// to divide 100.
+	// +kubebuilder:validation:Enum=1;2;4;5;10;20;25;50;100
+	Percentage int `json:"percentage,omitempty"`
+	// Counters are kept per tag (tag), globally (all) or per value of the given record key (default: tag)
+	SampleUnit string `json:"sample_unit,omitempty"`
+	// Pass all records through until this rate (records per minute) is reached for a counter (default: nil)
+	MinimumRatePerMin int `json:"minimum_rate_per_min,omitempty"`
+}
+
+// #### Example `Sample` filter configurations
+// ```yaml
+//apiVersion: logging.banzaicloud.io/v1beta1
+//kind: Flow
+//metadata:
+//  name: demo-flow
+//spec:
+//  filters:
+//    - sample:
+//        interval: 10
+//        sample_unit: all
+//  selectors: {}
+//  localOutputRefs:
+//    - demo-output
+// ```
+//
+// #### Fluentd Config Result
+// ```yaml
+//<filter **>
+//  @type sampling_filter
+//  @id test_sample
+//  interval 10
+//  sample_unit all
+//</filter>
+// ```
+type _expSample interface{} //nolint:deadcode,unused
+
+func (s *Sample) ToDirective(secretLoader secret.SecretLoader, id string) (types.Directive, error) {
+	const pluginType = "sampling_filter"
+	sample := &types.GenericDirective{
+		PluginMeta: types.PluginMeta{
+			Type:      pluginType,
+			Directive: "filter",
+			Tag:       "**",
+			Id:        id,
+		},
+	}
+	sampleConfig := s.DeepCopy()
+	switch {
+	case s.Interval != 0 && s.Percentage != 0:
+		return nil, errors.New("interval and percentage are mutually exclusive")
+	case s.Percentage != 0:
+		if s.Percentage < 0 || 100%s.Percentage != 0 {
+			return nil, errors.Errorf("percentage %d does not divide 100", s.Percentage)
+		}
+		sampleConfig.Interval = 100 / s.Percentage
+		sampleConfig.Percentage = 0
+	case s.Interval < 1:
+		return nil, errors.New("interval or percentage is required")
+	}
+	if params, err := types.NewStructToStringMapper(secretLoader).StringsMap(sampleConfig); err != nil {
+		return nil, err
+	} else {
+		sample.Params = params
+	}
+	return sample, nil
+}
@@ -0,0 +1,74 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/filter"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/render"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSample(t *testing.T) {
+	CONFIG := []byte(`
+interval: 10
+sample_unit: all
+minimum_rate_per_min: 100
+`)
+	expected := `
+<filter **>
+  @type sampling_filter
+  @id test
+  interval 10
+  minimum_rate_per_min 100
+  sample_unit all
+</filter>
+`
+	sample := &filter.Sample{}
+	require.NoError(t, yaml.Unmarshal(CONFIG, sample))
+	test := render.NewOutputPluginTest(t, sample)
+	test.DiffResult(expected)
+}
+
+func TestSamplePercentage(t *testing.T) {
+	CONFIG := []byte(`
+percentage: 25
+`)
+	expected := `
+<filter **>
+  @type sampling_filter
+  @id test
+  interval 4
+</filter>
+`
+	sample := &filter.Sample{}
+	require.NoError(t, yaml.Unmarshal(CONFIG, sample))
+	test := render.NewOutputPluginTest(t, sample)
+	test.DiffResult(expected)
+}
+
+func TestSampleInvalid(t *testing.T) {
+	for _, sample := range []*filter.Sample{
+		{},
+		{Interval: 10, Percentage: 10},
+		{Percentage: 30},
+	} {
+		if _, err := sample.ToDirective(nil, "test"); err == nil {
+			t.Errorf("expected an error for %+v", sample)
+		}
+	}
+}
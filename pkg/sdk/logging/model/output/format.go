@@ -32,13 +32,16 @@ type _metaFormat interface{} //nolint:deadcode,unused
 
 // +kubebuilder:object:generate=true
 type Format struct {
-	// Output line formatting: out_file,json,ltsv,csv,msgpack,hash,single_value (default: json)
-	// +kubebuilder:validation:Enum=out_file;json;ltsv;csv;msgpack;hash;single_value
+	// Output line formatting: out_file,json,ltsv,csv,msgpack,hash,single_value,cef,leef (default: json)
+	// +kubebuilder:validation:Enum=out_file;json;ltsv;csv;msgpack;hash;single_value;cef;leef
 	Type string `json:"type,omitempty"`
 	// When type is single_value add '\n' to the end of the message (default: true)
 	AddNewline *bool `json:"add_newline,omitempty"`
 	// When type is single_value specify the key holding information
 	MessageKey string `json:"message_key,omitempty"`
+	// Header and extension mapping when type is cef or leef
+	// +docLink:"Format SIEM,../format_siem/"
+	SIEM *SIEMFormat `json:"siem,omitempty"`
 }
 
 func (f *Format) ToDirective(secretLoader secret.SecretLoader, id string) (types.Directive, error) {
@@ -52,5 +55,6 @@ func (f *Format) ToDirective(secretLoader secret.SecretLoader, id string) (types
 		metadata.Type = "json"
 	}
 	format.Type = ""
+	format.SIEM = nil
 	return types.NewFlatDirective(metadata, format, secretLoader)
 }
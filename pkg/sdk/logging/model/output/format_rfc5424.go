@@ -32,8 +32,8 @@ type _metaFormatRfc5424 interface{} //nolint:deadcode,unused
 
 // +kubebuilder:object:generate=true
 type FormatRfc5424 struct {
-	// Output line formatting: out_file,json,ltsv,csv,msgpack,hash,single_value (default: json)
-	// +kubebuilder:validation:Enum=out_file;json;ltsv;csv;msgpack;hash;single_value
+	// Output line formatting: out_file,json,ltsv,csv,msgpack,hash,single_value,cef,leef (default: json)
+	// +kubebuilder:validation:Enum=out_file;json;ltsv;csv;msgpack;hash;single_value;cef;leef
 	Type string `json:"type,omitempty"`
 	// Prepends message length for syslog transmission (default: true)
 	Rfc6587MessageSize *bool `json:"rfc6587_message_size,omitempty"`
@@ -49,6 +49,9 @@ type FormatRfc5424 struct {
 	StructuredDataField string `json:"structured_data_field,omitempty"`
 	// Sets log in syslog from field in fluentd, delimited by '.' (default: log)
 	LogField string `json:"log_field,omitempty"`
+	// Header and extension mapping when type is cef or leef
+	// +docLink:"Format SIEM,../format_siem/"
+	SIEM *SIEMFormat `json:"siem,omitempty"`
 }
 
 func (f *FormatRfc5424) ToDirective(secretLoader secret.SecretLoader, id string) (types.Directive, error) {
@@ -62,5 +65,6 @@ func (f *FormatRfc5424) ToDirective(secretLoader secret.SecretLoader, id string)
 		metadata.Type = "syslog_rfc5424"
 	}
 	format.Type = ""
+	format.SIEM = nil
 	return types.NewFlatDirective(metadata, format, secretLoader)
 }
@@ -0,0 +1,56 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+// +name:"Format SIEM"
+// +weight:"200"
+type _hugoFormatSIEM interface{} //nolint:deadcode,unused
+
+// +name:"Format SIEM"
+// +url:"https://docs.fluentd.org/filter/record_transformer"
+// +version:"more info"
+// +description:"Header and extension mapping of the CEF and LEEF formats."
+// +status:"Testing"
+type _metaFormatSIEM interface{} //nolint:deadcode,unused
+
+const (
+	// FormatCEF is the ArcSight Common Event Format
+	FormatCEF = "cef"
+	// FormatLEEF is the QRadar Log Event Extended Format (2.0)
+	FormatLEEF = "leef"
+)
+
+// +kubebuilder:object:generate=true
+type SIEMFormat struct {
+	// Vendor of the device in the header (default: Kubernetes)
+	DeviceVendor string `json:"device_vendor,omitempty"`
+	// Product of the device in the header (default: logging-operator)
+	DeviceProduct string `json:"device_product,omitempty"`
+	// Version of the device in the header (default: 1.0)
+	DeviceVersion string `json:"device_version,omitempty"`
+	// Sets the event class id (CEF signature id, LEEF event id) from field in fluentd, delimited by '.' (default: kubernetes.container_name)
+	EventIDField string `json:"event_id_field,omitempty"`
+	// Sets the name of the event from field in fluentd, delimited by '.', CEF only (default: kubernetes.container_name)
+	NameField string `json:"name_field,omitempty"`
+	// Sets the severity from field in fluentd, delimited by '.'
+	SeverityField string `json:"severity_field,omitempty"`
+	// Severity of the events without a severity field (default: 5)
+	Severity string `json:"severity,omitempty"`
+	// Extension keys set from fields in fluentd, delimited by '.'. They are merged over the defaults mapping the log
+	// and the Kubernetes metadata, a default is dropped by mapping its key to an empty field.
+	Extensions map[string]string `json:"extensions,omitempty"`
+	// Extension keys with constant values, like the labels of the CEF custom strings (cs1Label)
+	StaticExtensions map[string]string `json:"static_extensions,omitempty"`
+}
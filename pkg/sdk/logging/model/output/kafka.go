@@ -15,6 +15,9 @@
 package output
 
 import (
+	"encoding/json"
+
+	"emperror.dev/errors"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
 	"github.com/banzaicloud/operator-tools/pkg/secret"
 )
@@ -97,6 +100,11 @@ type KafkaOutputConfig struct {
 	Password *secret.Secret `json:"password,omitempty"`
 	// If set, use SCRAM authentication with specified mechanism. When unset, default to PLAIN authentication
 	ScramMechanism string `json:"scram_mechanism,omitempty"`
+	// Use SASL/OAUTHBEARER authentication with tokens requested from an OIDC token endpoint.
+	// The output is rendered with the rdkafka2 plugin in this case.
+	OAuthBearer *KafkaOAuthBearer `json:"oauthbearer,omitempty"`
+	// Use AWS MSK IAM authentication
+	AwsMskIam *KafkaAwsMskIam `json:"aws_msk_iam,omitempty"`
 	// Number of times to retry sending of messages to a leader (default: 1)
 	MaxSendRetries int `json:"max_send_retries,omitempty"`
 	// The number of acks required per request (default: -1).
@@ -129,6 +137,48 @@ type KafkaOutputConfig struct {
 	Buffer *Buffer `json:"buffer,omitempty"`
 }
 
+// +kubebuilder:object:generate=true
+// +docName:"Kafka OAUTHBEARER"
+type KafkaOAuthBearer struct {
+	// URL of the OIDC token endpoint
+	TokenEndpointURL string `json:"token_endpoint_url"`
+	// Client ID used to request the token
+	// +docLink:"Secret,../secret/"
+	ClientID *secret.Secret `json:"client_id"`
+	// Client secret used to request the token
+	// +docLink:"Secret,../secret/"
+	ClientSecret *secret.Secret `json:"client_secret"`
+	// Scope of the requested token
+	Scope string `json:"scope,omitempty"`
+	// Extensions sent with the token, comma separated key=value pairs
+	Extensions string `json:"extensions,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+// +docName:"Kafka AWS MSK IAM"
+type KafkaAwsMskIam struct {
+	// AWS access key ID
+	// +docLink:"Secret,../secret/"
+	AccessKeyID *secret.Secret `json:"access_key_id"`
+	// AWS secret access key
+	// +docLink:"Secret,../secret/"
+	SecretAccessKey *secret.Secret `json:"secret_access_key"`
+	// AWS region of the cluster
+	Region string `json:"region"`
+}
+
+// rdkafkaUnsupportedParams are the kafka2 parameters not known by rdkafka2
+var rdkafkaUnsupportedParams = []string{
+	"sasl_over_ssl",
+	"scram_mechanism",
+	"idempotent",
+	"get_kafka_client_log",
+	"kafka_agg_max_bytes",
+	"kafka_agg_max_messages",
+	"ssl_ca_certs_from_system",
+	"ssl_verify_hostname",
+}
+
 func (e *KafkaOutputConfig) ToDirective(secretLoader secret.SecretLoader, id string) (types.Directive, error) {
 	const pluginType = "kafka2"
 	kafka := &types.OutputPlugin{
@@ -144,6 +194,9 @@ func (e *KafkaOutputConfig) ToDirective(secretLoader secret.SecretLoader, id str
 	} else {
 		kafka.Params = params
 	}
+	if err := e.authParams(kafka, secretLoader); err != nil {
+		return nil, err
+	}
 	if e.Buffer == nil {
 		e.Buffer = &Buffer{}
 	}
@@ -162,3 +215,72 @@ func (e *KafkaOutputConfig) ToDirective(secretLoader secret.SecretLoader, id str
 	}
 	return kafka, nil
 }
+
+// authParams renders the OAUTHBEARER and MSK IAM authentication settings of the output
+func (e *KafkaOutputConfig) authParams(kafka *types.OutputPlugin, secretLoader secret.SecretLoader) error {
+	if e.OAuthBearer != nil && e.AwsMskIam != nil {
+		return errors.New("oauthbearer and aws_msk_iam authentication are mutually exclusive")
+	}
+	if (e.OAuthBearer != nil || e.AwsMskIam != nil) && (e.Username != nil || e.ScramMechanism != "") {
+		return errors.New("oauthbearer and aws_msk_iam authentication cannot be combined with username/password authentication")
+	}
+
+	if iam := e.AwsMskIam; iam != nil {
+		if iam.Region == "" {
+			return errors.New("aws_msk_iam region is required")
+		}
+		accessKeyID, err := secretLoader.Load(iam.AccessKeyID)
+		if err != nil {
+			return errors.WrapIf(err, "failed to load aws_msk_iam access_key_id")
+		}
+		secretAccessKey, err := secretLoader.Load(iam.SecretAccessKey)
+		if err != nil {
+			return errors.WrapIf(err, "failed to load aws_msk_iam secret_access_key")
+		}
+		kafka.Params["sasl_aws_msk_iam_access_key_id"] = accessKeyID
+		kafka.Params["sasl_aws_msk_iam_secret_key_id"] = secretAccessKey
+		kafka.Params["sasl_aws_msk_iam_aws_region"] = iam.Region
+	}
+
+	if oauth := e.OAuthBearer; oauth != nil {
+		if oauth.TokenEndpointURL == "" {
+			return errors.New("oauthbearer token_endpoint_url is required")
+		}
+		clientID, err := secretLoader.Load(oauth.ClientID)
+		if err != nil {
+			return errors.WrapIf(err, "failed to load oauthbearer client_id")
+		}
+		clientSecret, err := secretLoader.Load(oauth.ClientSecret)
+		if err != nil {
+			return errors.WrapIf(err, "failed to load oauthbearer client_secret")
+		}
+		options := map[string]string{
+			"security.protocol":                   "SASL_SSL",
+			"sasl.mechanism":                      "OAUTHBEARER",
+			"sasl.oauthbearer.method":             "oidc",
+			"sasl.oauthbearer.token.endpoint.url": oauth.TokenEndpointURL,
+			"sasl.oauthbearer.client.id":          clientID,
+			"sasl.oauthbearer.client.secret":      clientSecret,
+		}
+		if !e.SaslOverSSL {
+			options["security.protocol"] = "SASL_PLAINTEXT"
+		}
+		if oauth.Scope != "" {
+			options["sasl.oauthbearer.scope"] = oauth.Scope
+		}
+		if oauth.Extensions != "" {
+			options["sasl.oauthbearer.extensions"] = oauth.Extensions
+		}
+		rdkafkaOptions, err := json.Marshal(options)
+		if err != nil {
+			return errors.WrapIf(err, "failed to marshal rdkafka options")
+		}
+		// ruby-kafka has no OIDC token support, librdkafka requests and refreshes the tokens itself
+		kafka.Type = "rdkafka2"
+		for _, p := range rdkafkaUnsupportedParams {
+			delete(kafka.Params, p)
+		}
+		kafka.Params["rdkafka_options"] = string(rdkafkaOptions)
+	}
+	return nil
+}
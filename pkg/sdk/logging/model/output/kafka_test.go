@@ -63,3 +63,87 @@ buffer:
 	test := render.NewOutputPluginTest(t, kafka)
 	test.DiffResult(expected)
 }
+
+func TestKafkaOAuthBearer(t *testing.T) {
+	CONFIG := []byte(`
+brokers: kafka-headless.kafka.svc.cluster.local:29092
+default_topic: topic
+sasl_over_ssl: true
+oauthbearer:
+  token_endpoint_url: https://idp.example.com/oauth2/token
+  client_id:
+    value: client
+  client_secret:
+    value: secret
+  scope: kafka
+format:
+  type: json
+`)
+	expected := `
+  <match **>
+    @type rdkafka2
+    @id test
+    brokers kafka-headless.kafka.svc.cluster.local:29092
+    default_topic topic
+    rdkafka_options {"sasl.mechanism":"OAUTHBEARER","sasl.oauthbearer.client.id":"client","sasl.oauthbearer.client.secret":"secret","sasl.oauthbearer.method":"oidc","sasl.oauthbearer.scope":"kafka","sasl.oauthbearer.token.endpoint.url":"https://idp.example.com/oauth2/token","security.protocol":"SASL_SSL"}
+    <buffer tag,time>
+      @type file
+	  chunk_limit_size 8MB
+      path /buffers/test.*.buffer
+      retry_forever true
+      timekey 10m
+      timekey_wait 1m
+    </buffer>
+    <format>
+      @type json
+    </format>
+  </match>
+`
+	kafka := &output.KafkaOutputConfig{}
+	require.NoError(t, yaml.Unmarshal(CONFIG, kafka))
+	test := render.NewOutputPluginTest(t, kafka)
+	test.DiffResult(expected)
+}
+
+func TestKafkaAwsMskIam(t *testing.T) {
+	CONFIG := []byte(`
+brokers: b-1.msk.kafka.eu-west-1.amazonaws.com:9098
+default_topic: topic
+sasl_over_ssl: true
+aws_msk_iam:
+  access_key_id:
+    value: AKID
+  secret_access_key:
+    value: SECRET
+  region: eu-west-1
+format:
+  type: json
+`)
+	expected := `
+  <match **>
+    @type kafka2
+    @id test
+    brokers b-1.msk.kafka.eu-west-1.amazonaws.com:9098
+    default_topic topic
+    sasl_aws_msk_iam_access_key_id AKID
+    sasl_aws_msk_iam_aws_region eu-west-1
+    sasl_aws_msk_iam_secret_key_id SECRET
+    sasl_over_ssl true
+    <buffer tag,time>
+      @type file
+	  chunk_limit_size 8MB
+      path /buffers/test.*.buffer
+      retry_forever true
+      timekey 10m
+      timekey_wait 1m
+    </buffer>
+    <format>
+      @type json
+    </format>
+  </match>
+`
+	kafka := &output.KafkaOutputConfig{}
+	require.NoError(t, yaml.Unmarshal(CONFIG, kafka))
+	test := render.NewOutputPluginTest(t, kafka)
+	test.DiffResult(expected)
+}
@@ -0,0 +1,172 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/common"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+)
+
+// +name:"Logging Operator Forward"
+// +weight:"200"
+type _hugoLoggingOperatorForward interface{} //nolint:deadcode,unused
+
+// +docName:"Logging operator forward output plugin"
+// Forwards the logs to the fluentd aggregator of a logging managed by the logging operator in another cluster,
+// over mutual TLS with compression, for hub-and-spoke multi-cluster log aggregation.
+//
+// On the aggregator cluster enable `tls.autoGenerate` for fluentd and list the forwarding clusters in
+// `tls.forwardPeers`. The operator issues a client certificate for each peer into the
+// `<logging>-fluentd-peer-<peer>` secret of the control namespace, holding `ca.crt`, `tls.crt` and `tls.key`.
+// Copy that secret to the forwarding cluster and reference it as `peerSecret`. Removing a peer deletes its secret and
+// replaces the CA of the peers, the secrets of the remaining peers have to be copied again.
+//
+// #### Example output configurations
+// ```yaml
+// spec:
+//   loggingOperatorForward:
+//     host: logs.hub.example.com
+//     serverName: hub-fluentd.logging.svc
+//     peerSecret: hub-fluentd-peer-edge-1
+//     sharedKey:
+//       valueFrom:
+//         secretKeyRef:
+//           name: hub-shared-key
+//           key: sharedKey
+// ```
+type _docLoggingOperatorForward interface{} //nolint:deadcode,unused
+
+// +name:"Logging Operator Forward"
+// +url:"https://docs.fluentd.org/output/forward"
+// +version:"more info"
+// +description:"Forwards events to the aggregator of another logging operator"
+// +status:"Testing"
+type _metaLoggingOperatorForward interface{} //nolint:deadcode,unused
+
+// +kubebuilder:object:generate=true
+// +docName:"Output Config"
+type LoggingOperatorForwardOutput struct {
+	// Address of the fluentd aggregator of the other cluster, typically the host of its load balancer
+	Host string `json:"host"`
+	// Forward port of the aggregator (default: 24240)
+	Port int `json:"port,omitempty"`
+	// Name the certificate of the aggregator is verified against, when it differs from the host
+	ServerName string `json:"serverName,omitempty"`
+	// Name of the secret holding the CA certificate (ca.crt), the client certificate (tls.crt) and key (tls.key)
+	// issued by the operator of the aggregator for this peer, in the namespace of the output
+	PeerSecret string `json:"peerSecret,omitempty"`
+	// CA certificate of the aggregator, overrides ca.crt of the peer secret
+	// +docLink:"Secret,../secret/"
+	CACert *secret.Secret `json:"caCert,omitempty"`
+	// Client certificate, overrides tls.crt of the peer secret
+	// +docLink:"Secret,../secret/"
+	ClientCert *secret.Secret `json:"clientCert,omitempty"`
+	// Client private key, overrides tls.key of the peer secret
+	// +docLink:"Secret,../secret/"
+	ClientKey *secret.Secret `json:"clientKey,omitempty"`
+	// Shared key of the aggregator, see tls.sharedKey of its fluentd
+	// +docLink:"Secret,../secret/"
+	SharedKey *secret.Secret `json:"sharedKey,omitempty"`
+	// Hostname this cluster identifies itself with to the aggregator (default: logging-operator)
+	SelfHostname string `json:"selfHostname,omitempty"`
+	// Compression of the forwarded chunks [gzip, text] (default: gzip)
+	Compress string `json:"compress,omitempty"`
+	// Wait for the aggregator to acknowledge the chunks (default: true)
+	RequireAckResponse *bool `json:"requireAckResponse,omitempty"`
+	// The version of TLS transport [TLSv1_2, TLSv1_3] (default: TLSv1_2)
+	TlsVersion string `json:"tlsVersion,omitempty"`
+	// The cipher configuration of TLS transport
+	TlsCiphers string `json:"tlsCiphers,omitempty"`
+	// +docLink:"Buffer,../buffer/"
+	Buffer *Buffer `json:"buffer,omitempty"`
+}
+
+func (o *LoggingOperatorForwardOutput) ToDirective(secretLoader secret.SecretLoader, id string) (types.Directive, error) {
+	if o.Host == "" {
+		return nil, errors.New("loggingOperatorForward host is required")
+	}
+	compress := o.Compress
+	switch compress {
+	case "":
+		compress = "gzip"
+	case "gzip", "text":
+	default:
+		return nil, errors.Errorf("unsupported loggingOperatorForward compression %q, must be one of gzip, text", compress)
+	}
+
+	peerKey := func(value *secret.Secret, key string) *secret.Secret {
+		if value != nil || o.PeerSecret == "" {
+			return value
+		}
+		return &secret.Secret{
+			MountFrom: &secret.ValueFrom{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: o.PeerSecret},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	forward := &ForwardOutput{
+		FluentdServers: []FluentdServer{{
+			Host: o.Host,
+			Name: o.ServerName,
+			Port: o.Port,
+		}},
+		Transport:               "tls",
+		RequireAckResponse:      o.RequireAckResponse == nil || *o.RequireAckResponse,
+		TlsVersion:              o.TlsVersion,
+		TlsCiphers:              o.TlsCiphers,
+		TlsVerifyHostname:       true,
+		TlsCertPath:             peerKey(o.CACert, "ca.crt"),
+		TlsClientCertPath:       peerKey(o.ClientCert, "tls.crt"),
+		TlsClientPrivateKeyPath: peerKey(o.ClientKey, "tls.key"),
+		Keepalive:               true,
+		Buffer:                  o.Buffer,
+	}
+	if forward.FluentdServers[0].Port == 0 {
+		forward.FluentdServers[0].Port = 24240
+	}
+	if forward.TlsVersion == "" {
+		forward.TlsVersion = "TLSv1_2"
+	}
+	if forward.TlsCertPath == nil || forward.TlsClientCertPath == nil || forward.TlsClientPrivateKeyPath == nil {
+		return nil, errors.New("loggingOperatorForward requires the peer secret or the CA, client certificate and key")
+	}
+	if o.SharedKey != nil {
+		sharedKey, err := secretLoader.Load(o.SharedKey)
+		if err != nil {
+			return nil, errors.WrapIf(err, "failed to load the shared key")
+		}
+		forward.Security = &common.Security{
+			SelfHostname: o.SelfHostname,
+			SharedKey:    sharedKey,
+		}
+		if forward.Security.SelfHostname == "" {
+			forward.Security.SelfHostname = "logging-operator"
+		}
+	}
+
+	directive, err := forward.ToDirective(secretLoader, id)
+	if err != nil {
+		return nil, err
+	}
+	directive.(*types.OutputPlugin).Params["compress"] = compress
+	return directive, nil
+}
@@ -0,0 +1,91 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output_test
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/render"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingOperatorForward(t *testing.T) {
+	CONFIG := []byte(`
+host: logs.hub.example.com
+serverName: hub-fluentd.logging.svc
+caCert:
+  value: /certs/ca.crt
+clientCert:
+  value: /certs/tls.crt
+clientKey:
+  value: /certs/tls.key
+sharedKey:
+  value: secret
+selfHostname: edge-1
+buffer:
+  timekey: 1m
+  timekey_wait: 30s
+  timekey_use_utc: true
+`)
+	expected := `
+  <match **>
+    @type forward
+    @id test
+    compress gzip
+    keepalive true
+    require_ack_response true
+    tls_cert_path /certs/ca.crt
+    tls_client_cert_path /certs/tls.crt
+    tls_client_private_key_path /certs/tls.key
+    tls_verify_hostname true
+    tls_version TLSv1_2
+    transport tls
+    <buffer tag,time>
+      @type file
+	  chunk_limit_size 8MB
+      path /buffers/test.*.buffer
+      retry_forever true
+      timekey 1m
+      timekey_use_utc true
+      timekey_wait 30s
+    </buffer>
+    <security>
+      self_hostname edge-1
+      shared_key secret
+    </security>
+    <server>
+      host logs.hub.example.com
+      name hub-fluentd.logging.svc
+      port 24240
+    </server>
+  </match>
+`
+	forward := &output.LoggingOperatorForwardOutput{}
+	require.NoError(t, yaml.Unmarshal(CONFIG, forward))
+	test := render.NewOutputPluginTest(t, forward)
+	test.DiffResult(expected)
+}
+
+func TestLoggingOperatorForwardRequiresCertificates(t *testing.T) {
+	forward := &output.LoggingOperatorForwardOutput{
+		Host:   "logs.hub.example.com",
+		CACert: &secret.Secret{Value: "/certs/ca.crt"},
+	}
+	_, err := forward.ToDirective(secret.NewSecretLoader(nil, "", "", nil), "test")
+	require.Error(t, err)
+}
@@ -0,0 +1,106 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+)
+
+// +name:"NATS"
+// +weight:"200"
+type _hugoNATS interface{} //nolint:deadcode,unused
+
+// +docName:"NATS output plugin for Fluentd"
+// Publishes the logs to a NATS server over core NATS. The records are published to the subject of their tag, which
+// can be shaped with the tag_normaliser filter of the flow, for example to `logs.${namespace_name}.${pod_name}`.
+//
+// This is not a JetStream output. The plugin is a core NATS publisher without a buffer: records are published as they
+// are processed, are lost while the server is unreachable and are never acknowledged, even on subjects captured by a
+// JetStream stream. It supports neither credentials files, NKeys nor a custom CA, `ssl` verifies the server against
+// the CAs of the fluentd image. Use the kafka output where delivery guarantees are required.
+// More info at https://github.com/cosmo0920/fluent-plugin-nats
+//
+// #### Example output configurations
+// ```yaml
+// spec:
+//   nats:
+//     host: nats.messaging.svc
+//     user:
+//       value: logging
+//     password:
+//       valueFrom:
+//         secretKeyRef:
+//           name: nats
+//           key: password
+//     ssl: true
+// ```
+type _docNATS interface{} //nolint:deadcode,unused
+
+// +name:"NATS"
+// +url:"https://github.com/cosmo0920/fluent-plugin-nats"
+// +version:"1.0.0"
+// +description:"Publishes logs to NATS subjects"
+// +status:"Testing"
+type _metaNATS interface{} //nolint:deadcode,unused
+
+// +kubebuilder:object:generate=true
+// +docName:"Output Config"
+type NATSOutput struct {
+	// Host of the NATS server (default: localhost)
+	Host string `json:"host,omitempty"`
+	// Port of the NATS server (default: 4222)
+	Port int `json:"port,omitempty"`
+	// User to authenticate with
+	// +docLink:"Secret,../secret/"
+	User *secret.Secret `json:"user,omitempty"`
+	// Password to authenticate with
+	// +docLink:"Secret,../secret/"
+	Password *secret.Secret `json:"password,omitempty"`
+	// Connect to the server over TLS (default: false)
+	SSL bool `json:"ssl,omitempty"`
+	// Number of attempts to reconnect to the server (default: 150)
+	MaxReconnectAttempts int `json:"max_reconnect_attempts,omitempty"`
+	// Seconds to wait between the reconnect attempts (default: 2)
+	ReconnectTimeWait int `json:"reconnect_time_wait,omitempty"`
+	// +docLink:"Format,../format/"
+	Format *Format `json:"format,omitempty"`
+}
+
+func (n *NATSOutput) ToDirective(secretLoader secret.SecretLoader, id string) (types.Directive, error) {
+	const pluginType = "nats"
+	nats := &types.OutputPlugin{
+		PluginMeta: types.PluginMeta{
+			Type:      pluginType,
+			Directive: "match",
+			Tag:       "**",
+			Id:        id,
+		},
+	}
+	if params, err := types.NewStructToStringMapper(secretLoader).StringsMap(n); err != nil {
+		return nil, err
+	} else {
+		nats.Params = params
+	}
+	if n.Format != nil {
+		if format, err := n.Format.ToDirective(secretLoader, ""); err != nil {
+			return nil, err
+		} else {
+			nats.SubDirectives = append(nats.SubDirectives, format)
+		}
+	}
+	return nats, nil
+}
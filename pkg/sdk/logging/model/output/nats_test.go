@@ -0,0 +1,56 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output_test
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/render"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNATS(t *testing.T) {
+	CONFIG := []byte(`
+host: nats.messaging.svc
+port: 4222
+user:
+  value: logging
+password:
+  value: pass
+ssl: true
+format:
+  type: json
+`)
+	expected := `
+  <match **>
+    @type nats
+    @id test
+    host nats.messaging.svc
+    password pass
+    port 4222
+    ssl true
+    user logging
+    <format>
+      @type json
+    </format>
+  </match>
+`
+	nats := &output.NATSOutput{}
+	require.NoError(t, yaml.Unmarshal(CONFIG, nats))
+	test := render.NewOutputPluginTest(t, nats)
+	test.DiffResult(expected)
+}
@@ -0,0 +1,208 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/types"
+)
+
+// +name:"OpenTelemetry"
+// +weight:"200"
+type _hugoOTLP interface{} //nolint:deadcode,unused
+
+// +docName:"OpenTelemetry output plugin"
+// Sends logs to an OpenTelemetry collector or any OTLP compatible backend over gRPC or HTTP.
+// More info at https://github.com/fluent/fluent-plugin-opentelemetry
+//
+// #### Example output configurations
+// ```yaml
+// spec:
+//   otlp:
+//     endpoint: otel-collector.observability.svc:4317
+//     protocol: grpc
+//     headers:
+//       authorization:
+//         valueFrom:
+//           secretKeyRef:
+//             name: otlp-token
+//             key: token
+//     buffer:
+//       timekey: 1m
+//       timekey_wait: 30s
+//       timekey_use_utc: true
+// ```
+type _docOTLP interface{} //nolint:deadcode,unused
+
+// +name:"OpenTelemetry"
+// +url:"https://github.com/fluent/fluent-plugin-opentelemetry"
+// +version:"0.2.0"
+// +description:"Send logs to OpenTelemetry compatible backends via OTLP"
+// +status:"Testing"
+type _metaOTLP interface{} //nolint:deadcode,unused
+
+// +kubebuilder:object:generate=true
+// +docName:"Output Config"
+type OTLPOutput struct {
+	// Endpoint of the collector, host:port for grpc (e.g. otel-collector:4317), URL for http (e.g. https://otel-collector:4318/v1/logs)
+	Endpoint string `json:"endpoint"`
+	// Protocol used to ship the logs [grpc, http] (default: grpc)
+	Protocol string `json:"protocol,omitempty"`
+	// Compression of the requests [gzip, none] (default: none)
+	Compress string `json:"compress,omitempty"`
+	// Timeout of the requests (default: 60s)
+	Timeout string `json:"timeout,omitempty"`
+	// Set the Kubernetes metadata of the records as resource attributes following the OpenTelemetry semantic conventions (default: true)
+	ConfigureKubernetesResourceAttributes *bool `json:"configure_kubernetes_resource_attributes,omitempty"`
+	// Resource attributes set from the records, in record_accessor syntax (e.g. service.name: $.kubernetes.labels.app)
+	ResourceAttributes OTLPResourceAttributes `json:"resource_attributes,omitempty"`
+	// Headers sent with each request, typically used for authentication
+	// +docLink:"Secret,../secret/"
+	Headers OTLPHeaders `json:"headers,omitempty"`
+	// CA certificate to verify the collector
+	// +docLink:"Secret,../secret/"
+	TLSCACert *secret.Secret `json:"tls_ca_cert,omitempty"`
+	// Client certificate for mutual TLS
+	// +docLink:"Secret,../secret/"
+	TLSClientCert *secret.Secret `json:"tls_client_cert,omitempty"`
+	// Client private key for mutual TLS
+	// +docLink:"Secret,../secret/"
+	TLSClientKey *secret.Secret `json:"tls_client_key,omitempty"`
+	// Skip the verification of the collector certificate (default: false)
+	TLSInsecureSkipVerify bool `json:"tls_insecure_skip_verify,omitempty"`
+	// +docLink:"Buffer,../buffer/"
+	Buffer *Buffer `json:"buffer,omitempty"`
+}
+
+// OTLPResourceAttributes maps resource attribute names to record accessors
+type OTLPResourceAttributes map[string]string
+
+// OTLPHeaders maps header names to their values
+type OTLPHeaders map[string]*secret.Secret
+
+func (o *OTLPOutput) ToDirective(secretLoader secret.SecretLoader, id string) (types.Directive, error) {
+	const pluginType = "opentelemetry"
+	otlp := &types.OutputPlugin{
+		PluginMeta: types.PluginMeta{
+			Type:      pluginType,
+			Directive: "match",
+			Tag:       "**",
+			Id:        id,
+		},
+	}
+	if o.ConfigureKubernetesResourceAttributes == nil || *o.ConfigureKubernetesResourceAttributes {
+		attributes := OTLPResourceAttributes{
+			"k8s.namespace.name": `$.kubernetes.namespace_name`,
+			"k8s.pod.name":       `$.kubernetes.pod_name`,
+			"k8s.pod.uid":        `$.kubernetes.pod_id`,
+			"k8s.container.name": `$.kubernetes.container_name`,
+			"k8s.node.name":      `$.kubernetes.host`,
+			"container.id":       `$.kubernetes.docker_id`,
+		}
+		for k, v := range o.ResourceAttributes {
+			attributes[k] = v
+		}
+		o.ResourceAttributes = attributes
+		// Prevent meta configuration from marshalling
+		o.ConfigureKubernetesResourceAttributes = nil
+	}
+
+	if o.Endpoint == "" {
+		return nil, errors.New("otlp endpoint is required")
+	}
+	var transport string
+	switch o.Protocol {
+	case "", "grpc":
+		transport = "grpc"
+	case "http":
+		transport = "http"
+	default:
+		return nil, errors.Errorf("unsupported otlp protocol %q, must be one of grpc, http", o.Protocol)
+	}
+
+	endpoint := &types.GenericDirective{
+		PluginMeta: types.PluginMeta{
+			Directive: transport,
+		},
+		Params: map[string]string{
+			"endpoint": o.Endpoint,
+		},
+	}
+	if o.Compress != "" {
+		endpoint.Params["compress"] = o.Compress
+	}
+	if o.Timeout != "" {
+		endpoint.Params["timeout"] = o.Timeout
+	}
+	otlp.SubDirectives = append(otlp.SubDirectives, endpoint)
+
+	if len(o.ResourceAttributes) > 0 {
+		otlp.SubDirectives = append(otlp.SubDirectives, &types.GenericDirective{
+			PluginMeta: types.PluginMeta{
+				Directive: "resource",
+			},
+			Params: o.ResourceAttributes,
+		})
+	}
+	if len(o.Headers) > 0 {
+		headers := &types.GenericDirective{
+			PluginMeta: types.PluginMeta{
+				Directive: "headers",
+			},
+			Params: map[string]string{},
+		}
+		for name, value := range o.Headers {
+			loaded, err := secretLoader.Load(value)
+			if err != nil {
+				return nil, errors.WrapIff(err, "failed to load header %q", name)
+			}
+			headers.Params[name] = loaded
+		}
+		otlp.SubDirectives = append(otlp.SubDirectives, headers)
+	}
+	if o.TLSCACert != nil || o.TLSClientCert != nil || o.TLSClientKey != nil || o.TLSInsecureSkipVerify {
+		tls, err := types.NewFlatDirective(types.PluginMeta{Directive: "transport"}, &otlpTransport{
+			Protocol:        "tls",
+			CAPath:          o.TLSCACert,
+			CertPath:        o.TLSClientCert,
+			PrivateKeyPath:  o.TLSClientKey,
+			InsecureSkipTLS: o.TLSInsecureSkipVerify,
+		}, secretLoader)
+		if err != nil {
+			return nil, err
+		}
+		otlp.SubDirectives = append(otlp.SubDirectives, tls)
+	}
+
+	if o.Buffer == nil {
+		o.Buffer = &Buffer{}
+	}
+	if buffer, err := o.Buffer.ToDirective(secretLoader, id); err != nil {
+		return nil, err
+	} else {
+		otlp.SubDirectives = append(otlp.SubDirectives, buffer)
+	}
+	return otlp, nil
+}
+
+type otlpTransport struct {
+	Protocol        string         `json:"protocol"`
+	CAPath          *secret.Secret `json:"ca_path,omitempty"`
+	CertPath        *secret.Secret `json:"cert_path,omitempty"`
+	PrivateKeyPath  *secret.Secret `json:"private_key_path,omitempty"`
+	InsecureSkipTLS bool           `json:"insecure,omitempty"`
+}
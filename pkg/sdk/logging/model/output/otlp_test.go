@@ -0,0 +1,139 @@
+// Copyright © 2022 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output_test
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/secret"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/output"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/logging/model/render"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLP(t *testing.T) {
+	CONFIG := []byte(`
+endpoint: https://otel-collector:4318/v1/logs
+protocol: http
+compress: gzip
+resource_attributes:
+  service.name: $.kubernetes.labels.app
+headers:
+  authorization:
+    value: Bearer token
+tls_insecure_skip_verify: true
+buffer:
+  timekey: 1m
+  timekey_wait: 30s
+  timekey_use_utc: true
+`)
+	expected := `
+  <match **>
+    @type opentelemetry
+    @id test
+    <http>
+      compress gzip
+      endpoint https://otel-collector:4318/v1/logs
+    </http>
+    <resource>
+      container.id $.kubernetes.docker_id
+      k8s.container.name $.kubernetes.container_name
+      k8s.namespace.name $.kubernetes.namespace_name
+      k8s.node.name $.kubernetes.host
+      k8s.pod.name $.kubernetes.pod_name
+      k8s.pod.uid $.kubernetes.pod_id
+      service.name $.kubernetes.labels.app
+    </resource>
+    <headers>
+      authorization Bearer token
+    </headers>
+    <transport>
+      insecure true
+      protocol tls
+    </transport>
+    <buffer tag,time>
+      @type file
+	  chunk_limit_size 8MB
+      path /buffers/test.*.buffer
+      retry_forever true
+      timekey 1m
+      timekey_use_utc true
+      timekey_wait 30s
+    </buffer>
+  </match>
+`
+	otlp := &output.OTLPOutput{}
+	require.NoError(t, yaml.Unmarshal(CONFIG, otlp))
+	test := render.NewOutputPluginTest(t, otlp)
+	test.DiffResult(expected)
+}
+
+func TestOTLPGRPC(t *testing.T) {
+	CONFIG := []byte(`
+endpoint: otel-collector:4317
+timeout: 10s
+configure_kubernetes_resource_attributes: false
+resource_attributes:
+  deployment.environment: production
+tls_ca_cert:
+  value: /certs/ca.crt
+buffer:
+  timekey: 1m
+  timekey_wait: 30s
+  timekey_use_utc: true
+`)
+	expected := `
+  <match **>
+    @type opentelemetry
+    @id test
+    <grpc>
+      endpoint otel-collector:4317
+      timeout 10s
+    </grpc>
+    <resource>
+      deployment.environment production
+    </resource>
+    <transport>
+      ca_path /certs/ca.crt
+      protocol tls
+    </transport>
+    <buffer tag,time>
+      @type file
+	  chunk_limit_size 8MB
+      path /buffers/test.*.buffer
+      retry_forever true
+      timekey 1m
+      timekey_use_utc true
+      timekey_wait 30s
+    </buffer>
+  </match>
+`
+	otlp := &output.OTLPOutput{}
+	require.NoError(t, yaml.Unmarshal(CONFIG, otlp))
+	test := render.NewOutputPluginTest(t, otlp)
+	test.DiffResult(expected)
+}
+
+func TestOTLPInvalid(t *testing.T) {
+	for _, otlp := range []*output.OTLPOutput{
+		{},
+		{Endpoint: "otel-collector:4317", Protocol: "udp"},
+	} {
+		_, err := otlp.ToDirective(secret.NewSecretLoader(nil, "", "", nil), "test")
+		require.Error(t, err)
+	}
+}
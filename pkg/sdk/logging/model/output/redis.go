@@ -28,6 +28,9 @@ type _hugoRedis interface{} //nolint:deadcode,unused
 // Sends logs to Redis endpoints.
 // More info at https://github.com/fluent-plugins-nursery/fluent-plugin-redis
 //
+// The records are stored as hashes keyed by the tag and the time, the writes of a chunk are pipelined. The plugin
+// supports neither lists, streams nor TLS.
+//
 // #### Example output configurations
 // ```yaml
 // spec:
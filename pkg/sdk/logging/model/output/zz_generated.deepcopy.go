@@ -437,6 +437,11 @@ func (in *Format) DeepCopyInto(out *Format) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SIEM != nil {
+		in, out := &in.SIEM, &out.SIEM
+		*out = new(SIEMFormat)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Format.
@@ -457,6 +462,11 @@ func (in *FormatRfc5424) DeepCopyInto(out *FormatRfc5424) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SIEM != nil {
+		in, out := &in.SIEM, &out.SIEM
+		*out = new(SIEMFormat)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FormatRfc5424.
@@ -675,6 +685,56 @@ func (in *HTTPOutputConfig) DeepCopy() *HTTPOutputConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaAwsMskIam) DeepCopyInto(out *KafkaAwsMskIam) {
+	*out = *in
+	if in.AccessKeyID != nil {
+		in, out := &in.AccessKeyID, &out.AccessKeyID
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretAccessKey != nil {
+		in, out := &in.SecretAccessKey, &out.SecretAccessKey
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaAwsMskIam.
+func (in *KafkaAwsMskIam) DeepCopy() *KafkaAwsMskIam {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaAwsMskIam)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaOAuthBearer) DeepCopyInto(out *KafkaOAuthBearer) {
+	*out = *in
+	if in.ClientID != nil {
+		in, out := &in.ClientID, &out.ClientID
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientSecret != nil {
+		in, out := &in.ClientSecret, &out.ClientSecret
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaOAuthBearer.
+func (in *KafkaOAuthBearer) DeepCopy() *KafkaOAuthBearer {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaOAuthBearer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaOutputConfig) DeepCopyInto(out *KafkaOutputConfig) {
 	*out = *in
@@ -702,6 +762,16 @@ func (in *KafkaOutputConfig) DeepCopyInto(out *KafkaOutputConfig) {
 		*out = new(secret.Secret)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.OAuthBearer != nil {
+		in, out := &in.OAuthBearer, &out.OAuthBearer
+		*out = new(KafkaOAuthBearer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AwsMskIam != nil {
+		in, out := &in.AwsMskIam, &out.AwsMskIam
+		*out = new(KafkaAwsMskIam)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SSLCACertsFromSystem != nil {
 		in, out := &in.SSLCACertsFromSystem, &out.SSLCACertsFromSystem
 		*out = new(bool)
@@ -964,6 +1034,51 @@ func (in *LogZOutput) DeepCopy() *LogZOutput {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingOperatorForwardOutput) DeepCopyInto(out *LoggingOperatorForwardOutput) {
+	*out = *in
+	if in.CACert != nil {
+		in, out := &in.CACert, &out.CACert
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCert != nil {
+		in, out := &in.ClientCert, &out.ClientCert
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientKey != nil {
+		in, out := &in.ClientKey, &out.ClientKey
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedKey != nil {
+		in, out := &in.SharedKey, &out.SharedKey
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequireAckResponse != nil {
+		in, out := &in.RequireAckResponse, &out.RequireAckResponse
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(Buffer)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingOperatorForwardOutput.
+func (in *LoggingOperatorForwardOutput) DeepCopy() *LoggingOperatorForwardOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingOperatorForwardOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LokiOutput) DeepCopyInto(out *LokiOutput) {
 	*out = *in
@@ -1048,6 +1163,36 @@ func (in *LokiOutput) DeepCopy() *LokiOutput {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NATSOutput) DeepCopyInto(out *NATSOutput) {
+	*out = *in
+	if in.User != nil {
+		in, out := &in.User, &out.User
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Format != nil {
+		in, out := &in.Format, &out.Format
+		*out = new(Format)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NATSOutput.
+func (in *NATSOutput) DeepCopy() *NATSOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(NATSOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NewRelicOutputConfig) DeepCopyInto(out *NewRelicOutputConfig) {
 	*out = *in
@@ -1123,6 +1268,68 @@ func (in *OSSOutput) DeepCopy() *OSSOutput {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OTLPOutput) DeepCopyInto(out *OTLPOutput) {
+	*out = *in
+	if in.ConfigureKubernetesResourceAttributes != nil {
+		in, out := &in.ConfigureKubernetesResourceAttributes, &out.ConfigureKubernetesResourceAttributes
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ResourceAttributes != nil {
+		in, out := &in.ResourceAttributes, &out.ResourceAttributes
+		*out = make(OTLPResourceAttributes, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(OTLPHeaders, len(*in))
+		for key, val := range *in {
+			var outVal *secret.Secret
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(secret.Secret)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.TLSCACert != nil {
+		in, out := &in.TLSCACert, &out.TLSCACert
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLSClientCert != nil {
+		in, out := &in.TLSClientCert, &out.TLSClientCert
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLSClientKey != nil {
+		in, out := &in.TLSClientKey, &out.TLSClientKey
+		*out = new(secret.Secret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(Buffer)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OTLPOutput.
+func (in *OTLPOutput) DeepCopy() *OTLPOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(OTLPOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenSearchOutput) DeepCopyInto(out *OpenSearchOutput) {
 	*out = *in
@@ -1373,6 +1580,35 @@ func (in *S3SharedCredentials) DeepCopy() *S3SharedCredentials {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SIEMFormat) DeepCopyInto(out *SIEMFormat) {
+	*out = *in
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.StaticExtensions != nil {
+		in, out := &in.StaticExtensions, &out.StaticExtensions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SIEMFormat.
+func (in *SIEMFormat) DeepCopy() *SIEMFormat {
+	if in == nil {
+		return nil
+	}
+	out := new(SIEMFormat)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SQSOutputConfig) DeepCopyInto(out *SQSOutputConfig) {
 	*out = *in
@@ -509,6 +509,96 @@ func TestRenderErrorOutput(t *testing.T) {
 	}
 }
 
+func TestRenderFailoverFlow(t *testing.T) {
+	system := types.NewSystemBuilder(toDirective(t, input.NewTailInputConfig("input.log")), nil, types.NewRouter("test", nil))
+
+	flowObj, err := types.NewFlow(
+		[]types.FlowMatch{
+			{Labels: map[string]string{
+				"key1": "val1",
+				"key2": "val2"},
+				Namespaces: []string{"ns-test"}},
+		}, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	failoverFlow, err := types.NewFlow(nil, "failover", "failover", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	failoverFlow.WithOutputs(toDirective(t, output.NewNullOutputConfig()))
+
+	primary := toDirective(t, output.NewNullOutputConfig()).(*types.OutputPlugin)
+	primary.SubDirectives = append(primary.SubDirectives, &types.GenericDirective{
+		PluginMeta: types.PluginMeta{
+			Type:      "relabel",
+			Directive: "secondary",
+			Label:     failoverFlow.FlowLabel,
+		},
+	})
+	flowObj.WithOutputs(primary)
+	flowObj.FailoverFlows = append(flowObj.FailoverFlows, failoverFlow)
+
+	err = system.RegisterFlow(flowObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fluentConfig, err := system.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &bytes.Buffer{}
+	renderer := render.FluentRender{
+		Out:    b,
+		Indent: 2,
+	}
+	err = renderer.Render(fluentConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+		<source>
+          @type tail
+          @id test
+          path input.log
+        </source>
+        <match **>
+          @type label_router
+          @id test
+          <route>
+            @label @901f778f9602a78e8fd702c1973d8d8d
+			  <match>
+			    labels key1:val1,key2:val2
+			    namespaces ns-test
+			    negate false
+			  </match>
+          </route>
+        </match>
+        <label @901f778f9602a78e8fd702c1973d8d8d>
+          <match **>
+            @type null
+            @id test
+            <secondary>
+              @type relabel
+              @label @3ad6e9988864aeae2ef386345946dc6d
+            </secondary>
+          </match>
+        </label>
+        <label @3ad6e9988864aeae2ef386345946dc6d>
+          <match **>
+            @type null
+            @id test
+          </match>
+        </label>`
+
+	if a, e := diff.TrimLinesInString(b.String()), diff.TrimLinesInString(expected); a != e {
+		t.Errorf("Result does not match (-actual vs +expected):\n%v\nActual: %s", diff.LineDiff(a, e), b.String())
+	}
+}
+
 func TestRenderFullFluentConfigWithGlobalFilter(t *testing.T) {
 	globalFilters := []types.Filter{toDirective(t, filter.NewStdOutFilterConfig())}
 	system := types.NewSystemBuilder(toDirective(t, input.NewTailInputConfig("input.log")), globalFilters, types.NewRouter("test", nil))
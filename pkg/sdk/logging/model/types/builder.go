@@ -25,6 +25,13 @@ type SystemBuilder struct {
 	globalFilters []Filter
 	flows         []*Flow
 	router        *Router
+	inputs        []labeledInput
+}
+
+type labeledInput struct {
+	input   Input
+	label   string
+	filters []Filter
 }
 
 func NewSystemBuilder(input Input, globalFilers []Filter, router *Router) *SystemBuilder {
@@ -76,11 +83,44 @@ func (s *SystemBuilder) RegisterDefaultFlow(f *Flow) error {
 	return nil
 }
 
+// RegisterLabeledInput adds an input emitting into the given label, where the filters are applied to its events
+// before they are routed to the flows by a copy of the router
+func (s *SystemBuilder) RegisterLabeledInput(input Input, label string, filters []Filter) error {
+	for _, e := range s.inputs {
+		if e.label == label {
+			return errors.New("Input label already exists")
+		}
+	}
+	s.inputs = append(s.inputs, labeledInput{input: input, label: label, filters: filters})
+	return nil
+}
+
 func (s *SystemBuilder) Build() (*System, error) {
-	return &System{
+	system := &System{
 		Input:         s.input,
 		GlobalFilters: s.globalFilters,
 		Router:        s.router,
 		Flows:         s.flows,
-	}, nil
+	}
+	for _, i := range s.inputs {
+		// The copy shares the routes, its metrics are disabled as they would be registered twice
+		router := *s.router
+		router.Id = i.input.GetPluginMeta().Id + "-router"
+		router.Params = Params{}
+		for k, v := range s.router.Params {
+			router.Params[k] = v
+		}
+		router.Params["metrics"] = "false"
+		system.Inputs = append(system.Inputs, i.input)
+		system.InputFlows = append(system.InputFlows, &Flow{
+			PluginMeta: PluginMeta{
+				Directive: "label",
+				Tag:       i.label,
+			},
+			FlowLabel: i.label,
+			Filters:   i.filters,
+			Outputs:   []Output{&router},
+		})
+	}
+	return system, nil
 }
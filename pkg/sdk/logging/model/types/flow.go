@@ -32,6 +32,10 @@ type System struct {
 	GlobalFilters []Filter `json:"globalFilters"`
 	Router        *Router  `json:"router"`
 	Flows         []*Flow  `json:"flows"`
+	// Additional inputs emitting into labels of their own
+	Inputs []Input `json:"inputs,omitempty"`
+	// Labels of the additional inputs, routing their events to the flows
+	InputFlows []*Flow `json:"inputFlows,omitempty"`
 }
 
 func (s *System) GetDirectives() []Directive {
@@ -39,15 +43,24 @@ func (s *System) GetDirectives() []Directive {
 	directives := []Directive{
 		s.Input,
 	}
+	for _, input := range s.Inputs {
+		directives = append(directives, input)
+	}
 	// Add GlobalFilters between input and router
 	for _, filter := range s.GlobalFilters {
 		directives = append(directives, filter)
 	}
 	// Add router directive
 	directives = append(directives, s.Router)
+	for _, flow := range s.InputFlows {
+		directives = append(directives, flow)
+	}
 	// Add Flows after router
 	for _, flow := range s.Flows {
 		directives = append(directives, flow)
+		for _, failover := range flow.FailoverFlows {
+			directives = append(directives, failover)
+		}
 	}
 	return directives
 }
@@ -67,6 +80,9 @@ type Flow struct {
 
 	// Fluentd label
 	FlowLabel string `json:"-"`
+
+	// Labels of the failover and formatting outputs, rendered after the flow
+	FailoverFlows []*Flow `json:"-"`
 }
 
 func (f *Flow) GetPluginMeta() *PluginMeta {
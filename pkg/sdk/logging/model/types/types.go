@@ -158,6 +158,7 @@ func NewCopyDirective(directives []Output) Directive {
 				Type:      d.GetPluginMeta().Type,
 				Id:        d.GetPluginMeta().Id,
 				LogLevel:  d.GetPluginMeta().LogLevel,
+				Label:     d.GetPluginMeta().Label,
 				Directive: "store",
 			},
 			Params:        d.GetParams(),
@@ -26,56 +26,56 @@ var Root = func() http.FileSystem {
 			modTime:          time.Time{},
 			uncompressedSize: 280,
 
-			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\xce\xbd\x4a\xc5\x40\x10\xc5\xf1\x7e\x9f\x62\x48\x9f\x88\x9d\x6c\x6b\x61\x6f\x61\x23\xb7\x98\xec\x3d\xe0\x98\x64\x67\x99\x99\x8d\x1f\x4f\x2f\xb1\x12\x09\xb7\x3e\x9c\x1f\x7f\x6e\xf2\x02\x73\xd1\x9a\xc9\x66\x2e\x13\xf7\x78\x53\x93\x6f\x0e\xd1\x3a\x2d\x0f\x3e\x89\xde\xed\xf7\x69\x91\x7a\xcd\xf4\xb8\x76\x0f\xd8\xb3\xae\x48\x1b\x82\xaf\x1c\x9c\x13\x51\xe5\x0d\x99\x9a\xe9\xe7\xd7\x68\xc7\x68\x7d\x85\xe7\x34\x12\x37\x79\x32\xed\xcd\x33\xbd\x0e\x07\x8e\x1a\x52\xfe\xea\xc3\x25\x11\x19\x5c\xbb\x95\xe3\x43\x34\x52\xe8\x82\x6a\xd8\x05\x1f\x9e\x88\x76\xd8\xfc\x0b\x14\x03\x07\x86\xcb\x19\xfc\xbf\xfa\xcc\xf5\x3e\xbf\xa3\x04\x97\x02\xf7\x5b\xfe\x4f\x00\x00\x00\xff\xff\x4b\x41\x4e\x63\x18\x01\x00\x00"),
+			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\xce\xbd\x4a\xc5\x40\x10\xc5\xf1\x7e\x9f\x62\x48\x9f\x88\x9d\x6c\x6b\x61\x6f\x61\x23\xb7\x98\xec\x3d\xe0\x98\x64\x67\x99\x99\x8d\x1f\x4f\x2f\xb1\x12\x09\xb7\x3e\x9c\x1f\x7f\x6e\xf2\x02\x73\xd1\x9a\xc9\x66\x2e\x13\xf7\x78\x53\x93\x6f\x0e\xd1\x3a\x2d\x0f\x3e\x89\xde\xed\xf7\x69\x91\x7a\xcd\xf4\xb8\x76\x0f\xd8\xb3\xae\x48\x1b\x82\xaf\x1c\x9c\x13\x51\xe5\x0d\x99\x9a\xe9\xe7\xd7\x68\xc7\x68\x7d\x85\xe7\x34\x12\x37\x79\x32\xed\xcd\x33\xbd\x0e\x07\x8e\x1a\x52\xfe\xea\xc3\x25\x11\x19\x5c\xbb\x95\xe3\x43\x34\x52\xe8\x82\x6a\xd8\x05\x1f\x9e\x88\x76\xd8\xfc\x0b\x14\x03\x07\x86\xcb\x19\xfc\xbf\xfa\xcc\xf5\x3e\xbf\xa3\x04\x97\x02\xf7\x5b\xfe\xcf\x00\x4b\x41\x4e\x63\x18\x01\x00\x00"),
 		},
 		"/auth_proxy_role_binding.yaml": &vfsgen۰CompressedFileInfo{
 			name:             "auth_proxy_role_binding.yaml",
 			modTime:          time.Time{},
 			uncompressedSize: 257,
 
-			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8d\x31\x4e\xc0\x30\x0c\x45\xf7\x9c\xc2\x17\x68\x11\x1b\xca\x06\x0c\xec\x45\x62\x77\x13\x17\x4c\xd3\x38\xb2\x9d\x8a\x72\x7a\x54\xa9\xb0\x80\xd8\x6c\xe9\xbd\xff\xb0\xf1\x0b\xa9\xb1\xd4\x08\x3a\x63\x1a\xb1\xfb\x9b\x28\x7f\xa2\xb3\xd4\x71\xbd\xb3\x91\xe5\x66\xbf\x0d\x2b\xd7\x1c\xe1\xb1\x74\x73\xd2\x49\x0a\x3d\x70\xcd\x5c\x5f\xc3\x46\x8e\x19\x1d\x63\x00\xa8\xb8\x51\x84\xa6\xf2\x71\x0c\x2a\x85\xe6\x8b\x39\xef\x89\x96\x13\xc1\xc6\x4f\x2a\xbd\xfd\x93\x0b\x00\xbf\x6a\x7f\x8c\x07\xeb\xf3\x3b\x25\xb7\x18\x86\x4b\x78\x26\xdd\x39\xd1\x7d\x4a\xd2\xab\xff\x38\x99\x16\xec\xe5\xfb\xb7\x86\x89\x22\xd8\x61\x4e\x5b\xf8\x0a\x00\x00\xff\xff\x38\xc6\x1a\x54\x01\x01\x00\x00"),
+			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8d\x31\x4e\xc0\x30\x0c\x45\xf7\x9c\xc2\x17\x68\x11\x1b\xca\x06\x0c\xec\x45\x62\x77\x13\x17\x4c\xd3\x38\xb2\x9d\x8a\x72\x7a\x54\xa9\xb0\x80\xd8\x6c\xe9\xbd\xff\xb0\xf1\x0b\xa9\xb1\xd4\x08\x3a\x63\x1a\xb1\xfb\x9b\x28\x7f\xa2\xb3\xd4\x71\xbd\xb3\x91\xe5\x66\xbf\x0d\x2b\xd7\x1c\xe1\xb1\x74\x73\xd2\x49\x0a\x3d\x70\xcd\x5c\x5f\xc3\x46\x8e\x19\x1d\x63\x00\xa8\xb8\x51\x84\xa6\xf2\x71\x0c\x2a\x85\xe6\x8b\x39\xef\x89\x96\x13\xc1\xc6\x4f\x2a\xbd\xfd\x93\x0b\x00\xbf\x6a\x7f\x8c\x07\xeb\xf3\x3b\x25\xb7\x18\x86\x4b\x78\x26\xdd\x39\xd1\x7d\x4a\xd2\xab\xff\x38\x99\x16\xec\xe5\xfb\xb7\x86\x89\x22\xd8\x61\x4e\x5b\xf8\x1a\x00\x38\xc6\x1a\x54\x01\x01\x00\x00"),
 		},
 		"/auth_proxy_service.yaml": &vfsgen۰CompressedFileInfo{
 			name:             "auth_proxy_service.yaml",
 			modTime:          time.Time{},
 			uncompressedSize: 379,
 
-			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x8f\xb1\x6a\x04\x31\x0c\x44\x7b\x7f\x85\xd8\xde\x09\x21\x57\x04\x7f\x45\x20\x90\x5e\xf1\x0d\xb7\x26\xb6\x65\x24\xdd\x41\xfe\x3e\x78\xb3\x07\x29\xb6\xb8\x72\x34\x62\x66\x1e\x8f\xf2\x09\xb5\x22\x3d\xd1\xed\x25\x7c\x97\x7e\x4e\xf4\x01\xbd\x95\x8c\xd0\xe0\x7c\x66\xe7\x14\x88\xb8\x77\x71\xf6\x22\xdd\xa6\x24\x1a\x2a\x0d\xbe\xe2\x6a\x4f\x45\x9e\x87\xa8\x27\x5a\xde\x4e\xa7\xd7\xe5\xc0\xb6\xbc\xa2\x21\xd1\xea\x3e\xec\xd0\x57\x1e\x48\xb4\xb8\x5e\x31\x03\x2a\x7f\xa1\xee\x4d\x59\xba\xab\xd4\x38\x2a\x77\xa4\xbb\xac\xd0\xd8\xb8\xf3\x05\x1a\x88\x3a\xb7\x43\x2b\x36\xb8\x96\x6c\xd1\x76\xa6\xbf\x57\x1b\x9c\x91\xc8\x7e\xcc\xd1\x82\x0d\xe4\x59\x35\x21\xb6\xce\xb8\xe7\xfd\x9b\xbb\xf1\x4d\xbc\x4d\x3a\xeb\x05\xfe\xbe\x1d\xef\x4f\x86\x8a\xec\xa2\x8f\x8e\xfe\x0d\x00\x00\xff\xff\xe8\x4f\xf6\xaf\x7b\x01\x00\x00"),
+			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x8f\xb1\x6a\x04\x31\x0c\x44\x7b\x7f\x85\xd8\xde\x09\x21\x57\x04\x7f\x45\x20\x90\x5e\xf1\x0d\xb7\x26\xb6\x65\x24\xdd\x41\xfe\x3e\x78\xb3\x07\x29\xb6\xb8\x52\x9a\x61\x66\x1e\x8f\xf2\x09\xb5\x22\x3d\xd1\xed\x25\x7c\x97\x7e\x4e\xf4\x01\xbd\x95\x8c\xd0\xe0\x7c\x66\xe7\x14\x88\xb8\x77\x71\xf6\x22\xdd\xe6\x49\x34\x54\x1a\x7c\xc5\xd5\x9e\x8a\x3c\x0f\x51\x4f\xb4\xbc\x9d\x4e\xaf\xcb\x81\x6c\x79\x45\x43\xa2\xd5\x7d\xd8\xa1\xae\x3c\x90\x68\x71\xbd\x62\x06\x54\xfe\x42\xdd\x9b\xb2\x74\x57\xa9\x71\x54\xee\x48\xf7\xb3\x42\x63\xe3\xce\x17\x68\x20\xea\xdc\x0e\xa5\xd8\xe0\x5a\xb2\x45\xdb\x99\xfe\xac\x36\x38\x23\x91\xfd\x98\xa3\x05\x1b\xc8\xb3\x6a\x42\x6c\x9d\x71\xcf\xfb\x37\x77\xe3\x9b\x78\xdb\x24\x67\xbd\xc0\xdf\xb7\xe7\xdd\x64\xa8\xc8\x2e\xfa\xe8\xe8\xdf\x01\x00\xe8\x4f\xf6\xaf\x7b\x01\x00\x00"),
 		},
 		"/kustomization.yaml": &vfsgen۰CompressedFileInfo{
 			name:             "kustomization.yaml",
 			modTime:          time.Time{},
 			uncompressedSize: 344,
 
-			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x8f\xb1\x4e\xc5\x30\x0c\x45\xf7\x7e\x85\xa5\x2e\x30\xb4\x19\xd8\xde\xca\x87\x44\x49\xea\xd7\x58\x24\x71\xe5\x38\x94\xf2\xf5\xe8\x45\x80\x5a\xc4\x66\xf9\x9e\xab\xab\x23\x58\xb9\x49\xc0\x7a\x1b\x26\x10\x4e\x38\x1f\x2e\xa7\xef\xdb\x7a\x2a\x0b\x95\xf5\xe7\x97\xd0\x2d\x28\x16\x13\x06\x25\x2e\xf6\xcc\xff\x97\x5d\xfb\x23\xbc\x72\xce\x58\x14\x34\x22\xdc\x39\x25\xde\xa9\xac\xf0\x02\x89\x0a\x56\xa0\x3b\x1c\xdc\x60\x77\x0f\x82\x61\xa1\xea\x7c\xc2\x61\xec\xb8\x6b\x1a\x61\x13\xfe\x38\xe0\x29\xaa\x6e\xf5\x66\xcc\x4a\x1a\x9b\x9f\x03\x67\xe3\xc5\x95\xf0\x69\xde\x9a\xc7\x49\xbc\x0b\x53\x47\x9f\x87\x11\xf6\x48\xa1\x37\x15\x83\xd6\xc7\x82\x80\xc9\xa8\x42\xa1\x02\x96\x65\x63\x2a\x3a\x0f\x53\x5f\xb0\xbd\x66\x2b\xca\x3b\x85\x5f\xb5\x53\x72\x36\xfe\xf3\xbe\xca\x7e\x05\x00\x00\xff\xff\xac\x90\x8c\xf8\x58\x01\x00\x00"),
+			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x8f\xb1\x4e\xc5\x30\x0c\x45\xf7\x7e\x85\xa5\x2e\x30\xb4\x19\xd8\xde\xca\x87\x44\x49\xea\xd7\x58\x24\x71\xe5\x38\x94\xf2\xf5\xe8\x45\x80\x5a\xc4\x66\xf9\x9e\xab\xab\x23\x58\xb9\x49\xc0\x7a\x1b\x26\x10\x4e\x38\x1f\x2e\xa7\xef\xdb\x7a\x2a\x0b\x95\xf5\xe7\x97\xd0\x2d\x28\x16\x13\x06\x25\x2e\xf6\xcc\xff\x97\x5d\xfb\x23\xbc\x72\xce\x58\x14\x34\x22\xdc\x39\x25\xde\xa9\xac\xf0\x02\x89\x0a\x56\xa0\x3b\x1c\xdc\x60\x77\x0f\x82\x61\xa1\xea\x7c\xc2\x61\xec\xb8\x6b\x1a\x61\x13\xfe\x38\xe0\x29\xaa\x6e\xf5\x66\xcc\x4a\x1a\x9b\x9f\x03\x67\xe3\xc5\x95\xf0\x69\xde\x9a\xc7\x49\xbc\x0b\x53\x47\x9f\x87\x11\xf6\x48\xa1\x37\x15\x83\xd6\xc7\x82\x80\xc9\xa8\x42\xa1\x02\x96\x65\x63\x2a\x3a\x0f\x53\x5f\xb0\xbd\x66\x2b\xca\x3b\x85\x5f\xb5\x53\x72\x36\xfe\xf3\xbe\xca\x7e\x0d\x00\xac\x90\x8c\xf8\x58\x01\x00\x00"),
 		},
 		"/leader_election_role.yaml": &vfsgen۰CompressedFileInfo{
 			name:             "leader_election_role.yaml",
 			modTime:          time.Time{},
 			uncompressedSize: 355,
 
-			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x4e\xbd\x4e\xc3\x40\x0c\xde\xef\x29\xac\x32\x27\x15\x1b\xca\x0b\xb0\x33\xb0\xbb\xb9\x8f\xd6\xea\xe5\x7c\xb2\x7d\x45\xe2\xe9\x51\xd2\x94\x05\x31\x30\xf9\xd3\xf7\xeb\x27\x6a\xb0\x45\xdc\x45\xab\x53\x28\x65\xa5\x02\xce\x30\x42\xc1\x1c\xa2\x75\x4c\xdc\xe4\x1d\xb6\x5a\x26\xb2\x13\xcf\x23\xf7\xb8\xa8\xc9\x17\x6f\xfa\xf5\xc5\x47\xd1\xe3\xed\x39\x5d\xa5\xe6\x89\xde\xb4\x20\x2d\x08\xce\x1c\x3c\x25\xa2\xca\x0b\xa6\xbd\x76\x78\xd4\x0e\xb6\xda\xac\x17\xf8\x94\x06\xe2\x26\xaf\xa6\xbd\xf9\x1a\x18\xe8\x70\x48\x44\x06\xd7\x6e\x33\x76\x6e\xd6\xfa\x21\xe7\x85\x9b\x27\xa2\x1b\xec\xb4\xf3\x67\xc4\x76\x8b\xf8\x1d\x7c\x72\xcc\x97\x7b\xc4\xc0\x81\x0d\xf6\x96\x1f\xb0\xfd\xe8\x19\x05\x81\xff\xce\x1f\x3d\x38\xfa\x1f\x5f\xfc\xda\xf9\x0e\x00\x00\xff\xff\xcb\xa9\x3f\x0f\x63\x01\x00\x00"),
+			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x8e\xb1\x6e\xc3\x30\x0c\x44\x77\x7d\x05\x91\xce\x76\xd0\xad\xf0\x0f\x74\xef\xd0\x9d\x91\xae\x09\x11\xd9\x14\x48\x2a\x05\xfa\xf5\x85\x5d\xa7\x4b\xd1\x21\x93\x1e\xee\x4e\x77\x7c\xa2\x06\x9b\xc5\x5d\x74\x71\x0a\xa5\xa2\x54\xc1\x05\x46\xa8\xc8\x21\xba\x8c\x89\x9b\xbc\xc3\xd6\xc8\x44\x76\xe2\x3c\x72\x8f\x8b\x9a\x7c\xf1\xe6\x5f\x5f\x7c\x14\x3d\xde\x9e\xd3\x55\x96\x32\xd1\x9b\x56\xa4\x19\xc1\x85\x83\xa7\x44\xb4\xf0\x8c\x69\xaf\x1d\xee\xb5\x83\xad\x31\xeb\x15\x3e\xa5\x81\xb8\xc9\xab\x69\x6f\xbe\x7e\x18\xe8\x70\x48\x44\x06\xd7\x6e\x19\xbb\x96\x75\xf9\x90\xf3\xcc\xcd\x13\xd1\x0d\x76\xda\xf5\x33\x62\x7b\xab\xf8\x0f\x7c\x72\xe4\xcb\x46\xd9\xc0\x81\x0d\x7b\x2b\x77\x6c\xbf\x7e\x41\x45\xe0\xd1\xf9\xa3\x07\x47\xff\xe7\x8a\x3f\x3b\xdf\x03\x00\xcb\xa9\x3f\x0f\x63\x01\x00\x00"),
 		},
 		"/leader_election_role_binding.yaml": &vfsgen۰CompressedFileInfo{
 			name:             "leader_election_role_binding.yaml",
 			modTime:          time.Time{},
 			uncompressedSize: 263,
 
-			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8d\x3d\x8a\xc3\x30\x10\x46\x7b\x9d\x62\x2e\x60\x2f\xdb\x2d\xea\x76\x9b\xed\x1d\x48\x3f\x96\x3e\x27\x13\xcb\x1a\xa1\x1f\x43\x72\xfa\x60\x70\x92\xce\xdd\x0c\xbc\xf7\x3d\x4e\x72\x46\x2e\xa2\xd1\x52\x1e\xd9\xf5\xdc\xea\x55\xb3\x3c\xb8\x8a\xc6\x7e\xfe\x29\xbd\xe8\xd7\xfa\x6d\x66\x89\xde\xd2\xa0\x01\x7f\x12\xbd\xc4\x8b\x59\x50\xd9\x73\x65\x6b\x88\x22\x2f\xb0\x14\xc0\x1e\xb9\x43\x80\xdb\xec\x2e\x6b\xc0\xb8\xd3\xdb\x3d\x60\xda\x60\x4e\xf2\x9f\xb5\xa5\x83\xa2\x21\xfa\x04\x0f\xf7\x4d\x69\xe3\x0d\xae\x16\x6b\xba\xdd\x39\x21\xaf\xe2\xf0\xeb\x9c\xb6\x58\xdf\xb6\xc7\xc4\x2d\xbc\xfe\x92\xd8\xc1\x52\xb9\x97\x8a\xc5\x3c\x03\x00\x00\xff\xff\xc0\xbe\x81\x75\x07\x01\x00\x00"),
+			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8d\x3d\x8a\xc3\x30\x10\x46\x7b\x9d\x62\x2e\x60\x2f\xdb\x2d\xea\x76\x9b\xed\x1d\x48\x3f\x96\x3e\x27\x13\xcb\x1a\xa1\x1f\x43\x72\xfa\x60\x70\x92\xce\xdd\x0c\xbc\xf7\x3d\x4e\x72\x46\x2e\xa2\xd1\x52\x1e\xd9\xf5\xdc\xea\x55\xb3\x3c\xb8\x8a\xc6\x7e\xfe\x29\xbd\xe8\xd7\xfa\x6d\x66\x89\xde\xd2\xa0\x01\x7f\x12\xbd\xc4\x8b\x59\x50\xd9\x73\x65\x6b\x88\x22\x2f\xb0\x14\xc0\x1e\xb9\x43\x80\xdb\xec\x2e\x6b\xc0\xb8\xd3\xdb\x3d\x60\xda\x60\x4e\xf2\x9f\xb5\xa5\x83\xa2\x21\xfa\x04\x0f\xf7\x4d\x69\xe3\x0d\xae\x16\x6b\xba\xdd\x39\x21\xaf\xe2\xf0\xeb\x9c\xb6\x58\xdf\xb6\xc7\xc4\x2d\xbc\xfe\x92\xd8\xc1\x52\xb9\x97\x8a\xc5\x3c\x07\x00\xc0\xbe\x81\x75\x07\x01\x00\x00"),
 		},
 		"/role.yaml": &vfsgen۰CompressedFileInfo{
 			name:             "role.yaml",
 			modTime:          time.Time{},
-			uncompressedSize: 3895,
+			uncompressedSize: 4581,
 
-			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcc\x97\xc1\x6e\xdb\x30\x0c\x86\xef\x7e\x0a\xa1\x97\x02\x03\x9c\x62\xb7\x21\xd7\x1d\x76\x1f\x86\xdd\x69\x89\x75\xb8\x4a\xa2\x40\x49\x69\xd3\xa7\x1f\x6c\xcb\x8b\x6b\x37\xed\xda\x26\x69\x4e\x96\x69\x89\xfc\xf8\x53\x92\xa5\xaa\xae\xeb\x0a\x02\xfd\x46\x89\xc4\x7e\xad\xa4\x01\xbd\x82\x9c\x36\x2c\xf4\x08\x89\xd8\xaf\xee\xbe\xc5\x15\xf1\xcd\xf6\x6b\x75\x47\xde\xac\xd5\x77\x9b\x63\x42\xf9\xc9\x16\x2b\x87\x09\x0c\x24\x58\x57\x4a\x69\xc1\x7e\xc0\x2f\x72\x18\x13\xb8\xb0\x56\x3e\x5b\x5b\x29\xe5\xc1\xe1\x5a\x39\xf0\xd0\xa2\xd4\xd2\x0d\x94\x6c\x31\xae\xab\x5a\x41\xa0\x1f\xc2\x39\xc4\xce\x45\xad\xae\xae\x2a\xa5\x04\x23\x67\xd1\x58\x6c\x9a\xfd\x2d\xb5\x0e\x42\xac\x94\xda\xa2\x34\xa3\xbd\x0b\x88\x7d\xd3\xa0\xc5\xd2\x6c\x31\xf5\x4f\x4b\x71\x68\x04\x48\x7a\xd3\xb7\x72\x30\xe3\x80\xfb\xde\xf8\xf6\xf0\xb5\x8a\xa8\x05\xd3\xe7\xa0\xa0\x37\x81\xc9\xa7\x81\xa4\x93\x35\x06\xd0\x58\x5e\xd9\x4c\x5b\x37\x41\xf8\x61\xf7\x14\x73\x01\xf4\x96\xd8\x5b\xf4\x2f\xa4\xfd\x11\xd7\xa1\x9b\x7d\x31\xa1\x4f\x5b\xb6\xd9\xa1\xb6\x40\x6e\x48\x25\xb0\x19\x65\x97\x2d\x69\x04\xad\x39\x8f\x02\x14\xdb\xe7\xd4\x62\x09\x74\x06\x86\xb1\x0c\x65\x51\x9e\xac\x4a\x10\xfa\xd9\x3e\x73\x6e\x00\x1d\xfb\x88\x45\x7e\xc1\x60\x49\xc3\xbf\xf7\x98\x20\xe1\x6d\xb6\xf1\x1c\xcb\xa3\x10\xd6\x0a\x1f\x12\xfa\x6e\xf3\x7a\x0d\xf8\x12\x78\x30\x58\xde\xb9\x17\x4b\x74\x56\xa0\xb3\x96\xac\x29\x7d\x67\x0c\x7f\xb8\x39\x7d\x6c\xcd\x2c\x86\xfc\xf4\x8f\xb6\x24\xb1\x08\x71\xbe\x9b\x5c\x7f\xb9\x5e\x7a\x7b\xc7\x22\xfc\xdf\x95\xf7\xa4\x5a\xb5\xf2\x98\xee\x59\xee\xc8\xb7\x07\xa3\x91\x6f\x05\xe3\x02\xfc\x04\x22\xce\xd8\x02\x5b\xd2\xbb\x67\xb6\x73\x36\x11\x75\x16\x4a\xbb\xbe\x0b\x1d\x13\x2d\xc7\x17\x11\x2d\xb7\x2d\xf9\xb6\xde\xa3\xae\x1a\xf0\x8f\x40\xda\x72\x36\x87\xab\x95\x80\x2c\xca\xe9\x25\xfc\x20\xdf\x4d\xb7\x60\xf3\x81\xa9\xb5\xe0\x38\x46\xf4\x0d\xc7\x4b\x16\x67\x82\x77\x1c\x6d\x5e\x8d\xa8\x87\xe3\xef\xad\xe5\xfb\x38\x35\x70\x4e\x21\x97\x3f\xe1\xfe\x63\xf1\x3a\xbc\xec\xbb\x9c\x45\xc7\x37\xa5\xb2\x57\x6f\x9e\xd1\xf4\xcb\xa2\xeb\x98\xdf\xd4\xb6\x18\xf7\xae\x7a\x38\xf6\x94\x58\xba\x3c\x34\x0b\x72\x5c\x69\x76\xcf\x6c\x36\xc2\x0e\xd3\x06\x73\xec\xaf\x14\xc3\x5f\x6d\x38\x9a\x15\x0f\xa7\xd7\xfb\xe0\xa5\xe9\xa0\xe4\xdd\x1d\xa8\x21\x6f\xca\xd4\xb8\x70\xbc\x27\xf6\x72\xf6\x9b\xf7\x18\x3f\x5d\x62\x2a\x47\xe7\xfa\x1b\x00\x00\xff\xff\x6e\xe9\x45\xb6\x37\x0f\x00\x00"),
+			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcc\x97\xcd\x6e\xdb\x30\x0c\xc7\xef\x7e\x0a\xa1\x97\x02\x03\x9c\x62\xb7\x21\xd7\x1d\x76\x1f\x86\xdd\x19\x99\x71\xb8\xca\xa2\x40\x51\x69\xd3\xa7\x1f\xec\xca\x8b\x63\xcf\xe9\x57\x92\xe6\x64\x99\xa2\xf4\xff\x89\xd4\x67\x51\x96\x65\x01\x81\x7e\xa3\x44\x62\xbf\x34\xb2\x02\xbb\x80\xa4\x1b\x16\x7a\x02\x25\xf6\x8b\xfb\x6f\x71\x41\x7c\xb7\xfd\x5a\xdc\x93\xaf\x96\xe6\xbb\x4b\x51\x51\x7e\xb2\xc3\xa2\x41\x85\x0a\x14\x96\x85\x31\x56\xb0\x6b\xf0\x8b\x1a\x8c\x0a\x4d\x58\x1a\x9f\x9c\x2b\x8c\xf1\xd0\xe0\xd2\x34\xe0\xa1\x46\x29\xa5\x6d\x28\xc9\x61\x5c\x16\xa5\x81\x40\x3f\x84\x53\x88\x6d\x17\xa5\xb9\xb9\x29\x8c\x11\x8c\x9c\xc4\x62\xb6\x59\xf6\x6b\xaa\x1b\x08\xb1\x30\x66\x8b\xb2\xea\xed\xad\x20\x76\xc5\x0a\x1d\xe6\x62\x8d\xda\x7d\x1d\xc5\xe7\x42\x00\xb5\x9b\xae\x94\x42\xd5\x37\x78\xe8\x8c\x6f\x97\x2f\x4d\x44\x2b\xa8\x9f\x83\x82\xbe\x0a\x4c\xbe\x53\x2f\xbb\xb0\xc6\x00\x16\xf3\x2f\x57\xc3\xd2\x5d\x10\x7e\xdc\x1d\x62\x4e\x80\xde\xa2\xbd\x45\x7f\x64\xd8\x1f\xe9\x3a\xb4\xb3\x2f\x2a\x7a\xdd\xb2\x4b\x0d\x5a\x07\xd4\xb4\x52\xa5\x09\x5c\xf5\x61\x97\x2d\x59\x04\x6b\x39\x79\x3d\xb0\x7d\x4e\x2e\xa6\x40\x17\x60\xe8\xd3\x90\x17\xe5\xc7\xb3\xb4\x27\x98\x91\x85\x10\xe2\x54\xa6\x02\x6c\xd8\x47\xcc\x89\x10\x0c\x8e\x2c\xfc\xfb\x8f\x0a\x8a\xeb\xe4\x22\x1e\x61\x39\x55\x60\x32\x61\x69\xf0\x51\xd1\xb7\xdb\xd8\x4b\xc0\xd7\xc0\x83\xc1\xf1\xae\x41\x7f\x2d\x40\x97\x4d\x59\x52\x8e\x16\x1c\xf9\x7a\x76\x26\x6f\x51\x94\x2c\xb8\xc0\x55\xef\x8e\x72\x7e\xb4\x55\xf6\x1d\xd1\xfc\xe1\xd5\xf9\xb5\x2d\x8a\x96\xf9\x98\xfc\x6f\x4c\x5a\x07\x5a\x93\x05\xc5\xcb\xd0\xf4\x62\xb3\x59\xb2\xcf\x97\x01\x95\x14\x75\x95\x7c\xe5\xc6\x60\x13\x84\x39\x35\x66\xa9\xc8\x0f\xaf\x1c\x53\x35\x87\x10\xc7\x02\xb7\x5f\x6e\xa7\xbd\xbd\x63\x97\x7c\x2d\xe8\xc1\x22\x2a\x8d\x47\x7d\x60\xb9\x3f\x36\x93\xc9\xd7\x82\x31\x5e\x20\x65\x23\xb6\xc0\x8e\xec\x6e\x0a\xd4\x1e\xaa\x68\x93\x90\xee\x3a\x17\x3a\x25\x5a\x8a\x47\x11\x1d\xd7\x35\xf9\xba\xdc\xa3\x2e\x56\xe0\x9f\x80\xac\xe3\x54\xcd\x67\x4b\x81\x2e\xb2\xfe\x3f\xc8\x77\xd7\xee\xa3\x69\x84\x59\xe3\x0c\xc7\x29\xd4\x37\x1c\xaf\x39\x38\x03\xbc\xd3\xc4\xe6\x45\xc5\xbc\x25\xad\x1d\x3f\xc4\xa1\x81\x93\x86\x94\x2f\x28\xfb\xca\xdc\xeb\xf3\xcf\xde\xe5\x22\x71\x7c\xd3\x50\xf6\xd1\x1b\x8f\x68\x58\x33\x71\xed\xc7\x37\xb4\x4d\xda\x9d\x35\x1f\xd9\x2f\x08\xaf\xe9\xdd\xc7\x43\xc3\x9e\x94\xa5\x0d\x9a\x65\x41\x8e\x0b\xcb\xcd\x54\x2b\x08\x37\xa8\x1b\x4c\xb1\x7b\x60\x0e\x2f\xea\xb9\x87\xf3\x27\xf7\x15\x27\x42\x76\x39\xfd\xe6\x3b\x83\x34\xfb\xaa\x9f\x9d\x72\xed\x23\x7d\x45\xbe\xca\x4b\xe3\xca\xf1\x0e\xec\xf9\x49\x32\xf6\xe8\xab\xae\x71\x28\xaf\xe2\xc2\xf6\x0a\x0c\xef\x80\xfc\x3b\x00\x04\xd5\xe7\x69\xe5\x11\x00\x00"),
 		},
 		"/role_binding.yaml": &vfsgen۰CompressedFileInfo{
 			name:             "role_binding.yaml",
 			modTime:          time.Time{},
 			uncompressedSize: 261,
 
-			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8d\xb1\x4e\x03\x31\x0c\x86\xf7\x3c\x85\x5f\xa0\x87\xd8\x50\x36\x60\x60\x2f\x12\xbb\x2f\x71\x8b\x69\x62\x47\xb6\x53\x09\x9e\x1e\x9d\x74\xb0\x80\xba\xd9\xd2\xf7\xfd\x1f\x0e\x7e\x23\x73\x56\xc9\x60\x2b\x96\x05\x67\xbc\xab\xf1\x17\x06\xab\x2c\x97\x07\x5f\x58\xef\xae\xf7\xe9\xc2\x52\x33\x3c\xb7\xe9\x41\x76\xd4\x46\x4f\x2c\x95\xe5\x9c\x3a\x05\x56\x0c\xcc\x09\x40\xb0\x53\x86\x8e\x82\x67\xb2\x83\x69\xa3\x75\xa7\xb6\xfb\x48\xa7\x0d\xc2\xc1\x2f\xa6\x73\xdc\x08\x26\x80\x3f\xbd\x7f\xe7\x93\xcf\xf5\x83\x4a\x78\x4e\x87\x5d\x79\x25\xbb\x72\xa1\xc7\x52\x74\x4a\xfc\x5a\x95\x4e\x38\xdb\xcf\xef\x03\x0b\x65\xf0\x4f\x0f\xea\xe9\x3b\x00\x00\xff\xff\x3d\x57\xd3\xb6\x05\x01\x00\x00"),
+			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8d\xb1\x4e\x03\x31\x0c\x86\xf7\x3c\x85\x5f\xa0\x87\xd8\x50\x36\x60\x60\x2f\x12\xbb\x2f\x71\x8b\x69\x62\x47\xb6\x53\x09\x9e\x1e\x9d\x74\xb0\x80\xba\xd9\xd2\xf7\xfd\x1f\x0e\x7e\x23\x73\x56\xc9\x60\x2b\x96\x05\x67\xbc\xab\xf1\x17\x06\xab\x2c\x97\x07\x5f\x58\xef\xae\xf7\xe9\xc2\x52\x33\x3c\xb7\xe9\x41\x76\xd4\x46\x4f\x2c\x95\xe5\x9c\x3a\x05\x56\x0c\xcc\x09\x40\xb0\x53\x86\x8e\x82\x67\xb2\x83\x69\xa3\x75\xa7\xb6\xfb\x48\xa7\x0d\xc2\xc1\x2f\xa6\x73\xdc\x08\x26\x80\x3f\xbd\x7f\xe7\x93\xcf\xf5\x83\x4a\x78\x4e\x87\x5d\x79\x25\xbb\x72\xa1\xc7\x52\x74\x4a\xfc\x5a\x95\x4e\x38\xdb\xcf\xef\x03\x0b\x65\xf0\x4f\x0f\xea\xe9\x7b\x00\x3d\x57\xd3\xb6\x05\x01\x00\x00"),
 		},
 	}
 	fs["/"].(*vfsgen۰DirInfo).entries = []os.FileInfo{